@@ -11,8 +11,11 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/ace-platform/api-gateway/internal/bootstrap"
+	"github.com/ace-platform/api-gateway/internal/clone"
 	"github.com/ace-platform/api-gateway/internal/db"
 	"github.com/ace-platform/api-gateway/internal/handlers"
+	"github.com/ace-platform/api-gateway/internal/itemstats"
+	"github.com/ace-platform/api-gateway/internal/sweeper"
 )
 
 func main() {
@@ -27,6 +30,10 @@ func main() {
 	}
 	defer pool.Close()
 
+	if err := db.CheckSchema(context.Background(), pool); err != nil {
+		log.Fatal(err)
+	}
+
 	if err := bootstrap.UsersFromEnv(context.Background(), pool); err != nil {
 		log.Fatal(err)
 	}
@@ -59,7 +66,8 @@ func main() {
 		}
 		h.Set("Access-Control-Allow-Credentials", "true")
 		h.Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		h.Set("Access-Control-Allow-Headers", "Content-Type,Accept,Authorization,X-CSRF-Token")
+		h.Set("Access-Control-Allow-Headers", "Content-Type,Accept,Authorization,X-CSRF-Token,Idempotency-Key")
+		h.Set("Access-Control-Expose-Headers", "Idempotent-Replayed")
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
@@ -103,6 +111,18 @@ func main() {
 	handlers.RegisterExamRoutes(r, pool)
 	handlers.RegisterQuestionRoutes(r, pool)
 	handlers.RegisterAdminRoutes(r, pool)
+	handlers.RegisterNotificationRoutes(r, pool)
+	handlers.RegisterGamificationRoutes(r, pool)
+	handlers.RegisterLeaderboardRoutes(r, pool)
+
+	if n, err := clone.FailStale(context.Background(), pool, clone.JobTimeout()); err != nil {
+		log.Printf("clone: failed to clear stale jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("clone: marked %d stale jobs failed", n)
+	}
+
+	go itemstats.RunNightly(context.Background(), pool)
+	go sweeper.Run(context.Background(), pool, sweeper.ConfigFromEnv())
 
 	if err := r.Run(":" + port); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ace-platform/api-gateway/internal/db"
+	"github.com/ace-platform/api-gateway/internal/irt"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	examPackageID := flag.String("exam-package", "", "Exam package id to calibrate (required)")
+	model := flag.String("model", string(irt.Model2PL), "IRT model: 2pl or 3pl")
+	minResponses := flag.Int("min-responses", irt.DefaultMinResponses(), "Minimum scored responses per question")
+	flag.Parse()
+
+	if strings.TrimSpace(*examPackageID) == "" {
+		usageAndExit("--exam-package is required")
+	}
+	m := irt.Model(strings.ToLower(strings.TrimSpace(*model)))
+	if m != irt.Model2PL && m != irt.Model3PL {
+		usageAndExit(fmt.Sprintf("unknown model %q", *model))
+	}
+	if *minResponses < 1 {
+		usageAndExit("--min-responses must be at least 1")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	req := irt.CalibrationRequest{ExamPackageID: strings.TrimSpace(*examPackageID), Model: m, MinResponses: *minResponses}
+	id, err := irt.StartCalibration(ctx, pool, req)
+	if err != nil {
+		log.Fatalf("start calibration: %v", err)
+	}
+
+	start := time.Now()
+	log.Printf("calibration %s: fitting %s for exam package %s (min responses %d) ...", id, m, req.ExamPackageID, req.MinResponses)
+	if err := irt.RunCalibration(ctx, pool, id, req); err != nil {
+		log.Fatalf("calibration %s failed: %v", id, err)
+	}
+
+	var items, skipped, persons, responses, iterations int
+	var converged bool
+	_ = pool.QueryRow(ctx, `select items_calibrated, items_skipped, persons, responses, iterations, converged from irt_calibrations where id=$1`, id).
+		Scan(&items, &skipped, &persons, &responses, &iterations, &converged)
+	log.Printf("calibration %s: %d questions calibrated, %d below threshold, %d students, %d responses, %d iterations (converged=%v) in %s",
+		id, items, skipped, persons, responses, iterations, converged, time.Since(start).Round(time.Millisecond))
+}
+
+func usageAndExit(msg string) {
+	if msg != "" {
+		fmt.Fprintln(os.Stderr, "error:", msg)
+	}
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  irt-calibrate --exam-package ID [--model 2pl|3pl] [--min-responses N]")
+	os.Exit(2)
+}
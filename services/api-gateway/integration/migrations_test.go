@@ -13,6 +13,8 @@ import (
 	"net/url"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/db"
 )
 
 func TestMigrateCLI_AppliesSchemaAndSeeds(t *testing.T) {
@@ -60,6 +62,13 @@ func TestMigrateCLI_AppliesSchemaAndSeeds(t *testing.T) {
 	assertTableExists(t, ctx2, pool, "users")
 	assertTableExists(t, ctx2, pool, "exam_packages")
 	assertTableExists(t, ctx2, pool, "question_bank_difficulties")
+	assertTableExists(t, ctx2, pool, "question_bank_topics")
+	assertTableExists(t, ctx2, pool, "exam_package_question_bank_packages")
+
+	// The gateway refuses to boot unless the migrated schema matches what the handlers query.
+	if err := db.CheckSchema(ctx2, pool); err != nil {
+		t.Fatalf("%v", err)
+	}
 
 	var pkgCount int
 	if err := pool.QueryRow(ctx2, `select count(*) from exam_packages`).Scan(&pkgCount); err != nil {
@@ -121,6 +130,42 @@ func dropTestDatabase(ctx context.Context, adminURL string, dbName string) error
 	return err
 }
 
+// newMigratedPool migrates a fresh database, dropped when the test ends, and
+// connects to it.
+func newMigratedPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	baseURL := os.Getenv("DATABASE_URL")
+	if baseURL == "" {
+		t.Fatalf("DATABASE_URL is required (run via docker compose so the test can reach the db service)")
+	}
+	adminURL, testURL, dbName, err := prepareTestDatabase(ctx, baseURL)
+	if err != nil {
+		t.Fatalf("prepare test db: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = dropTestDatabase(context.Background(), adminURL, dbName)
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	cmd := exec.CommandContext(ctx, "go", "run", "./cmd/migrate", "--database", testURL, "--path", "./migrations", "up")
+	cmd.Dir = filepath.Clean(filepath.Join(wd, ".."))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("migrate up failed: %v\n%s", err, string(out))
+	}
+
+	pool, err := pgxpool.New(ctx, testURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
 func assertTableExists(t *testing.T, ctx context.Context, pool *pgxpool.Pool, table string) {
 	t.Helper()
 	var regclass string
@@ -0,0 +1,368 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/handlers"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+)
+
+func TestPracticeAnswers_ConcurrentSubmitsRecordOneAnswer(t *testing.T) {
+	env := newPracticeAnswerEnv(t)
+
+	const submits = 8
+	codes := env.submitConcurrently(t, submits, "q1-a", "")
+
+	accepted, conflicts := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			accepted++
+		case http.StatusConflict:
+			conflicts++
+		default:
+			t.Fatalf("unexpected status %d", code)
+		}
+	}
+	if accepted != 1 || conflicts != submits-1 {
+		t.Fatalf("expected 1 accepted and %d conflicts, got %d and %d", submits-1, accepted, conflicts)
+	}
+	env.assertSession(t, 1, 1, 1)
+}
+
+func TestPracticeAnswers_IdempotencyKeyReplaysResponse(t *testing.T) {
+	env := newPracticeAnswerEnv(t)
+
+	codes := env.submitConcurrently(t, 4, "q1-a", "key-1")
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("every request with the same key should succeed, got %v", codes)
+		}
+	}
+	env.assertSession(t, 1, 1, 1)
+
+	// A retry after the session moved on still gets the original response.
+	retry, _ := env.submit(t, "q1", "q1-a", "key-1")
+	again, replayed := env.submit(t, "q1", "q1-a", "key-1")
+	if retry.Code != http.StatusOK || again.Code != http.StatusOK || !bytes.Equal(retry.Body, again.Body) || !replayed {
+		t.Fatalf("expected identical replayed responses, got %d %s and %d %s", retry.Code, retry.Body, again.Code, again.Body)
+	}
+	env.assertSession(t, 1, 1, 1)
+
+	if res, _ := env.submit(t, "q1", "q1-b", "key-1"); res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reusing a key for another answer should get 422, got %d", res.Code)
+	}
+	if res, _ := env.submit(t, "q2", "q2-a", "key-2"); res.Code != http.StatusOK {
+		t.Fatalf("a new key should answer the next question, got %d %s", res.Code, res.Body)
+	}
+	env.assertSession(t, 2, 2, 1)
+}
+
+func TestPracticeAnswers_SyncAppliesQueueOnce(t *testing.T) {
+	env := newPracticeAnswerEnv(t)
+
+	queue := []map[string]any{
+		{"seq": 1, "questionId": "q1", "choiceId": "q1-a", "durationSeconds": 3600},
+		{"seq": 2, "questionId": "q2", "choiceId": "q2-b"},
+	}
+	first := env.sync(t, queue)
+	if first.Results[0].Status != "applied" || first.Results[1].Status != "applied" {
+		t.Fatalf("expected both answers applied, got %+v", first.Results)
+	}
+	// The session started moments ago, so an hour cannot be credited.
+	if first.Results[0].CreditedSeconds > 60 {
+		t.Fatalf("credited time should be bounded by the server clock, got %d", first.Results[0].CreditedSeconds)
+	}
+	if first.Session.Status != "finished" {
+		t.Fatalf("expected the session to finish, got %q", first.Session.Status)
+	}
+	env.assertSession(t, 2, 2, 1)
+
+	// Resending the queue after a lost response applies nothing twice.
+	again := env.sync(t, append(queue, map[string]any{"seq": 3, "questionId": "q1", "choiceId": "q1-b"}))
+	got := []string{again.Results[0].Status, again.Results[1].Status, again.Results[2].Status}
+	if got[0] != "duplicate" || got[1] != "duplicate" || got[2] != "conflict" {
+		t.Fatalf("expected duplicate, duplicate, conflict; got %v", got)
+	}
+	env.assertSession(t, 2, 2, 1)
+}
+
+func TestPracticeAnswers_FinishAwardsCoinsOnce(t *testing.T) {
+	env := newPracticeAnswerEnv(t)
+
+	for _, a := range [][2]string{{"q1", "q1-a"}, {"q2", "q2-b"}} {
+		if res, _ := env.submit(t, a[0], a[1], ""); res.Code != http.StatusOK {
+			t.Fatalf("answer %s: got %d %s", a[0], res.Code, res.Body)
+		}
+	}
+	var userID string
+	if err := env.pool.QueryRow(env.ctx, `select user_id from practice_sessions where id=$1`, env.sessionID).Scan(&userID); err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	// Replaying the finish event pays nothing more.
+	if err := gamification.PracticeFinished(env.ctx, env.pool, userID, env.sessionID); err != nil {
+		t.Fatalf("replay award: %v", err)
+	}
+
+	var balance, entries int
+	if err := env.pool.QueryRow(env.ctx, `select coalesce(sum(amount), 0), count(*) from coin_ledger where user_id=$1`, userID).Scan(&balance, &entries); err != nil {
+		t.Fatalf("load ledger: %v", err)
+	}
+	// Session, first streak day and the first-practice achievement.
+	want := gamification.PracticeFinishedCoins + gamification.StreakDayCoins + 10
+	if balance != want || entries != 3 {
+		t.Fatalf("expected %d coins in 3 entries, got %d in %d", want, balance, entries)
+	}
+	var streak int
+	if err := env.pool.QueryRow(env.ctx, `select current_days from user_streaks where user_id=$1`, userID).Scan(&streak); err != nil || streak != 1 {
+		t.Fatalf("expected a 1-day streak, got %d (%v)", streak, err)
+	}
+}
+
+func TestPracticeAnswers_FinishJoinsLeaderboardOnce(t *testing.T) {
+	env := newPracticeAnswerEnv(t)
+
+	for _, a := range [][2]string{{"q1", "q1-a"}, {"q2", "q2-b"}} {
+		if res, _ := env.submit(t, a[0], a[1], ""); res.Code != http.StatusOK {
+			t.Fatalf("answer %s: got %d %s", a[0], res.Code, res.Body)
+		}
+	}
+	if err := leaderboard.RecordPractice(env.ctx, env.pool, "u-student", env.sessionID); err != nil {
+		t.Fatalf("replay record: %v", err)
+	}
+
+	board, err := leaderboard.Load(env.ctx, env.pool, leaderboard.Query{
+		Scope: leaderboard.ScopeCohort, ScopeID: "coh-none", Window: leaderboard.WindowAll, At: time.Now(), UserID: "u-student", Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("load cohort board: %v", err)
+	}
+	if len(board.Items) != 0 || board.Me != nil {
+		t.Fatalf("a student outside the cohort should not be ranked, got %+v", board)
+	}
+
+	if _, err := env.pool.Exec(env.ctx, `insert into cohorts (id, name) values ('coh-1', 'Cohort 1')`); err != nil {
+		t.Fatalf("seed cohort: %v", err)
+	}
+	if _, err := env.pool.Exec(env.ctx, `insert into cohort_members (cohort_id, user_id) values ('coh-1', 'u-student')`); err != nil {
+		t.Fatalf("seed membership: %v", err)
+	}
+	board, err = leaderboard.Load(env.ctx, env.pool, leaderboard.Query{
+		Scope: leaderboard.ScopeCohort, ScopeID: "coh-1", Window: leaderboard.WindowWeek, At: time.Now(), UserID: "u-student", Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("load cohort board: %v", err)
+	}
+	if board.Me == nil || board.Me.Rank != 1 || board.Me.Score != 1 || board.Me.Sessions != 1 {
+		t.Fatalf("expected rank 1 with one correct answer in one session, got %+v", board.Me)
+	}
+	practiceSeconds := board.Me.DurationSeconds
+
+	// An exam counts, but adds no points and so no time to break ties with.
+	if _, err := env.pool.Exec(env.ctx, `insert into exam_sessions (user_id, id, status, snapshot, created_at, submitted_at)
+		values ('u-student', 'ex-1', 'finished', '{}', now() - interval '1 hour', now())`); err != nil {
+		t.Fatalf("seed exam: %v", err)
+	}
+	if err := leaderboard.RecordExam(env.ctx, env.pool, "u-student", "ex-1"); err != nil {
+		t.Fatalf("record exam: %v", err)
+	}
+	board, err = leaderboard.Load(env.ctx, env.pool, leaderboard.Query{
+		Scope: leaderboard.ScopeCohort, ScopeID: "coh-1", Window: leaderboard.WindowWeek, At: time.Now(), UserID: "u-student", Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("load cohort board: %v", err)
+	}
+	if board.Me == nil || board.Me.Exams != 1 || board.Me.Score != 1 || board.Me.DurationSeconds != practiceSeconds {
+		t.Fatalf("expected the exam counted without points or time, got %+v", board.Me)
+	}
+
+	if _, err := env.pool.Exec(env.ctx, `update users set leaderboard_opt_out=true where id='u-student'`); err != nil {
+		t.Fatalf("opt out: %v", err)
+	}
+	board, err = leaderboard.Load(env.ctx, env.pool, leaderboard.Query{
+		Scope: leaderboard.ScopeCohort, ScopeID: "coh-1", Window: leaderboard.WindowWeek, At: time.Now(), UserID: "u-student", Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("load cohort board: %v", err)
+	}
+	if !board.OptedOut || board.Me != nil || len(board.Items) != 0 {
+		t.Fatalf("an opted-out student should be hidden, got %+v", board)
+	}
+}
+
+type practiceAnswerEnv struct {
+	ctx       context.Context
+	pool      *pgxpool.Pool
+	server    *httptest.Server
+	token     string
+	sessionID string
+}
+
+type answerResult struct {
+	Code int
+	Body []byte
+}
+
+// newPracticeAnswerEnv migrates a fresh database and seeds a student with a
+// linear session of two questions: q1 (correct choice q1-a) and q2 (q2-a).
+func newPracticeAnswerEnv(t *testing.T) *practiceAnswerEnv {
+	t.Helper()
+	ctx := context.Background()
+	pool := newMigratedPool(t)
+
+	seed := []string{
+		`insert into users (id, email, password_hash, role) values ('u-student', 'student@example.com', 'x', 'student')`,
+		`insert into question_banks (id, name) values ('qb-1', 'Bank')`,
+		`insert into question_bank_questions (id, package_id, prompt, explanation_text, status) values
+			('q1', 'qb-1', 'One?', 'Because.', 'published'), ('q2', 'qb-1', 'Two?', 'Because.', 'published')`,
+		`insert into question_bank_choices (id, question_id, order_index, text) values
+			('q1-a', 'q1', 0, 'A'), ('q1-b', 'q1', 1, 'B'), ('q2-a', 'q2', 0, 'A'), ('q2-b', 'q2', 1, 'B')`,
+		`insert into practice_sessions (id, user_id, is_timed, started_at, target_count, status, question_order, questions_snapshot, current_question_started_at, question_timings)
+			values ('ps-1', 'u-student', false, now(), 2, 'active', '["q1","q2"]', $1, now(), '{}')`,
+	}
+	snapshot := `[
+		{"id": "q1", "prompt": "One?", "choices": [{"id": "q1-a", "text": "A"}, {"id": "q1-b", "text": "B"}], "correctChoiceId": "q1-a", "explanation": "Because."},
+		{"id": "q2", "prompt": "Two?", "choices": [{"id": "q2-a", "text": "A"}, {"id": "q2-b", "text": "B"}], "correctChoiceId": "q2-a", "explanation": "Because."}
+	]`
+	for i, stmt := range seed {
+		var args []any
+		if i == len(seed)-1 {
+			args = append(args, snapshot)
+		}
+		if _, err := pool.Exec(ctx, stmt, args...); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	t.Setenv("JWT_SECRET", "integration-test-secret")
+	token, err := auth.IssueAccessToken("u-student", "student", "student", "", time.Hour)
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handlers.RegisterPracticeRoutes(r, pool)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return &practiceAnswerEnv{ctx: ctx, pool: pool, server: server, token: token, sessionID: "ps-1"}
+}
+
+// submit answers a question, returning the response and whether it was a replay.
+func (e *practiceAnswerEnv) submit(t *testing.T, questionID, choiceID, key string) (answerResult, bool) {
+	t.Helper()
+	res, replayed, err := e.post(questionID, choiceID, key)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	return res, replayed
+}
+
+func (e *practiceAnswerEnv) post(questionID, choiceID, key string) (answerResult, bool, error) {
+	body, _ := json.Marshal(map[string]string{"questionId": questionID, "choiceId": choiceID})
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/practice-sessions/"+e.sessionID+"/answers", bytes.NewReader(body))
+	if err != nil {
+		return answerResult{}, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.token)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return answerResult{}, false, err
+	}
+	defer res.Body.Close()
+	raw, err := io.ReadAll(res.Body)
+	return answerResult{Code: res.StatusCode, Body: raw}, res.Header.Get("Idempotent-Replayed") == "true", err
+}
+
+type syncResult struct {
+	Results []struct {
+		Status          string `json:"status"`
+		CreditedSeconds int    `json:"creditedSeconds"`
+	} `json:"results"`
+	Session struct {
+		Status string `json:"status"`
+	} `json:"session"`
+}
+
+// sync sends an offline answer queue and decodes the response.
+func (e *practiceAnswerEnv) sync(t *testing.T, answers []map[string]any) syncResult {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"answers": answers})
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/practice-sessions/"+e.sessionID+"/sync", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	defer res.Body.Close()
+	raw, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("sync: status %d %s", res.StatusCode, raw)
+	}
+	var out syncResult
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode sync response: %v", err)
+	}
+	return out
+}
+
+// submitConcurrently sends n identical answers to q1 at once and returns
+// their status codes.
+func (e *practiceAnswerEnv) submitConcurrently(t *testing.T, n int, choiceID, key string) []int {
+	t.Helper()
+	codes := make([]int, n)
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			res, _, err := e.post("q1", choiceID, key)
+			codes[i], errs[i] = res.Code, err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	return codes
+}
+
+func (e *practiceAnswerEnv) assertSession(t *testing.T, wantAnswers, wantIndex, wantCorrect int) {
+	t.Helper()
+	var answers, index, correct int
+	if err := e.pool.QueryRow(e.ctx, `select (select count(*) from practice_answers where session_id=$1), current_index, correct_count from practice_sessions where id=$1`, e.sessionID).
+		Scan(&answers, &index, &correct); err != nil {
+		t.Fatalf("load session: %v", err)
+	}
+	if answers != wantAnswers || index != wantIndex || correct != wantCorrect {
+		t.Fatalf("expected %d answers, index %d, %d correct; got %d, %d, %d", wantAnswers, wantIndex, wantCorrect, answers, index, correct)
+	}
+}
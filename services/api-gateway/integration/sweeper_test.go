@@ -0,0 +1,160 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/sweeper"
+)
+
+var sweepConfig = sweeper.Config{Interval: time.Minute, HeartbeatTimeout: 30 * time.Minute, StaleExamAction: sweeper.ActionSubmit}
+
+func TestSweeper_FinalizesOverdueSessions(t *testing.T) {
+	ctx := context.Background()
+	pool := newSweeperEnv(t)
+
+	res, err := sweeper.Sweep(ctx, pool, sweepConfig)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if res != (sweeper.Result{PracticeExpired: 2, ExamsSubmitted: 1}) {
+		t.Fatalf("expected 2 expired practice sessions and 1 submitted exam, got %+v", res)
+	}
+	assertStatuses(t, pool, map[string]string{
+		"ps-late-1": "finished", "ps-late-2": "finished", "ps-running": "active",
+		"ex-stale": "finished", "ex-live": "active",
+	})
+
+	var events, audits int
+	if err := pool.QueryRow(ctx, `select
+			(select count(*) from practice_session_events where event_type='expired') + (select count(*) from exam_session_events where event_type='auto_submitted'),
+			(select count(*) from audit_log where actor_role='system')`).Scan(&events, &audits); err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if events != 3 || audits != 3 {
+		t.Fatalf("expected 3 events and 3 audit entries, got %d and %d", events, audits)
+	}
+
+	res, err = sweeper.Sweep(ctx, pool, sweepConfig)
+	if err != nil || res != (sweeper.Result{}) {
+		t.Fatalf("a second sweep should find nothing, got %+v (%v)", res, err)
+	}
+}
+
+func TestSweeper_SkipsSessionsLockedByAnotherTransaction(t *testing.T) {
+	ctx := context.Background()
+	pool := newSweeperEnv(t)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `select 1 from practice_sessions where id='ps-late-1' for update`); err != nil {
+		t.Fatalf("lock practice session: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `select 1 from exam_sessions where id='ex-stale' for update`); err != nil {
+		t.Fatalf("lock exam session: %v", err)
+	}
+
+	res, err := sweeper.Sweep(ctx, pool, sweepConfig)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if res != (sweeper.Result{PracticeExpired: 1}) {
+		t.Fatalf("expected only the unlocked practice session to expire, got %+v", res)
+	}
+	assertStatuses(t, pool, map[string]string{"ps-late-1": "active", "ps-late-2": "finished", "ex-stale": "active"})
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	res, err = sweeper.Sweep(ctx, pool, sweepConfig)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if res != (sweeper.Result{PracticeExpired: 1, ExamsSubmitted: 1}) {
+		t.Fatalf("expected the released sessions to be swept, got %+v", res)
+	}
+	assertStatuses(t, pool, map[string]string{"ps-late-1": "finished", "ex-stale": "finished"})
+}
+
+func TestSweeper_AdvisoryLockKeepsSweepsApart(t *testing.T) {
+	ctx := context.Background()
+	pool := newSweeperEnv(t)
+
+	// Another replica is mid-sweep.
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, `select pg_advisory_lock($1)`, sweeper.AdvisoryLockKey); err != nil {
+		t.Fatalf("take lock: %v", err)
+	}
+
+	res, swept, err := sweeper.TrySweep(ctx, pool, sweepConfig)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if swept || res != (sweeper.Result{}) {
+		t.Fatalf("a sweep should not run while the lock is held, got %v %+v", swept, res)
+	}
+	assertStatuses(t, pool, map[string]string{"ps-late-1": "active", "ps-late-2": "active", "ex-stale": "active"})
+
+	if _, err := conn.Exec(ctx, `select pg_advisory_unlock($1)`, sweeper.AdvisoryLockKey); err != nil {
+		t.Fatalf("release lock: %v", err)
+	}
+	res, swept, err = sweeper.TrySweep(ctx, pool, sweepConfig)
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if !swept || res != (sweeper.Result{PracticeExpired: 2, ExamsSubmitted: 1}) {
+		t.Fatalf("expected a full sweep once the lock is free, got %v %+v", swept, res)
+	}
+}
+
+// newSweeperEnv migrates a fresh database and seeds a student with two timed
+// practice sessions past their limit (ps-late-1, ps-late-2), one still
+// running (ps-running), an exam whose heartbeats stopped an hour ago
+// (ex-stale) and a live one (ex-live).
+func newSweeperEnv(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	pool := newMigratedPool(t)
+
+	seed := []string{
+		`insert into users (id, email, password_hash, role) values ('u-student', 'student@example.com', 'x', 'student')`,
+		`insert into practice_sessions (id, user_id, is_timed, time_limit_seconds, started_at, target_count, status, question_order, questions_snapshot, question_timings) values
+			('ps-late-1', 'u-student', true, 60, now() - interval '2 hours', 1, 'active', '[]', '[]', '{}'),
+			('ps-late-2', 'u-student', true, 60, now() - interval '1 hour', 1, 'active', '[]', '[]', '{}'),
+			('ps-running', 'u-student', true, 3600, now(), 1, 'active', '[]', '[]', '{}')`,
+		`insert into exam_sessions (user_id, id, status, snapshot, created_at, last_heartbeat_at) values
+			('u-student', 'ex-stale', 'active', '{}', now() - interval '2 hours', now() - interval '1 hour'),
+			('u-student', 'ex-live', 'active', '{}', now(), now())`,
+	}
+	for _, stmt := range seed {
+		if _, err := pool.Exec(context.Background(), stmt); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return pool
+}
+
+// assertStatuses checks the status of practice (ps-) and exam (ex-) sessions.
+func assertStatuses(t *testing.T, pool *pgxpool.Pool, want map[string]string) {
+	t.Helper()
+	for id, status := range want {
+		var got string
+		err := pool.QueryRow(context.Background(), `select status from practice_sessions where id=$1
+			union all select status from exam_sessions where id=$1`, id).Scan(&got)
+		if err != nil {
+			t.Fatalf("load %s: %v", id, err)
+		}
+		if got != status {
+			t.Fatalf("expected %s to be %s, got %s", id, status, got)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package blueprint describes multi-section practice sessions: a template
+// lists sections, each filled from per-topic and per-difficulty quotas and
+// with its own time limit and ordering rule.
+//
+// A blueprint session lays its sections out back to back in question_order;
+// Boundaries records where each one starts.
+package blueprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// MaxQuestions caps the total size of a blueprint.
+const MaxQuestions = 200
+
+// Ordering rules for the questions of a section. Stimulus groups always stay
+// together and in authored order.
+const (
+	// OrderRandom shuffles the section.
+	OrderRandom = "random"
+	// OrderQuota keeps quotas in the order written, shuffling within each.
+	OrderQuota = "quota"
+	// OrderDifficulty goes from easiest to hardest difficulty, shuffling
+	// within a difficulty.
+	OrderDifficulty = "difficulty"
+)
+
+type Quota struct {
+	// TopicID and DifficultyID are optional; nil draws from the whole section pool.
+	TopicID      *string `json:"topicId,omitempty"`
+	DifficultyID *string `json:"difficultyId,omitempty"`
+	Count        int     `json:"count"`
+}
+
+type Section struct {
+	Name string `json:"name"`
+	// TimeLimitSeconds applies to timed sessions; nil leaves the section
+	// under the session limit only.
+	TimeLimitSeconds *int    `json:"timeLimitSeconds,omitempty"`
+	Ordering         string  `json:"ordering,omitempty"`
+	Quotas           []Quota `json:"quotas"`
+	// Timing overrides the template's timing policy for this section. Its
+	// totalSeconds is the section limit, so it excludes TimeLimitSeconds;
+	// grace is set for the whole session on the template.
+	Timing *timing.Policy `json:"timing,omitempty"`
+}
+
+// Total is the number of questions the section asks for.
+func (s Section) Total() int {
+	n := 0
+	for _, q := range s.Quotas {
+		n += q.Count
+	}
+	return n
+}
+
+// Parse decodes and validates the sections of a blueprint written by an
+// instructor. Empty input or null means no blueprint (nil, nil).
+func Parse(raw []byte) ([]Section, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	if raw[0] != '[' {
+		return nil, errors.New("sections must be a json array")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var sections []Section
+	if err := dec.Decode(&sections); err != nil {
+		return nil, fmt.Errorf("invalid sections: %v", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid sections: trailing data")
+	}
+	for i := range sections {
+		sections[i].Name = strings.TrimSpace(sections[i].Name)
+		if sections[i].Ordering == "" {
+			sections[i].Ordering = OrderRandom
+		}
+	}
+	return sections, Validate(sections)
+}
+
+// Validate checks a blueprint: at least one section, unique names, at least
+// one positive quota per section and at most MaxQuestions in total.
+func Validate(sections []Section) error {
+	if len(sections) == 0 {
+		return errors.New("a blueprint needs at least one section")
+	}
+	seen := map[string]bool{}
+	total := 0
+	for i, s := range sections {
+		if s.Name == "" {
+			return fmt.Errorf("section %d needs a name", i+1)
+		}
+		if seen[strings.ToLower(s.Name)] {
+			return fmt.Errorf("duplicate section name %q", s.Name)
+		}
+		seen[strings.ToLower(s.Name)] = true
+		switch s.Ordering {
+		case OrderRandom, OrderQuota, OrderDifficulty:
+		default:
+			return fmt.Errorf("section %q: ordering must be random, quota or difficulty", s.Name)
+		}
+		if s.TimeLimitSeconds != nil && *s.TimeLimitSeconds <= 0 {
+			return fmt.Errorf("section %q: timeLimitSeconds must be positive", s.Name)
+		}
+		if s.Timing != nil {
+			if err := s.Timing.Validate(); err != nil {
+				return fmt.Errorf("section %q: %v", s.Name, err)
+			}
+			if s.Timing.TotalSeconds != nil && s.TimeLimitSeconds != nil {
+				return fmt.Errorf("section %q: set timeLimitSeconds or timing.totalSeconds, not both", s.Name)
+			}
+			if s.Timing.GraceSeconds != nil {
+				return fmt.Errorf("section %q: graceSeconds applies to the whole session; set it on the template", s.Name)
+			}
+		}
+		if len(s.Quotas) == 0 {
+			return fmt.Errorf("section %q needs at least one quota", s.Name)
+		}
+		for j, q := range s.Quotas {
+			if q.Count <= 0 {
+				return fmt.Errorf("section %q quota %d: count must be positive", s.Name, j+1)
+			}
+		}
+		total += s.Total()
+	}
+	if total > MaxQuestions {
+		return fmt.Errorf("a blueprint holds at most %d questions, got %d", MaxQuestions, total)
+	}
+	return nil
+}
+
+// Total is the number of questions a blueprint asks for.
+func Total(sections []Section) int {
+	n := 0
+	for _, s := range sections {
+		n += s.Total()
+	}
+	return n
+}
+
+// Boundary is where a section sits in a session's question order.
+type Boundary struct {
+	Name             string `json:"name"`
+	StartIndex       int    `json:"startIndex"`
+	Count            int    `json:"count"`
+	TimeLimitSeconds *int   `json:"timeLimitSeconds,omitempty"`
+	Ordering         string `json:"ordering"`
+}
+
+// Boundaries lays out sections holding counts[i] questions each.
+func Boundaries(sections []Section, counts []int) []Boundary {
+	out := make([]Boundary, 0, len(sections))
+	start := 0
+	for i, s := range sections {
+		out = append(out, Boundary{Name: s.Name, StartIndex: start, Count: counts[i], TimeLimitSeconds: s.TimeLimitSeconds, Ordering: s.Ordering})
+		start += counts[i]
+	}
+	return out
+}
+
+// SectionAt returns the index of the section holding question index idx, or
+// -1 when idx lies outside every section.
+func SectionAt(boundaries []Boundary, idx int) int {
+	for i, b := range boundaries {
+		if idx >= b.StartIndex && idx < b.StartIndex+b.Count {
+			return i
+		}
+	}
+	return -1
+}
+
+// Shortage reports a quota the question pool could not fill.
+type Shortage struct {
+	Section      string  `json:"section"`
+	Quota        int     `json:"quota"`
+	TopicID      *string `json:"topicId"`
+	DifficultyID *string `json:"difficultyId"`
+	Requested    int     `json:"requested"`
+	Available    int     `json:"available"`
+}
+
+// Unit is a standalone question or a stimulus group drawn for a section.
+type Unit struct {
+	// Quota is the index of the quota the unit was drawn for.
+	Quota int
+	// DifficultyRank is the sort order of the unit's difficulty.
+	DifficultyRank int
+}
+
+// Order returns the order in which to lay out a section's units.
+func Order(units []Unit, ordering string, rng *rand.Rand) []int {
+	out := make([]int, len(units))
+	for i := range out {
+		out[i] = i
+	}
+	rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	switch ordering {
+	case OrderQuota:
+		sort.SliceStable(out, func(i, j int) bool { return units[out[i]].Quota < units[out[j]].Quota })
+	case OrderDifficulty:
+		sort.SliceStable(out, func(i, j int) bool { return units[out[i]].DifficultyRank < units[out[j]].DifficultyRank })
+	}
+	return out
+}
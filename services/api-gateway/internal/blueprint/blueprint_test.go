@@ -0,0 +1,100 @@
+package blueprint
+
+import (
+    "math/rand"
+    "testing"
+)
+
+func TestParse(t *testing.T) {
+    sections, err := Parse([]byte(`[
+        {"name": "Quant", "timeLimitSeconds": 1200, "ordering": "difficulty", "quotas": [
+            {"topicId": "algebra", "difficultyId": "easy", "count": 6},
+            {"topicId": "geometry", "difficultyId": "medium", "count": 8}
+        ]},
+        {"name": "Verbal", "quotas": [{"count": 20}]}
+    ]`))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if len(sections) != 2 || sections[0].Total() != 14 || Total(sections) != 34 {
+        t.Fatalf("unexpected sections %+v", sections)
+    }
+    if sections[1].Ordering != OrderRandom {
+        t.Fatalf("expected default ordering, got %q", sections[1].Ordering)
+    }
+
+    if sections, err := Parse([]byte(`null`)); err != nil || sections != nil {
+        t.Fatalf("null should mean no blueprint, got %+v, %v", sections, err)
+    }
+}
+
+func TestParseRejects(t *testing.T) {
+    for _, raw := range []string{
+        `{}`,
+        `[]`,
+        `[{"name": "", "quotas": [{"count": 1}]}]`,
+        `[{"name": "A", "quotas": []}]`,
+        `[{"name": "A", "quotas": [{"count": 0}]}]`,
+        `[{"name": "A", "quotas": [{"count": 1}]}, {"name": "a", "quotas": [{"count": 1}]}]`,
+        `[{"name": "A", "ordering": "alphabetical", "quotas": [{"count": 1}]}]`,
+        `[{"name": "A", "timeLimitSeconds": 0, "quotas": [{"count": 1}]}]`,
+        `[{"name": "A", "quotas": [{"count": 201}]}]`,
+        `[{"name": "A", "quotas": [{"count": 1, "topic": "x"}]}]`,
+    } {
+        if _, err := Parse([]byte(raw)); err == nil {
+            t.Fatalf("expected %s to be rejected", raw)
+        }
+    }
+}
+
+func TestBoundaries(t *testing.T) {
+    limit := 600
+    sections := []Section{{Name: "Quant", TimeLimitSeconds: &limit, Ordering: OrderRandom}, {Name: "Verbal", Ordering: OrderQuota}}
+    b := Boundaries(sections, []int{3, 4})
+    if b[0].StartIndex != 0 || b[0].Count != 3 || b[1].StartIndex != 3 || b[1].Count != 4 {
+        t.Fatalf("unexpected boundaries %+v", b)
+    }
+    if SectionAt(b, 2) != 0 || SectionAt(b, 3) != 1 || SectionAt(b, 6) != 1 || SectionAt(b, 7) != -1 {
+        t.Fatalf("unexpected section lookup")
+    }
+}
+
+func TestOrder(t *testing.T) {
+    units := []Unit{{Quota: 1, DifficultyRank: 3}, {Quota: 0, DifficultyRank: 2}, {Quota: 1, DifficultyRank: 1}, {Quota: 0, DifficultyRank: 3}}
+    rng := rand.New(rand.NewSource(7))
+
+    byQuota := Order(units, OrderQuota, rng)
+    for i := 1; i < len(byQuota); i++ {
+        if units[byQuota[i-1]].Quota > units[byQuota[i]].Quota {
+            t.Fatalf("quota order broken: %v", byQuota)
+        }
+    }
+    byDifficulty := Order(units, OrderDifficulty, rng)
+    for i := 1; i < len(byDifficulty); i++ {
+        if units[byDifficulty[i-1]].DifficultyRank > units[byDifficulty[i]].DifficultyRank {
+            t.Fatalf("difficulty order broken: %v", byDifficulty)
+        }
+    }
+    if got := Order(units, OrderRandom, rng); len(got) != len(units) {
+        t.Fatalf("random order lost units: %v", got)
+    }
+}
+
+func TestParseSectionTiming(t *testing.T) {
+    sections, err := Parse([]byte(`[{"name": "Math", "timing": {"secondsPerQuestion": 75, "autoAdvance": true}, "quotas": [{"count": 4}]}]`))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if sections[0].Timing == nil || sections[0].Timing.QuestionSeconds("") != 75 {
+        t.Fatalf("unexpected section timing %+v", sections[0].Timing)
+    }
+    for _, raw := range []string{
+        `[{"name": "Math", "timing": {"secondsPerQuestion": 0}, "quotas": [{"count": 4}]}]`,
+        `[{"name": "Math", "timeLimitSeconds": 600, "timing": {"totalSeconds": 300}, "quotas": [{"count": 4}]}]`,
+        `[{"name": "Math", "timing": {"graceSeconds": 5}, "quotas": [{"count": 4}]}]`,
+    } {
+        if _, err := Parse([]byte(raw)); err == nil {
+            t.Fatalf("expected %s to be rejected", raw)
+        }
+    }
+}
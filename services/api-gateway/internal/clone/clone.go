@@ -0,0 +1,576 @@
+// Package clone deep-copies question banks and exam packages for new
+// editions. Copies get new ids and a cloned_from_id pointing back at the
+// original row. Jobs run in the background and report progress on their
+// clone_jobs row; the copy itself is one transaction, so a failed job
+// leaves nothing behind.
+package clone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+const (
+	KindQuestionBank = "question_bank"
+	KindExamPackage  = "exam_package"
+)
+
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// questionChunk bounds how many questions are copied per round trip and
+// between progress updates.
+const questionChunk = 200
+
+var ErrSourceNotFound = errors.New("clone: source not found")
+
+type Request struct {
+	Kind     string
+	SourceID string
+	// Name of the copy; question banks default to the original's name.
+	Name string
+	// Code of the exam package copy (exam packages only).
+	Code string
+	// IncludeQuestionBanks also copies the banks attached to an exam package;
+	// otherwise the copy is attached to the same banks.
+	IncludeQuestionBanks bool
+	// KeepPublished keeps published questions published. Without it they are
+	// copied as drafts, like every question that was mid-review.
+	KeepPublished   bool
+	CreatedByUserID string
+}
+
+// JobTimeout is how long a job may stay running before it is treated as
+// abandoned by a process that exited mid-copy (CLONE_JOB_TIMEOUT_MINUTES,
+// default 60).
+func JobTimeout() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("CLONE_JOB_TIMEOUT_MINUTES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+	return time.Hour
+}
+
+// FailStale marks running jobs older than timeout as failed. Jobs run in the
+// API process, so a restart leaves its jobs running with nothing behind them;
+// their copy transaction was rolled back with the process.
+func FailStale(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration) (int64, error) {
+	ct, err := pool.Exec(ctx, `update clone_jobs set status=$2, error='timed out', completed_at=now()
+		where status=$1 and created_at < now() - $3 * interval '1 second'`, JobRunning, JobFailed, timeout.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return ct.RowsAffected(), nil
+}
+
+// Start records a running job and returns its id. total_items is counted
+// up front so progress can be reported while the copy runs. Abandoned jobs
+// are failed first (see FailStale).
+func Start(ctx context.Context, pool *pgxpool.Pool, req Request) (string, error) {
+	if _, err := FailStale(ctx, pool, JobTimeout()); err != nil {
+		return "", err
+	}
+	var total int
+	var err error
+	switch req.Kind {
+	case KindQuestionBank:
+		total, err = bankItemCount(ctx, pool, req.SourceID)
+	case KindExamPackage:
+		total, err = examPackageItemCount(ctx, pool, req.SourceID, req.IncludeQuestionBanks)
+	default:
+		return "", fmt.Errorf("clone: unknown kind %q", req.Kind)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	options, _ := json.Marshal(map[string]any{
+		"name":                 req.Name,
+		"code":                 req.Code,
+		"includeQuestionBanks": req.IncludeQuestionBanks,
+		"keepPublished":        req.KeepPublished,
+	})
+	id := util.NewID("cln")
+	_, err = pool.Exec(ctx, `insert into clone_jobs (id, kind, source_id, status, options, total_items, created_by_user_id) values ($1,$2,$3,$4,$5,$6,$7)`,
+		id, req.Kind, req.SourceID, JobRunning, options, total, req.CreatedByUserID)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Run performs a job created by Start. The job row records the outcome either way.
+func Run(ctx context.Context, pool *pgxpool.Pool, jobID string, req Request) error {
+	err := run(ctx, pool, jobID, req)
+	if err != nil {
+		_, _ = pool.Exec(context.Background(), `update clone_jobs set status=$1, error=$2, completed_at=now() where id=$3`,
+			JobFailed, err.Error(), jobID)
+	}
+	return err
+}
+
+type job struct {
+	pool   *pgxpool.Pool
+	id     string
+	copied int
+}
+
+// advance reports progress outside the copy transaction so pollers see it.
+func (j *job) advance(ctx context.Context, n int) {
+	j.copied += n
+	_, _ = j.pool.Exec(ctx, `update clone_jobs set copied_items=$2 where id=$1`, j.id, j.copied)
+}
+
+func run(ctx context.Context, pool *pgxpool.Pool, jobID string, req Request) error {
+	j := &job{pool: pool, id: jobID}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var targetID string
+	switch req.Kind {
+	case KindQuestionBank:
+		targetID, _, err = cloneBank(ctx, tx, j, req.SourceID, req.Name, req)
+	case KindExamPackage:
+		targetID, err = cloneExamPackage(ctx, tx, j, req)
+	default:
+		err = fmt.Errorf("clone: unknown kind %q", req.Kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `update clone_jobs set status=$2, target_id=$3, copied_items=total_items, completed_at=now() where id=$1`,
+		jobID, JobCompleted, targetID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func bankItemCount(ctx context.Context, pool *pgxpool.Pool, bankID string) (int, error) {
+	var exists bool
+	var n int
+	err := pool.QueryRow(ctx, `select
+			exists(select 1 from question_banks where id=$1 and deleted_at is null),
+			(select count(*) from question_bank_topics where package_id=$1 and deleted_at is null)
+			+ (select count(*) from question_stimuli where question_bank_id=$1)
+			+ (select count(*) from question_bank_questions where package_id=$1 and deleted_at is null)`, bankID).Scan(&exists, &n)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrSourceNotFound
+	}
+	return n, nil
+}
+
+func examPackageItemCount(ctx context.Context, pool *pgxpool.Pool, examPackageID string, includeBanks bool) (int, error) {
+	var exists bool
+	var n int
+	err := pool.QueryRow(ctx, `select
+			exists(select 1 from exam_packages where id::text=$1),
+			(select count(*) from exam_package_tiers where exam_package_id::text=$1)
+			+ (select count(*) from practice_templates where exam_package_id::text=$1)`, examPackageID).Scan(&exists, &n)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, ErrSourceNotFound
+	}
+	banks, err := attachedBanks(ctx, pool, examPackageID)
+	if err != nil {
+		return 0, err
+	}
+	n += len(banks)
+	if includeBanks {
+		for _, bankID := range banks {
+			items, err := bankItemCount(ctx, pool, bankID)
+			if err != nil {
+				return 0, err
+			}
+			n += items
+		}
+	}
+	return n, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func attachedBanks(ctx context.Context, q querier, examPackageID string) ([]string, error) {
+	return queryIDs(ctx, q, `select m.question_bank_package_id from exam_package_question_bank_packages m
+		join question_banks b on b.id=m.question_bank_package_id
+		where m.exam_package_id::text=$1 and b.deleted_at is null
+		order by b.name, b.id`, examPackageID)
+}
+
+func queryIDs(ctx context.Context, q querier, sql string, args ...any) ([]string, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// idMap pairs original ids with freshly generated ones. It is passed to SQL
+// as two parallel arrays and joined with unnest.
+type idMap struct {
+	old []string
+	new []string
+	m   map[string]string
+}
+
+func newIDMap(prefix string, ids []string) idMap {
+	out := idMap{old: ids, new: make([]string, len(ids)), m: make(map[string]string, len(ids))}
+	for i, id := range ids {
+		out.new[i] = util.NewID(prefix)
+		out.m[id] = out.new[i]
+	}
+	return out
+}
+
+// cloneBank copies a live bank with its live topics, stimuli and questions
+// (choices, correct answers, translations). Item statistics, IRT parameters,
+// reviews and reports describe the original and are not copied. It returns
+// the new bank id and the topic id mapping.
+func cloneBank(ctx context.Context, tx pgx.Tx, j *job, bankID string, name string, req Request) (string, map[string]string, error) {
+	var srcName string
+	err := tx.QueryRow(ctx, `select name from question_banks where id=$1 and deleted_at is null`, bankID).Scan(&srcName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrSourceNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		name = srcName
+	}
+
+	newBankID := util.NewID("pkg")
+	_, err = tx.Exec(ctx, `insert into question_banks (id, name, created_by_user_id, is_hidden, required_approvals, source_locale, cloned_from_id)
+		select $2, $3, $4, is_hidden, required_approvals, source_locale, id from question_banks where id=$1`,
+		bankID, newBankID, name, req.CreatedByUserID)
+	if err != nil {
+		return "", nil, fmt.Errorf("copy bank: %w", err)
+	}
+
+	topicIDs, err := queryIDs(ctx, tx, `select id from question_bank_topics where package_id=$1 and deleted_at is null`, bankID)
+	if err != nil {
+		return "", nil, err
+	}
+	topics := newIDMap("top", topicIDs)
+	_, err = tx.Exec(ctx, `insert into question_bank_topics (id, package_id, name, is_hidden, created_by_user_id, cloned_from_id)
+		select m.new_id, $1, t.name, t.is_hidden, $2, t.id
+		from question_bank_topics t join unnest($3::text[], $4::text[]) as m(old_id, new_id) on m.old_id=t.id`,
+		newBankID, req.CreatedByUserID, topics.old, topics.new)
+	if err != nil {
+		return "", nil, fmt.Errorf("copy topics: %w", err)
+	}
+	if err := copyTranslations(ctx, tx, "topic", topics, nil); err != nil {
+		return "", nil, err
+	}
+	j.advance(ctx, len(topicIDs))
+
+	stimulusIDs, err := queryIDs(ctx, tx, `select id from question_stimuli where question_bank_id=$1`, bankID)
+	if err != nil {
+		return "", nil, err
+	}
+	stimuli := newIDMap("stm", stimulusIDs)
+	_, err = tx.Exec(ctx, `insert into question_stimuli (id, question_bank_id, title, passage, media_url, media_type, created_by_user_id, updated_by_user_id, cloned_from_id)
+		select m.new_id, $1, s.title, s.passage, s.media_url, s.media_type, $2, $2, s.id
+		from question_stimuli s join unnest($3::text[], $4::text[]) as m(old_id, new_id) on m.old_id=s.id`,
+		newBankID, req.CreatedByUserID, stimuli.old, stimuli.new)
+	if err != nil {
+		return "", nil, fmt.Errorf("copy stimuli: %w", err)
+	}
+	j.advance(ctx, len(stimulusIDs))
+
+	questionIDs, err := queryIDs(ctx, tx, `select id from question_bank_questions where package_id=$1 and deleted_at is null order by created_at, id`, bankID)
+	if err != nil {
+		return "", nil, err
+	}
+	for start := 0; start < len(questionIDs); start += questionChunk {
+		end := min(start+questionChunk, len(questionIDs))
+		if err := cloneQuestions(ctx, tx, newBankID, newIDMap("qst", questionIDs[start:end]), topics, stimuli, req); err != nil {
+			return "", nil, err
+		}
+		j.advance(ctx, end-start)
+	}
+	return newBankID, topics.m, nil
+}
+
+func cloneQuestions(ctx context.Context, tx pgx.Tx, bankID string, questions, topics, stimuli idMap, req Request) error {
+	// Review state does not carry over: anything mid-review starts as a draft.
+	_, err := tx.Exec(ctx, `insert into question_bank_questions (id, package_id, topic_id, difficulty_id, prompt, explanation_text, status,
+			created_by_user_id, updated_by_user_id, stimulus_id, stimulus_order, revision, shuffle_choices, cloned_from_id)
+		select qm.new_id, $1, tm.new_id, q.difficulty_id, q.prompt, q.explanation_text,
+			case when q.status='archived' or (q.status='published' and $3) then q.status else 'draft' end,
+			$2, $2, sm.new_id, q.stimulus_order, q.revision, q.shuffle_choices, q.id
+		from question_bank_questions q
+		join unnest($4::text[], $5::text[]) as qm(old_id, new_id) on qm.old_id=q.id
+		left join unnest($6::text[], $7::text[]) as tm(old_id, new_id) on tm.old_id=q.topic_id
+		left join unnest($8::text[], $9::text[]) as sm(old_id, new_id) on sm.old_id=q.stimulus_id`,
+		bankID, req.CreatedByUserID, req.KeepPublished, questions.old, questions.new, topics.old, topics.new, stimuli.old, stimuli.new)
+	if err != nil {
+		return fmt.Errorf("copy questions: %w", err)
+	}
+
+	choiceIDs, err := queryIDs(ctx, tx, `select id from question_bank_choices where question_id = any($1)`, questions.old)
+	if err != nil {
+		return err
+	}
+	choices := newIDMap("ch", choiceIDs)
+	_, err = tx.Exec(ctx, `insert into question_bank_choices (id, question_id, order_index, text, is_pinned, cloned_from_id)
+		select cm.new_id, qm.new_id, c.order_index, c.text, c.is_pinned, c.id
+		from question_bank_choices c
+		join unnest($1::text[], $2::text[]) as cm(old_id, new_id) on cm.old_id=c.id
+		join unnest($3::text[], $4::text[]) as qm(old_id, new_id) on qm.old_id=c.question_id`,
+		choices.old, choices.new, questions.old, questions.new)
+	if err != nil {
+		return fmt.Errorf("copy choices: %w", err)
+	}
+	_, err = tx.Exec(ctx, `insert into question_bank_correct_choice (question_id, choice_id)
+		select qm.new_id, cm.new_id
+		from question_bank_correct_choice cc
+		join unnest($1::text[], $2::text[]) as qm(old_id, new_id) on qm.old_id=cc.question_id
+		join unnest($3::text[], $4::text[]) as cm(old_id, new_id) on cm.old_id=cc.choice_id`,
+		questions.old, questions.new, choices.old, choices.new)
+	if err != nil {
+		return fmt.Errorf("copy correct choices: %w", err)
+	}
+	hintIDs, err := queryIDs(ctx, tx, `select id from question_bank_hints where question_id = any($1)`, questions.old)
+	if err != nil {
+		return err
+	}
+	hints := newIDMap("qh", hintIDs)
+	_, err = tx.Exec(ctx, `insert into question_bank_hints (id, question_id, order_index, text, cloned_from_id)
+		select hm.new_id, qm.new_id, h.order_index, h.text, h.id
+		from question_bank_hints h
+		join unnest($1::text[], $2::text[]) as hm(old_id, new_id) on hm.old_id=h.id
+		join unnest($3::text[], $4::text[]) as qm(old_id, new_id) on qm.old_id=h.question_id`,
+		hints.old, hints.new, questions.old, questions.new)
+	if err != nil {
+		return fmt.Errorf("copy hints: %w", err)
+	}
+	return copyTranslations(ctx, tx, "question", questions, choices.m)
+}
+
+// copyTranslations copies content_translations rows to the new entity ids,
+// keeping their review status. Question translations key choice text by
+// choice id, so those keys are remapped too.
+func copyTranslations(ctx context.Context, tx pgx.Tx, entityType string, entities idMap, choiceMap map[string]string) error {
+	if len(entities.old) == 0 {
+		return nil
+	}
+	rows, err := tx.Query(ctx, `select entity_id, locale, fields, source_revision, status, review_note, created_by_user_id, updated_by_user_id, reviewed_by_user_id, reviewed_at
+		from content_translations where entity_type=$1 and entity_id = any($2)`, entityType, entities.old)
+	if err != nil {
+		return err
+	}
+	type translation struct {
+		entityID, locale, status, reviewNote, createdBy, updatedBy string
+		fields                                                     []byte
+		sourceRevision                                             *int
+		reviewedBy                                                 *string
+		reviewedAt                                                 *time.Time
+	}
+	var items []translation
+	for rows.Next() {
+		var t translation
+		if err := rows.Scan(&t.entityID, &t.locale, &t.fields, &t.sourceRevision, &t.status, &t.reviewNote, &t.createdBy, &t.updatedBy, &t.reviewedBy, &t.reviewedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, t := range items {
+		fields := t.fields
+		if choiceMap != nil {
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(t.fields, &raw); err == nil && raw["choices"] != nil {
+				var old map[string]string
+				if err := json.Unmarshal(raw["choices"], &old); err == nil {
+					remapped := make(map[string]string, len(old))
+					for id, text := range old {
+						if newID, ok := choiceMap[id]; ok {
+							remapped[newID] = text
+						}
+					}
+					raw["choices"], _ = json.Marshal(remapped)
+					fields, _ = json.Marshal(raw)
+				}
+			}
+		}
+		batch.Queue(`insert into content_translations (id, entity_type, entity_id, locale, fields, source_revision, status, review_note, created_by_user_id, updated_by_user_id, reviewed_by_user_id, reviewed_at)
+			values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+			util.NewID("tr"), entityType, entities.m[t.entityID], t.locale, fields, t.sourceRevision, t.status, t.reviewNote, t.createdBy, t.updatedBy, t.reviewedBy, t.reviewedAt)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("copy translations: %w", err)
+	}
+	return nil
+}
+
+// cloneExamPackage copies an exam package with its tiers and practice
+// templates. The copy starts hidden so it can be prepared before students
+// see it. Enrollments and sessions are never copied.
+func cloneExamPackage(ctx context.Context, tx pgx.Tx, j *job, req Request) (string, error) {
+	var newID string
+	err := tx.QueryRow(ctx, `insert into exam_packages (code, name, subtitle, overview, modules, highlights, module_sections, is_hidden, source_locale, cloned_from_id)
+		select $2, $3, subtitle, overview, modules, highlights, module_sections, true, source_locale, id
+		from exam_packages where id::text=$1
+		returning id::text`, req.SourceID, req.Code, req.Name).Scan(&newID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrSourceNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("copy exam package: %w", err)
+	}
+	packageIDs := idMap{old: []string{req.SourceID}, new: []string{newID}, m: map[string]string{req.SourceID: newID}}
+	if err := copyTranslations(ctx, tx, "exam_package", packageIDs, nil); err != nil {
+		return "", err
+	}
+
+	ct, err := tx.Exec(ctx, `insert into exam_package_tiers (exam_package_id, code, name, sort_order, is_default, is_active, policy,
+			max_practice_sessions_per_week, max_exam_sessions_per_week, cloned_from_id)
+		select $2::uuid, code, name, sort_order, is_default, is_active, policy,
+			max_practice_sessions_per_week, max_exam_sessions_per_week, id
+		from exam_package_tiers where exam_package_id::text=$1`, req.SourceID, newID)
+	if err != nil {
+		return "", fmt.Errorf("copy tiers: %w", err)
+	}
+	j.advance(ctx, int(ct.RowsAffected()))
+
+	banks, err := attachedBanks(ctx, tx, req.SourceID)
+	if err != nil {
+		return "", err
+	}
+	topicOld, topicNew := []string{}, []string{}
+	for _, bankID := range banks {
+		attachID := bankID
+		if req.IncludeQuestionBanks {
+			newBankID, topics, err := cloneBank(ctx, tx, j, bankID, "", req)
+			if err != nil {
+				return "", err
+			}
+			attachID = newBankID
+			for oldID, newTopicID := range topics {
+				topicOld = append(topicOld, oldID)
+				topicNew = append(topicNew, newTopicID)
+			}
+		}
+		_, err := tx.Exec(ctx, `insert into exam_package_question_bank_packages (exam_package_id, question_bank_package_id, created_by_user_id) values ($1::uuid,$2,$3)`,
+			newID, attachID, req.CreatedByUserID)
+		if err != nil {
+			return "", fmt.Errorf("attach question bank: %w", err)
+		}
+		j.advance(ctx, 1)
+	}
+
+	// Templates follow their topic into the copied bank when banks are copied.
+	ct, err = tx.Exec(ctx, `insert into practice_templates (exam_package_id, name, section, topic_id, difficulty_id, is_timed, target_count, sort_order,
+			is_published, created_by_user_id, updated_by_user_id, cloned_from_id, sections, timing)
+		select $2::uuid, t.name, t.section, coalesce(tm.new_id, t.topic_id), t.difficulty_id, t.is_timed, t.target_count, t.sort_order,
+			t.is_published, $3, $3, t.id, t.sections, t.timing
+		from practice_templates t
+		left join unnest($4::text[], $5::text[]) as tm(old_id, new_id) on tm.old_id=t.topic_id
+		where t.exam_package_id::text=$1`, req.SourceID, newID, req.CreatedByUserID, topicOld, topicNew)
+	if err != nil {
+		return "", fmt.Errorf("copy practice templates: %w", err)
+	}
+	if len(topicOld) > 0 {
+		if err := remapBlueprintTopics(ctx, tx, newID, topicOld, topicNew); err != nil {
+			return "", err
+		}
+	}
+	j.advance(ctx, int(ct.RowsAffected()))
+	return newID, nil
+}
+
+// remapBlueprintTopics points the section quotas of copied blueprint
+// templates at the copied topics.
+func remapBlueprintTopics(ctx context.Context, tx pgx.Tx, examPackageID string, topicOld, topicNew []string) error {
+	topics := make(map[string]string, len(topicOld))
+	for i := range topicOld {
+		topics[topicOld[i]] = topicNew[i]
+	}
+	rows, err := tx.Query(ctx, `select id::text, sections from practice_templates where exam_package_id=$1::uuid and sections is not null`, examPackageID)
+	if err != nil {
+		return fmt.Errorf("load blueprint templates: %w", err)
+	}
+	type template struct {
+		ID       string
+		Sections []blueprint.Section
+	}
+	var templates []template
+	for rows.Next() {
+		var t template
+		var raw []byte
+		if err := rows.Scan(&t.ID, &raw); err != nil {
+			rows.Close()
+			return fmt.Errorf("load blueprint templates: %w", err)
+		}
+		if err := json.Unmarshal(raw, &t.Sections); err != nil {
+			continue
+		}
+		templates = append(templates, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load blueprint templates: %w", err)
+	}
+
+	for _, t := range templates {
+		for i := range t.Sections {
+			for j, q := range t.Sections[i].Quotas {
+				if q.TopicID == nil {
+					continue
+				}
+				if newID, ok := topics[*q.TopicID]; ok {
+					t.Sections[i].Quotas[j].TopicID = &newID
+				}
+			}
+		}
+		raw, _ := json.Marshal(t.Sections)
+		if _, err := tx.Exec(ctx, `update practice_templates set sections=$2 where id::text=$1`, t.ID, raw); err != nil {
+			return fmt.Errorf("remap blueprint topics: %w", err)
+		}
+	}
+	return nil
+}
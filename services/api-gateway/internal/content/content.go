@@ -0,0 +1,430 @@
+// Package content validates, normalizes and renders authored question text.
+//
+// Question prompts, explanations and choices are written in a small Markdown
+// subset with TeX math. Process is the single entry point used by handlers:
+// it normalizes whitespace, sanitizes embedded HTML against an allowlist,
+// rejects malformed math, and renders the HTML instructors see in previews.
+package content
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength bounds a single normalized content field (in bytes).
+const MaxLength = 20000
+
+var (
+	ErrTooLong          = errors.New("content is too long")
+	ErrUnbalancedMath   = errors.New("unbalanced math delimiter")
+	ErrUnbalancedBraces = errors.New("unbalanced braces in math")
+	ErrUnbalancedEnv    = errors.New("unbalanced \\begin/\\end in math")
+	ErrUnsupportedMacro = errors.New("unsupported math command")
+	ErrUnclosedCode     = errors.New("unclosed code fence")
+)
+
+// Result is the outcome of running content through the pipeline.
+// Normalized is what gets stored; HTML is the sanitized rendering.
+type Result struct {
+	Normalized string
+	HTML       string
+}
+
+// Process normalizes, sanitizes and validates src and renders it to HTML.
+func Process(src string) (Result, error) {
+	norm := Normalize(src)
+	if len(norm) > MaxLength {
+		return Result{}, ErrTooLong
+	}
+	segs, err := scan(norm)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, s := range segs {
+		if s.kind == segMathInline || s.kind == segMathDisplay {
+			if err := validateMath(s.body); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+
+	clean, frags := sanitize(segs)
+	return Result{Normalized: clean, HTML: render(frags)}, nil
+}
+
+// Normalize canonicalizes line endings and whitespace without touching meaning:
+// CRLF/CR become LF, control characters are dropped, trailing spaces are trimmed
+// per line, runs of blank lines collapse to one, and the result is trimmed.
+func Normalize(src string) string {
+	src = strings.ToValidUTF8(src, "�")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	var b strings.Builder
+	b.Grow(len(src))
+	for _, r := range src {
+		if r == '\n' || r == '\t' || (r >= 0x20 && r != 0x7f) {
+			b.WriteRune(r)
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+type segKind int
+
+const (
+	segText segKind = iota
+	segCode
+	segCodeBlock
+	segMathInline
+	segMathDisplay
+)
+
+// segment is a slice of the source. raw is the exact source text; body is the
+// inner content for code and math (delimiters stripped).
+type segment struct {
+	kind segKind
+	raw  string
+	body string
+	lang string
+}
+
+// scan splits src into text, code and math segments. Math and code bodies are
+// opaque to Markdown and HTML processing.
+func scan(src string) ([]segment, error) {
+	segs := make([]segment, 0, 8)
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			segs = append(segs, segment{kind: segText, raw: text.String()})
+			text.Reset()
+		}
+	}
+
+	i := 0
+	for i < len(src) {
+		atLineStart := i == 0 || src[i-1] == '\n'
+
+		if atLineStart && strings.HasPrefix(src[i:], "```") {
+			lineEnd := strings.IndexByte(src[i:], '\n')
+			if lineEnd < 0 {
+				return nil, ErrUnclosedCode
+			}
+			lang := strings.TrimSpace(src[i+3 : i+lineEnd])
+			bodyStart := i + lineEnd + 1
+			closeAt := -1
+			for j := bodyStart; j < len(src); {
+				if strings.HasPrefix(src[j:], "```") {
+					closeAt = j
+					break
+				}
+				nl := strings.IndexByte(src[j:], '\n')
+				if nl < 0 {
+					break
+				}
+				j += nl + 1
+			}
+			if closeAt < 0 {
+				return nil, ErrUnclosedCode
+			}
+			end := closeAt + 3
+			if nl := strings.IndexByte(src[end:], '\n'); nl >= 0 {
+				end += nl
+			} else {
+				end = len(src)
+			}
+			flush()
+			segs = append(segs, segment{
+				kind: segCodeBlock,
+				raw:  src[i:end],
+				body: strings.TrimSuffix(src[bodyStart:closeAt], "\n"),
+				lang: lang,
+			})
+			i = end
+			continue
+		}
+
+		ch := src[i]
+		switch {
+		case ch == '\\' && i+1 < len(src):
+			next := src[i+1]
+			switch next {
+			case '(', '[':
+				closer := `\)`
+				kind := segMathInline
+				if next == '[' {
+					closer = `\]`
+					kind = segMathDisplay
+				}
+				end := strings.Index(src[i+2:], closer)
+				if end < 0 {
+					return nil, ErrUnbalancedMath
+				}
+				flush()
+				segs = append(segs, segment{kind: kind, raw: src[i : i+2+end+2], body: src[i+2 : i+2+end]})
+				i += 2 + end + 2
+				continue
+			case ')', ']':
+				return nil, ErrUnbalancedMath
+			}
+			text.WriteByte(ch)
+			text.WriteByte(next)
+			i += 2
+			continue
+
+		case ch == '`':
+			n := 0
+			for i+n < len(src) && src[i+n] == '`' {
+				n++
+			}
+			end := findBacktickRun(src, i+n, n)
+			if end < 0 {
+				text.WriteString(src[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			segs = append(segs, segment{kind: segCode, raw: src[i : end+n], body: strings.TrimSpace(src[i+n : end])})
+			i = end + n
+			continue
+
+		case ch == '$' && strings.HasPrefix(src[i:], "$$"):
+			end := findUnescaped(src, i+2, "$$")
+			if end < 0 {
+				return nil, ErrUnbalancedMath
+			}
+			body := src[i+2 : end]
+			if strings.TrimSpace(body) == "" {
+				return nil, ErrUnbalancedMath
+			}
+			flush()
+			segs = append(segs, segment{kind: segMathDisplay, raw: src[i : end+2], body: body})
+			i = end + 2
+			continue
+
+		case ch == '$':
+			end := findInlineDollarClose(src, i+1)
+			if end < 0 {
+				// A lone dollar before a number reads as currency ("$5"); anything
+				// else is an unterminated formula. Authors can always write \$.
+				if i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9' {
+					text.WriteByte(ch)
+					i++
+					continue
+				}
+				return nil, ErrUnbalancedMath
+			}
+			flush()
+			segs = append(segs, segment{kind: segMathInline, raw: src[i : end+1], body: src[i+1 : end]})
+			i = end + 1
+			continue
+		}
+
+		text.WriteByte(ch)
+		i++
+	}
+	flush()
+	return segs, nil
+}
+
+func findBacktickRun(src string, from int, n int) int {
+	for j := from; j < len(src); {
+		if src[j] != '`' {
+			j++
+			continue
+		}
+		k := j
+		for k < len(src) && src[k] == '`' {
+			k++
+		}
+		if k-j == n {
+			return j
+		}
+		j = k
+	}
+	return -1
+}
+
+func findUnescaped(src string, from int, needle string) int {
+	for j := from; j < len(src); j++ {
+		if src[j] == '\\' {
+			j++
+			continue
+		}
+		if strings.HasPrefix(src[j:], needle) {
+			return j
+		}
+	}
+	return -1
+}
+
+// findInlineDollarClose follows the pandoc rule: the opening $ must be followed
+// by a non-space, the closing $ must follow a non-space and must not be followed
+// by a digit. Inline math never spans a blank line.
+func findInlineDollarClose(src string, from int) int {
+	if from >= len(src) || isSpace(src[from]) || src[from] == '$' {
+		return -1
+	}
+	for j := from; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '\n':
+			if j+1 < len(src) && src[j+1] == '\n' {
+				return -1
+			}
+		case '$':
+			if isSpace(src[j-1]) {
+				continue
+			}
+			if j+1 < len(src) && src[j+1] >= '0' && src[j+1] <= '9' {
+				continue
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n'
+}
+
+// disallowedMacros are TeX commands that renderers may turn into links, raw
+// HTML or external fetches.
+var disallowedMacros = map[string]bool{
+	"href":            true,
+	"url":             true,
+	"includegraphics": true,
+	"htmlClass":       true,
+	"htmlId":          true,
+	"htmlStyle":       true,
+	"htmlData":        true,
+	"def":             true,
+	"gdef":            true,
+	"edef":            true,
+	"xdef":            true,
+	"let":             true,
+	"newcommand":      true,
+	"renewcommand":    true,
+	"input":           true,
+	"include":         true,
+}
+
+func validateMath(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return ErrUnbalancedMath
+	}
+	depth := 0
+	envs := make([]string, 0, 2)
+	leftRight := 0
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return ErrUnbalancedBraces
+			}
+		case '\\':
+			if i+1 >= len(body) {
+				return ErrUnbalancedMath
+			}
+			j := i + 1
+			for j < len(body) && isASCIILetter(body[j]) {
+				j++
+			}
+			if j == i+1 {
+				// Escaped symbol such as \{ or \\.
+				i++
+				continue
+			}
+			name := body[i+1 : j]
+			if disallowedMacros[name] {
+				return ErrUnsupportedMacro
+			}
+			switch name {
+			case "left":
+				leftRight++
+			case "right":
+				leftRight--
+				if leftRight < 0 {
+					return ErrUnbalancedMath
+				}
+			case "begin", "end":
+				env, n := readBraceArg(body[j:])
+				if env == "" {
+					return ErrUnbalancedEnv
+				}
+				if name == "begin" {
+					envs = append(envs, env)
+				} else {
+					if len(envs) == 0 || envs[len(envs)-1] != env {
+						return ErrUnbalancedEnv
+					}
+					envs = envs[:len(envs)-1]
+				}
+				j += n
+			}
+			i = j - 1
+		}
+	}
+	if depth != 0 {
+		return ErrUnbalancedBraces
+	}
+	if len(envs) != 0 {
+		return ErrUnbalancedEnv
+	}
+	if leftRight != 0 {
+		return ErrUnbalancedMath
+	}
+	return nil
+}
+
+// readBraceArg reads a "{name}" argument and returns name and bytes consumed.
+func readBraceArg(s string) (string, int) {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	if i >= len(s) || s[i] != '{' {
+		return "", 0
+	}
+	end := strings.IndexByte(s[i:], '}')
+	if end < 0 {
+		return "", 0
+	}
+	name := strings.TrimSpace(s[i+1 : i+end])
+	return name, i + end + 1
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// truncateRunes is a small helper for attribute values.
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max])
+}
@@ -0,0 +1,138 @@
+package content
+
+import (
+    "errors"
+    "strings"
+    "testing"
+)
+
+func TestNormalize(t *testing.T) {
+    got := Normalize("  Line one   \r\n\r\n\r\n\r\nLine\x07 two\t\r\n")
+    if got != "Line one\n\nLine two" {
+        t.Fatalf("unexpected normalized form: %q", got)
+    }
+}
+
+func TestProcessRejectsUnbalancedMath(t *testing.T) {
+    cases := map[string]error{
+        "Solve $x^2 + 1 for x":        ErrUnbalancedMath,
+        "Solve $$x^2 + 1 for x":       ErrUnbalancedMath,
+        `Solve \(x + 1 for x`:         ErrUnbalancedMath,
+        `Stray \] closer`:             ErrUnbalancedMath,
+        `Braces $\frac{1}{2$ here`:    ErrUnbalancedBraces,
+        `$\begin{matrix} a \end{cases}$`: ErrUnbalancedEnv,
+        `$\left( x$`:                  ErrUnbalancedMath,
+        `$\href{https://x}{y}$`:       ErrUnsupportedMacro,
+        "```go\nfmt.Println()":        ErrUnclosedCode,
+    }
+    for src, want := range cases {
+        if _, err := Process(src); !errors.Is(err, want) {
+            t.Fatalf("Process(%q) error = %v, want %v", src, err, want)
+        }
+    }
+}
+
+func TestProcessAllowsCurrencyAndEscapes(t *testing.T) {
+    for _, src := range []string{"It costs $5 and $10.", `A price of \$3`, "`$not math`"} {
+        if _, err := Process(src); err != nil {
+            t.Fatalf("Process(%q) unexpected error: %v", src, err)
+        }
+    }
+}
+
+func TestProcessRendersMarkdownAndMath(t *testing.T) {
+    res, err := Process("If $x<2$, then **which** is *true*?\n\n- one\n- two\n\nSee $$\\frac{a}{b}$$.")
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    for _, want := range []string{
+        `<span class="math math-inline">x&lt;2</span>`,
+        "<strong>which</strong>",
+        "<em>true</em>",
+        "<ul>\n<li>one</li>\n<li>two</li>\n</ul>",
+        `<span class="math math-display">\frac{a}{b}</span>`,
+    } {
+        if !strings.Contains(res.HTML, want) {
+            t.Fatalf("rendered HTML missing %q:\n%s", want, res.HTML)
+        }
+    }
+}
+
+func TestProcessSanitizesHTML(t *testing.T) {
+    src := `Hi <b onclick="x()">bold</b><script>alert(1)</script> <a href="javascript:alert(1)">link</a> <img src=x onerror=alert(1)> <sup>2`
+    res, err := Process(src)
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    for _, bad := range []string{"onclick", "script", "alert", "javascript", "onerror"} {
+        if strings.Contains(res.Normalized, bad) || strings.Contains(res.HTML, bad) {
+            t.Fatalf("%q survived sanitization:\nnormalized=%s\nhtml=%s", bad, res.Normalized, res.HTML)
+        }
+    }
+    if !strings.Contains(res.Normalized, "<b>bold</b>") {
+        t.Fatalf("allowed tag dropped: %s", res.Normalized)
+    }
+    if !strings.HasSuffix(res.Normalized, "<sup>2</sup>") {
+        t.Fatalf("unclosed tag not closed: %s", res.Normalized)
+    }
+}
+
+func TestProcessEscapesText(t *testing.T) {
+    res, err := Process(`Is 3 < 4 & "yes"? [x](javascript:alert(1))`)
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    if strings.Contains(res.HTML, "<4") || strings.Contains(res.HTML, "href") {
+        t.Fatalf("unexpected HTML: %s", res.HTML)
+    }
+}
+
+func TestProcessIsIdempotent(t *testing.T) {
+    first, err := Process("A <i>b</i> $c$ <div>d</div>\r\n\r\n\r\ne")
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    second, err := Process(first.Normalized)
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    if first.Normalized != second.Normalized || first.HTML != second.HTML {
+        t.Fatalf("not idempotent:\n%q\n%q", first.Normalized, second.Normalized)
+    }
+}
+
+func TestProcessDropsUnsafeLinkURLs(t *testing.T) {
+    res, err := Process("[x](javascript:alert(1)) [w](javascript&colon;alert(2)) ![y](//evil.example/a.png) [$z$](data:text/html,hi) [ok](/docs)")
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    for _, bad := range []string{"javascript", "evil.example", "data:"} {
+        if strings.Contains(res.Normalized, bad) || strings.Contains(res.HTML, bad) {
+            t.Fatalf("%q survived sanitization:\nnormalized=%s\nhtml=%s", bad, res.Normalized, res.HTML)
+        }
+    }
+    if !strings.Contains(res.Normalized, "[ok](/docs)") || !strings.Contains(res.HTML, `<a href="/docs"`) {
+        t.Fatalf("safe link dropped:\nnormalized=%s\nhtml=%s", res.Normalized, res.HTML)
+    }
+}
+
+func TestProcessLinksWithParentheses(t *testing.T) {
+    res, err := Process("See [Foo](https://en.wikipedia.org/wiki/Foo_(bar)).")
+    if err != nil {
+        t.Fatalf("Process error: %v", err)
+    }
+    if !strings.Contains(res.HTML, `href="https://en.wikipedia.org/wiki/Foo_(bar)"`) || !strings.Contains(res.HTML, "</a>.") {
+        t.Fatalf("unexpected HTML: %s", res.HTML)
+    }
+}
+
+func TestSafeURLRejectsProtocolRelative(t *testing.T) {
+    for _, raw := range []string{"//evil.example", " //evil.example", `/\evil.example`, `\\evil.example`} {
+        if _, ok := safeURL(raw, true); ok {
+            t.Fatalf("expected %q to be rejected", raw)
+        }
+    }
+    if _, ok := safeURL("/docs/a", true); !ok {
+        t.Fatalf("expected relative path to be allowed")
+    }
+}
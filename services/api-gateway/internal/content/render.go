@@ -0,0 +1,257 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	ulItemRe  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	olItemRe  = regexp.MustCompile(`^[0-9]{1,9}[.)]\s+(.*)$`)
+)
+
+// render turns the sanitized skeleton into HTML. Supported blocks: paragraphs,
+// ATX headings, flat bullet/numbered lists and fenced code. Supported inline
+// syntax: **strong**, *em*/_em_, `code`, links, images, backslash escapes and
+// math (left as TeX inside span.math for client-side typesetting).
+func render(f fragments) string {
+	var out strings.Builder
+	para := make([]string, 0, 4)
+	list := ""
+
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		out.WriteString("<p>" + renderInline(strings.Join(para, "\n"), f.pieces) + "</p>\n")
+		para = para[:0]
+	}
+	closeList := func() {
+		if list != "" {
+			out.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		if list != kind {
+			closeList()
+			out.WriteString("<" + kind + ">\n")
+			list = kind
+		}
+	}
+
+	for _, line := range strings.Split(f.skeleton, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flushPara()
+			closeList()
+			continue
+		}
+		if idx, ok := blockPlaceholder(trimmed, f); ok {
+			flushPara()
+			closeList()
+			out.WriteString(f.pieces[idx] + "\n")
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			closeList()
+			level := strconv.Itoa(len(m[1]))
+			out.WriteString("<h" + level + ">" + renderInline(m[2], f.pieces) + "</h" + level + ">\n")
+			continue
+		}
+		if m := ulItemRe.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			openList("ul")
+			out.WriteString("<li>" + renderInline(m[1], f.pieces) + "</li>\n")
+			continue
+		}
+		if m := olItemRe.FindStringSubmatch(trimmed); m != nil {
+			flushPara()
+			openList("ol")
+			out.WriteString("<li>" + renderInline(m[1], f.pieces) + "</li>\n")
+			continue
+		}
+		closeList()
+		para = append(para, trimmed)
+	}
+	flushPara()
+	closeList()
+	return strings.TrimSpace(out.String())
+}
+
+func blockPlaceholder(line string, f fragments) (int, bool) {
+	if len(line) < 3 || line[0] != 0 || line[len(line)-1] != 0 {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(line[1 : len(line)-1])
+	if err != nil || !f.block[idx] {
+		return 0, false
+	}
+	return idx, true
+}
+
+func renderInline(s string, pieces []string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == 0:
+			end := strings.IndexByte(s[i+1:], 0)
+			if end < 0 {
+				i++
+				continue
+			}
+			if idx, err := strconv.Atoi(s[i+1 : i+1+end]); err == nil && idx < len(pieces) {
+				b.WriteString(pieces[idx])
+			}
+			i += end + 2
+			continue
+
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '*' && strings.HasPrefix(s[i:], "**"):
+			if end := strings.Index(s[i+2:], "**"); end > 0 && !isSpace(s[i+2]) {
+				b.WriteString("<strong>" + renderInline(s[i+2:i+2+end], pieces) + "</strong>")
+				i += 2 + end + 2
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if c == '_' && i > 0 && isWordByte(s[i-1]) {
+				break
+			}
+			if end := findEmphasisClose(s, i+1, c); end > 0 {
+				b.WriteString("<em>" + renderInline(s[i+1:end], pieces) + "</em>")
+				i = end + 1
+				continue
+			}
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			if alt, url, n, ok := parseLink(s[i+1:]); ok {
+				if u, ok := safeURL(html.UnescapeString(url), false); ok {
+					b.WriteString(`<img src="` + html.EscapeString(u) + `" alt="` + html.EscapeString(stripPlaceholders(alt)) + `" />`)
+				} else {
+					b.WriteString(html.EscapeString(stripPlaceholders(alt)))
+				}
+				i += 1 + n
+				continue
+			}
+
+		case c == '[':
+			if text, url, n, ok := parseLink(s[i:]); ok {
+				if u, ok := safeURL(html.UnescapeString(url), true); ok {
+					b.WriteString(`<a href="` + html.EscapeString(u) + `"` + linkRel + `>` + renderInline(text, pieces) + `</a>`)
+				} else {
+					b.WriteString(renderInline(text, pieces))
+				}
+				i += n
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// findEmphasisClose finds a single (not doubled) closing marker for *em* or _em_.
+func findEmphasisClose(s string, from int, marker byte) int {
+	if from >= len(s) || isSpace(s[from]) || s[from] == marker {
+		return -1
+	}
+	for j := from; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] != marker || isSpace(s[j-1]) {
+			continue
+		}
+		if j+1 < len(s) && s[j+1] == marker {
+			j++
+			continue
+		}
+		if marker == '_' && j+1 < len(s) && isWordByte(s[j+1]) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// parseLink parses "[text](url)" at the start of s and returns the number of
+// bytes consumed.
+func parseLink(s string) (string, string, int, bool) {
+	if len(s) == 0 || s[0] != '[' {
+		return "", "", 0, false
+	}
+	closeText := strings.IndexByte(s, ']')
+	if closeText < 0 {
+		return "", "", 0, false
+	}
+	url, n, ok := linkDestination(s[closeText+1:])
+	if !ok {
+		return "", "", 0, false
+	}
+	return s[1:closeText], url, closeText + 1 + n, true
+}
+
+// linkDestination parses the "(url)" following a link's text and returns the
+// URL and the bytes consumed. Parentheses inside the URL must balance, as in
+// https://en.wikipedia.org/wiki/Foo_(bar); a backslash escapes the next byte.
+func linkDestination(s string) (string, int, bool) {
+	if len(s) == 0 || s[0] != '(' {
+		return "", 0, false
+	}
+	depth := 0
+	for j := 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case ' ', '\t', '\n':
+			return "", 0, false
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if j == 1 {
+				return "", 0, false
+			}
+			return s[1:j], j + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+func stripPlaceholders(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 {
+			if end := strings.IndexByte(s[i+1:], 0); end >= 0 {
+				i += end + 1
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isASCIIPunct(b byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", b) >= 0
+}
+
+func isWordByte(b byte) bool {
+	return isASCIILetter(b) || (b >= '0' && b <= '9') || b >= 0x80
+}
@@ -0,0 +1,369 @@
+package content
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// allowedTags maps permitted HTML elements to their permitted attributes.
+// Anything else is stripped (the element's text content is kept).
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"caption":    nil,
+	"code":       nil,
+	"em":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"li":         nil,
+	"mark":       nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"s":          nil,
+	"small":      nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// dropContentTags are removed together with everything up to their closing tag.
+var dropContentTags = map[string]bool{
+	"embed":    true,
+	"iframe":   true,
+	"math":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+var (
+	tagRe  = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)([^<>]*)>`)
+	attrRe = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+	numRe  = regexp.MustCompile(`^[0-9]{1,4}$`)
+	langRe = regexp.MustCompile(`^[a-zA-Z0-9_+-]{1,32}$`)
+)
+
+const linkRel = ` rel="nofollow noopener noreferrer"`
+
+// fragments is the intermediate form handed to the renderer: the sanitized
+// text with every pre-rendered piece (math, code, allowed tags) replaced by a
+// NUL-delimited placeholder. Normalize strips NUL, so placeholders cannot be
+// forged by authors.
+type fragments struct {
+	skeleton string
+	pieces   []string
+	block    map[int]bool
+}
+
+// sanitize walks the segments once, producing the normalized source (with
+// disallowed HTML removed and allowed tags re-serialized) and the fragments for
+// rendering. Unclosed allowed tags are closed at the end of the document.
+func sanitize(segs []segment) (string, fragments) {
+	var norm strings.Builder
+	var skel strings.Builder
+	f := fragments{block: map[int]bool{}}
+	placeholder := func(piece string, block bool) {
+		idx := len(f.pieces)
+		f.pieces = append(f.pieces, piece)
+		if block {
+			f.block[idx] = true
+		}
+		skel.WriteByte(0)
+		skel.WriteString(strconv.Itoa(idx))
+		skel.WriteByte(0)
+	}
+
+	open := make([]string, 0, 4)
+	dropping := ""
+	// opener is the last unclosed Markdown bracket: '[' for a link, '!' for
+	// an image, 0 for none. It carries across segments so link text may hold
+	// math or code.
+	var opener byte
+
+	for _, s := range segs {
+		if s.kind != segText {
+			if dropping != "" {
+				continue
+			}
+			norm.WriteString(s.raw)
+			switch s.kind {
+			case segCode:
+				placeholder("<code>"+html.EscapeString(s.body)+"</code>", false)
+			case segCodeBlock:
+				class := ""
+				if langRe.MatchString(s.lang) {
+					class = ` class="language-` + strings.ToLower(s.lang) + `"`
+				}
+				placeholder("<pre><code"+class+">"+html.EscapeString(s.body)+"</code></pre>", true)
+			case segMathInline:
+				placeholder(`<span class="math math-inline">`+html.EscapeString(s.body)+`</span>`, false)
+			case segMathDisplay:
+				placeholder(`<span class="math math-display">`+html.EscapeString(s.body)+`</span>`, false)
+			}
+			continue
+		}
+
+		raw := s.raw
+		for i := 0; i < len(raw); {
+			if dropping != "" {
+				end := indexFold(raw[i:], "</"+dropping)
+				if end < 0 {
+					i = len(raw)
+					continue
+				}
+				i += end
+				if gt := strings.IndexByte(raw[i:], '>'); gt >= 0 {
+					i += gt + 1
+				} else {
+					i = len(raw)
+				}
+				dropping = ""
+				continue
+			}
+
+			switch raw[i] {
+			case '[':
+				opener = '['
+				if i > 0 && raw[i-1] == '!' {
+					opener = '!'
+				}
+			case ']':
+				if url, n, ok := linkDestination(raw[i+1:]); ok && opener != 0 {
+					norm.WriteByte(']')
+					skel.WriteByte(']')
+					// Entities in the destination decode before the scheme
+					// check, so "javascript&colon;" is caught like "javascript:".
+					if _, safe := safeURL(html.UnescapeString(url), opener == '['); safe {
+						norm.WriteString(raw[i+1 : i+1+n])
+						skel.WriteString(raw[i+1 : i+1+n])
+					}
+					opener = 0
+					i += 1 + n
+					continue
+				}
+				opener = 0
+			case '\\':
+				if i+1 < len(raw) && (raw[i+1] == '[' || raw[i+1] == ']') {
+					norm.WriteString(raw[i : i+2])
+					skel.WriteString(raw[i : i+2])
+					i += 2
+					continue
+				}
+			}
+
+			if raw[i] != '<' {
+				norm.WriteByte(raw[i])
+				skel.WriteByte(raw[i])
+				i++
+				continue
+			}
+
+			if strings.HasPrefix(raw[i:], "<!--") {
+				if end := strings.Index(raw[i+4:], "-->"); end >= 0 {
+					i += 4 + end + 3
+				} else {
+					i = len(raw)
+				}
+				continue
+			}
+
+			m := tagRe.FindStringSubmatch(raw[i:])
+			if m == nil {
+				norm.WriteByte('<')
+				skel.WriteByte('<')
+				i++
+				continue
+			}
+			i += len(m[0])
+			closing := m[1] == "/"
+			name := strings.ToLower(m[2])
+
+			if dropContentTags[name] {
+				if !closing && !strings.HasSuffix(strings.TrimSpace(m[3]), "/") {
+					dropping = name
+				}
+				continue
+			}
+			allowed, ok := allowedTags[name]
+			if !ok {
+				continue
+			}
+
+			if closing {
+				at := lastIndex(open, name)
+				if at < 0 {
+					continue
+				}
+				for len(open) > at {
+					top := open[len(open)-1]
+					open = open[:len(open)-1]
+					norm.WriteString("</" + top + ">")
+					placeholder("</"+top+">", false)
+				}
+				continue
+			}
+
+			tag, keep := buildTag(name, m[3], allowed)
+			if !keep {
+				continue
+			}
+			norm.WriteString(tag)
+			placeholder(renderTag(name, tag), false)
+			if !voidTags[name] {
+				open = append(open, name)
+			}
+		}
+	}
+
+	for len(open) > 0 {
+		top := open[len(open)-1]
+		open = open[:len(open)-1]
+		norm.WriteString("</" + top + ">")
+		placeholder("</"+top+">", false)
+	}
+
+	f.skeleton = skel.String()
+	return norm.String(), f
+}
+
+// buildTag re-serializes an opening tag keeping only allowed, safe attributes.
+func buildTag(name string, rawAttrs string, allowed []string) (string, bool) {
+	var b strings.Builder
+	b.WriteString("<" + name)
+	hasSrc := false
+	seen := map[string]bool{}
+	for _, am := range attrRe.FindAllStringSubmatch(rawAttrs, -1) {
+		key := strings.ToLower(am[1])
+		if seen[key] || !contains(allowed, key) {
+			continue
+		}
+		val := am[2] + am[3] + am[4]
+		val = html.UnescapeString(val)
+		switch key {
+		case "href":
+			u, ok := safeURL(val, true)
+			if !ok {
+				continue
+			}
+			val = u
+		case "src":
+			u, ok := safeURL(val, false)
+			if !ok {
+				continue
+			}
+			val = u
+			hasSrc = true
+		case "colspan", "rowspan", "start", "width", "height":
+			if !numRe.MatchString(strings.TrimSpace(val)) {
+				continue
+			}
+			val = strings.TrimSpace(val)
+		default:
+			val = truncateRunes(val, 300)
+		}
+		seen[key] = true
+		b.WriteString(" " + key + `="` + html.EscapeString(val) + `"`)
+	}
+	if name == "img" && !hasSrc {
+		return "", false
+	}
+	if voidTags[name] {
+		b.WriteString(" />")
+	} else {
+		b.WriteString(">")
+	}
+	return b.String(), true
+}
+
+// renderTag adds render-only attributes (not stored in the normalized form).
+func renderTag(name string, tag string) string {
+	if name != "a" {
+		return tag
+	}
+	return strings.TrimSuffix(tag, ">") + linkRel + ">"
+}
+
+// safeURL accepts http(s) URLs, mailto (for links) and relative paths.
+func safeURL(raw string, allowMailto bool) (string, bool) {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(raw) {
+		if r <= 0x20 || r == 0x7f {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	u := b.String()
+	if u == "" {
+		return "", false
+	}
+	// Protocol-relative URLs ("//host", and "/\host" as browsers read it)
+	// point off-site.
+	if len(u) >= 2 && (u[0] == '/' || u[0] == '\\') && (u[1] == '/' || u[1] == '\\') {
+		return "", false
+	}
+	colon := strings.IndexByte(u, ':')
+	if colon < 0 {
+		return u, true
+	}
+	if cut := strings.IndexAny(u, "/?#"); cut >= 0 && cut < colon {
+		return u, true
+	}
+	switch strings.ToLower(u[:colon]) {
+	case "http", "https":
+		return u, true
+	case "mailto":
+		return u, allowMailto
+	}
+	return "", false
+}
+
+// indexFold is an ASCII case-insensitive strings.Index.
+func indexFold(s string, substr string) int {
+	n := len(substr)
+	for i := 0; i+n <= len(s); i++ {
+		if strings.EqualFold(s[i:i+n], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func lastIndex(list []string, v string) int {
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// requiredColumns lists the tables/columns the handlers query directly.
+// It is not the full schema: it covers the columns that earlier migrations
+// got wrong, so a stale database fails at boot instead of at first request.
+var requiredColumns = map[string][]string{
+	"question_banks":                      {"id", "name", "is_hidden", "created_by_user_id", "created_at", "deleted_at", "source_locale", "cloned_from_id"},
+	"question_bank_topics":                {"id", "package_id", "name", "is_hidden", "created_by_user_id", "created_at", "deleted_at"},
+	"question_bank_difficulties":          {"id", "display_name", "sort_order"},
+	"question_bank_questions":             {"id", "package_id", "topic_id", "difficulty_id", "prompt", "explanation_text", "status", "stimulus_id", "stimulus_order", "revision", "review_round", "shuffle_choices", "deleted_at", "cloned_from_id"},
+	"question_bank_choices":               {"id", "question_id", "order_index", "text", "is_pinned", "cloned_from_id"},
+	"question_bank_correct_choice":        {"question_id", "choice_id"},
+	"question_bank_hints":                 {"id", "question_id", "order_index", "text", "cloned_from_id"},
+	"exam_package_question_bank_packages": {"exam_package_id", "question_bank_package_id", "created_at"},
+	"practice_templates":                  {"id", "exam_package_id", "topic_id", "difficulty_id", "cloned_from_id", "sections", "timing"},
+	"practice_sessions":                   {"id", "user_id", "package_id", "status", "question_order", "questions_snapshot", "stimuli_snapshot", "question_timings", "shuffle_seed", "locale", "mode", "source", "topic_id", "difficulty_id", "navigation", "marked_question_ids", "sections", "section_started_at", "timing", "hints_used", "hint_penalty"},
+	"exam_packages":                       {"id", "source_locale", "cloned_from_id"},
+	"users":                               {"id", "locale", "timezone", "leaderboard_opt_out", "leaderboard_name"},
+	"content_translations":                {"id", "entity_type", "entity_id", "locale", "fields", "source_revision", "status"},
+	"user_topic_mastery":                  {"user_id", "topic_id", "rating", "attempts", "correct_count"},
+	"question_elo_ratings":                {"question_id", "rating", "attempts"},
+	"review_deck_items":                   {"user_id", "question_id", "source", "ease_factor", "interval_days", "repetitions", "lapses", "due_at"},
+	"question_bookmarks":                  {"user_id", "question_id", "note"},
+	"clone_jobs":                          {"id", "kind", "source_id", "target_id", "status", "total_items", "copied_items"},
+	"practice_session_events":             {"session_id", "user_id", "event_type", "payload"},
+	"exam_sessions":                       {"user_id", "id", "status", "last_heartbeat_at", "abandoned_at"},
+	"practice_answers":                    {"session_id", "user_id", "question_id", "choice_id", "correct", "is_final", "client_seq", "client_ts", "hints_used", "credit"},
+	"practice_answer_requests":            {"session_id", "idempotency_key", "question_id", "choice_id", "response"},
+	"coin_ledger":                         {"id", "user_id", "amount", "reason", "source_id", "award_key", "created_at"},
+	"achievements":                        {"id", "code", "name", "description", "rule", "coins", "is_active", "sort_order"},
+	"user_achievements":                   {"user_id", "achievement_id", "earned_at"},
+	"user_streaks":                        {"user_id", "current_days", "longest_days", "last_active_date"},
+	"leaderboard_results":                 {"user_id", "source", "source_id", "exam_package_id", "template_id", "score", "duration_seconds", "finished_at"},
+	"leaderboard_scores":                  {"scope_type", "scope_id", "period", "period_start", "user_id", "score", "duration_seconds", "sessions", "exams"},
+	"cohorts":                             {"id", "name", "created_by_user_id", "created_at"},
+	"cohort_members":                      {"cohort_id", "user_id", "added_by_user_id", "created_at"},
+}
+
+// CheckSchema verifies that every required column exists in the current
+// schema. The service does not run migrations itself, so this is the guard
+// against booting on a database that is behind the code.
+func CheckSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	tables := make([]string, 0, len(requiredColumns))
+	for t := range requiredColumns {
+		tables = append(tables, t)
+	}
+
+	rows, err := pool.Query(ctx, `select table_name, column_name from information_schema.columns
+		where table_schema=current_schema() and table_name = any($1)`, tables)
+	if err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+	defer rows.Close()
+
+	present := map[string]bool{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("schema check: %w", err)
+		}
+		present[table+"."+column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+
+	missing := []string{}
+	for table, columns := range requiredColumns {
+		for _, column := range columns {
+			if !present[table+"."+column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("schema check: missing columns %s (run migrations: go run ./cmd/migrate up)", strings.Join(missing, ", "))
+	}
+	return nil
+}
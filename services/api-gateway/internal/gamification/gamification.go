@@ -0,0 +1,184 @@
+// Package gamification awards coins, daily streaks and achievements for
+// study events the server observes: finished practice sessions and submitted
+// exams. Coins live in an append-only ledger; every award has a key that is
+// unique per student, so replaying an event never pays twice.
+//
+// A streak counts consecutive calendar days with at least one award event,
+// in the student's timezone. Achievements are rows of data whose rule names
+// a metric and a threshold; they are checked after every event.
+package gamification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	// Timezones must resolve even where the host has no zoneinfo.
+	_ "time/tzdata"
+)
+
+// Coins paid per event.
+const (
+	PracticeFinishedCoins = 5
+	ExamSubmittedCoins    = 25
+	StreakDayCoins        = 2
+)
+
+// Ledger reasons.
+const (
+	ReasonPracticeFinished = "practice_finished"
+	ReasonExamSubmitted    = "exam_submitted"
+	ReasonStreakDay        = "streak_day"
+	ReasonAchievement      = "achievement"
+)
+
+// Achievement rule metrics.
+const (
+	// MetricPracticeSessions counts finished practice sessions with at least
+	// one graded answer.
+	MetricPracticeSessions = "practiceSessionsCompleted"
+	// MetricExamsSubmitted counts submitted exam sessions.
+	MetricExamsSubmitted = "examsSubmitted"
+	// MetricCorrectAnswers counts correct graded practice answers.
+	MetricCorrectAnswers = "correctAnswers"
+	// MetricStreakDays is the longest streak, in days.
+	MetricStreakDays = "streakDays"
+	// MetricSessionAccuracy is the accuracy, in percent, of the practice
+	// session that triggered the check; sessions shorter than MinQuestions
+	// do not count.
+	MetricSessionAccuracy = "sessionAccuracy"
+)
+
+// Rule is the condition of an achievement.
+type Rule struct {
+	Metric       string `json:"metric"`
+	Threshold    int    `json:"threshold"`
+	MinQuestions int    `json:"minQuestions,omitempty"`
+}
+
+// ParseRule decodes and validates a rule written by an admin.
+func ParseRule(raw []byte) (Rule, error) {
+	var r Rule
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimSpace(raw)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&r); err != nil {
+		return Rule{}, fmt.Errorf("invalid rule: %v", err)
+	}
+	if dec.More() {
+		return Rule{}, errors.New("invalid rule: trailing data")
+	}
+	return r, r.Validate()
+}
+
+// Validate checks the metric and value ranges.
+func (r Rule) Validate() error {
+	switch r.Metric {
+	case MetricPracticeSessions, MetricExamsSubmitted, MetricCorrectAnswers, MetricStreakDays:
+		if r.MinQuestions != 0 {
+			return errors.New("minQuestions only applies to sessionAccuracy")
+		}
+	case MetricSessionAccuracy:
+		if r.Threshold > 100 {
+			return errors.New("sessionAccuracy threshold must be at most 100")
+		}
+		if r.MinQuestions < 0 {
+			return errors.New("minQuestions must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown metric %q", r.Metric)
+	}
+	if r.Threshold < 1 {
+		return errors.New("threshold must be at least 1")
+	}
+	return nil
+}
+
+// SessionResult is the outcome of the practice session behind an event.
+type SessionResult struct {
+	Questions int
+	Correct   int
+}
+
+// Stats are a student's totals after an event.
+type Stats struct {
+	PracticeSessionsCompleted int
+	ExamsSubmitted            int
+	CorrectAnswers            int
+	StreakDays                int
+	// Session is nil for events other than a finished practice session.
+	Session *SessionResult
+}
+
+// Met reports whether the stats satisfy the rule.
+func (r Rule) Met(s Stats) bool {
+	switch r.Metric {
+	case MetricPracticeSessions:
+		return s.PracticeSessionsCompleted >= r.Threshold
+	case MetricExamsSubmitted:
+		return s.ExamsSubmitted >= r.Threshold
+	case MetricCorrectAnswers:
+		return s.CorrectAnswers >= r.Threshold
+	case MetricStreakDays:
+		return s.StreakDays >= r.Threshold
+	case MetricSessionAccuracy:
+		if s.Session == nil || s.Session.Questions == 0 || s.Session.Questions < r.MinQuestions {
+			return false
+		}
+		return s.Session.Correct*100 >= r.Threshold*s.Session.Questions
+	}
+	return false
+}
+
+// Streak is a student's run of consecutive active days.
+type Streak struct {
+	CurrentDays int
+	LongestDays int
+	// LastActiveDate is the last counted day, at midnight UTC.
+	LastActiveDate *time.Time
+}
+
+// ValidTimezone reports whether tz is an IANA timezone name.
+func ValidTimezone(tz string) bool {
+	if tz == "" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// LocalDay is the calendar day of at in the timezone tz, as midnight UTC of
+// that date. Unknown timezones count as UTC.
+func LocalDay(at time.Time, tz string) time.Time {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := at.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Record counts day in the streak. It reports false when the day was already
+// counted, or is older than the last counted day (a late event).
+func (s Streak) Record(day time.Time) (Streak, bool) {
+	if s.LastActiveDate != nil && !day.After(*s.LastActiveDate) {
+		return s, false
+	}
+	if s.LastActiveDate != nil && day.Equal(s.LastActiveDate.AddDate(0, 0, 1)) {
+		s.CurrentDays++
+	} else {
+		s.CurrentDays = 1
+	}
+	s.LongestDays = max(s.LongestDays, s.CurrentDays)
+	s.LastActiveDate = &day
+	return s, true
+}
+
+// CurrentAt is the streak as of today: a streak whose last day is before
+// yesterday has been broken.
+func (s Streak) CurrentAt(today time.Time) int {
+	if s.LastActiveDate == nil || s.LastActiveDate.Before(today.AddDate(0, 0, -1)) {
+		return 0
+	}
+	return s.CurrentDays
+}
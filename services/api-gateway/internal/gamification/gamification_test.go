@@ -0,0 +1,114 @@
+package gamification
+
+import (
+    "testing"
+    "time"
+)
+
+func day(s string) time.Time {
+    t, err := time.Parse(time.DateOnly, s)
+    if err != nil {
+        panic(err)
+    }
+    return t
+}
+
+func TestParseRule(t *testing.T) {
+    r, err := ParseRule([]byte(`{"metric": "sessionAccuracy", "threshold": 90, "minQuestions": 10}`))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if r.Metric != MetricSessionAccuracy || r.Threshold != 90 || r.MinQuestions != 10 {
+        t.Fatalf("unexpected rule %+v", r)
+    }
+    for _, raw := range []string{
+        `{"metric": "logins", "threshold": 1}`,
+        `{"metric": "examsSubmitted", "threshold": 0}`,
+        `{"metric": "examsSubmitted", "threshold": 1, "minQuestions": 5}`,
+        `{"metric": "sessionAccuracy", "threshold": 101}`,
+        `{"metric": "streakDays", "threshold": 7, "extra": true}`,
+        `[]`,
+    } {
+        if _, err := ParseRule([]byte(raw)); err == nil {
+            t.Fatalf("expected %s to be rejected", raw)
+        }
+    }
+}
+
+func TestRuleMet(t *testing.T) {
+    stats := Stats{PracticeSessionsCompleted: 3, ExamsSubmitted: 1, CorrectAnswers: 40, StreakDays: 6}
+    cases := []struct {
+        rule Rule
+        want bool
+    }{
+        {Rule{Metric: MetricPracticeSessions, Threshold: 3}, true},
+        {Rule{Metric: MetricPracticeSessions, Threshold: 4}, false},
+        {Rule{Metric: MetricExamsSubmitted, Threshold: 1}, true},
+        {Rule{Metric: MetricCorrectAnswers, Threshold: 50}, false},
+        {Rule{Metric: MetricStreakDays, Threshold: 7}, false},
+        // Accuracy needs a session behind the event.
+        {Rule{Metric: MetricSessionAccuracy, Threshold: 80}, false},
+    }
+    for _, tc := range cases {
+        if got := tc.rule.Met(stats); got != tc.want {
+            t.Fatalf("%+v: got %v, want %v", tc.rule, got, tc.want)
+        }
+    }
+
+    stats.Session = &SessionResult{Questions: 10, Correct: 9}
+    if !(Rule{Metric: MetricSessionAccuracy, Threshold: 90, MinQuestions: 10}).Met(stats) {
+        t.Fatalf("9 of 10 should reach 90%%")
+    }
+    if (Rule{Metric: MetricSessionAccuracy, Threshold: 91}).Met(stats) {
+        t.Fatalf("9 of 10 should not reach 91%%")
+    }
+    if (Rule{Metric: MetricSessionAccuracy, Threshold: 50, MinQuestions: 20}).Met(stats) {
+        t.Fatalf("a session shorter than minQuestions should not count")
+    }
+}
+
+func TestLocalDay(t *testing.T) {
+    at := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+    if got := LocalDay(at, "UTC"); !got.Equal(day("2026-03-01")) {
+        t.Fatalf("expected 2026-03-01 in UTC, got %v", got)
+    }
+    if got := LocalDay(at, "Asia/Tokyo"); !got.Equal(day("2026-03-02")) {
+        t.Fatalf("expected 2026-03-02 in Tokyo, got %v", got)
+    }
+    if got := LocalDay(at, "Not/AZone"); !got.Equal(day("2026-03-01")) {
+        t.Fatalf("unknown timezones should count as UTC, got %v", got)
+    }
+    if ValidTimezone("Not/AZone") || !ValidTimezone("America/New_York") {
+        t.Fatalf("unexpected timezone validation")
+    }
+}
+
+func TestStreakRecord(t *testing.T) {
+    var s Streak
+    s, counted := s.Record(day("2026-03-01"))
+    if !counted || s.CurrentDays != 1 || s.LongestDays != 1 {
+        t.Fatalf("first day should start a streak, got %+v", s)
+    }
+    if _, counted := s.Record(day("2026-03-01")); counted {
+        t.Fatalf("the same day should count once")
+    }
+    s, _ = s.Record(day("2026-03-02"))
+    s, _ = s.Record(day("2026-03-03"))
+    if s.CurrentDays != 3 || s.LongestDays != 3 {
+        t.Fatalf("consecutive days should extend the streak, got %+v", s)
+    }
+    if _, counted := s.Record(day("2026-02-27")); counted {
+        t.Fatalf("a late event for an earlier day should not count")
+    }
+    s, _ = s.Record(day("2026-03-05"))
+    if s.CurrentDays != 1 || s.LongestDays != 3 {
+        t.Fatalf("a missed day should restart the streak, got %+v", s)
+    }
+
+    if got := s.CurrentAt(day("2026-03-06")); got != 1 {
+        t.Fatalf("a streak active yesterday is still current, got %d", got)
+    }
+    if got := s.CurrentAt(day("2026-03-07")); got != 0 {
+        t.Fatalf("a streak last active two days ago is broken, got %d", got)
+    }
+}
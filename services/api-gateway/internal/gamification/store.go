@@ -0,0 +1,199 @@
+package gamification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+// event is one award-worthy thing a student did.
+type event struct {
+	key      string
+	reason   string
+	sourceID string
+	coins    int
+	at       time.Time
+	session  *SessionResult
+}
+
+// PracticeFinished awards a finished practice session: its coins, the day's
+// streak and any achievements it unlocks. Sessions without a graded answer
+// earn nothing. Calling it again for the same session is a no-op.
+func PracticeFinished(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) error {
+	var status string
+	var finishedAt time.Time
+	var targetCount, correctCount, answered int
+	err := pool.QueryRow(ctx, `select s.status, coalesce(s.last_activity_at, s.created_at), s.target_count, s.correct_count,
+			(select count(*) from practice_answers a where a.session_id=s.id and a.is_final)
+		from practice_sessions s where s.id=$1 and s.user_id=$2`, sessionID, userID).
+		Scan(&status, &finishedAt, &targetCount, &correctCount, &answered)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "finished" || answered == 0 {
+		return nil
+	}
+	return award(ctx, pool, userID, event{
+		key:      "practice:" + sessionID,
+		reason:   ReasonPracticeFinished,
+		sourceID: sessionID,
+		coins:    PracticeFinishedCoins,
+		at:       finishedAt,
+		session:  &SessionResult{Questions: targetCount, Correct: correctCount},
+	})
+}
+
+// ExamSubmitted awards a submitted exam session, like PracticeFinished.
+func ExamSubmitted(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) error {
+	var status string
+	var submittedAt *time.Time
+	err := pool.QueryRow(ctx, `select status, submitted_at from exam_sessions where user_id=$1 and id=$2`, userID, sessionID).
+		Scan(&status, &submittedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "finished" || submittedAt == nil {
+		return nil
+	}
+	return award(ctx, pool, userID, event{
+		key:      "exam:" + sessionID,
+		reason:   ReasonExamSubmitted,
+		sourceID: sessionID,
+		coins:    ExamSubmittedCoins,
+		at:       *submittedAt,
+	})
+}
+
+// LoadStreak returns a student's streak and timezone ("UTC" when unset).
+func LoadStreak(ctx context.Context, pool *pgxpool.Pool, userID string) (Streak, string, error) {
+	var s Streak
+	var tz string
+	err := pool.QueryRow(ctx, `select coalesce(s.current_days, 0), coalesce(s.longest_days, 0), s.last_active_date, coalesce(u.timezone, 'UTC')
+		from users u left join user_streaks s on s.user_id=u.id where u.id=$1`, userID).
+		Scan(&s.CurrentDays, &s.LongestDays, &s.LastActiveDate, &tz)
+	return s, tz, err
+}
+
+func award(ctx context.Context, pool *pgxpool.Pool, userID string, e event) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The streak row serializes a student's awards.
+	if _, err := tx.Exec(ctx, `insert into user_streaks (user_id) values ($1) on conflict (user_id) do nothing`, userID); err != nil {
+		return err
+	}
+	var streak Streak
+	var tz string
+	err = tx.QueryRow(ctx, `select s.current_days, s.longest_days, s.last_active_date, coalesce(u.timezone, 'UTC')
+		from user_streaks s join users u on u.id=s.user_id where s.user_id=$1 for update of s`, userID).
+		Scan(&streak.CurrentDays, &streak.LongestDays, &streak.LastActiveDate, &tz)
+	if err != nil {
+		return err
+	}
+
+	paid, err := credit(ctx, tx, userID, e.coins, e.reason, e.sourceID, e.key)
+	if err != nil || !paid {
+		return err
+	}
+
+	day := LocalDay(e.at, tz)
+	if next, counted := streak.Record(day); counted {
+		streak = next
+		_, err := tx.Exec(ctx, `update user_streaks set current_days=$2, longest_days=$3, last_active_date=$4, updated_at=now() where user_id=$1`,
+			userID, streak.CurrentDays, streak.LongestDays, day)
+		if err != nil {
+			return err
+		}
+		date := day.Format(time.DateOnly)
+		if _, err := credit(ctx, tx, userID, StreakDayCoins, ReasonStreakDay, date, "streak:"+date); err != nil {
+			return err
+		}
+	}
+
+	stats := Stats{StreakDays: streak.LongestDays, Session: e.session}
+	err = tx.QueryRow(ctx, `select
+			(select count(*) from practice_sessions s where s.user_id=$1 and s.status='finished'
+				and exists (select 1 from practice_answers a where a.session_id=s.id and a.is_final)),
+			(select count(*) from exam_sessions where user_id=$1 and status='finished' and submitted_at is not null),
+			(select count(*) from practice_answers where user_id=$1 and is_final and correct)`, userID).
+		Scan(&stats.PracticeSessionsCompleted, &stats.ExamsSubmitted, &stats.CorrectAnswers)
+	if err != nil {
+		return err
+	}
+	if err := unlockAchievements(ctx, tx, userID, stats); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// unlockAchievements grants the active achievements the student has not
+// earned yet and now meets, paying their coins.
+func unlockAchievements(ctx context.Context, tx pgx.Tx, userID string, stats Stats) error {
+	rows, err := tx.Query(ctx, `select a.id, a.rule, a.coins from achievements a
+		where a.is_active and not exists (select 1 from user_achievements ua where ua.user_id=$1 and ua.achievement_id=a.id)
+		order by a.sort_order, a.id`, userID)
+	if err != nil {
+		return err
+	}
+	type candidate struct {
+		id    string
+		rule  []byte
+		coins int
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var a candidate
+		if err := rows.Scan(&a.id, &a.rule, &a.coins); err != nil {
+			rows.Close()
+			return err
+		}
+		candidates = append(candidates, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range candidates {
+		rule, err := ParseRule(a.rule)
+		if err != nil || !rule.Met(stats) {
+			continue
+		}
+		tag, err := tx.Exec(ctx, `insert into user_achievements (user_id, achievement_id) values ($1,$2) on conflict do nothing`, userID, a.id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 || a.coins == 0 {
+			continue
+		}
+		if _, err := credit(ctx, tx, userID, a.coins, ReasonAchievement, a.id, "achievement:"+a.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credit appends a ledger entry unless one with the same key exists, and
+// reports whether it did.
+func credit(ctx context.Context, tx pgx.Tx, userID string, amount int, reason, sourceID, key string) (bool, error) {
+	tag, err := tx.Exec(ctx, `insert into coin_ledger (id, user_id, amount, reason, source_id, award_key) values ($1,$2,$3,$4,$5,$6)
+		on conflict (user_id, award_key) do nothing`, util.NewID("coin"), userID, amount, reason, sourceID, key)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,137 @@
+// Package grading grades practice sessions whose answers are only scored at
+// the end (free navigation). Until then every saved answer is a draft row in
+// practice_answers (is_final = false); grading marks the latest answer to each
+// question as final, so the earlier rows remain as the answer-change history.
+package grading
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/hints"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+	"github.com/ace-platform/api-gateway/internal/mastery"
+	"github.com/ace-platform/api-gateway/internal/srs"
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// DefaultSecondsPerQuestion is the expected answer time of untimed sessions,
+// used to grade review-deck quality.
+const DefaultSecondsPerQuestion = timing.DefaultSecondsPerQuestion
+
+// Answer is a graded answer.
+type Answer struct {
+	QuestionID string
+	Correct    bool
+	HintsUsed  int
+	// Credit is what the answer earned after the hint penalty.
+	Credit float64
+}
+
+// Finalize finishes a session and grades its pending answers: the latest
+// answer to each question becomes final, recording the hints revealed for the
+// question and the credit earned, and correct_count is recomputed from the
+// final answers. Newly graded answers then update the student's mastery and
+// review deck, and the finished session earns its coins and achievements and
+// joins the leaderboards. Finalize is idempotent and a no-op for sessions
+// graded as they went (linear navigation), apart from finishing them.
+func Finalize(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) ([]Answer, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var mode string
+	var isTimed bool
+	var timeLimitSeconds *int
+	var targetCount int
+	var hintPenalty float64
+	var timingsRaw, orderRaw, timingRaw, hintsUsedRaw []byte
+	err = tx.QueryRow(ctx, `select mode, is_timed, time_limit_seconds, target_count, question_timings, question_order, timing, hints_used, hint_penalty
+		from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).
+		Scan(&mode, &isTimed, &timeLimitSeconds, &targetCount, &timingsRaw, &orderRaw, &timingRaw, &hintsUsedRaw, &hintPenalty)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, `update practice_answers set is_final=true, hints_used=coalesce(($3::json->>question_id)::int, 0)
+		where id in (
+			select distinct on (question_id) id from practice_answers
+			where session_id=$1 and user_id=$2
+			order by question_id, ts desc, id desc
+		) and not is_final
+		and not exists (select 1 from practice_answers f where f.session_id=$1 and f.question_id=practice_answers.question_id and f.is_final)
+		returning question_id, correct, hints_used`, sessionID, userID, hintsUsedRaw)
+	if err != nil {
+		return nil, err
+	}
+	var graded []Answer
+	for rows.Next() {
+		var a Answer
+		if err := rows.Scan(&a.QuestionID, &a.Correct, &a.HintsUsed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		graded = append(graded, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, a := range graded {
+		graded[i].Credit = hints.Credit(a.Correct, a.HintsUsed, hintPenalty)
+		_, err := tx.Exec(ctx, `update practice_answers set credit=$3 where session_id=$1 and question_id=$2 and is_final`, sessionID, a.QuestionID, graded[i].Credit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `update practice_sessions set status='finished', last_activity_at=now(),
+			correct_count=(select count(*) from practice_answers where session_id=$1 and is_final and correct)
+		where id=$1 and user_id=$2`, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Mastery commits with the grading, so a retry never counts an answer twice.
+	for _, a := range graded {
+		if err := mastery.Record(ctx, tx, userID, a.QuestionID, a.Correct, a.Credit); err != nil {
+			log.Printf("mastery: record answer for %s failed: %v", a.QuestionID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	timings := map[string]int{}
+	_ = json.Unmarshal(timingsRaw, &timings)
+	var order []string
+	_ = json.Unmarshal(orderRaw, &order)
+	snap := timing.DecodeSnapshot(timingRaw)
+	// Sessions started before timing snapshots spread their limit evenly.
+	expectedFor := func(questionID string) int {
+		if timingRaw == nil && isTimed && timeLimitSeconds != nil && targetCount > 0 {
+			return max(1, *timeLimitSeconds/targetCount)
+		}
+		return snap.Seconds(slices.Index(order, questionID))
+	}
+	for _, a := range graded {
+		quality := srs.Grade(a.Correct, timings[a.QuestionID], expectedFor(a.QuestionID))
+		if err := srs.RecordAnswer(ctx, pool, userID, a.QuestionID, quality, mode == "review"); err != nil {
+			log.Printf("srs: record answer for %s failed: %v", a.QuestionID, err)
+		}
+	}
+	if err := gamification.PracticeFinished(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("gamification: award practice session %s failed: %v", sessionID, err)
+	}
+	if err := leaderboard.RecordPractice(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("leaderboard: record practice session %s failed: %v", sessionID, err)
+	}
+	return graded, nil
+}
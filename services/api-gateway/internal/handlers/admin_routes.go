@@ -325,9 +325,9 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				(select count(*) from users where deleted_at is null and role='student') as users_student,
 				(select count(*) from users where deleted_at is null and role='instructor') as users_instructor,
 				(select count(*) from users where deleted_at is null and role='admin') as users_admin,
-				(select count(*) from question_banks) as qb_packages,
-				(select count(*) from question_bank_topics) as qb_topics,
-				(select count(*) from question_bank_questions) as qb_questions,
+				(select count(*) from question_banks where deleted_at is null) as qb_packages,
+				(select count(*) from question_bank_topics where deleted_at is null) as qb_topics,
+				(select count(*) from question_bank_questions where deleted_at is null) as qb_questions,
 				(select count(*) from exam_sessions) as exam_sessions,
 				(select count(*) from exam_sessions where submitted_at is not null) as exam_submitted,
 				(select count(*) from exam_session_events) as exam_events,
@@ -353,7 +353,7 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		}
 
 		questionByStatus := map[string]int64{}
-		rows, err := pool.Query(ctx, `select status, count(*) from question_bank_questions group by status`)
+		rows, err := pool.Query(ctx, `select status, count(*) from question_bank_questions where deleted_at is null group by status`)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute question stats"})
 			return
@@ -690,6 +690,7 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				c.JSON(http.StatusNotFound, gin.H{"message": "exam package not found"})
 				return
 			}
+			_, _ = pool.Exec(context.Background(), `delete from content_translations where entity_type='exam_package' and entity_id=$1`, examPackageID)
 			audit(context.Background(), pool, actorUserID, actorRole, "admin.exam_packages.delete", "exam_package", examPackageID, nil)
 			c.JSON(http.StatusOK, gin.H{"success": true})
 		})
@@ -781,16 +782,10 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				return
 			}
 
-			policy := json.RawMessage(`{}`)
-			if req.Policy != nil {
-				if !json.Valid(*req.Policy) {
-					c.JSON(http.StatusBadRequest, gin.H{"message": "policy must be valid json"})
-					return
-				}
-				policy = *req.Policy
-				if len(policy) == 0 {
-					policy = json.RawMessage(`{}`)
-				}
+			policy, tierPolicy, err := parseTierPolicy(req.Policy)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
 			}
 
 			isActive := true
@@ -821,9 +816,9 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 			var newID string
 			err = tx.QueryRow(ctx, `
-				insert into exam_package_tiers (exam_package_id, code, name, sort_order, is_default, is_active, policy)
-				values ($1, $2, $3, $4, false, $5, $6)
-				returning id::text`, examPackageID, code, name, sortOrder, isActive, policy).Scan(&newID)
+				insert into exam_package_tiers (exam_package_id, code, name, sort_order, is_default, is_active, policy, max_practice_sessions_per_week, max_exam_sessions_per_week)
+				values ($1, $2, $3, $4, false, $5, $6, $7, $8)
+				returning id::text`, examPackageID, code, name, sortOrder, isActive, policy, tierPolicy.MaxPracticeSessionsPerWeek, tierPolicy.MaxExamSessionsPerWeek).Scan(&newID)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to create tier"})
 				return
@@ -936,17 +931,15 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				idx++
 			}
 			if req.Policy != nil {
-				if !json.Valid(*req.Policy) {
-					c.JSON(http.StatusBadRequest, gin.H{"message": "policy must be valid json"})
+				v, tierPolicy, err := parseTierPolicy(req.Policy)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 					return
 				}
-				v := *req.Policy
-				if len(v) == 0 {
-					v = json.RawMessage(`{}`)
-				}
-				set = append(set, "policy="+sqlParam(idx))
-				args = append(args, v)
-				idx++
+				// The quota columns mirror the policy.
+				set = append(set, "policy="+sqlParam(idx), "max_practice_sessions_per_week="+sqlParam(idx+1), "max_exam_sessions_per_week="+sqlParam(idx+2))
+				args = append(args, v, tierPolicy.MaxPracticeSessionsPerWeek, tierPolicy.MaxExamSessionsPerWeek)
+				idx += 3
 			}
 
 			if len(set) == 1 && (req.IsDefault == nil) {
@@ -1620,7 +1613,7 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		r.GET("/admin/exam-sessions", adminAuth, func(c *gin.Context) {
 			limit, offset := parseListParams(c)
 			status := strings.TrimSpace(strings.ToLower(c.Query("status")))
-			if status != "" && status != "active" && status != "finished" && status != "terminated" && status != "invalid" {
+			if status != "" && status != "active" && status != "finished" && status != "terminated" && status != "invalid" && status != "abandoned" {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid status"})
 				return
 			}
@@ -1762,6 +1755,7 @@ func RegisterAdminRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 
 			audit(ctx, pool, actorUserID, actorRole, "exam.force_submit", "exam_session", userID+":"+sessionID, nil)
+			recordExamSubmitted(ctx, pool, userID, sessionID)
 			c.JSON(http.StatusOK, gin.H{"ok": true})
 		})
 
@@ -1 +1,17 @@
 package handlers
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// envInt reads an integer setting from the environment, falling back when unset or invalid.
+func envInt(name string, fallback int) int {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
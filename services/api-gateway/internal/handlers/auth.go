@@ -25,10 +25,11 @@ type LoginRequest struct {
 }
 
 type UserResponse struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Role      string `json:"role"`
-	CreatedAt string `json:"createdAt"`
+	ID        string  `json:"id"`
+	Email     string  `json:"email"`
+	Role      string  `json:"role"`
+	Locale    *string `json:"locale,omitempty"`
+	CreatedAt string  `json:"createdAt"`
 }
 
 type AuthResponse struct {
@@ -437,13 +438,14 @@ func handleMe(r *gin.Engine, pool *pgxpool.Pool, path string, role string, audie
 		var email string
 		var createdAt time.Time
 		var storedRole string
-		err := pool.QueryRow(ctx, `select email, created_at, role from users where id=$1 and deleted_at is null`, userID).Scan(&email, &createdAt, &storedRole)
+		var profileLocale *string
+		err := pool.QueryRow(ctx, `select email, created_at, role, locale from users where id=$1 and deleted_at is null`, userID).Scan(&email, &createdAt, &storedRole, &profileLocale)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
 			return
 		}
 
-		c.JSON(http.StatusOK, UserResponse{ID: userID, Email: email, Role: storedRole, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
+		c.JSON(http.StatusOK, UserResponse{ID: userID, Email: email, Role: storedRole, Locale: profileLocale, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
 	})
 }
 
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/srs"
+)
+
+const maxBookmarkNoteLength = 1000
+
+type QuestionBookmark struct {
+	QuestionID     string  `json:"questionId"`
+	QuestionBankID string  `json:"questionBankId"`
+	TopicID        *string `json:"topicId"`
+	DifficultyID   *string `json:"difficultyId"`
+	Prompt         string  `json:"prompt"`
+	Locale         string  `json:"locale,omitempty"`
+	Note           string  `json:"note"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+type ListQuestionBookmarksResponse struct {
+	Items   []QuestionBookmark `json:"items"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	HasMore bool               `json:"hasMore"`
+}
+
+type PutQuestionBookmarkRequest struct {
+	Note *string `json:"note"`
+	// AddToReviewDeck also puts the question in the student's review deck.
+	AddToReviewDeck bool `json:"addToReviewDeck"`
+}
+
+// enrolledQuestionSQL matches questions whose bank is attached to an exam
+// package the user (a SQL expression) is enrolled in.
+func enrolledQuestionSQL(q, user string) string {
+	return `exists (select 1 from exam_package_question_bank_packages m
+		join user_exam_package_enrollments e on e.exam_package_id=m.exam_package_id
+		where m.question_bank_package_id=` + q + `.package_id and e.user_id=` + user + `)`
+}
+
+func registerBookmarkRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireStudent := auth.RequirePortalAuth(pool, "student", "student")
+
+	// Bookmarks hide while their question is unpublished, in the trash, or
+	// outside the student's enrolled packages.
+	r.GET("/student/bookmarks", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		limit, offset := parseListParams(c)
+
+		args := []any{userID, string(QuestionPublished)}
+		where := []string{"bm.user_id=$1", "q.status=$2", "q.deleted_at is null", "b.deleted_at is null", "b.is_hidden=false", enrolledQuestionSQL("q", "$1")}
+		if v := strings.TrimSpace(c.Query("examPackageId")); v != "" {
+			args = append(args, v)
+			where = append(where, `exists (select 1 from exam_package_question_bank_packages m where m.question_bank_package_id=b.id and m.exam_package_id::text=`+sqlParam(len(args))+`)`)
+		}
+		if v := strings.TrimSpace(c.Query("topicId")); v != "" {
+			args = append(args, v)
+			where = append(where, "q.topic_id="+sqlParam(len(args)))
+		}
+		if v := strings.TrimSpace(c.Query("difficultyId")); v != "" {
+			args = append(args, v)
+			where = append(where, "q.difficulty_id="+sqlParam(len(args)))
+		}
+		args = append(args, limit+1, offset)
+		query := `select bm.question_id, q.package_id, q.topic_id, q.difficulty_id, q.prompt, b.source_locale, bm.note, bm.created_at, bm.updated_at
+			from question_bookmarks bm
+			join question_bank_questions q on q.id=bm.question_id
+			join question_banks b on b.id=q.package_id
+			where ` + strings.Join(where, " and ") + `
+			order by bm.created_at desc, bm.question_id asc
+			limit ` + sqlParam(len(args)-1) + ` offset ` + sqlParam(len(args))
+
+		ctx := context.Background()
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list bookmarks"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]QuestionBookmark, 0, limit)
+		for rows.Next() {
+			var item QuestionBookmark
+			var createdAt, updatedAt time.Time
+			if err := rows.Scan(&item.QuestionID, &item.QuestionBankID, &item.TopicID, &item.DifficultyID, &item.Prompt, &item.Locale, &item.Note, &createdAt, &updatedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list bookmarks"})
+				return
+			}
+			item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+			items = append(items, item)
+		}
+		rows.Close()
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+
+		sources := map[string]string{}
+		for _, item := range items {
+			sources[item.QuestionID] = item.Locale
+		}
+		translations, err := loadTranslations(ctx, pool, translationEntityQuestion, sources, requestLocales(ctx, pool, c, userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+			return
+		}
+		for i := range items {
+			if tr, ok := translations[items[i].QuestionID]; ok {
+				items[i].Prompt = localizedText(items[i].Prompt, tr.Fields.Prompt)
+				items[i].Locale = tr.Locale
+			}
+		}
+		c.JSON(http.StatusOK, ListQuestionBookmarksResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// PUT creates the bookmark (201) or updates its note (200). Omitting the
+	// note keeps the current one.
+	r.PUT("/student/bookmarks/:questionId", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		questionID := c.Param("questionId")
+
+		var req PutQuestionBookmarkRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		var note *string
+		if req.Note != nil {
+			v := strings.TrimSpace(*req.Note)
+			if utf8.RuneCountInString(v) > maxBookmarkNoteLength {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "note is too long"})
+				return
+			}
+			note = &v
+		}
+
+		ctx := context.Background()
+		var exists bool
+		err := pool.QueryRow(ctx, `select exists(select 1 from question_bank_questions q join question_banks b on b.id=q.package_id
+			where q.id=$1 and q.status=$2 and q.deleted_at is null and b.deleted_at is null and b.is_hidden=false and `+enrolledQuestionSQL("q", "$3")+`)`,
+			questionID, string(QuestionPublished), userID).Scan(&exists)
+		if err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+
+		var created bool
+		err = pool.QueryRow(ctx, `insert into question_bookmarks (user_id, question_id, note) values ($1,$2,coalesce($3,''))
+			on conflict (user_id, question_id) do update set note=coalesce($3, question_bookmarks.note), updated_at=now()
+			returning (xmax = 0)`, userID, questionID, note).Scan(&created)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save bookmark"})
+			return
+		}
+		if req.AddToReviewDeck {
+			if _, err := srs.Enroll(ctx, pool, userID, questionID, srs.SourceBookmarked); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add review item"})
+				return
+			}
+		}
+		if created {
+			c.JSON(http.StatusCreated, gin.H{"ok": true})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	r.DELETE("/student/bookmarks/:questionId", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		ct, err := pool.Exec(context.Background(), `delete from question_bookmarks where user_id=$1 and question_id=$2`, userID, c.Param("questionId"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to remove bookmark"})
+			return
+		}
+		if ct.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "bookmark not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/clone"
+)
+
+type CloneJob struct {
+	ID       string  `json:"id"`
+	Kind     string  `json:"kind"`
+	SourceID string  `json:"sourceId"`
+	TargetID *string `json:"targetId"`
+	Status   string  `json:"status"`
+	// Progress is copiedItems/totalItems in [0, 1].
+	Progress        float64 `json:"progress"`
+	TotalItems      int     `json:"totalItems"`
+	CopiedItems     int     `json:"copiedItems"`
+	Error           *string `json:"error"`
+	CreatedByUserID string  `json:"createdByUserId"`
+	CreatedAt       string  `json:"createdAt"`
+	CompletedAt     *string `json:"completedAt"`
+}
+
+type ListCloneJobsResponse struct {
+	Items   []CloneJob `json:"items"`
+	Limit   int        `json:"limit"`
+	Offset  int        `json:"offset"`
+	HasMore bool       `json:"hasMore"`
+}
+
+type CloneQuestionBankRequest struct {
+	// Name defaults to "<original name> (copy)".
+	Name string `json:"name"`
+}
+
+type CloneExamPackageRequest struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// IncludeQuestionBanks copies the attached banks too; otherwise the copy
+	// shares the original's banks.
+	IncludeQuestionBanks bool `json:"includeQuestionBanks"`
+}
+
+const cloneJobColumns = `id, kind, source_id, target_id, status, total_items, copied_items, error, created_by_user_id, created_at, completed_at`
+
+func scanCloneJob(row interface{ Scan(...any) error }) (CloneJob, error) {
+	var job CloneJob
+	var createdAt time.Time
+	var completedAt *time.Time
+	err := row.Scan(&job.ID, &job.Kind, &job.SourceID, &job.TargetID, &job.Status, &job.TotalItems, &job.CopiedItems, &job.Error,
+		&job.CreatedByUserID, &createdAt, &completedAt)
+	if err != nil {
+		return job, err
+	}
+	switch {
+	case job.Status == clone.JobCompleted:
+		job.Progress = 1
+	case job.TotalItems > 0:
+		job.Progress = float64(job.CopiedItems) / float64(job.TotalItems)
+	}
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	if completedAt != nil {
+		v := completedAt.UTC().Format(time.RFC3339)
+		job.CompletedAt = &v
+	}
+	return job, nil
+}
+
+// startCloneJob starts req in the background and responds 202 with the job.
+func startCloneJob(c *gin.Context, pool *pgxpool.Pool, req clone.Request, notFound string) (string, bool) {
+	ctx := context.Background()
+	id, err := clone.Start(ctx, pool, req)
+	if errors.Is(err, clone.ErrSourceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": notFound})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to start clone"})
+		return "", false
+	}
+	go func() {
+		if err := clone.Run(context.Background(), pool, id, req); err != nil {
+			log.Printf("clone: job %s failed: %v", id, err)
+		}
+	}()
+
+	job, err := scanCloneJob(pool.QueryRow(ctx, `select `+cloneJobColumns+` from clone_jobs where id=$1`, id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load clone job"})
+		return "", false
+	}
+	c.JSON(http.StatusAccepted, job)
+	return id, true
+}
+
+func registerCloneJobRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+	requireAdmin := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	// Clones run in the background; poll the returned job for progress and the new id.
+	r.POST("/instructor/question-banks/:questionBankId/clone", requireInstructorOrAdmin, func(c *gin.Context) {
+		actorUserID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		actorRole, _ := auth.GetRole(c)
+		bankID := c.Param("questionBankId")
+
+		var req CloneQuestionBankRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		// Instructors clone only their own banks, whose questions they can
+		// already see unpublished.
+		query := `select name from question_banks where id=$1 and deleted_at is null`
+		args := []any{bankID}
+		if actorRole != "admin" {
+			query += ` and created_by_user_id=$2`
+			args = append(args, actorUserID)
+		}
+		var srcName string
+		if err := pool.QueryRow(context.Background(), query, args...).Scan(&srcName); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question bank not found"})
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			name = srcName + " (copy)"
+		}
+
+		// Only admins publish, so an instructor's copy starts with drafts.
+		cloneReq := clone.Request{
+			Kind:            clone.KindQuestionBank,
+			SourceID:        bankID,
+			Name:            name,
+			KeepPublished:   actorRole == "admin",
+			CreatedByUserID: actorUserID,
+		}
+		id, ok := startCloneJob(c, pool, cloneReq, "question bank not found")
+		if !ok {
+			return
+		}
+		audit(context.Background(), pool, actorUserID, actorRole, "instructor.question_banks.clone", "question_bank", bankID, gin.H{"jobId": id, "name": name})
+	})
+
+	r.POST("/admin/exam-packages/:examPackageId/clone", requireAdmin, func(c *gin.Context) {
+		actorUserID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		actorRole, _ := auth.GetRole(c)
+		examPackageID := c.Param("examPackageId")
+
+		var req CloneExamPackageRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		code := strings.TrimSpace(req.Code)
+		name := strings.TrimSpace(req.Name)
+		if code == "" || name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "code and name are required"})
+			return
+		}
+
+		ctx := context.Background()
+		var taken bool
+		if err := pool.QueryRow(ctx, `select exists(select 1 from exam_packages where code=$1 or name=$2)`, code, name).Scan(&taken); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to start clone"})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"message": "an exam package with this code or name already exists"})
+			return
+		}
+
+		cloneReq := clone.Request{
+			Kind:                 clone.KindExamPackage,
+			SourceID:             examPackageID,
+			Name:                 name,
+			Code:                 code,
+			IncludeQuestionBanks: req.IncludeQuestionBanks,
+			KeepPublished:        true,
+			CreatedByUserID:      actorUserID,
+		}
+		id, ok := startCloneJob(c, pool, cloneReq, "exam package not found")
+		if !ok {
+			return
+		}
+		audit(ctx, pool, actorUserID, actorRole, "admin.exam_packages.clone", "exam_package", examPackageID,
+			gin.H{"jobId": id, "code": code, "name": name, "includeQuestionBanks": req.IncludeQuestionBanks})
+	})
+
+	// Instructors see their own jobs; admins see everyone's.
+	r.GET("/instructor/clone-jobs", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		limit, offset := parseListParams(c)
+
+		rows, err := pool.Query(context.Background(), `select `+cloneJobColumns+` from clone_jobs
+			where ($1 or created_by_user_id=$2)
+			order by created_at desc, id desc limit $3 offset $4`, role == "admin", userID, limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list clone jobs"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]CloneJob, 0, limit)
+		for rows.Next() {
+			job, err := scanCloneJob(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list clone jobs"})
+				return
+			}
+			items = append(items, job)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListCloneJobsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	r.GET("/instructor/clone-jobs/:jobId", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		job, err := scanCloneJob(pool.QueryRow(context.Background(), `select `+cloneJobColumns+` from clone_jobs where id=$1`, c.Param("jobId")))
+		if err != nil || (role != "admin" && job.CreatedByUserID != userID) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "clone job not found"})
+			return
+		}
+		c.JSON(http.StatusOK, job)
+	})
+}
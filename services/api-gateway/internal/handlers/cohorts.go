@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+type Cohort struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	MemberCount     int     `json:"memberCount"`
+	CreatedByUserID *string `json:"createdByUserId"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+type ListCohortsResponse struct {
+	Items   []Cohort `json:"items"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+	HasMore bool     `json:"hasMore"`
+}
+
+type CreateCohortRequest struct {
+	Name string `json:"name"`
+}
+
+type AddCohortMemberRequest struct {
+	UserID string `json:"userId"`
+}
+
+type StudentCohort struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ownsCohort reports whether an instructor manages a cohort; admins manage
+// every cohort.
+func ownsCohort(ctx context.Context, pool *pgxpool.Pool, cohortID, userID, role string) (bool, error) {
+	var ok bool
+	err := pool.QueryRow(ctx, `select exists (select 1 from cohorts where id=$1 and ($2 or created_by_user_id=$3))`,
+		cohortID, role == "admin", userID).Scan(&ok)
+	return ok, err
+}
+
+// registerCohortRoutes manages cohorts: groups of students an instructor
+// teaches together, ranked on cohort leaderboards.
+func registerCohortRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	r.GET("/instructor/cohorts", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		limit, offset := parseListParams(c)
+
+		rows, err := pool.Query(context.Background(), `select c.id, c.name, (select count(*) from cohort_members m where m.cohort_id=c.id), c.created_by_user_id, c.created_at
+			from cohorts c
+			where ($1 or c.created_by_user_id=$2)
+			order by c.name asc, c.id asc limit $3 offset $4`, role == "admin", userID, limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list cohorts"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]Cohort, 0, limit)
+		for rows.Next() {
+			var item Cohort
+			var createdAt time.Time
+			if err := rows.Scan(&item.ID, &item.Name, &item.MemberCount, &item.CreatedByUserID, &createdAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list cohorts"})
+				return
+			}
+			item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			items = append(items, item)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListCohortsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	r.POST("/instructor/cohorts", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		var req CreateCohortRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "name is required"})
+			return
+		}
+		ctx := context.Background()
+		cohortID := util.NewID("coh")
+		if _, err := pool.Exec(ctx, `insert into cohorts (id, name, created_by_user_id) values ($1,$2,$3)`, cohortID, name, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create cohort"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.cohorts.create", "cohort", cohortID, gin.H{"name": name})
+		c.JSON(http.StatusOK, gin.H{"id": cohortID})
+	})
+
+	r.POST("/instructor/cohorts/:cohortId/members", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		cohortID := c.Param("cohortId")
+		var req AddCohortMemberRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		memberID := strings.TrimSpace(req.UserID)
+		if memberID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "userId is required"})
+			return
+		}
+		ctx := context.Background()
+		owns, err := ownsCohort(ctx, pool, cohortID, userID, role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add member"})
+			return
+		}
+		if !owns {
+			c.JSON(http.StatusNotFound, gin.H{"message": "cohort not found"})
+			return
+		}
+		cmd, err := pool.Exec(ctx, `insert into cohort_members (cohort_id, user_id, added_by_user_id)
+			select $1, id, $3 from users where id=$2 and role='student' and deleted_at is null
+			on conflict do nothing`, cohortID, memberID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add member"})
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			var member bool
+			_ = pool.QueryRow(ctx, `select exists (select 1 from cohort_members where cohort_id=$1 and user_id=$2)`, cohortID, memberID).Scan(&member)
+			if !member {
+				c.JSON(http.StatusNotFound, gin.H{"message": "student not found"})
+				return
+			}
+		}
+		audit(ctx, pool, userID, role, "instructor.cohorts.add_member", "cohort", cohortID, gin.H{"userId": memberID})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	r.DELETE("/instructor/cohorts/:cohortId/members/:userId", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, _ := auth.GetUserID(c)
+		role, _ := auth.GetRole(c)
+		cohortID := c.Param("cohortId")
+		memberID := c.Param("userId")
+		ctx := context.Background()
+		owns, err := ownsCohort(ctx, pool, cohortID, userID, role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to remove member"})
+			return
+		}
+		if !owns {
+			c.JSON(http.StatusNotFound, gin.H{"message": "cohort not found"})
+			return
+		}
+		if _, err := pool.Exec(ctx, `delete from cohort_members where cohort_id=$1 and user_id=$2`, cohortID, memberID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to remove member"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.cohorts.remove_member", "cohort", cohortID, gin.H{"userId": memberID})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	// Students see their cohorts so they can open the cohort boards.
+	r.GET("/student/cohorts", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		rows, err := pool.Query(context.Background(), `select c.id, c.name from cohorts c
+			join cohort_members m on m.cohort_id=c.id and m.user_id=$1
+			order by c.name asc, c.id asc`, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list cohorts"})
+			return
+		}
+		defer rows.Close()
+		items := []StudentCohort{}
+		for rows.Next() {
+			var item StudentCohort
+			if err := rows.Scan(&item.ID, &item.Name); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list cohorts"})
+				return
+			}
+			items = append(items, item)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+}
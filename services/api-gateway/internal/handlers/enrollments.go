@@ -11,6 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/locale"
 )
 
 type ExamPackageModuleSection struct {
@@ -27,6 +28,7 @@ type ExamPackageListItem struct {
 	Modules        []string                  `json:"modules"`
 	Highlights     []string                  `json:"highlights"`
 	ModuleSections []ExamPackageModuleSection `json:"moduleSections"`
+	Locale         string                    `json:"locale"`
 	CreatedAt      string                    `json:"createdAt"`
 }
 
@@ -75,12 +77,17 @@ type UpdateInstructorExamPackageRequest struct {
 	Modules        *[]string                  `json:"modules"`
 	Highlights     *[]string                  `json:"highlights"`
 	ModuleSections *[]ExamPackageModuleSection `json:"moduleSections"`
+	SourceLocale   *string                    `json:"sourceLocale"`
 }
 
 func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	registerEntitlementRoutes(r, pool)
+
 	// Public-ish reference data (used by multiple portals)
+	// Unauthenticated, so the locale comes from Accept-Language alone.
 	r.GET("/exam-packages", func(c *gin.Context) {
-		rows, err := pool.Query(context.Background(), `
+		ctx := context.Background()
+		rows, err := pool.Query(ctx, `
 			select
 				id::text,
 				name,
@@ -89,6 +96,7 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				modules,
 				highlights,
 				module_sections,
+				source_locale,
 				created_at
 			from exam_packages
 			where is_hidden=false
@@ -107,8 +115,9 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var modulesRaw []byte
 			var highlightsRaw []byte
 			var moduleSectionsRaw []byte
+			var sourceLocale string
 			var createdAt time.Time
-			if err := rows.Scan(&id, &name, &subtitle, &overview, &modulesRaw, &highlightsRaw, &moduleSectionsRaw, &createdAt); err != nil {
+			if err := rows.Scan(&id, &name, &subtitle, &overview, &modulesRaw, &highlightsRaw, &moduleSectionsRaw, &sourceLocale, &createdAt); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list exam packages"})
 				return
 			}
@@ -134,9 +143,35 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				Modules:        modules,
 				Highlights:     highlights,
 				ModuleSections: moduleSections,
+				Locale:         sourceLocale,
 				CreatedAt:      createdAt.UTC().Format(time.RFC3339),
 			})
 		}
+		rows.Close()
+
+		sources := map[string]string{}
+		for _, item := range items {
+			sources[item.ID] = item.Locale
+		}
+		translations, err := loadTranslations(ctx, pool, translationEntityExamPackage, sources, requestLocales(ctx, pool, c, ""))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+			return
+		}
+		for i := range items {
+			tr, ok := translations[items[i].ID]
+			if !ok {
+				continue
+			}
+			items[i].Name = localizedText(items[i].Name, tr.Fields.Name)
+			if tr.Fields.Subtitle != nil {
+				items[i].Subtitle = tr.Fields.Subtitle
+			}
+			if tr.Fields.Overview != nil {
+				items[i].Overview = tr.Fields.Overview
+			}
+			items[i].Locale = tr.Locale
+		}
 		c.JSON(http.StatusOK, ListExamPackagesResponse{Items: items})
 	})
 
@@ -355,16 +390,10 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				return
 			}
 
-			policy := json.RawMessage(`{}`)
-			if req.Policy != nil {
-				if !json.Valid(*req.Policy) {
-					c.JSON(http.StatusBadRequest, gin.H{"message": "policy must be valid json"})
-					return
-				}
-				policy = *req.Policy
-				if len(policy) == 0 {
-					policy = json.RawMessage(`{}`)
-				}
+			policy, tierPolicy, err := parseTierPolicy(req.Policy)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
 			}
 
 			isActive := true
@@ -395,9 +424,9 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 			var newID string
 			err = tx.QueryRow(ctx, `
-				insert into exam_package_tiers (exam_package_id, code, name, sort_order, is_default, is_active, policy)
-				values ($1, $2, $3, $4, false, $5, $6)
-				returning id::text`, examPackageID, code, name, sortOrder, isActive, policy).Scan(&newID)
+				insert into exam_package_tiers (exam_package_id, code, name, sort_order, is_default, is_active, policy, max_practice_sessions_per_week, max_exam_sessions_per_week)
+				values ($1, $2, $3, $4, false, $5, $6, $7, $8)
+				returning id::text`, examPackageID, code, name, sortOrder, isActive, policy, tierPolicy.MaxPracticeSessionsPerWeek, tierPolicy.MaxExamSessionsPerWeek).Scan(&newID)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to create tier"})
 				return
@@ -505,17 +534,15 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				idx++
 			}
 			if req.Policy != nil {
-				if !json.Valid(*req.Policy) {
-					c.JSON(http.StatusBadRequest, gin.H{"message": "policy must be valid json"})
+				v, tierPolicy, err := parseTierPolicy(req.Policy)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 					return
 				}
-				v := *req.Policy
-				if len(v) == 0 {
-					v = json.RawMessage(`{}`)
-				}
-				set = append(set, "policy="+sqlParam(idx))
-				args = append(args, v)
-				idx++
+				// The quota columns mirror the policy.
+				set = append(set, "policy="+sqlParam(idx), "max_practice_sessions_per_week="+sqlParam(idx+1), "max_exam_sessions_per_week="+sqlParam(idx+2))
+				args = append(args, v, tierPolicy.MaxPracticeSessionsPerWeek, tierPolicy.MaxExamSessionsPerWeek)
+				idx += 3
 			}
 
 			if len(set) == 1 && (req.IsDefault == nil) {
@@ -647,6 +674,16 @@ func RegisterEnrollmentRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				args = append(args, b)
 				idx++
 			}
+			if req.SourceLocale != nil {
+				loc, ok := locale.Normalize(*req.SourceLocale)
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid sourceLocale"})
+					return
+				}
+				set = append(set, "source_locale="+sqlParam(idx))
+				args = append(args, loc)
+				idx++
+			}
 			if len(set) == 1 {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "no fields to update"})
 				return
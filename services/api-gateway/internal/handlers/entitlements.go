@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/policy"
+)
+
+type SessionQuota struct {
+	// Limit and Remaining are null when the tier has no quota.
+	Limit     *int `json:"limit"`
+	Used      int  `json:"used"`
+	Remaining *int `json:"remaining"`
+}
+
+type Entitlement struct {
+	ExamPackageID          string        `json:"examPackageId"`
+	TierID                 string        `json:"tierId"`
+	TierCode               string        `json:"tierCode"`
+	TierName               string        `json:"tierName"`
+	PracticeSessions       SessionQuota  `json:"practiceSessions"`
+	ExamSessions           SessionQuota  `json:"examSessions"`
+	MaxQuestionsPerSession *int          `json:"maxQuestionsPerSession"`
+	TimedPractice          bool          `json:"timedPractice"`
+	Explanations           bool          `json:"explanations"`
+	Review                 bool          `json:"review"`
+	Policy                 policy.Policy `json:"policy"`
+	PeriodStart            string        `json:"periodStart"`
+	ResetsAt               string        `json:"resetsAt"`
+}
+
+type EntitlementsResponse struct {
+	Items []Entitlement `json:"items"`
+}
+
+// parseTierPolicy validates a tier policy from an admin request and returns
+// it in canonical form. A nil raw is the unrestricted policy {}.
+func parseTierPolicy(raw *json.RawMessage) (json.RawMessage, policy.Policy, error) {
+	var p policy.Policy
+	if raw != nil {
+		var err error
+		if p, err = policy.Parse(*raw); err != nil {
+			return nil, p, err
+		}
+	}
+	out, err := json.Marshal(p)
+	if err != nil {
+		return nil, p, err
+	}
+	return out, p, nil
+}
+
+// loadTierPolicy reads the policy of a tier. Sessions without a tier
+// (created before tiers were snapshotted) are unrestricted.
+func loadTierPolicy(ctx context.Context, pool *pgxpool.Pool, tierID *string) (policy.Policy, error) {
+	if tierID == nil || strings.TrimSpace(*tierID) == "" {
+		return policy.Policy{}, nil
+	}
+	var raw []byte
+	err := pool.QueryRow(ctx, `select policy from exam_package_tiers where id::text=$1`, strings.TrimSpace(*tierID)).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return policy.Policy{}, nil
+	}
+	if err != nil {
+		return policy.Policy{}, err
+	}
+	return policy.Decode(raw), nil
+}
+
+// weeklySessionCounts counts the practice and exam sessions a student started
+// in an exam package since the start of the current quota week.
+func weeklySessionCounts(ctx context.Context, db rowQuerier, userID, examPackageID string, since time.Time) (int, int, error) {
+	var practice, exam int
+	err := db.QueryRow(ctx, `select
+			(select count(*) from practice_sessions where user_id=$1 and package_id::text=$2 and created_at >= $3),
+			(select count(*) from exam_sessions where user_id=$1 and exam_package_id::text=$2 and created_at >= $3)`,
+		userID, examPackageID, since).Scan(&practice, &exam)
+	return practice, exam, err
+}
+
+// lockSessionQuota serializes session starts of a student in an exam package
+// until tx ends, so concurrent starts cannot both pass the weekly quota. Count
+// and insert the session in tx after taking the lock.
+func lockSessionQuota(ctx context.Context, tx pgx.Tx, userID, examPackageID string) error {
+	_, err := tx.Exec(ctx, `select pg_advisory_xact_lock(hashtext('session_quota'), hashtext($1 || ':' || $2))`, userID, examPackageID)
+	return err
+}
+
+// quotaExceeded responds 403 and tells the student when the quota resets.
+func quotaExceeded(c *gin.Context, message string, weekStart time.Time) {
+	c.JSON(http.StatusForbidden, gin.H{"message": message, "resetsAt": weekStart.AddDate(0, 0, 7).Format(time.RFC3339)})
+}
+
+func registerEntitlementRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	r.GET("/student/entitlements", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+
+		args := []any{userID}
+		query := `select e.exam_package_id::text, t.id::text, t.code, t.name, t.policy
+			from user_exam_package_enrollments e
+			join exam_package_tiers t on t.id=e.tier_id
+			where e.user_id=$1`
+		if v := strings.TrimSpace(c.Query("examPackageId")); v != "" {
+			args = append(args, v)
+			query += ` and e.exam_package_id::text=$2`
+		}
+		query += ` order by e.created_at asc`
+
+		ctx := context.Background()
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load entitlements"})
+			return
+		}
+		defer rows.Close()
+
+		items := []Entitlement{}
+		for rows.Next() {
+			var item Entitlement
+			var raw []byte
+			if err := rows.Scan(&item.ExamPackageID, &item.TierID, &item.TierCode, &item.TierName, &raw); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load entitlements"})
+				return
+			}
+			item.Policy = policy.Decode(raw)
+			items = append(items, item)
+		}
+		rows.Close()
+
+		weekStart := policy.WeekStart(time.Now())
+		for i := range items {
+			p := items[i].Policy
+			practice, exam, err := weeklySessionCounts(ctx, pool, userID, items[i].ExamPackageID, weekStart)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load entitlements"})
+				return
+			}
+			items[i].PracticeSessions = SessionQuota{Limit: p.MaxPracticeSessionsPerWeek, Used: practice, Remaining: policy.Remaining(p.MaxPracticeSessionsPerWeek, practice)}
+			items[i].ExamSessions = SessionQuota{Limit: p.MaxExamSessionsPerWeek, Used: exam, Remaining: policy.Remaining(p.MaxExamSessionsPerWeek, exam)}
+			items[i].MaxQuestionsPerSession = p.MaxQuestionsPerSession
+			items[i].TimedPractice = p.AllowsTimedPractice()
+			items[i].Explanations = p.AllowsExplanations()
+			items[i].Review = p.AllowsReview()
+			items[i].PeriodStart = weekStart.Format(time.RFC3339)
+			items[i].ResetsAt = weekStart.AddDate(0, 0, 7).Format(time.RFC3339)
+		}
+		c.JSON(http.StatusOK, EntitlementsResponse{Items: items})
+	})
+}
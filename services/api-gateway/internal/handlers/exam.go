@@ -3,14 +3,18 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/policy"
+	"github.com/ace-platform/api-gateway/internal/shuffle"
 )
 
 type ExamSessionStatus string
@@ -20,6 +24,8 @@ const (
 	ExamSessionFinished ExamSessionStatus = "finished"
 	ExamSessionTerminated ExamSessionStatus = "terminated"
 	ExamSessionInvalid  ExamSessionStatus = "invalid"
+	// Set by the session sweeper when heartbeats stop and EXAM_STALE_ACTION=abandon.
+	ExamSessionAbandoned ExamSessionStatus = "abandoned"
 )
 
 type ExamEventRequest struct {
@@ -55,6 +61,15 @@ type ExamSessionResponse struct {
 	Snapshot         json.RawMessage `json:"snapshot"`
 }
 
+// ExamQuestionChoicesResponse lists a question's choices in the order this
+// session shows them. Answers are still recorded by choice id.
+type ExamQuestionChoicesResponse struct {
+	QuestionID         string                   `json:"questionId"`
+	Choices            []PracticeQuestionChoice `json:"choices"`
+	ChoiceOrder        []ReviewChoiceOrder      `json:"choiceOrder"`
+	CanonicalChoiceIDs []string                 `json:"canonicalChoiceIds"`
+}
+
 type ExamSessionListItem struct {
 	SessionID       string          `json:"sessionId"`
 	ExamPackageID   *string         `json:"examPackageId,omitempty"`
@@ -127,7 +142,7 @@ func RegisterExamRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 		limit, offset := parseListParams(c)
 			status := c.Query("status")
-			if status != "" && status != string(ExamSessionActive) && status != string(ExamSessionFinished) && status != string(ExamSessionTerminated) && status != string(ExamSessionInvalid) {
+			if status != "" && status != string(ExamSessionActive) && status != string(ExamSessionFinished) && status != string(ExamSessionTerminated) && status != string(ExamSessionInvalid) && status != string(ExamSessionAbandoned) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid status"})
 			return
 		}
@@ -234,10 +249,24 @@ func RegisterExamRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				return
 			}
 
+			// Heartbeats of a student in a package take the quota lock, so the
+			// quota of a first heartbeat is counted and the session inserted
+			// before a parallel start can count.
+			tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to persist heartbeat"})
+				return
+			}
+			defer func() { _ = tx.Rollback(ctx) }()
+			if err := lockSessionQuota(ctx, tx, userID, resolvedPkg); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to persist heartbeat"})
+				return
+			}
+
 			var existingStatus string
 			var existingPkg *string
 			var existingTier *string
-			err := pool.QueryRow(ctx, `select status, exam_package_id, tier_id from exam_sessions where user_id=$1 and id=$2`, userID, sessionID).Scan(&existingStatus, &existingPkg, &existingTier)
+			err = tx.QueryRow(ctx, `select status, exam_package_id, tier_id from exam_sessions where user_id=$1 and id=$2`, userID, sessionID).Scan(&existingStatus, &existingPkg, &existingTier)
 			if err == nil {
 				if existingStatus != string(ExamSessionActive) {
 					c.JSON(http.StatusConflict, gin.H{"message": "session is not active"})
@@ -247,21 +276,46 @@ func RegisterExamRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					c.JSON(http.StatusConflict, gin.H{"message": "examPackageId mismatch"})
 					return
 				}
+			} else if errors.Is(err, pgx.ErrNoRows) {
+				// The first heartbeat starts the session, so it is where the
+				// weekly quota of the enrolled tier applies.
+				tierPolicy, err := loadTierPolicy(ctx, pool, &resolvedTier)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load tier policy"})
+					return
+				}
+				if tierPolicy.MaxExamSessionsPerWeek != nil {
+					weekStart := policy.WeekStart(now)
+					_, used, err := weeklySessionCounts(ctx, tx, userID, resolvedPkg, weekStart)
+					if err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to check session quota"})
+						return
+					}
+					if used >= *tierPolicy.MaxExamSessionsPerWeek {
+						quotaExceeded(c, "weekly exam session limit reached", weekStart)
+						return
+					}
+				}
 			}
 
-			_, err = pool.Exec(ctx, `insert into exam_sessions (user_id, id, status, exam_package_id, tier_id, snapshot, created_at, updated_at, last_heartbeat_at)
-				values ($1,$2,$3,$4,$5,$6,now(),now(),now())
+			// The shuffle seed is fixed by the first heartbeat and never updated.
+			_, err = tx.Exec(ctx, `insert into exam_sessions (user_id, id, status, exam_package_id, tier_id, snapshot, created_at, updated_at, last_heartbeat_at, shuffle_seed)
+				values ($1,$2,$3,$4,$5,$6,now(),now(),now(),$7)
 				on conflict (user_id, id) do update set
 					exam_package_id = coalesce(exam_sessions.exam_package_id, excluded.exam_package_id),
 					tier_id = coalesce(exam_sessions.tier_id, excluded.tier_id),
 					snapshot=excluded.snapshot,
 					updated_at=excluded.updated_at,
 					last_heartbeat_at=excluded.last_heartbeat_at`,
-				userID, sessionID, string(ExamSessionActive), resolvedPkg, resolvedTier, snapshot)
+				userID, sessionID, string(ExamSessionActive), resolvedPkg, resolvedTier, snapshot, shuffle.NewSeed())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to persist heartbeat"})
 			return
 		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to persist heartbeat"})
+			return
+		}
 
 		c.JSON(http.StatusOK, HeartbeatResponse{Ok: true, ServerTS: now.Format(time.RFC3339)})
 	})
@@ -343,6 +397,8 @@ func RegisterExamRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			return
 		}
 
+		recordExamSubmitted(ctx, pool, userID, sessionID)
+
 		if len(snapshot) == 0 {
 			snapshot = []byte("{}")
 		}
@@ -365,6 +421,68 @@ func RegisterExamRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		})
 	})
 
+	// Choices for a question in the session's exam package, shuffled with the
+	// session seed so a reload or a review shows the same order.
+	r.GET("/exam-sessions/:sessionId/questions/:questionId/choices", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+
+		sessionID := c.Param("sessionId")
+		questionID := c.Param("questionId")
+		ctx := context.Background()
+
+		var examPackageID *string
+		var seed *int64
+		if err := pool.QueryRow(ctx, `select exam_package_id::text, shuffle_seed from exam_sessions where user_id=$1 and id=$2`, userID, sessionID).Scan(&examPackageID, &seed); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		if examPackageID == nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+
+		q := practiceQuestionSnapshot{ID: questionID}
+		err := pool.QueryRow(ctx, `select q.shuffle_choices from question_bank_questions q
+			join exam_package_question_bank_packages m on m.question_bank_package_id=q.package_id
+			where q.id=$1 and m.exam_package_id::text=$2 and q.status=$3 and `+liveQuestionSQL("q"),
+			questionID, *examPackageID, string(QuestionPublished)).Scan(&q.ShuffleChoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+
+		rows, err := pool.Query(ctx, `select id, text, is_pinned from question_bank_choices where question_id=$1 order by order_index asc`, questionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var ch PracticeQuestionChoice
+			var pinned bool
+			if err := rows.Scan(&ch.ID, &ch.Text, &pinned); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
+				return
+			}
+			q.Choices = append(q.Choices, ch)
+			if pinned {
+				q.PinnedChoiceIDs = append(q.PinnedChoiceIDs, ch.ID)
+			}
+		}
+
+		displayed, order, canonicalIDs := q.choiceMapping(seed)
+		c.JSON(http.StatusOK, ExamQuestionChoicesResponse{
+			QuestionID:         questionID,
+			Choices:            displayed,
+			ChoiceOrder:        order,
+			CanonicalChoiceIDs: canonicalIDs,
+		})
+	})
+
 	r.POST("/exam-sessions/:sessionId/events", studentAuth, func(c *gin.Context) {
 		userID, ok := auth.GetUserID(c)
 		if !ok {
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+)
+
+// ExamPackageQuestionBank is a question bank attached to an exam package.
+type ExamPackageQuestionBank struct {
+	QuestionBankID    string  `json:"questionBankId"`
+	Name              string  `json:"name"`
+	IsHidden          bool    `json:"isHidden"`
+	PublishedCount    int     `json:"publishedCount"`
+	AttachedAt        string  `json:"attachedAt"`
+	AttachedByUserID  *string `json:"attachedByUserId"`
+	OtherExamPackages int     `json:"otherExamPackages"`
+}
+
+type ListExamPackageQuestionBanksResponse struct {
+	Items []ExamPackageQuestionBank `json:"items"`
+}
+
+func registerExamPackageBankRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	adminAuth := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	r.GET("/admin/exam-packages/:examPackageId/question-banks", adminAuth, func(c *gin.Context) {
+		examPackageID := strings.TrimSpace(c.Param("examPackageId"))
+		ctx := context.Background()
+
+		var exists bool
+		if err := pool.QueryRow(ctx, `select exists(select 1 from exam_packages where id::text=$1)`, examPackageID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "exam package not found"})
+			return
+		}
+
+		rows, err := pool.Query(ctx, `
+			select
+				b.id,
+				b.name,
+				b.is_hidden,
+				(select count(*) from question_bank_questions q where q.package_id=b.id and q.status=$2 and q.deleted_at is null),
+				m.created_at,
+				m.created_by_user_id,
+				(select count(*) from exam_package_question_bank_packages o where o.question_bank_package_id=b.id and o.exam_package_id<>m.exam_package_id)
+			from exam_package_question_bank_packages m
+			join question_banks b on b.id=m.question_bank_package_id
+			where m.exam_package_id::text=$1 and b.deleted_at is null
+			order by b.name asc`, examPackageID, string(QuestionPublished))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
+			return
+		}
+		defer rows.Close()
+
+		items := []ExamPackageQuestionBank{}
+		for rows.Next() {
+			var item ExamPackageQuestionBank
+			var attachedAt time.Time
+			if err := rows.Scan(&item.QuestionBankID, &item.Name, &item.IsHidden, &item.PublishedCount, &attachedAt, &item.AttachedByUserID, &item.OtherExamPackages); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
+				return
+			}
+			item.AttachedAt = attachedAt.UTC().Format(time.RFC3339)
+			items = append(items, item)
+		}
+		c.JSON(http.StatusOK, ListExamPackageQuestionBanksResponse{Items: items})
+	})
+
+	// Attaching is idempotent: re-attaching an attached bank is a no-op.
+	r.PUT("/admin/exam-packages/:examPackageId/question-banks/:questionBankId", adminAuth, func(c *gin.Context) {
+		actorUserID, _ := auth.GetUserID(c)
+		actorRole, _ := auth.GetRole(c)
+		examPackageID := strings.TrimSpace(c.Param("examPackageId"))
+		questionBankID := strings.TrimSpace(c.Param("questionBankId"))
+		ctx := context.Background()
+
+		var packageExists, bankExists bool
+		if err := pool.QueryRow(ctx, `select exists(select 1 from exam_packages where id::text=$1), exists(select 1 from question_banks where id=$2 and deleted_at is null)`,
+			examPackageID, questionBankID).Scan(&packageExists, &bankExists); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to attach question bank"})
+			return
+		}
+		if !packageExists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "exam package not found"})
+			return
+		}
+		if !bankExists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question bank not found"})
+			return
+		}
+
+		ct, err := pool.Exec(ctx, `insert into exam_package_question_bank_packages (exam_package_id, question_bank_package_id, created_by_user_id)
+			values ($1::uuid,$2,$3) on conflict do nothing`, examPackageID, questionBankID, actorUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to attach question bank"})
+			return
+		}
+		if ct.RowsAffected() > 0 {
+			audit(ctx, pool, actorUserID, actorRole, "admin.exam_packages.attach_question_bank", "exam_package", examPackageID, gin.H{"questionBankId": questionBankID})
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "attached": ct.RowsAffected() > 0})
+	})
+
+	// Detaching only removes the mapping; the bank and its questions are untouched.
+	// Sessions already started keep their question snapshot.
+	r.DELETE("/admin/exam-packages/:examPackageId/question-banks/:questionBankId", adminAuth, func(c *gin.Context) {
+		actorUserID, _ := auth.GetUserID(c)
+		actorRole, _ := auth.GetRole(c)
+		examPackageID := strings.TrimSpace(c.Param("examPackageId"))
+		questionBankID := strings.TrimSpace(c.Param("questionBankId"))
+		ctx := context.Background()
+
+		ct, err := pool.Exec(ctx, `delete from exam_package_question_bank_packages where exam_package_id::text=$1 and question_bank_package_id=$2`, examPackageID, questionBankID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to detach question bank"})
+			return
+		}
+		if ct.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question bank is not attached to this exam package"})
+			return
+		}
+		audit(ctx, pool, actorUserID, actorRole, "admin.exam_packages.detach_question_bank", "exam_package", examPackageID, gin.H{"questionBankId": questionBankID})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
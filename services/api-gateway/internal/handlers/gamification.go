@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+type CoinBalanceResponse struct {
+	Balance int `json:"balance"`
+	// CurrentStreakDays is 0 once a day has been missed.
+	CurrentStreakDays int     `json:"currentStreakDays"`
+	LongestStreakDays int     `json:"longestStreakDays"`
+	LastActiveDate    *string `json:"lastActiveDate"`
+	Timezone          string  `json:"timezone"`
+}
+
+type CoinLedgerEntry struct {
+	ID        string  `json:"id"`
+	Amount    int     `json:"amount"`
+	Reason    string  `json:"reason"`
+	SourceID  *string `json:"sourceId"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type ListCoinLedgerResponse struct {
+	Items   []CoinLedgerEntry `json:"items"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	HasMore bool              `json:"hasMore"`
+}
+
+type StudentAchievement struct {
+	ID          string  `json:"id"`
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Coins       int     `json:"coins"`
+	Earned      bool    `json:"earned"`
+	EarnedAt    *string `json:"earnedAt"`
+}
+
+type AdminAchievement struct {
+	ID          string          `json:"id"`
+	Code        string          `json:"code"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Rule        json.RawMessage `json:"rule"`
+	Coins       int             `json:"coins"`
+	IsActive    bool            `json:"isActive"`
+	SortOrder   int             `json:"sortOrder"`
+	EarnedCount int             `json:"earnedCount"`
+	CreatedAt   string          `json:"createdAt"`
+	UpdatedAt   string          `json:"updatedAt"`
+}
+
+type CreateAchievementRequest struct {
+	Code        string          `json:"code"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description"`
+	Rule        json.RawMessage `json:"rule"`
+	Coins       int             `json:"coins"`
+	IsActive    *bool           `json:"isActive"`
+	SortOrder   int             `json:"sortOrder"`
+}
+
+// UpdateAchievementRequest changes an achievement. Students who already
+// earned it keep it, whatever the new rule.
+type UpdateAchievementRequest struct {
+	Name        *string         `json:"name"`
+	Description *string         `json:"description"`
+	Rule        json.RawMessage `json:"rule"`
+	Coins       *int            `json:"coins"`
+	IsActive    *bool           `json:"isActive"`
+	SortOrder   *int            `json:"sortOrder"`
+}
+
+const adminAchievementColumns = `a.id, a.code, a.name, a.description, a.rule, a.coins, a.is_active, a.sort_order,
+	(select count(*) from user_achievements ua where ua.achievement_id=a.id), a.created_at, a.updated_at`
+
+func scanAdminAchievement(row interface{ Scan(...any) error }) (AdminAchievement, error) {
+	var a AdminAchievement
+	var rule []byte
+	var createdAt, updatedAt time.Time
+	if err := row.Scan(&a.ID, &a.Code, &a.Name, &a.Description, &rule, &a.Coins, &a.IsActive, &a.SortOrder, &a.EarnedCount, &createdAt, &updatedAt); err != nil {
+		return AdminAchievement{}, err
+	}
+	a.Rule = json.RawMessage(rule)
+	a.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	a.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return a, nil
+}
+
+// normalizeAchievementRule validates a rule and returns it re-encoded, so
+// stored rules are always well-formed.
+func normalizeAchievementRule(raw json.RawMessage) ([]byte, error) {
+	rule, err := gamification.ParseRule(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rule)
+}
+
+func RegisterGamificationRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	studentAuth := auth.RequirePortalAuth(pool, "student", "student")
+	adminAuth := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	r.GET("/student/coins", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		ctx := context.Background()
+
+		var balance int
+		if err := pool.QueryRow(ctx, `select coalesce(sum(amount), 0) from coin_ledger where user_id=$1`, userID).Scan(&balance); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load coins"})
+			return
+		}
+		streak, tz, err := gamification.LoadStreak(ctx, pool, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load coins"})
+			return
+		}
+		resp := CoinBalanceResponse{
+			Balance:           balance,
+			CurrentStreakDays: streak.CurrentAt(gamification.LocalDay(time.Now(), tz)),
+			LongestStreakDays: streak.LongestDays,
+			Timezone:          tz,
+		}
+		if streak.LastActiveDate != nil {
+			v := streak.LastActiveDate.Format(time.DateOnly)
+			resp.LastActiveDate = &v
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	r.GET("/student/coins/history", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		limit, offset := parseListParams(c)
+
+		rows, err := pool.Query(context.Background(), `select id, amount, reason, source_id, created_at from coin_ledger
+			where user_id=$1 order by created_at desc, id desc limit $2 offset $3`, userID, limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list coins"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]CoinLedgerEntry, 0, limit)
+		for rows.Next() {
+			var e CoinLedgerEntry
+			var createdAt time.Time
+			if err := rows.Scan(&e.ID, &e.Amount, &e.Reason, &e.SourceID, &createdAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list coins"})
+				return
+			}
+			e.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			items = append(items, e)
+		}
+		rows.Close()
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListCoinLedgerResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// Earned achievements stay listed after an admin deactivates them.
+	r.GET("/student/achievements", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+
+		rows, err := pool.Query(context.Background(), `select a.id, a.code, a.name, a.description, a.coins, ua.earned_at
+			from achievements a left join user_achievements ua on ua.achievement_id=a.id and ua.user_id=$1
+			where a.is_active or ua.user_id is not null
+			order by a.sort_order, a.id`, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list achievements"})
+			return
+		}
+		defer rows.Close()
+
+		items := []StudentAchievement{}
+		for rows.Next() {
+			var a StudentAchievement
+			var earnedAt *time.Time
+			if err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Description, &a.Coins, &earnedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list achievements"})
+				return
+			}
+			if earnedAt != nil {
+				v := earnedAt.UTC().Format(time.RFC3339)
+				a.Earned = true
+				a.EarnedAt = &v
+			}
+			items = append(items, a)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	r.GET("/admin/achievements", adminAuth, func(c *gin.Context) {
+		rows, err := pool.Query(context.Background(), `select `+adminAchievementColumns+` from achievements a order by a.sort_order, a.id`)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list achievements"})
+			return
+		}
+		defer rows.Close()
+
+		items := []AdminAchievement{}
+		for rows.Next() {
+			a, err := scanAdminAchievement(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list achievements"})
+				return
+			}
+			items = append(items, a)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	r.POST("/admin/achievements", adminAuth, func(c *gin.Context) {
+		actorUserID, _ := auth.GetUserID(c)
+		actorRole, _ := auth.GetRole(c)
+
+		var req CreateAchievementRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		code := strings.TrimSpace(strings.ToLower(req.Code))
+		name := strings.TrimSpace(req.Name)
+		if code == "" || name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "code and name are required"})
+			return
+		}
+		if req.Coins < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "coins must not be negative"})
+			return
+		}
+		rule, err := normalizeAchievementRule(req.Rule)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		isActive := true
+		if req.IsActive != nil {
+			isActive = *req.IsActive
+		}
+
+		id := util.NewID("ach")
+		ctx := context.Background()
+		_, err = pool.Exec(ctx, `insert into achievements (id, code, name, description, rule, coins, is_active, sort_order)
+			values ($1,$2,$3,$4,$5,$6,$7,$8)`, id, code, name, req.Description, rule, req.Coins, isActive, req.SortOrder)
+		if err != nil {
+			var pgerr *pgconn.PgError
+			if errors.As(err, &pgerr) && pgerr.Code == "23505" {
+				c.JSON(http.StatusConflict, gin.H{"message": "achievement code already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create achievement"})
+			return
+		}
+
+		audit(ctx, pool, actorUserID, actorRole, "achievement.create", "achievement", id, gin.H{"code": code, "rule": json.RawMessage(rule), "coins": req.Coins})
+		a, err := scanAdminAchievement(pool.QueryRow(ctx, `select `+adminAchievementColumns+` from achievements a where a.id=$1`, id))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load achievement"})
+			return
+		}
+		c.JSON(http.StatusOK, a)
+	})
+
+	r.PATCH("/admin/achievements/:achievementId", adminAuth, func(c *gin.Context) {
+		actorUserID, _ := auth.GetUserID(c)
+		actorRole, _ := auth.GetRole(c)
+
+		achievementID := c.Param("achievementId")
+		var req UpdateAchievementRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+
+		set := []string{"updated_at=now()"}
+		args := []any{}
+		changed := gin.H{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "name must not be empty"})
+				return
+			}
+			args = append(args, name)
+			set = append(set, "name="+sqlParam(len(args)))
+			changed["name"] = name
+		}
+		if req.Description != nil {
+			args = append(args, *req.Description)
+			set = append(set, "description="+sqlParam(len(args)))
+			changed["description"] = *req.Description
+		}
+		if len(req.Rule) > 0 {
+			rule, err := normalizeAchievementRule(req.Rule)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
+			}
+			args = append(args, rule)
+			set = append(set, "rule="+sqlParam(len(args)))
+			changed["rule"] = json.RawMessage(rule)
+		}
+		if req.Coins != nil {
+			if *req.Coins < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "coins must not be negative"})
+				return
+			}
+			args = append(args, *req.Coins)
+			set = append(set, "coins="+sqlParam(len(args)))
+			changed["coins"] = *req.Coins
+		}
+		if req.IsActive != nil {
+			args = append(args, *req.IsActive)
+			set = append(set, "is_active="+sqlParam(len(args)))
+			changed["isActive"] = *req.IsActive
+		}
+		if req.SortOrder != nil {
+			args = append(args, *req.SortOrder)
+			set = append(set, "sort_order="+sqlParam(len(args)))
+			changed["sortOrder"] = *req.SortOrder
+		}
+		if len(set) == 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "no updates"})
+			return
+		}
+
+		ctx := context.Background()
+		args = append(args, achievementID)
+		cmd, err := pool.Exec(ctx, `update achievements set `+strings.Join(set, ", ")+` where id=`+sqlParam(len(args)), args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update achievement"})
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "achievement not found"})
+			return
+		}
+
+		audit(ctx, pool, actorUserID, actorRole, "achievement.update", "achievement", achievementID, changed)
+		a, err := scanAdminAchievement(pool.QueryRow(ctx, `select `+adminAchievementColumns+` from achievements a where a.id=$1`, achievementID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load achievement"})
+			return
+		}
+		c.JSON(http.StatusOK, a)
+	})
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/irt"
+)
+
+type IRTCalibration struct {
+	ID              string  `json:"id"`
+	ExamPackageID   string  `json:"examPackageId"`
+	Model           string  `json:"model"`
+	Status          string  `json:"status"`
+	MinResponses    int     `json:"minResponses"`
+	ItemsCalibrated int     `json:"itemsCalibrated"`
+	ItemsSkipped    int     `json:"itemsSkipped"`
+	Persons         int     `json:"persons"`
+	Responses       int     `json:"responses"`
+	Iterations      int     `json:"iterations"`
+	Converged       bool    `json:"converged"`
+	Error           *string `json:"error"`
+	CreatedByUserID *string `json:"createdByUserId"`
+	CreatedAt       string  `json:"createdAt"`
+	CompletedAt     *string `json:"completedAt"`
+}
+
+type ListIRTCalibrationsResponse struct {
+	Items   []IRTCalibration `json:"items"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasMore bool             `json:"hasMore"`
+}
+
+type CreateIRTCalibrationRequest struct {
+	// Model is "2pl" (default) or "3pl".
+	Model        string `json:"model"`
+	MinResponses *int   `json:"minResponses"`
+}
+
+type QuestionIRTParamsResponse struct {
+	Items []irt.Params `json:"items"`
+}
+
+const irtCalibrationColumns = `id, exam_package_id::text, model, status, min_responses, items_calibrated, items_skipped, persons, responses, iterations, converged, error, created_by_user_id, created_at, completed_at`
+
+func scanIRTCalibration(row interface{ Scan(...any) error }) (IRTCalibration, error) {
+	var cal IRTCalibration
+	var createdAt time.Time
+	var completedAt *time.Time
+	err := row.Scan(&cal.ID, &cal.ExamPackageID, &cal.Model, &cal.Status, &cal.MinResponses, &cal.ItemsCalibrated, &cal.ItemsSkipped,
+		&cal.Persons, &cal.Responses, &cal.Iterations, &cal.Converged, &cal.Error, &cal.CreatedByUserID, &createdAt, &completedAt)
+	if err != nil {
+		return cal, err
+	}
+	cal.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	if completedAt != nil {
+		v := completedAt.UTC().Format(time.RFC3339)
+		cal.CompletedAt = &v
+	}
+	return cal, nil
+}
+
+func registerIRTCalibrationRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+	requireAdmin := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	// Calibrations run in the background; poll the returned calibration for its status.
+	r.POST("/admin/exam-packages/:examPackageId/irt-calibrations", requireAdmin, func(c *gin.Context) {
+		actorUserID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		actorRole, _ := auth.GetRole(c)
+		examPackageID := c.Param("examPackageId")
+
+		var req CreateIRTCalibrationRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		model := irt.Model(strings.ToLower(strings.TrimSpace(req.Model)))
+		if model == "" {
+			model = irt.Model2PL
+		}
+		if model != irt.Model2PL && model != irt.Model3PL {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "model must be 2pl or 3pl"})
+			return
+		}
+		minResponses := irt.DefaultMinResponses()
+		if req.MinResponses != nil {
+			if *req.MinResponses < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "minResponses must be at least 1"})
+				return
+			}
+			minResponses = *req.MinResponses
+		}
+
+		ctx := context.Background()
+		var exists bool
+		if err := pool.QueryRow(ctx, `select exists(select 1 from exam_packages where id::text=$1)`, examPackageID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "exam package not found"})
+			return
+		}
+
+		calReq := irt.CalibrationRequest{ExamPackageID: examPackageID, Model: model, MinResponses: minResponses, CreatedByUserID: &actorUserID}
+		id, err := irt.StartCalibration(ctx, pool, calReq)
+		if errors.Is(err, irt.ErrCalibrationRunning) {
+			c.JSON(http.StatusConflict, gin.H{"message": "a calibration is already running for this exam package"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to start calibration"})
+			return
+		}
+		go func() {
+			if err := irt.RunCalibration(context.Background(), pool, id, calReq); err != nil {
+				log.Printf("irt: calibration %s failed: %v", id, err)
+			}
+		}()
+
+		audit(ctx, pool, actorUserID, actorRole, "admin.irt_calibrations.create", "exam_package", examPackageID, gin.H{"calibrationId": id, "model": model, "minResponses": minResponses})
+
+		cal, err := scanIRTCalibration(pool.QueryRow(ctx, `select `+irtCalibrationColumns+` from irt_calibrations where id=$1`, id))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load calibration"})
+			return
+		}
+		c.JSON(http.StatusAccepted, cal)
+	})
+
+	r.GET("/admin/exam-packages/:examPackageId/irt-calibrations", requireAdmin, func(c *gin.Context) {
+		limit, offset := parseListParams(c)
+		rows, err := pool.Query(context.Background(), `select `+irtCalibrationColumns+` from irt_calibrations
+			where exam_package_id::text=$1 order by created_at desc limit $2 offset $3`, c.Param("examPackageId"), limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list calibrations"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]IRTCalibration, 0, limit)
+		for rows.Next() {
+			cal, err := scanIRTCalibration(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list calibrations"})
+				return
+			}
+			items = append(items, cal)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListIRTCalibrationsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	r.GET("/admin/irt-calibrations/:calibrationId", requireAdmin, func(c *gin.Context) {
+		cal, err := scanIRTCalibration(pool.QueryRow(context.Background(), `select `+irtCalibrationColumns+` from irt_calibrations where id=$1`, c.Param("calibrationId")))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "calibration not found"})
+			return
+		}
+		c.JSON(http.StatusOK, cal)
+	})
+
+	r.GET("/instructor/questions/:questionId/irt", requireInstructorOrAdmin, func(c *gin.Context) {
+		items, err := irt.LoadParams(context.Background(), pool, c.Param("questionId"), false)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load irt parameters"})
+			return
+		}
+		c.JSON(http.StatusOK, QuestionIRTParamsResponse{Items: items})
+	})
+}
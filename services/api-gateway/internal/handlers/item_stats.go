@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/itemstats"
+)
+
+type RecomputeItemStatsRequest struct {
+	QuestionBankID *string `json:"questionBankId"`
+}
+
+type RecomputeItemStatsResponse struct {
+	Recomputed int `json:"recomputed"`
+}
+
+// registerItemStatsRoutes exposes on-demand recomputation; the nightly run lives in itemstats.RunNightly.
+func registerItemStatsRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+	requireAdmin := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	r.POST("/instructor/questions/:questionId/stats/recompute", requireInstructorOrAdmin, func(c *gin.Context) {
+		qid := c.Param("questionId")
+		ctx := context.Background()
+
+		var exists bool
+		if err := pool.QueryRow(ctx, `select exists(select 1 from question_bank_questions where id=$1)`, qid).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+		if _, err := itemstats.Recompute(ctx, pool, []string{qid}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute item statistics"})
+			return
+		}
+		stats, err := itemstats.Load(ctx, pool, qid)
+		if err != nil || stats == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load item statistics"})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+	})
+
+	r.POST("/admin/item-stats/recompute", requireAdmin, func(c *gin.Context) {
+		actorUserID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		actorRole, _ := auth.GetRole(c)
+
+		var req RecomputeItemStatsRequest
+		_ = c.ShouldBindJSON(&req)
+
+		ctx := context.Background()
+		var questionIDs []string
+		targetID := ""
+		if bankID := nilIfEmptyPtr(req.QuestionBankID); bankID != nil {
+			targetID = *bankID
+			rows, err := pool.Query(ctx, `select id from question_bank_questions where package_id=$1`, targetID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute item statistics"})
+				return
+			}
+			questionIDs = []string{}
+			for rows.Next() {
+				var id string
+				if err := rows.Scan(&id); err != nil {
+					rows.Close()
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute item statistics"})
+					return
+				}
+				questionIDs = append(questionIDs, id)
+			}
+			rows.Close()
+			if len(questionIDs) == 0 {
+				c.JSON(http.StatusOK, RecomputeItemStatsResponse{})
+				return
+			}
+		}
+
+		n, err := itemstats.Recompute(ctx, pool, questionIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to compute item statistics"})
+			return
+		}
+		audit(ctx, pool, actorUserID, actorRole, "admin.item_stats.recompute", "question_bank", targetID, gin.H{"recomputed": n})
+		c.JSON(http.StatusOK, RecomputeItemStatsResponse{Recomputed: n})
+	})
+}
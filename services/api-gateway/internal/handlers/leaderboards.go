@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+)
+
+type LeaderboardEntry struct {
+	Rank            int     `json:"rank"`
+	DisplayName     string  `json:"displayName"`
+	Score           float64 `json:"score"`
+	DurationSeconds int64   `json:"durationSeconds"`
+	Sessions        int     `json:"sessions"`
+	Exams           int     `json:"exams"`
+	IsMe            bool    `json:"isMe"`
+}
+
+type LeaderboardResponse struct {
+	Scope       string             `json:"scope"`
+	ScopeID     string             `json:"scopeId"`
+	Window      string             `json:"window"`
+	PeriodStart string             `json:"periodStart"`
+	Items       []LeaderboardEntry `json:"items"`
+	// Me is the caller's line even when it is outside Items; null when the
+	// caller has no result in the window or has opted out.
+	Me       *LeaderboardEntry `json:"me"`
+	OptedOut bool              `json:"optedOut"`
+}
+
+func toLeaderboardEntry(e leaderboard.Entry, userID string) LeaderboardEntry {
+	return LeaderboardEntry{
+		Rank:            e.Rank,
+		DisplayName:     e.DisplayName,
+		Score:           e.Score,
+		DurationSeconds: e.DurationSeconds,
+		Sessions:        e.Sessions,
+		Exams:           e.Exams,
+		IsMe:            e.UserID == userID,
+	}
+}
+
+// canViewLeaderboard reports whether a student may see a board: package
+// boards need an enrollment, template boards a published template in an
+// enrolled package, and cohort boards membership of the cohort.
+func canViewLeaderboard(ctx context.Context, pool *pgxpool.Pool, userID, scope, scopeID string) (bool, error) {
+	var query string
+	switch scope {
+	case leaderboard.ScopePackage:
+		query = `select exists (select 1 from user_exam_package_enrollments where user_id=$1 and exam_package_id::text=$2)`
+	case leaderboard.ScopeTemplate:
+		query = `select exists (select 1 from practice_templates t
+			join user_exam_package_enrollments e on e.exam_package_id=t.exam_package_id and e.user_id=$1
+			where t.id::text=$2 and t.is_published)`
+	case leaderboard.ScopeCohort:
+		query = `select exists (select 1 from cohort_members where user_id=$1 and cohort_id=$2)`
+	default:
+		return false, nil
+	}
+	var ok bool
+	err := pool.QueryRow(ctx, query, userID, scopeID).Scan(&ok)
+	return ok, err
+}
+
+func RegisterLeaderboardRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	registerCohortRoutes(r, pool)
+
+	r.GET("/student/leaderboards/:scope/:scopeId", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		scope := c.Param("scope")
+		scopeID := c.Param("scopeId")
+		window := c.DefaultQuery("window", leaderboard.WindowWeek)
+		if !leaderboard.ValidWindow(window) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "window must be week, month or all"})
+			return
+		}
+		limit, _ := parseListParams(c)
+		ctx := context.Background()
+
+		allowed, err := canViewLeaderboard(ctx, pool, userID, scope, scopeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load leaderboard"})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusNotFound, gin.H{"message": "leaderboard not found"})
+			return
+		}
+
+		board, err := leaderboard.Load(ctx, pool, leaderboard.Query{
+			Scope:   scope,
+			ScopeID: scopeID,
+			Window:  window,
+			At:      time.Now(),
+			UserID:  userID,
+			Limit:   limit,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load leaderboard"})
+			return
+		}
+
+		resp := LeaderboardResponse{
+			Scope:       scope,
+			ScopeID:     scopeID,
+			Window:      window,
+			PeriodStart: board.PeriodStart.Format(time.RFC3339),
+			Items:       make([]LeaderboardEntry, 0, len(board.Items)),
+			OptedOut:    board.OptedOut,
+		}
+		for _, e := range board.Items {
+			resp.Items = append(resp.Items, toLeaderboardEntry(e, userID))
+		}
+		if board.Me != nil {
+			me := toLeaderboardEntry(*board.Me, userID)
+			resp.Me = &me
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+}
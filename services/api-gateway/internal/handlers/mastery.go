@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/mastery"
+)
+
+type TopicMastery struct {
+	TopicID        string `json:"topicId"`
+	TopicName      string `json:"topicName"`
+	QuestionBankID string `json:"questionBankId"`
+	// Rating is the ability estimate on the IRT logit scale (0 = average).
+	Rating float64 `json:"rating"`
+	// Level is the expected success rate on a question of average difficulty.
+	Level           float64 `json:"level"`
+	Attempts        int     `json:"attempts"`
+	CorrectCount    int     `json:"correctCount"`
+	LastPracticedAt *string `json:"lastPracticedAt"`
+	Locale          string  `json:"locale,omitempty"`
+}
+
+type StudentMasteryResponse struct {
+	Items []TopicMastery `json:"items"`
+}
+
+func registerMasteryRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	// Without examPackageId only practiced topics are listed; with it, every
+	// visible topic of the package's banks is listed (unpracticed ones at 0).
+	r.GET("/student/mastery", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+
+		args := []any{userID}
+		query := `select question_bank_topics.id, question_bank_topics.name, question_bank_topics.package_id, b.source_locale,
+				coalesce(um.rating, 0), coalesce(um.attempts, 0), coalesce(um.correct_count, 0), um.updated_at
+			from question_bank_topics
+			join question_banks b on b.id=question_bank_topics.package_id
+			left join user_topic_mastery um on um.topic_id=question_bank_topics.id and um.user_id=$1
+			where question_bank_topics.is_hidden=false and b.is_hidden=false and ` + liveTopicSQL
+		if examPackageID := strings.TrimSpace(c.Query("examPackageId")); examPackageID != "" {
+			args = append(args, examPackageID)
+			query += ` and exists (select 1 from exam_package_question_bank_packages m where m.question_bank_package_id=b.id and m.exam_package_id::text=$2)`
+		} else {
+			query += ` and um.user_id is not null`
+		}
+		query += ` order by question_bank_topics.name asc, question_bank_topics.id asc`
+
+		ctx := context.Background()
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load mastery"})
+			return
+		}
+		defer rows.Close()
+
+		items := []TopicMastery{}
+		for rows.Next() {
+			var item TopicMastery
+			var updatedAt *time.Time
+			if err := rows.Scan(&item.TopicID, &item.TopicName, &item.QuestionBankID, &item.Locale, &item.Rating, &item.Attempts, &item.CorrectCount, &updatedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load mastery"})
+				return
+			}
+			item.Level = mastery.Level(item.Rating)
+			if updatedAt != nil && item.Attempts > 0 {
+				v := updatedAt.UTC().Format(time.RFC3339)
+				item.LastPracticedAt = &v
+			}
+			items = append(items, item)
+		}
+		rows.Close()
+
+		sources := map[string]string{}
+		for _, item := range items {
+			sources[item.TopicID] = item.Locale
+		}
+		translations, err := loadTranslations(ctx, pool, translationEntityTopic, sources, requestLocales(ctx, pool, c, userID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+			return
+		}
+		for i := range items {
+			if tr, ok := translations[items[i].TopicID]; ok {
+				items[i].TopicName = localizedText(items[i].TopicName, tr.Fields.Name)
+				items[i].Locale = tr.Locale
+			}
+		}
+		c.JSON(http.StatusOK, StudentMasteryResponse{Items: items})
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+)
+
+type UserNotification struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt string          `json:"createdAt"`
+	ReadAt    *string         `json:"readAt"`
+}
+
+type ListNotificationsResponse struct {
+	Items       []UserNotification `json:"items"`
+	UnreadCount int                `json:"unreadCount"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+	HasMore     bool               `json:"hasMore"`
+}
+
+const notificationQuestionReportFixed = "question_report.fixed"
+
+// notifyFixedQuestionReports tells every student who reported a question that
+// the fix is live. It only fires once the question is published and the report
+// was resolved as fixed, and each report notifies at most once.
+func notifyFixedQuestionReports(ctx context.Context, pool *pgxpool.Pool, questionID string) {
+	_, _ = pool.Exec(ctx, `with due as (
+			update question_reports r set notified_at=now()
+			from question_bank_questions q
+			where r.question_id=$1 and q.id=r.question_id and q.status=$2
+				and r.status=$3 and r.outcome=$4 and r.notified_at is null
+			returning r.id, r.question_id
+		)
+		insert into user_notifications (id, user_id, type, title, body, data)
+		select 'ntf_' || md5(due.id || ':' || s.user_id), s.user_id, $5,
+			'A question you reported has been fixed',
+			'Thanks for your report. The corrected question is now live.',
+			json_build_object('reportId', due.id, 'questionId', due.question_id)
+		from due join question_report_submissions s on s.report_id=due.id
+		on conflict (id) do nothing`,
+		questionID, string(QuestionPublished), questionReportResolved, questionReportOutcomeFixed, notificationQuestionReportFixed)
+}
+
+func RegisterNotificationRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	studentAuth := auth.RequirePortalAuth(pool, "student", "student")
+
+	r.GET("/student/notifications", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		limit, offset := parseListParams(c)
+		ctx := context.Background()
+
+		query := `select id, type, title, body, data, created_at, read_at from user_notifications where user_id=$1`
+		if parseBoolQuery(c, "unreadOnly") {
+			query += ` and read_at is null`
+		}
+		query += ` order by created_at desc limit $2 offset $3`
+		rows, err := pool.Query(ctx, query, userID, limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list notifications"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]UserNotification, 0, limit)
+		for rows.Next() {
+			var n UserNotification
+			var data []byte
+			var createdAt time.Time
+			var readAt *time.Time
+			if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &data, &createdAt, &readAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list notifications"})
+				return
+			}
+			if len(data) == 0 {
+				data = []byte("{}")
+			}
+			n.Data = json.RawMessage(data)
+			n.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			if readAt != nil {
+				v := readAt.UTC().Format(time.RFC3339)
+				n.ReadAt = &v
+			}
+			items = append(items, n)
+		}
+		rows.Close()
+
+		var unread int
+		_ = pool.QueryRow(ctx, `select count(*) from user_notifications where user_id=$1 and read_at is null`, userID).Scan(&unread)
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListNotificationsResponse{Items: items, UnreadCount: unread, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	r.POST("/student/notifications/:notificationId/read", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		cmd, err := pool.Exec(context.Background(), `update user_notifications set read_at=coalesce(read_at, now()) where id=$1 and user_id=$2`, c.Param("notificationId"), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update notification"})
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "notification not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	r.POST("/student/notifications/read-all", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		if _, err := pool.Exec(context.Background(), `update user_notifications set read_at=now() where user_id=$1 and read_at is null`, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update notifications"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -3,15 +3,28 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/grading"
+	"github.com/ace-platform/api-gateway/internal/hints"
+	"github.com/ace-platform/api-gateway/internal/locale"
+	"github.com/ace-platform/api-gateway/internal/mastery"
+	"github.com/ace-platform/api-gateway/internal/policy"
+	"github.com/ace-platform/api-gateway/internal/shuffle"
+	"github.com/ace-platform/api-gateway/internal/srs"
+	"github.com/ace-platform/api-gateway/internal/timing"
 	"github.com/ace-platform/api-gateway/internal/util"
 )
 
@@ -23,7 +36,23 @@ const (
 	PracticeSessionFinished PracticeSessionStatus = "finished"
 )
 
-const ironmanSecondsPerQuestion = 60
+const (
+	practiceModeStandard = "standard"
+	practiceModeAdaptive = "adaptive"
+	practiceModeReview   = "review"
+)
+
+const (
+	practiceSourceAll        = "all"
+	practiceSourceBookmarked = "bookmarked"
+	practiceSourceIncorrect  = "incorrect"
+	practiceSourceUnseen     = "unseen"
+)
+
+// adaptiveJitter (in logits) is added at random to each unit's distance from
+// the target difficulty, so equally suited units are not always served in the
+// same order.
+const adaptiveJitter = 0.5
 
 type PracticeQuestionChoice struct {
 	ID   string `json:"id"`
@@ -34,6 +63,10 @@ type PracticeQuestion struct {
 	ID      string                  `json:"id"`
 	Prompt  string                  `json:"prompt"`
 	Choices []PracticeQuestionChoice `json:"choices"`
+	StimulusID *string              `json:"stimulusId,omitempty"`
+	Locale  string                  `json:"locale,omitempty"`
+	// HintCount is the number of hints the student may reveal in practice.
+	HintCount int                   `json:"hintCount,omitempty"`
 }
 
 type CreatePracticeSessionRequest struct {
@@ -41,6 +74,21 @@ type CreatePracticeSessionRequest struct {
 	TemplateID *string `json:"templateId"`
 	Timed     bool    `json:"timed"`
 	Count     int     `json:"count"`
+	// Adaptive picks each next question near the student's mastery of its
+	// topic instead of drawing the whole session up front.
+	Adaptive  bool    `json:"adaptive"`
+	// ReviewDue draws the student's due review-deck items, most overdue first.
+	ReviewDue bool    `json:"reviewDue"`
+	// Source is "all" (default), "bookmarked", "incorrect" (latest practice
+	// answer was wrong) or "unseen" (never answered in practice).
+	Source    string  `json:"source"`
+	// TopicID and DifficultyID narrow sessions that are not template-driven;
+	// templates bring their own filters.
+	TopicID      *string `json:"topicId"`
+	DifficultyID *string `json:"difficultyId"`
+	// FreeNavigation lets the student move between questions, mark them for
+	// review and change answers; answers are graded on submit.
+	FreeNavigation bool `json:"freeNavigation"`
 }
 
 type PracticeSessionResponse struct {
@@ -50,6 +98,10 @@ type PracticeSessionResponse struct {
 	StartedAt    string               `json:"startedAt"`
 	ExamPackageID    *string              `json:"examPackageId"`
 	IsTimed      bool                 `json:"isTimed"`
+	Adaptive     bool                 `json:"adaptive"`
+	ReviewDue    bool                 `json:"reviewDue"`
+	Source       string               `json:"source"`
+	FreeNavigation bool               `json:"freeNavigation"`
 	TimeLimitSeconds *int             `json:"timeLimitSeconds,omitempty"`
 	CurrentQuestionStartedAt *string  `json:"currentQuestionStartedAt,omitempty"`
 	QuestionTimingsSeconds map[string]int `json:"questionTimingsSeconds,omitempty"`
@@ -58,6 +110,21 @@ type PracticeSessionResponse struct {
 	Total        int                  `json:"total"`
 	CorrectCount int                  `json:"correctCount"`
 	Question     *PracticeQuestion    `json:"question"`
+	// Stimulus is only sent with the first question of its group (and on resume or
+	// GET ?includeStimulus=true); later questions carry just question.stimulusId.
+	Stimulus     *PracticeStimulus    `json:"stimulus,omitempty"`
+	// Items is the question map of free-navigation sessions.
+	Items        []PracticeNavigationItem `json:"items,omitempty"`
+	// Sections are the boundaries of blueprint sessions; in timed sessions
+	// SectionTimeRemainingSeconds counts down the current section's limit.
+	Sections     []blueprint.Boundary `json:"sections,omitempty"`
+	SectionTimeRemainingSeconds *int  `json:"sectionTimeRemainingSeconds,omitempty"`
+	// Timed sessions report the server's countdowns: the session, and the
+	// current question when it auto-advances. Answers are still accepted for
+	// GraceSeconds after either runs out.
+	TimeRemainingSeconds *int         `json:"timeRemainingSeconds,omitempty"`
+	QuestionTimeRemainingSeconds *int `json:"questionTimeRemainingSeconds,omitempty"`
+	GraceSeconds *int                 `json:"graceSeconds,omitempty"`
 }
 
 type SubmitPracticeAnswerRequest struct {
@@ -70,6 +137,14 @@ type SubmitPracticeAnswerResponse struct {
 	Correct     bool   `json:"correct"`
 	Explanation string `json:"explanation"`
 	Done        bool   `json:"done"`
+	// HintsUsed is the number of hints revealed before answering; Credit is
+	// what the answer earned after the hint penalty.
+	HintsUsed   int     `json:"hintsUsed"`
+	Credit      float64 `json:"credit"`
+	// TimeRemainingSeconds and QuestionTimeRemainingSeconds are the timed
+	// countdowns after the answer, for the next question.
+	TimeRemainingSeconds *int         `json:"timeRemainingSeconds,omitempty"`
+	QuestionTimeRemainingSeconds *int `json:"questionTimeRemainingSeconds,omitempty"`
 }
 
 type PracticeSessionSummaryResponse struct {
@@ -77,6 +152,10 @@ type PracticeSessionSummaryResponse struct {
 	Total        int     `json:"total"`
 	CorrectCount int     `json:"correctCount"`
 	Accuracy     float64 `json:"accuracy"`
+	// Score sums the credit of the graded answers: correct answers count 1,
+	// less the hint penalty. HintsUsed counts the hints behind those answers.
+	Score        float64 `json:"score"`
+	HintsUsed    int     `json:"hintsUsed"`
 }
 
 type PracticeSessionReviewItem struct {
@@ -86,12 +165,25 @@ type PracticeSessionReviewItem struct {
 	Correct          *bool            `json:"correct,omitempty"`
 	Explanation      *string          `json:"explanation,omitempty"`
 	TimeTakenSeconds int              `json:"timeTakenSeconds"`
+	HintsUsed        int              `json:"hintsUsed"`
+	Credit           *float64         `json:"credit,omitempty"`
 	CorrectChoiceID  string           `json:"correctChoiceId"`
+	// Question.Choices are in the order the student saw them; ChoiceOrder maps
+	// each displayed position back to the canonical (authored) position.
+	ChoiceOrder        []ReviewChoiceOrder `json:"choiceOrder"`
+	CanonicalChoiceIDs []string            `json:"canonicalChoiceIds"`
+}
+
+type ReviewChoiceOrder struct {
+	ChoiceID       string `json:"choiceId"`
+	DisplayIndex   int    `json:"displayIndex"`
+	CanonicalIndex int    `json:"canonicalIndex"`
 }
 
 type PracticeSessionReviewResponse struct {
 	SessionID string                    `json:"sessionId"`
 	Items     []PracticeSessionReviewItem `json:"items"`
+	Stimuli   []PracticeStimulus          `json:"stimuli"`
 }
 
 type PracticeSessionListItem struct {
@@ -121,6 +213,52 @@ type practiceQuestionSnapshot struct {
 	Choices        []PracticeQuestionChoice `json:"choices"`
 	CorrectChoiceID string                 `json:"correctChoiceId"`
 	Explanation    string                  `json:"explanation"`
+	StimulusID     string                  `json:"stimulusId,omitempty"`
+	// Choices are stored in canonical order; the session seed decides the displayed order.
+	ShuffleChoices  bool                   `json:"shuffleChoices,omitempty"`
+	PinnedChoiceIDs []string               `json:"pinnedChoiceIds,omitempty"`
+	// Locale the text above was captured in (a translation or the source locale).
+	Locale          string                 `json:"locale,omitempty"`
+	// Hints in reveal order; never sent as a whole, only one by one on request.
+	Hints           []string               `json:"hints,omitempty"`
+}
+
+// displayedChoices returns the choices in the order this session shows them.
+// A nil seed (sessions created before shuffling) keeps the canonical order.
+func (q practiceQuestionSnapshot) displayedChoices(seed *int64) []PracticeQuestionChoice {
+	if seed == nil || !q.ShuffleChoices {
+		return q.Choices
+	}
+	pinned := make([]bool, len(q.Choices))
+	for i, ch := range q.Choices {
+		for _, id := range q.PinnedChoiceIDs {
+			if ch.ID == id {
+				pinned[i] = true
+			}
+		}
+	}
+	out := make([]PracticeQuestionChoice, len(q.Choices))
+	for i, canonical := range shuffle.Order(*seed, q.ID, len(q.Choices), pinned) {
+		out[i] = q.Choices[canonical]
+	}
+	return out
+}
+
+// choiceMapping returns the displayed choices together with the mapping from
+// each displayed position to its canonical position and the canonical ids.
+func (q practiceQuestionSnapshot) choiceMapping(seed *int64) ([]PracticeQuestionChoice, []ReviewChoiceOrder, []string) {
+	displayed := q.displayedChoices(seed)
+	canonicalIDs := make([]string, len(q.Choices))
+	canonicalIndex := map[string]int{}
+	for i, ch := range q.Choices {
+		canonicalIDs[i] = ch.ID
+		canonicalIndex[ch.ID] = i
+	}
+	order := make([]ReviewChoiceOrder, len(displayed))
+	for i, ch := range displayed {
+		order[i] = ReviewChoiceOrder{ChoiceID: ch.ID, DisplayIndex: i, CanonicalIndex: canonicalIndex[ch.ID]}
+	}
+	return displayed, order, canonicalIDs
 }
 
 type bankItem struct {
@@ -185,16 +323,472 @@ func findInBank(bank []bankItem, id string) (bankItem, bool) {
 	return bankItem{}, false
 }
 
-func loadSnapshotQuestion(snapshot []practiceQuestionSnapshot, idx int) *PracticeQuestion {
+func loadSnapshotQuestion(snapshot []practiceQuestionSnapshot, idx int, seed *int64) *PracticeQuestion {
 	if idx < 0 || idx >= len(snapshot) {
 		return nil
 	}
 	q := snapshot[idx]
-	return &PracticeQuestion{ID: q.ID, Prompt: q.Prompt, Choices: q.Choices}
+	return &PracticeQuestion{ID: q.ID, Prompt: q.Prompt, Choices: q.displayedChoices(seed), StimulusID: snapshotStimulusID(q), Locale: q.Locale, HintCount: len(q.Hints)}
+}
+
+func snapshotStimulusID(q practiceQuestionSnapshot) *string {
+	if q.StimulusID == "" {
+		return nil
+	}
+	v := q.StimulusID
+	return &v
+}
+
+// loadPracticeStimuli loads the stimuli referenced by a session snapshot, in
+// order of first appearance.
+func loadPracticeStimuli(ctx context.Context, pool *pgxpool.Pool, snapshot []practiceQuestionSnapshot) ([]PracticeStimulus, error) {
+	ids := make([]string, 0)
+	seen := map[string]bool{}
+	for _, q := range snapshot {
+		if q.StimulusID != "" && !seen[q.StimulusID] {
+			seen[q.StimulusID] = true
+			ids = append(ids, q.StimulusID)
+		}
+	}
+	stimuli := make([]PracticeStimulus, 0, len(ids))
+	if len(ids) == 0 {
+		return stimuli, nil
+	}
+
+	rows, err := pool.Query(ctx, `select id, title, passage, media_url, media_type from question_stimuli where id = any($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byID := map[string]PracticeStimulus{}
+	for rows.Next() {
+		var s PracticeStimulus
+		if err := rows.Scan(&s.ID, &s.Title, &s.Passage, &s.MediaURL, &s.MediaType); err != nil {
+			return nil, err
+		}
+		byID[s.ID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if s, ok := byID[id]; ok {
+			stimuli = append(stimuli, s)
+		}
+	}
+	return stimuli, nil
+}
+
+// stimulusForIndex returns the stimulus to deliver with the question at idx.
+// Passages go out once per group: only when idx opens its group, unless force
+// is set (e.g. on resume, where the client may have dropped its copy).
+func stimulusForIndex(snapshot []practiceQuestionSnapshot, stimuli []PracticeStimulus, idx int, force bool) *PracticeStimulus {
+	if idx < 0 || idx >= len(snapshot) || snapshot[idx].StimulusID == "" {
+		return nil
+	}
+	sid := snapshot[idx].StimulusID
+	if !force && idx > 0 && snapshot[idx-1].StimulusID == sid {
+		return nil
+	}
+	for i := range stimuli {
+		if stimuli[i].ID == sid {
+			s := stimuli[i]
+			return &s
+		}
+	}
+	return nil
+}
+
+// packQuestionUnits picks whole units (a standalone question or a complete
+// stimulus group, as index lists) in the given order until count is reached.
+// Units that do not fit are skipped; if the session is still short it is topped
+// up with the leading questions of the first skipped group, which keeps that
+// group contiguous and in authored order.
+func packQuestionUnits(units [][]int, count int) []int {
+	out := make([]int, 0, count)
+	var skipped []int
+	for _, u := range units {
+		if len(out) == count {
+			break
+		}
+		if len(out)+len(u) <= count {
+			out = append(out, u...)
+			continue
+		}
+		if skipped == nil {
+			skipped = u
+		}
+	}
+	if len(out) < count && skipped != nil {
+		out = append(out, skipped[:count-len(out)]...)
+	}
+	return out
+}
+
+var errInsufficientChoices = errors.New("question has insufficient choices")
+
+// practiceCandidate is an eligible question as selected for a session.
+type practiceCandidate struct {
+	ID         string
+	Prompt     string
+	Explain    string
+	CorrectID  string
+	StimulusID *string
+	Shuffle    bool
+	Locale     string
+}
+
+// practiceFilter narrows the questions a session draws from.
+type practiceFilter struct {
+	PackageID    string
+	TopicID      *string
+	DifficultyID *string
+	// Source limits the pool to the student's bookmarked questions, questions
+	// whose latest practice answer was wrong, or questions never answered.
+	Source string
+	UserID string
+}
+
+// eligiblePracticeSQL is the body of the "eligible" CTE: published, live
+// questions of the exam package, narrowed by f. Its parameters are appended
+// to args.
+func eligiblePracticeSQL(args *[]any, f practiceFilter) string {
+	*args = append(*args, string(QuestionPublished), f.PackageID)
+	query := `
+			select q.id, q.topic_id, q.difficulty_id, q.prompt, q.explanation_text, cc.choice_id, q.stimulus_id, q.stimulus_order, q.shuffle_choices, p.source_locale
+			from question_bank_questions q
+			join question_banks p on p.id=q.package_id
+			join exam_package_question_bank_packages m on m.question_bank_package_id=p.id
+			join question_bank_correct_choice cc on cc.question_id=q.id
+			where q.status=$` + strconv.Itoa(len(*args)-1) + ` and p.is_hidden=false and m.exam_package_id=$` + strconv.Itoa(len(*args)) + `
+				and q.deleted_at is null and p.deleted_at is null`
+	if f.TopicID != nil && strings.TrimSpace(*f.TopicID) != "" {
+		*args = append(*args, strings.TrimSpace(*f.TopicID))
+		query += " and q.topic_id=$" + strconv.Itoa(len(*args))
+	}
+	if f.DifficultyID != nil && strings.TrimSpace(*f.DifficultyID) != "" {
+		*args = append(*args, strings.TrimSpace(*f.DifficultyID))
+		query += " and q.difficulty_id=$" + strconv.Itoa(len(*args))
+	}
+	switch f.Source {
+	case practiceSourceBookmarked:
+		*args = append(*args, f.UserID)
+		query += " and exists (select 1 from question_bookmarks bm where bm.question_id=q.id and bm.user_id=$" + strconv.Itoa(len(*args)) + ")"
+	case practiceSourceIncorrect:
+		*args = append(*args, f.UserID)
+		query += " and (select pa.correct from practice_answers pa where pa.question_id=q.id and pa.user_id=$" + strconv.Itoa(len(*args)) + " and pa.is_final order by pa.ts desc limit 1) = false"
+	case practiceSourceUnseen:
+		*args = append(*args, f.UserID)
+		query += " and not exists (select 1 from practice_answers pa where pa.question_id=q.id and pa.user_id=$" + strconv.Itoa(len(*args)) + " and pa.is_final)"
+	}
+	return query
+}
+
+// selectAdaptiveUnit picks the next unit (a standalone question or a stimulus
+// group, capped at limit questions) for an adaptive session: the one whose
+// average difficulty, relative to the student's mastery of each question's
+// topic, is closest to mastery.TargetCorrect. Questions in exclude are skipped.
+// It returns nil when nothing is left.
+func selectAdaptiveUnit(ctx context.Context, db rowsQuerier, f practiceFilter, exclude []string, limit int) ([]practiceCandidate, error) {
+	args := []any{}
+	query := `with eligible as (` + eligiblePracticeSQL(&args, f)
+	args = append(args, exclude)
+	query += ` and q.id <> all($` + strconv.Itoa(len(args)) + `::text[])`
+	args = append(args, f.UserID, mastery.TargetDifficulty(0), adaptiveJitter)
+	n := len(args)
+	query += `),
+		scored as (
+		select e.*, ` + mastery.DifficultySQL("e") + ` - coalesce(um.rating, 0) as gap
+		from eligible e
+		left join user_topic_mastery um on um.topic_id=e.topic_id and um.user_id=$` + strconv.Itoa(n-2) + `),
+		unit as (
+		select coalesce(stimulus_id, id) as unit_id
+		from scored group by coalesce(stimulus_id, id)
+		order by abs(avg(gap) - $` + strconv.Itoa(n-1) + `) + random() * $` + strconv.Itoa(n) + ` limit 1)
+		select s.id, s.prompt, s.explanation_text, s.choice_id, s.stimulus_id, s.shuffle_choices, s.source_locale
+		from scored s join unit u on u.unit_id=coalesce(s.stimulus_id, s.id)
+		order by s.stimulus_order, s.id`
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []practiceCandidate{}
+	for rows.Next() {
+		var p practiceCandidate
+		if err := rows.Scan(&p.ID, &p.Prompt, &p.Explain, &p.CorrectID, &p.StimulusID, &p.Shuffle, &p.Locale); err != nil {
+			return nil, err
+		}
+		if len(out) < limit {
+			out = append(out, p)
+		}
+	}
+	return out, rows.Err()
+}
+
+// buildPracticeSnapshots loads the choices and hints of the picked questions
+// and returns their snapshots, localized for the given locale candidates.
+func buildPracticeSnapshots(ctx context.Context, pool *pgxpool.Pool, picked []practiceCandidate, locales []string) ([]practiceQuestionSnapshot, error) {
+	qIDs := make([]string, 0, len(picked))
+	for _, q := range picked {
+		qIDs = append(qIDs, q.ID)
+	}
+	choicesRows, err := pool.Query(ctx, `
+		select question_id, id, text, is_pinned
+		from question_bank_choices
+		where question_id = any($1)
+		order by question_id asc, order_index asc`, qIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer choicesRows.Close()
+
+	choicesByQ := map[string][]PracticeQuestionChoice{}
+	pinnedByQ := map[string][]string{}
+	for choicesRows.Next() {
+		var qid, cid, text string
+		var pinned bool
+		if err := choicesRows.Scan(&qid, &cid, &text, &pinned); err != nil {
+			return nil, err
+		}
+		choicesByQ[qid] = append(choicesByQ[qid], PracticeQuestionChoice{ID: cid, Text: text})
+		if pinned {
+			pinnedByQ[qid] = append(pinnedByQ[qid], cid)
+		}
+	}
+	choicesRows.Close()
+
+	hintsByQ, err := loadQuestionHints(ctx, pool, qIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]practiceQuestionSnapshot, 0, len(picked))
+	sourceLocales := map[string]string{}
+	for _, q := range picked {
+		chs := choicesByQ[q.ID]
+		if len(chs) < 2 {
+			return nil, errInsufficientChoices
+		}
+		snap := practiceQuestionSnapshot{
+			ID:              q.ID,
+			Prompt:          q.Prompt,
+			Choices:         chs,
+			CorrectChoiceID: q.CorrectID,
+			Explanation:     q.Explain,
+			ShuffleChoices:  q.Shuffle,
+			PinnedChoiceIDs: pinnedByQ[q.ID],
+			Hints:           hintsByQ[q.ID],
+		}
+		if q.StimulusID != nil {
+			snap.StimulusID = *q.StimulusID
+		}
+		snapshot = append(snapshot, snap)
+		sourceLocales[q.ID] = q.Locale
+	}
+
+	if err := localizeQuestionSnapshots(ctx, pool, snapshot, sourceLocales, locales); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// drawAdaptiveUnit snapshots the next unit of an adaptive session, chosen
+// with the student's current mastery. The unit is selected in tx, the answer
+// transaction, so it sees the mastery update of the answer being recorded.
+// An empty result means the pool ran out.
+func drawAdaptiveUnit(ctx context.Context, pool *pgxpool.Pool, tx pgx.Tx, filter practiceFilter, sessionLocale *string, order []string, limit int) ([]practiceQuestionSnapshot, error) {
+	next, err := selectAdaptiveUnit(ctx, tx, filter, order, limit)
+	if err != nil {
+		return nil, err
+	}
+	var locales []string
+	if sessionLocale != nil {
+		locales = locale.Candidates([]string{*sessionLocale})
+	}
+	return buildPracticeSnapshots(ctx, pool, next, locales)
+}
+
+// errPracticeSessionNotFound is returned for sessions that do not exist or
+// belong to another student.
+var errPracticeSessionNotFound = errors.New("practice session not found")
+
+// loadPracticeSession returns the current state of a student's session after
+// applying its time limits: expired sessions are finished, expired sections
+// closed and expired auto-advancing questions skipped.
+func loadPracticeSession(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string, includeStimulus bool) (PracticeSessionResponse, error) {
+	var status string
+	var createdAt time.Time
+	var startedAt time.Time
+	var packageID *string
+	var isTimed bool
+	var timeLimitSeconds int
+	var targetCount int
+	var currentIndex int
+	var correctCount int
+	var orderRaw []byte
+	var snapshotRaw []byte
+	var currentQuestionStartedAt time.Time
+	var questionTimingsRaw []byte
+	var stimuliRaw []byte
+	var shuffleSeed *int64
+	var mode string
+	var source string
+	var navigation string
+	var markedRaw []byte
+	var sectionsRaw []byte
+	var sectionStartedAt *time.Time
+	var timingRaw []byte
+
+	err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings, stimuli_snapshot, shuffle_seed, mode, source, navigation, marked_question_ids,
+			sections, section_started_at, timing
+		from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
+		Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw, &stimuliRaw, &shuffleSeed, &mode, &source, &navigation, &markedRaw,
+			&sectionsRaw, &sectionStartedAt, &timingRaw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PracticeSessionResponse{}, errPracticeSessionNotFound
+	}
+	if err != nil {
+		return PracticeSessionResponse{}, err
+	}
+
+	now := time.Now().UTC()
+
+	var order []string
+	_ = json.Unmarshal(orderRaw, &order)
+
+	questionTimings := map[string]int{}
+	if len(questionTimingsRaw) > 0 {
+		_ = json.Unmarshal(questionTimingsRaw, &questionTimings)
+	}
+	if questionTimings == nil {
+		questionTimings = map[string]int{}
+	}
+
+	timingSnap := timing.DecodeSnapshot(timingRaw)
+	if isTimed && status == string(PracticeSessionActive) {
+		if sessionTimeUp(timingSnap, startedAt, timeLimitSeconds, now) {
+			// Force-finish and persist the partial time for the current question.
+			if currentIndex >= 0 && currentIndex < len(order) {
+				qid := order[currentIndex]
+				spent := int(now.Sub(currentQuestionStartedAt).Seconds())
+				if spent < 0 {
+					spent = 0
+				}
+				questionTimings[qid] = questionTimings[qid] + spent
+			}
+			finishExpiredPracticeSession(ctx, pool, userID, sessionID, navigation, questionTimings)
+			status = string(PracticeSessionFinished)
+			if navigation == practiceNavigationFree {
+				_ = pool.QueryRow(ctx, `select correct_count from practice_sessions where id=$1`, sessionID).Scan(&correctCount)
+			}
+		}
+	}
+
+	// A blueprint section that ran out of time hands over to the next one.
+	boundaries := decodeSessionSections(sectionsRaw)
+	reload := func() {
+		_ = pool.QueryRow(ctx, `select status, current_index, current_question_started_at, section_started_at, question_timings, correct_count from practice_sessions where id=$1`, sessionID).
+			Scan(&status, &currentIndex, &currentQuestionStartedAt, &sectionStartedAt, &questionTimingsRaw, &correctCount)
+		_ = json.Unmarshal(questionTimingsRaw, &questionTimings)
+	}
+	if isTimed && status == string(PracticeSessionActive) && boundaries != nil {
+		if _, closed := closeExpiredSection(ctx, pool, userID, sessionID, navigation, boundaries, order, currentIndex, sectionStartedAt, questionTimingsRaw, currentQuestionStartedAt, timingSnap.GraceSeconds, now); closed {
+			reload()
+		}
+	}
+	// Questions whose hard limit ran out are skipped unanswered.
+	if isTimed && status == string(PracticeSessionActive) && navigation == practiceNavigationLinear && timingSnap.HasLimits() {
+		if _, _, moved := advanceExpiredQuestions(ctx, pool, userID, sessionID, timingSnap, boundaries, order, targetCount, currentIndex, currentQuestionStartedAt, questionTimingsRaw, now); moved {
+			reload()
+		}
+	}
+
+	var sectionRemaining *int
+	var timeRemaining *int
+	var questionRemaining *int
+	var graceSeconds *int
+	if isTimed && status == string(PracticeSessionActive) {
+		if boundaries != nil {
+			sectionRemaining = sectionTimeRemaining(boundaries, currentIndex, sectionStartedAt, now)
+		}
+		timeRemaining = sessionTimeRemaining(startedAt, timeLimitSeconds, now)
+		if navigation == practiceNavigationLinear {
+			questionRemaining = timingSnap.QuestionRemaining(currentIndex, currentQuestionStartedAt, now)
+		}
+		graceSeconds = &timingSnap.GraceSeconds
+	}
+
+	var navItems []PracticeNavigationItem
+	if navigation == practiceNavigationFree {
+		navItems, err = practiceNavigationItems(ctx, pool, sessionID, order, markedRaw)
+		if err != nil {
+			return PracticeSessionResponse{}, err
+		}
+	}
+
+	var snapshot []practiceQuestionSnapshot
+	_ = json.Unmarshal(snapshotRaw, &snapshot)
+
+	var stimuli []PracticeStimulus
+	_ = json.Unmarshal(stimuliRaw, &stimuli)
+
+	var question *PracticeQuestion
+	var stimulus *PracticeStimulus
+	if status == string(PracticeSessionActive) {
+		question = loadSnapshotQuestion(snapshot, currentIndex, shuffleSeed)
+		stimulus = stimulusForIndex(snapshot, stimuli, currentIndex, includeStimulus)
+	}
+
+	var timeLimitPtr *int
+	var currentQuestionStartedAtPtr *string
+	if isTimed {
+		copy := timeLimitSeconds
+		timeLimitPtr = &copy
+	}
+	if !currentQuestionStartedAt.IsZero() {
+		v := currentQuestionStartedAt.UTC().Format(time.RFC3339)
+		currentQuestionStartedAtPtr = &v
+	}
+
+	return PracticeSessionResponse{
+		SessionID:    sessionID,
+		Status:       PracticeSessionStatus(status),
+		CreatedAt:    createdAt.UTC().Format(time.RFC3339),
+		StartedAt:    startedAt.UTC().Format(time.RFC3339),
+		ExamPackageID:    packageID,
+		IsTimed:      isTimed,
+		Adaptive:     mode == practiceModeAdaptive,
+		ReviewDue:    mode == practiceModeReview,
+		Source:       source,
+		FreeNavigation: navigation == practiceNavigationFree,
+		TimeLimitSeconds: timeLimitPtr,
+		CurrentQuestionStartedAt: currentQuestionStartedAtPtr,
+		QuestionTimingsSeconds: questionTimings,
+		TargetCount:  targetCount,
+		CurrentIndex: currentIndex,
+		Total:        targetCount,
+		CorrectCount: correctCount,
+		Question:     question,
+		Stimulus:     stimulus,
+		Items:        navItems,
+		Sections:     boundaries,
+		SectionTimeRemainingSeconds: sectionRemaining,
+		TimeRemainingSeconds: timeRemaining,
+		QuestionTimeRemainingSeconds: questionRemaining,
+		GraceSeconds: graceSeconds,
+	}, nil
 }
 
 func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 	registerPracticeTemplateRoutes(r, pool)
+	registerMasteryRoutes(r, pool)
+	registerReviewDeckRoutes(r, pool)
+	registerBookmarkRoutes(r, pool)
+	registerPracticeNavigationRoutes(r, pool)
+	registerPracticeSyncRoutes(r, pool)
+	registerPracticeHintRoutes(r, pool)
 	r.GET("/practice-sessions", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
 		userID, ok := auth.GetUserID(c)
 		if !ok {
@@ -214,7 +808,7 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		now := time.Now().UTC()
 
 		args := []any{userID}
-		query := `select id, status, created_at, last_activity_at, package_id, is_timed, time_limit_seconds, started_at, target_count, correct_count
+		query := `select id, status, created_at, last_activity_at, package_id, is_timed, time_limit_seconds, started_at, target_count, correct_count, navigation, timing
 			from practice_sessions where user_id=$1`
 		if status != "" {
 			query += " and status=$2"
@@ -245,7 +839,9 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var startedAt time.Time
 			var targetCount int
 			var correctCount int
-			if err := rows.Scan(&id, &st, &createdAt, &lastActivityAt, &packageID, &isTimed, &timeLimitSeconds, &startedAt, &targetCount, &correctCount); err != nil {
+			var navigation string
+			var timingRaw []byte
+			if err := rows.Scan(&id, &st, &createdAt, &lastActivityAt, &packageID, &isTimed, &timeLimitSeconds, &startedAt, &targetCount, &correctCount, &navigation, &timingRaw); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list sessions"})
 				return
 			}
@@ -261,20 +857,24 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				copy := timeLimitSeconds
 				timeLimitPtr = &copy
 				if st == string(PracticeSessionActive) {
-					elapsedSeconds := int(now.Sub(startedAt).Seconds())
-					if elapsedSeconds >= timeLimitSeconds {
+					if sessionTimeUp(timing.DecodeSnapshot(timingRaw), startedAt, timeLimitSeconds, now) {
 						// Force-finish Ironman when time is up so catalog/history doesn't show stale "in progress".
 						_, _ = pool.Exec(ctx, `update practice_sessions set status=$1, last_activity_at=now() where id=$2 and user_id=$3 and status=$4`,
 							string(PracticeSessionFinished), id, userID, string(PracticeSessionActive))
 						st = string(PracticeSessionFinished)
-						elapsedSeconds = timeLimitSeconds
+						if navigation == practiceNavigationFree {
+							// Grade the saved answers so the listed score is final.
+							if _, err := grading.Finalize(ctx, pool, userID, id); err == nil {
+								_ = pool.QueryRow(ctx, `select correct_count from practice_sessions where id=$1`, id).Scan(&correctCount)
+								if targetCount > 0 {
+									accuracy = float64(correctCount) / float64(targetCount)
+								}
+							}
+						} else {
+							recordPracticeFinished(ctx, pool, userID, id)
+						}
 					}
-					remaining := timeLimitSeconds - elapsedSeconds
-					if remaining < 0 {
-						remaining = 0
-					}
-					copyRemaining := remaining
-					timeRemainingPtr = &copyRemaining
+					timeRemainingPtr = sessionTimeRemaining(startedAt, timeLimitSeconds, now)
 				}
 			}
 
@@ -317,6 +917,29 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
 			return
 		}
+		if req.Adaptive && req.ReviewDue {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "adaptive and reviewDue cannot be combined"})
+			return
+		}
+		if req.Adaptive && req.FreeNavigation {
+			// Adaptive sessions choose the next question from the previous answer.
+			c.JSON(http.StatusBadRequest, gin.H{"message": "adaptive sessions cannot use free navigation"})
+			return
+		}
+		source := strings.TrimSpace(req.Source)
+		if source == "" {
+			source = practiceSourceAll
+		}
+		switch source {
+		case practiceSourceAll, practiceSourceBookmarked, practiceSourceIncorrect, practiceSourceUnseen:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "source must be all, bookmarked, incorrect or unseen"})
+			return
+		}
+		if source != practiceSourceAll && req.ReviewDue {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "source cannot be combined with reviewDue"})
+			return
+		}
 
 		count := req.Count
 		if count <= 0 {
@@ -331,6 +954,8 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		var templateID *string
 		var templateTopicID *string
 		var templateDifficultyID *string
+		var templateSectionsRaw []byte
+		var templateTimingRaw []byte
 
 		// Resolve exam package selection.
 		var packageID string
@@ -353,12 +978,14 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					t.is_published,
 					t.is_timed,
 					t.target_count,
+					t.sections,
+					t.timing,
 					e.tier_id,
 					e.user_id is not null
 				from practice_templates t
 				left join user_exam_package_enrollments e on e.exam_package_id=t.exam_package_id and e.user_id=$2
 				where t.id=$1`, tid, userID).
-				Scan(&packageID, &templateTopicID, &templateDifficultyID, &isPublished, &isTimed, &targetCount, &enrollmentTierID, &enrolled); err != nil {
+				Scan(&packageID, &templateTopicID, &templateDifficultyID, &isPublished, &isTimed, &targetCount, &templateSectionsRaw, &templateTimingRaw, &enrollmentTierID, &enrolled); err != nil {
 				c.JSON(http.StatusNotFound, gin.H{"message": "template not found"})
 				return
 			}
@@ -425,119 +1052,293 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 		}
 
-		// Select published questions from the DB-backed question bank for this exam package.
-		args := []any{string(QuestionPublished), packageID}
-			query := `
-			select q.id, q.prompt, q.explanation_text, cc.choice_id
-			from question_bank_questions q
-			join question_banks p on p.id=q.package_id
-			join exam_package_question_bank_packages m on m.question_bank_package_id=p.id
-			join question_bank_correct_choice cc on cc.question_id=q.id
-			where q.status=$1 and p.is_hidden=false and m.exam_package_id=$2`
-		if templateTopicID != nil && strings.TrimSpace(*templateTopicID) != "" {
-			args = append(args, strings.TrimSpace(*templateTopicID))
-			query += " and q.topic_id=$" + strconv.Itoa(len(args))
+		filter := practiceFilter{PackageID: packageID, TopicID: templateTopicID, DifficultyID: templateDifficultyID, Source: source, UserID: userID}
+		if templateID == nil {
+			filter.TopicID = nilIfEmptyPtr(req.TopicID)
+			filter.DifficultyID = nilIfEmptyPtr(req.DifficultyID)
+		} else if nilIfEmptyPtr(req.TopicID) != nil || nilIfEmptyPtr(req.DifficultyID) != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "topicId and difficultyId cannot be combined with a template"})
+			return
 		}
-		if templateDifficultyID != nil && strings.TrimSpace(*templateDifficultyID) != "" {
-			args = append(args, strings.TrimSpace(*templateDifficultyID))
-			query += " and q.difficulty_id=$" + strconv.Itoa(len(args))
+
+		// Blueprint templates fill every section quota; their size is fixed.
+		sections := decodeTemplateSections(templateSectionsRaw)
+		if sections != nil {
+			if req.Adaptive || req.ReviewDue {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "blueprint templates cannot be adaptive or review sessions"})
+				return
+			}
+			count = blueprint.Total(sections)
 		}
-		args = append(args, count)
-		query += " order by random() limit $" + strconv.Itoa(len(args))
 
-		rows, err := pool.Query(ctx, query,
-			args...)
+		// Enforce the policy of the tier this session snapshots.
+		tierPolicy, err := loadTierPolicy(ctx, pool, &tierID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load tier policy"})
 			return
 		}
-		defer rows.Close()
+		if req.Timed && !tierPolicy.AllowsTimedPractice() {
+			c.JSON(http.StatusForbidden, gin.H{"message": "timed practice is not available on your tier"})
+			return
+		}
+		if maxQuestions := tierPolicy.MaxQuestionsPerSession; maxQuestions != nil && count > *maxQuestions {
+			// An explicit count and a blueprint are refused; template and
+			// default sizes are capped.
+			if sections != nil {
+				c.JSON(http.StatusForbidden, gin.H{"message": "this blueprint has " + strconv.Itoa(count) + " questions; your tier allows at most " + strconv.Itoa(*maxQuestions) + " per session"})
+				return
+			}
+			if templateID == nil && req.Count > *maxQuestions {
+				c.JSON(http.StatusForbidden, gin.H{"message": "your tier allows at most " + strconv.Itoa(*maxQuestions) + " questions per session"})
+				return
+			}
+			count = *maxQuestions
+		}
 
-		type picked struct {
-			ID        string
-			Prompt    string
-			Explain   string
-			CorrectID string
+		// The snapshot keeps the localized text, so a session stays in the
+		// language it was started in even if preferences or translations change.
+		locales := requestLocales(ctx, pool, c, userID)
+		var sessionLocale *string
+		if len(locales) > 0 {
+			sessionLocale = &locales[0]
 		}
-		pickedQs := make([]picked, 0, count)
-		for rows.Next() {
-			var p picked
-			if err := rows.Scan(&p.ID, &p.Prompt, &p.Explain, &p.CorrectID); err != nil {
+
+		mode := practiceModeStandard
+		var pickedQs []practiceCandidate
+		var boundaries []blueprint.Boundary
+		if sections != nil {
+			picked, counts, shortages, err := selectBlueprintQuestions(ctx, pool, filter, sections)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
 				return
 			}
-			pickedQs = append(pickedQs, p)
+			if len(shortages) > 0 {
+				c.JSON(http.StatusConflict, gin.H{"message": "not enough questions to fill the blueprint", "shortages": shortages})
+				return
+			}
+			pickedQs = picked
+			boundaries = blueprint.Boundaries(sections, counts)
+			count = len(pickedQs)
+		} else if req.Adaptive {
+			// Adaptive sessions start with one unit and grow by one unit per answer.
+			mode = practiceModeAdaptive
+			unit, err := selectAdaptiveUnit(ctx, pool, filter, []string{}, count)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+				return
+			}
+			pickedQs = unit
+		} else if req.ReviewDue {
+			mode = practiceModeReview
+			args := []any{}
+			query := `with eligible as (` + eligiblePracticeSQL(&args, filter)
+			args = append(args, userID, count)
+			query += `)
+				select e.id, e.prompt, e.explanation_text, e.choice_id, e.stimulus_id, e.shuffle_choices, e.source_locale
+				from eligible e join review_deck_items d on d.question_id=e.id and d.user_id=$` + strconv.Itoa(len(args)-1) + `
+				where d.due_at <= now()
+				order by d.due_at, e.id
+				limit $` + strconv.Itoa(len(args))
+			rows, err := pool.Query(ctx, query, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+				return
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var p practiceCandidate
+				if err := rows.Scan(&p.ID, &p.Prompt, &p.Explain, &p.CorrectID, &p.StimulusID, &p.Shuffle, &p.Locale); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+					return
+				}
+				pickedQs = append(pickedQs, p)
+			}
+			if len(pickedQs) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "no review items are due for this package"})
+				return
+			}
+			count = len(pickedQs)
+		} else {
+			// Select published questions from the DB-backed question bank for this exam package.
+			// Questions sharing a stimulus form one unit: units are shuffled, questions
+			// within a unit keep their authored order.
+			args := []any{}
+			query := `
+				with eligible as (` + eligiblePracticeSQL(&args, filter)
+			// Every unit holds at least one question, so count units always suffice.
+			args = append(args, count)
+			query += `),
+				units as (
+				select coalesce(stimulus_id, id) as unit_id, random() as r
+				from eligible group by coalesce(stimulus_id, id)
+				order by r limit $` + strconv.Itoa(len(args)) + `)
+				select e.id, e.prompt, e.explanation_text, e.choice_id, e.stimulus_id, e.shuffle_choices, e.source_locale
+				from eligible e join units u on u.unit_id=coalesce(e.stimulus_id, e.id)
+				order by u.r, e.stimulus_order, e.id`
+
+			rows, err := pool.Query(ctx, query,
+				args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+				return
+			}
+			defer rows.Close()
+
+			candidates := make([]practiceCandidate, 0, count)
+			units := [][]int{}
+			lastUnit := ""
+			for rows.Next() {
+				var p practiceCandidate
+				if err := rows.Scan(&p.ID, &p.Prompt, &p.Explain, &p.CorrectID, &p.StimulusID, &p.Shuffle, &p.Locale); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+					return
+				}
+				unit := p.ID
+				if p.StimulusID != nil {
+					unit = *p.StimulusID
+				}
+				if unit != lastUnit || len(units) == 0 {
+					units = append(units, []int{})
+					lastUnit = unit
+				}
+				units[len(units)-1] = append(units[len(units)-1], len(candidates))
+				candidates = append(candidates, p)
+			}
+			if count > len(candidates) {
+				count = len(candidates)
+			}
+			for _, i := range packQuestionUnits(units, count) {
+				pickedQs = append(pickedQs, candidates[i])
+			}
+			count = len(pickedQs)
+		}
+		if len(pickedQs) == 0 && source != practiceSourceAll {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "no questions match the selected source"})
+			return
 		}
 		if len(pickedQs) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "no published questions available for this package"})
 			return
 		}
-		if count > len(pickedQs) {
-			count = len(pickedQs)
-		}
 
-		// Load choices for all selected questions.
-		qIDs := make([]string, 0, len(pickedQs))
-		for _, q := range pickedQs {
-			qIDs = append(qIDs, q.ID)
+		snapshot, err := buildPracticeSnapshots(ctx, pool, pickedQs, locales)
+		if errors.Is(err, errInsufficientChoices) {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "question has insufficient choices"})
+			return
 		}
-		choicesRows, err := pool.Query(ctx, `
-			select question_id, id, text
-			from question_bank_choices
-			where question_id = any($1)
-			order by question_id asc, order_index asc`, qIDs)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
 			return
 		}
-		defer choicesRows.Close()
+		order := make([]string, 0, len(snapshot))
+		for _, q := range snapshot {
+			order = append(order, q.ID)
+		}
 
-		choicesByQ := map[string][]PracticeQuestionChoice{}
-		for choicesRows.Next() {
-			var qid, cid, text string
-			if err := choicesRows.Scan(&qid, &cid, &text); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
+		stimuli, err := loadPracticeStimuli(ctx, pool, snapshot)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load stimuli"})
+			return
+		}
+
+		orderJSON, _ := json.Marshal(order)
+		snapshotJSON, _ := json.Marshal(snapshot)
+		stimuliJSON, _ := json.Marshal(stimuli)
+
+		navigation := practiceNavigationLinear
+		var navItems []PracticeNavigationItem
+		if req.FreeNavigation {
+			navigation = practiceNavigationFree
+			for i, qid := range order {
+				navItems = append(navItems, PracticeNavigationItem{Index: i, QuestionID: qid})
+			}
+		}
+
+		// Timed sessions snapshot their pacing so later template edits don't
+		// move the deadlines of sessions in progress.
+		var timingSnap timing.Snapshot
+		var timingJSON []byte
+		limit := 0
+		if req.Timed {
+			timingSnap, limit, boundaries, err = resolveSessionTiming(ctx, pool, timing.Decode(templateTimingRaw), sections, boundaries, order, count)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to resolve timing"})
 				return
 			}
-			choicesByQ[qid] = append(choicesByQ[qid], PracticeQuestionChoice{ID: cid, Text: text})
+			// Auto-advance needs the next question already drawn and in sequence.
+			if timingSnap.HasLimits() && (navigation == practiceNavigationFree || mode == practiceModeAdaptive) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "auto-advancing questions cannot be combined with freeNavigation or adaptive sessions"})
+				return
+			}
+			timingJSON, _ = json.Marshal(timingSnap)
+		}
+
+		var sectionsJSON []byte
+		if boundaries != nil {
+			sectionsJSON, _ = json.Marshal(boundaries)
 		}
 
-		snapshot := make([]practiceQuestionSnapshot, 0, count)
-		order := make([]string, 0, count)
-		for i := 0; i < count; i++ {
-			q := pickedQs[i]
-			chs := choicesByQ[q.ID]
-			if len(chs) < 2 {
-				c.JSON(http.StatusInternalServerError, gin.H{"message": "question has insufficient choices"})
+		// The tier's hint penalty is fixed for the life of the session.
+		hintPenalty := hints.DefaultPenalty
+		if tierPolicy.HintPenalty != nil {
+			hintPenalty = *tierPolicy.HintPenalty
+		}
+
+		// The quota is counted under the lock that the insert commits with, so
+		// parallel starts cannot both take the last session of the week.
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create session"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		if tierPolicy.MaxPracticeSessionsPerWeek != nil {
+			weekStart := policy.WeekStart(time.Now())
+			used := 0
+			err := lockSessionQuota(ctx, tx, userID, packageID)
+			if err == nil {
+				used, _, err = weeklySessionCounts(ctx, tx, userID, packageID, weekStart)
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to check session quota"})
+				return
+			}
+			if used >= *tierPolicy.MaxPracticeSessionsPerWeek {
+				quotaExceeded(c, "weekly practice session limit reached", weekStart)
 				return
 			}
-			snapshot = append(snapshot, practiceQuestionSnapshot{
-				ID:              q.ID,
-				Prompt:          q.Prompt,
-				Choices:         chs,
-				CorrectChoiceID: q.CorrectID,
-				Explanation:     q.Explain,
-			})
-			order = append(order, q.ID)
 		}
-		orderJSON, _ := json.Marshal(order)
-		snapshotJSON, _ := json.Marshal(snapshot)
 
 		sessionID := util.NewID("ps")
-		_, err = pool.Exec(ctx, `insert into practice_sessions (id, user_id, package_id, tier_id, template_id, is_timed, target_count, current_index, correct_count, status, question_order, questions_snapshot)
-			values ($1,$2,$3,$4,$5,$6,$7,0,0,$8,$9,$10)` ,
-			sessionID, userID, packageID, tierID, templateID, req.Timed, count, string(PracticeSessionActive), orderJSON, snapshotJSON)
+		shuffleSeed := shuffle.NewSeed()
+		_, err = tx.Exec(ctx, `insert into practice_sessions (id, user_id, package_id, tier_id, template_id, is_timed, target_count, current_index, correct_count, status, question_order, questions_snapshot, stimuli_snapshot, shuffle_seed, locale, mode, source, topic_id, difficulty_id, navigation, sections, section_started_at, timing, hint_penalty)
+			values ($1,$2,$3,$4,$5,$6,$7,0,0,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,case when $19::json is null then null else now() end,$20,$21)` ,
+			sessionID, userID, packageID, tierID, templateID, req.Timed, count, string(PracticeSessionActive), orderJSON, snapshotJSON, stimuliJSON, shuffleSeed, sessionLocale, mode,
+			source, filter.TopicID, filter.DifficultyID, navigation, sectionsJSON, timingJSON, hintPenalty)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create session"})
 			return
 		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create session"})
+			return
+		}
 
 		now := time.Now().UTC()
 		var timeLimitSeconds *int
 		var currentQuestionStartedAt *string
 		questionTimings := map[string]int{}
+		var sectionRemaining *int
+		var timeRemaining *int
+		var questionRemaining *int
+		var graceSeconds *int
 		if req.Timed {
-			limit := count * ironmanSecondsPerQuestion
+			if boundaries != nil {
+				sectionRemaining = boundaries[0].TimeLimitSeconds
+			}
+			timeRemaining = sessionTimeRemaining(now, limit, now)
+			questionRemaining = timingSnap.QuestionRemaining(0, now, now)
+			graceSeconds = &timingSnap.GraceSeconds
 			_, _ = pool.Exec(ctx, `update practice_sessions set started_at=now(), time_limit_seconds=$1, current_question_started_at=now(), question_timings='{}'::jsonb where id=$2 and user_id=$3`,
 				limit, sessionID, userID)
 			timeLimitSeconds = &limit
@@ -556,6 +1357,10 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			StartedAt:    now.Format(time.RFC3339),
 			ExamPackageID:    pkgPtr,
 			IsTimed:      req.Timed,
+			Adaptive:     mode == practiceModeAdaptive,
+			ReviewDue:    mode == practiceModeReview,
+			Source:       source,
+			FreeNavigation: req.FreeNavigation,
 			TimeLimitSeconds: timeLimitSeconds,
 			CurrentQuestionStartedAt: currentQuestionStartedAt,
 			QuestionTimingsSeconds: questionTimings,
@@ -563,7 +1368,14 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			CurrentIndex: 0,
 			Total:        count,
 			CorrectCount: 0,
-			Question:     loadSnapshotQuestion(snapshot, 0),
+			Question:     loadSnapshotQuestion(snapshot, 0, &shuffleSeed),
+			Stimulus:     stimulusForIndex(snapshot, stimuli, 0, true),
+			Items:        navItems,
+			Sections:     boundaries,
+			SectionTimeRemainingSeconds: sectionRemaining,
+			TimeRemainingSeconds: timeRemaining,
+			QuestionTimeRemainingSeconds: questionRemaining,
+			GraceSeconds: graceSeconds,
 		})
 	})
 
@@ -574,98 +1386,16 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			return
 		}
 
-		sessionID := c.Param("sessionId")
-		ctx := context.Background()
-
-		var status string
-		var createdAt time.Time
-		var startedAt time.Time
-		var packageID *string
-		var isTimed bool
-		var timeLimitSeconds int
-		var targetCount int
-		var currentIndex int
-		var correctCount int
-		var orderRaw []byte
-		var snapshotRaw []byte
-		var currentQuestionStartedAt time.Time
-		var questionTimingsRaw []byte
-
-		err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings
-			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
-			Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw)
-		if err != nil {
+		resp, err := loadPracticeSession(context.Background(), pool, userID, c.Param("sessionId"), parseBoolQuery(c, "includeStimulus"))
+		if errors.Is(err, errPracticeSessionNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
 			return
 		}
-
-		now := time.Now().UTC()
-
-		var order []string
-		_ = json.Unmarshal(orderRaw, &order)
-
-		questionTimings := map[string]int{}
-		if len(questionTimingsRaw) > 0 {
-			_ = json.Unmarshal(questionTimingsRaw, &questionTimings)
-		}
-		if questionTimings == nil {
-			questionTimings = map[string]int{}
-		}
-
-		if isTimed && status == string(PracticeSessionActive) {
-			elapsedSeconds := int(now.Sub(startedAt).Seconds())
-			if elapsedSeconds >= timeLimitSeconds {
-				// Force-finish and persist the partial time for the current question.
-				if currentIndex >= 0 && currentIndex < len(order) {
-					qid := order[currentIndex]
-					spent := int(now.Sub(currentQuestionStartedAt).Seconds())
-					if spent < 0 {
-						spent = 0
-					}
-					questionTimings[qid] = questionTimings[qid] + spent
-				}
-				questionTimingsJSON, _ := json.Marshal(questionTimings)
-				_, _ = pool.Exec(ctx, `update practice_sessions set status=$1, question_timings=$2, last_activity_at=now() where id=$3 and user_id=$4`,
-					string(PracticeSessionFinished), questionTimingsJSON, sessionID, userID)
-				status = string(PracticeSessionFinished)
-			}
-		}
-
-		var snapshot []practiceQuestionSnapshot
-		_ = json.Unmarshal(snapshotRaw, &snapshot)
-
-		var question *PracticeQuestion
-		if status == string(PracticeSessionActive) {
-			question = loadSnapshotQuestion(snapshot, currentIndex)
-		}
-
-		var timeLimitPtr *int
-		var currentQuestionStartedAtPtr *string
-		if isTimed {
-			copy := timeLimitSeconds
-			timeLimitPtr = &copy
-		}
-		if !currentQuestionStartedAt.IsZero() {
-			v := currentQuestionStartedAt.UTC().Format(time.RFC3339)
-			currentQuestionStartedAtPtr = &v
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load session"})
+			return
 		}
-
-		c.JSON(http.StatusOK, PracticeSessionResponse{
-			SessionID:    sessionID,
-			Status:       PracticeSessionStatus(status),
-			CreatedAt:    createdAt.UTC().Format(time.RFC3339),
-			StartedAt:    startedAt.UTC().Format(time.RFC3339),
-			ExamPackageID:    packageID,
-			IsTimed:      isTimed,
-			TimeLimitSeconds: timeLimitPtr,
-			CurrentQuestionStartedAt: currentQuestionStartedAtPtr,
-			QuestionTimingsSeconds: questionTimings,
-			TargetCount:  targetCount,
-			CurrentIndex: currentIndex,
-			Total:        targetCount,
-			CorrectCount: correctCount,
-			Question:     question,
-		})
+		c.JSON(http.StatusOK, resp)
 	})
 
 	r.POST("/practice-sessions/:sessionId/pause", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
@@ -690,10 +1420,12 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		var orderRaw []byte
 		var currentQuestionStartedAt time.Time
 		var questionTimingsRaw []byte
+		var mode string
+		var source string
 
-		err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, current_question_started_at, question_timings
+		err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, current_question_started_at, question_timings, mode, source
 			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
-			Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &currentQuestionStartedAt, &questionTimingsRaw)
+			Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &currentQuestionStartedAt, &questionTimingsRaw, &mode, &source)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
 			return
@@ -763,6 +1495,9 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			StartedAt:    startedAt.UTC().Format(time.RFC3339),
 			ExamPackageID:    packageID,
 			IsTimed:      isTimed,
+			Adaptive:     mode == practiceModeAdaptive,
+			ReviewDue:    mode == practiceModeReview,
+			Source:       source,
 			TargetCount:  targetCount,
 			CurrentIndex: currentIndex,
 			Total:        targetCount,
@@ -796,10 +1531,14 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		var snapshotRaw []byte
 		var currentQuestionStartedAt time.Time
 		var questionTimingsRaw []byte
+		var stimuliRaw []byte
+		var shuffleSeed *int64
+		var mode string
+		var source string
 
-		err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings
+		err := pool.QueryRow(ctx, `select status, created_at, started_at, package_id, is_timed, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings, stimuli_snapshot, shuffle_seed, mode, source
 			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
-			Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw)
+			Scan(&status, &createdAt, &startedAt, &packageID, &isTimed, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw, &stimuliRaw, &shuffleSeed, &mode, &source)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
 			return
@@ -827,7 +1566,9 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 		var snapshot []practiceQuestionSnapshot
 		_ = json.Unmarshal(snapshotRaw, &snapshot)
-		question := loadSnapshotQuestion(snapshot, currentIndex)
+		question := loadSnapshotQuestion(snapshot, currentIndex, shuffleSeed)
+		var stimuli []PracticeStimulus
+		_ = json.Unmarshal(stimuliRaw, &stimuli)
 
 		now := time.Now().UTC()
 		v := now.Format(time.RFC3339)
@@ -838,11 +1579,15 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			StartedAt:    startedAt.UTC().Format(time.RFC3339),
 			ExamPackageID:    packageID,
 			IsTimed:      isTimed,
+			Adaptive:     mode == practiceModeAdaptive,
+			ReviewDue:    mode == practiceModeReview,
+			Source:       source,
 			TargetCount:  targetCount,
 			CurrentIndex: currentIndex,
 			Total:        targetCount,
 			CorrectCount: correctCount,
 			Question:     question,
+			Stimulus:     stimulusForIndex(snapshot, stimuli, currentIndex, true),
 			CurrentQuestionStartedAt: &v,
 		})
 	})
@@ -860,6 +1605,10 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
 			return
 		}
+		idempotencyKey, ok := answerIdempotencyKey(c)
+		if !ok {
+			return
+		}
 
 		ctx := context.Background()
 		var status string
@@ -873,14 +1622,31 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		var snapshotRaw []byte
 		var currentQuestionStartedAt time.Time
 		var questionTimingsRaw []byte
-
-		err := pool.QueryRow(ctx, `select status, is_timed, started_at, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings
+		var mode string
+		var packageID *string
+		var source string
+		var topicID, difficultyID *string
+		var sessionLocale *string
+		var sessionTierID *string
+		var navigation string
+		var sectionsRaw []byte
+		var sectionStartedAt *time.Time
+		var timingRaw []byte
+
+		err := pool.QueryRow(ctx, `select status, is_timed, started_at, time_limit_seconds, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings,
+				mode, package_id, source, topic_id, difficulty_id, locale, tier_id::text, navigation, sections, section_started_at, timing
 			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
-			Scan(&status, &isTimed, &startedAt, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw)
+			Scan(&status, &isTimed, &startedAt, &timeLimitSeconds, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &currentQuestionStartedAt, &questionTimingsRaw,
+				&mode, &packageID, &source, &topicID, &difficultyID, &sessionLocale, &sessionTierID, &navigation, &sectionsRaw, &sectionStartedAt, &timingRaw)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
 			return
 		}
+		// A retried request gets its original response, even once the
+		// session has moved on or finished.
+		if replayAnswer(c, ctx, pool, sessionID, idempotencyKey, req) {
+			return
+		}
 
 		if status != string(PracticeSessionActive) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "session not active"})
@@ -895,9 +1661,9 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		}
 
 		now := time.Now().UTC()
+		timingSnap := timing.DecodeSnapshot(timingRaw)
 		if isTimed {
-			elapsedSeconds := int(now.Sub(startedAt).Seconds())
-			if elapsedSeconds >= timeLimitSeconds {
+			if sessionTimeUp(timingSnap, startedAt, timeLimitSeconds, now) {
 				// Force-finish and persist the partial time for the current question.
 				questionTimings := map[string]int{}
 				if len(questionTimingsRaw) > 0 {
@@ -912,15 +1678,53 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					spent = 0
 				}
 				questionTimings[qid] = questionTimings[qid] + spent
-				questionTimingsJSON, _ := json.Marshal(questionTimings)
-				_, _ = pool.Exec(ctx, `update practice_sessions set status=$1, question_timings=$2, last_activity_at=now() where id=$3 and user_id=$4`,
-					string(PracticeSessionFinished), questionTimingsJSON, sessionID, userID)
+				finishExpiredPracticeSession(ctx, pool, userID, sessionID, navigation, questionTimings)
 				c.JSON(http.StatusBadRequest, gin.H{"message": "time is up"})
 				return
 			}
 		}
 
+		boundaries := decodeSessionSections(sectionsRaw)
+		if isTimed && boundaries != nil {
+			if next, closed := closeExpiredSection(ctx, pool, userID, sessionID, navigation, boundaries, order, currentIndex, sectionStartedAt, questionTimingsRaw, currentQuestionStartedAt, timingSnap.GraceSeconds, now); closed {
+				if next < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "time is up"})
+					return
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"message": "section time is up", "currentIndex": next})
+				return
+			}
+		}
+		if isTimed && navigation == practiceNavigationLinear && timingSnap.HasLimits() {
+			if next, _, moved := advanceExpiredQuestions(ctx, pool, userID, sessionID, timingSnap, boundaries, order, targetCount, currentIndex, currentQuestionStartedAt, questionTimingsRaw, now); moved {
+				if next >= min(targetCount, len(order)) {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "time is up"})
+					return
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"message": "question time is up", "currentIndex": next})
+				return
+			}
+		}
+
+		if navigation == practiceNavigationFree {
+			var snapshot []practiceQuestionSnapshot
+			_ = json.Unmarshal(snapshotRaw, &snapshot)
+			// Timed sections close behind the student.
+			if isTimed && boundaries != nil {
+				if idx := slices.Index(order, req.QuestionID); idx >= 0 && blueprint.SectionAt(boundaries, idx) != blueprint.SectionAt(boundaries, currentIndex) {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "question is not in the current section"})
+					return
+				}
+			}
+			saveDraftAnswer(c, ctx, pool, userID, sessionID, idempotencyKey, snapshot, req)
+			return
+		}
+
 		expectedQuestionID := order[currentIndex]
+		if idx := slices.Index(order, req.QuestionID); idx >= 0 && idx < currentIndex {
+			c.JSON(http.StatusConflict, gin.H{"message": "question was already answered", "currentIndex": currentIndex})
+			return
+		}
 		if req.QuestionID != expectedQuestionID {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "questionId mismatch"})
 			return
@@ -950,13 +1754,66 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			return
 		}
 
+		// Concurrent submits queue on the session row; only the first to lock
+		// it answers the current question, the others find current_index moved.
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update session"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		var lockedStatus string
+		var lockedIndex int
+		var hintsUsedRaw []byte
+		var hintPenalty float64
+		if err := tx.QueryRow(ctx, `select status, current_index, correct_count, hints_used, hint_penalty from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).
+			Scan(&lockedStatus, &lockedIndex, &correctCount, &hintsUsedRaw, &hintPenalty); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update session"})
+			return
+		}
+		if replayAnswer(c, ctx, tx, sessionID, idempotencyKey, req) {
+			return
+		}
+		if lockedStatus != string(PracticeSessionActive) || lockedIndex != currentIndex {
+			c.JSON(http.StatusConflict, gin.H{"message": "question was already answered", "currentIndex": lockedIndex})
+			return
+		}
+
 		isCorrect := req.ChoiceID == q.CorrectChoiceID
 		newCorrect := correctCount
 		if isCorrect {
 			newCorrect++
 		}
+		hintsUsed := decodeHintsUsed(hintsUsedRaw)[q.ID]
+		credit := hints.Credit(isCorrect, hintsUsed, hintPenalty)
+
+		if err := mastery.Record(ctx, tx, userID, q.ID, isCorrect, credit); err != nil {
+			log.Printf("mastery: record answer for %s failed: %v", q.ID, err)
+		}
 
 		newIndex := currentIndex + 1
+
+		// Adaptive sessions grow one unit at a time, chosen with the mastery
+		// estimate that already includes this answer. A session that runs out
+		// of questions ends early.
+		extended := false
+		if mode == practiceModeAdaptive && packageID != nil && newIndex < targetCount && newIndex >= len(order) {
+			filter := practiceFilter{PackageID: *packageID, TopicID: topicID, DifficultyID: difficultyID, Source: source, UserID: userID}
+			nextSnapshot, err := drawAdaptiveUnit(ctx, pool, tx, filter, sessionLocale, order, targetCount-newIndex)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+				return
+			}
+			if len(nextSnapshot) == 0 {
+				targetCount = newIndex
+			}
+			for _, nq := range nextSnapshot {
+				snapshot = append(snapshot, nq)
+				order = append(order, nq.ID)
+				extended = true
+			}
+		}
+
 		newStatus := status
 		if newIndex >= targetCount {
 			newStatus = string(PracticeSessionFinished)
@@ -976,22 +1833,78 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		questionTimings[expectedQuestionID] = questionTimings[expectedQuestionID] + spent
 		questionTimingsJSON, _ := json.Marshal(questionTimings)
 
-		_, err = pool.Exec(ctx, `update practice_sessions set current_index=$1, correct_count=$2, status=$3, current_question_started_at=now(), question_timings=$4, last_activity_at=now() where id=$5 and user_id=$6`,
-			newIndex, newCorrect, newStatus, questionTimingsJSON, sessionID, userID)
+		args := []any{newIndex, newCorrect, newStatus, questionTimingsJSON, sessionID, userID, targetCount}
+		query := `update practice_sessions set current_index=$1, correct_count=$2, status=$3, current_question_started_at=now(), question_timings=$4, last_activity_at=now(), target_count=$7`
+		if boundaries != nil && blueprint.SectionAt(boundaries, newIndex) != blueprint.SectionAt(boundaries, currentIndex) {
+			query += `, section_started_at=now()`
+		}
+		if extended {
+			stimuli, err := loadPracticeStimuli(ctx, pool, snapshot)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load stimuli"})
+				return
+			}
+			orderJSON, _ := json.Marshal(order)
+			snapshotJSON, _ := json.Marshal(snapshot)
+			stimuliJSON, _ := json.Marshal(stimuli)
+			args = append(args, orderJSON, snapshotJSON, stimuliJSON)
+			query += `, question_order=$8, questions_snapshot=$9, stimuli_snapshot=$10`
+		}
+		_, err = tx.Exec(ctx, query+` where id=$5 and user_id=$6`, args...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update session"})
 			return
 		}
 
-		_, _ = pool.Exec(ctx, `insert into practice_answers (session_id, user_id, question_id, choice_id, correct, explanation, ts) values ($1,$2,$3,$4,$5,$6,now())`,
-			sessionID, userID, req.QuestionID, req.ChoiceID, isCorrect, q.Explanation)
+		_, err = tx.Exec(ctx, `insert into practice_answers (session_id, user_id, question_id, choice_id, correct, explanation, hints_used, credit, ts) values ($1,$2,$3,$4,$5,$6,$7,$8,now())`,
+			sessionID, userID, req.QuestionID, req.ChoiceID, isCorrect, q.Explanation, hintsUsed, credit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record answer"})
+			return
+		}
 
-		c.JSON(http.StatusOK, SubmitPracticeAnswerResponse{
+		// Explanations are available after submitting an answer, if the
+		// session's tier includes them.
+		explanation := q.Explanation
+		if tierPolicy, err := loadTierPolicy(ctx, pool, sessionTierID); err != nil || !tierPolicy.AllowsExplanations() {
+			explanation = ""
+		}
+
+		var timeRemaining *int
+		var questionRemaining *int
+		if isTimed && newStatus != string(PracticeSessionFinished) {
+			timeRemaining = sessionTimeRemaining(startedAt, timeLimitSeconds, now)
+			questionRemaining = timingSnap.QuestionRemaining(newIndex, now, now)
+		}
+
+		resp := SubmitPracticeAnswerResponse{
 			Correct:     isCorrect,
-			// Explanations are available after submitting an answer.
-			Explanation: q.Explanation,
+			Explanation: explanation,
 			Done:        newStatus == string(PracticeSessionFinished),
-		})
+			HintsUsed:   hintsUsed,
+			Credit:      credit,
+			TimeRemainingSeconds: timeRemaining,
+			QuestionTimeRemainingSeconds: questionRemaining,
+		}
+		if err := storeAnswerResponse(ctx, tx, sessionID, idempotencyKey, req, resp); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record answer"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record answer"})
+			return
+		}
+
+		// Missed questions join the review deck; review sessions reschedule every answer.
+		quality := srs.Grade(isCorrect, questionTimings[expectedQuestionID], timingSnap.Seconds(currentIndex))
+		if err := srs.RecordAnswer(ctx, pool, userID, q.ID, quality, mode == practiceModeReview); err != nil {
+			log.Printf("srs: record answer for %s failed: %v", q.ID, err)
+		}
+		if resp.Done {
+			recordPracticeFinished(ctx, pool, userID, sessionID)
+		}
+
+		c.JSON(http.StatusOK, resp)
 	})
 
 	r.GET("/practice-sessions/:sessionId/review", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
@@ -1008,8 +1921,11 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		var orderRaw []byte
 		var questionTimingsRaw []byte
 		var snapshotRaw []byte
-		err := pool.QueryRow(ctx, `select status, question_order, questions_snapshot, question_timings from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
-			Scan(&status, &orderRaw, &snapshotRaw, &questionTimingsRaw)
+		var stimuliRaw []byte
+		var shuffleSeed *int64
+		var tierID *string
+		err := pool.QueryRow(ctx, `select status, question_order, questions_snapshot, question_timings, stimuli_snapshot, shuffle_seed, tier_id::text from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
+			Scan(&status, &orderRaw, &snapshotRaw, &questionTimingsRaw, &stimuliRaw, &shuffleSeed, &tierID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
 			return
@@ -1018,6 +1934,15 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "review is only available for finished sessions"})
 			return
 		}
+		tierPolicy, err := loadTierPolicy(ctx, pool, tierID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load tier policy"})
+			return
+		}
+		if !tierPolicy.AllowsReview() {
+			c.JSON(http.StatusForbidden, gin.H{"message": "review is not available on your tier"})
+			return
+		}
 
 		var order []string
 		_ = json.Unmarshal(orderRaw, &order)
@@ -1041,9 +1966,11 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			ChoiceID    string
 			Correct     bool
 			Explanation string
+			HintsUsed   int
+			Credit      *float64
 		}
 		answers := map[string]answerRow{}
-		rows, err := pool.Query(ctx, `select question_id, choice_id, correct, explanation from practice_answers where session_id=$1 and user_id=$2 order by ts asc`, sessionID, userID)
+		rows, err := pool.Query(ctx, `select question_id, choice_id, correct, explanation, hints_used, credit from practice_answers where session_id=$1 and user_id=$2 and is_final order by ts asc`, sessionID, userID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load review answers"})
 			return
@@ -1051,7 +1978,7 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		defer rows.Close()
 		for rows.Next() {
 			var r answerRow
-			if err := rows.Scan(&r.QuestionID, &r.ChoiceID, &r.Correct, &r.Explanation); err != nil {
+			if err := rows.Scan(&r.QuestionID, &r.ChoiceID, &r.Correct, &r.Explanation, &r.HintsUsed, &r.Credit); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load review answers"})
 				return
 			}
@@ -1069,26 +1996,68 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var selectedChoiceID *string
 			var correctPtr *bool
 			var explanationPtr *string
+			var hintsUsed int
+			var credit *float64
 			if ans, ok := answers[qid]; ok {
+				hintsUsed, credit = ans.HintsUsed, ans.Credit
 				selectedChoiceID = &ans.ChoiceID
 				correctCopy := ans.Correct
 				correctPtr = &correctCopy
-				explCopy := ans.Explanation
-				explanationPtr = &explCopy
+				if tierPolicy.AllowsExplanations() {
+					explCopy := ans.Explanation
+					explanationPtr = &explCopy
+				}
 			}
 
+			displayed, choiceOrder, canonicalIDs := s.choiceMapping(shuffleSeed)
+
 			items = append(items, PracticeSessionReviewItem{
 				Index:            i,
-				Question:         PracticeQuestion{ID: s.ID, Prompt: s.Prompt, Choices: s.Choices},
+				Question:         PracticeQuestion{ID: s.ID, Prompt: s.Prompt, Choices: displayed, StimulusID: snapshotStimulusID(s), Locale: s.Locale, HintCount: len(s.Hints)},
+				ChoiceOrder:        choiceOrder,
+				CanonicalChoiceIDs: canonicalIDs,
 				SelectedChoiceID: selectedChoiceID,
 				Correct:          correctPtr,
 				Explanation:      explanationPtr,
 				TimeTakenSeconds: questionTimings[qid],
+				HintsUsed:        hintsUsed,
+				Credit:           credit,
 				CorrectChoiceID:  s.CorrectChoiceID,
 			})
 		}
 
-		c.JSON(http.StatusOK, PracticeSessionReviewResponse{SessionID: sessionID, Items: items})
+		stimuli := []PracticeStimulus{}
+		_ = json.Unmarshal(stimuliRaw, &stimuli)
+		if stimuli == nil {
+			stimuli = []PracticeStimulus{}
+		}
+
+		c.JSON(http.StatusOK, PracticeSessionReviewResponse{SessionID: sessionID, Items: items, Stimuli: stimuli})
+	})
+
+	// Re-fetch a passage mid-group (e.g. after a reload); only stimuli in the session snapshot are visible.
+	r.GET("/practice-sessions/:sessionId/stimuli/:stimulusId", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+
+		var stimuliRaw []byte
+		err := pool.QueryRow(context.Background(), `select stimuli_snapshot from practice_sessions where id=$1 and user_id=$2`, c.Param("sessionId"), userID).Scan(&stimuliRaw)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		var stimuli []PracticeStimulus
+		_ = json.Unmarshal(stimuliRaw, &stimuli)
+		for _, s := range stimuli {
+			if s.ID == c.Param("stimulusId") {
+				c.JSON(http.StatusOK, s)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"message": "stimulus not found"})
 	})
 
 	r.GET("/practice-sessions/:sessionId/summary", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
@@ -1115,11 +2084,23 @@ func RegisterPracticeRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			accuracy = float64(correctCount) / float64(targetCount)
 		}
 
+		// Answers recorded before hints have no credit; they count in full.
+		var score float64
+		var hintsUsed int
+		err = pool.QueryRow(ctx, `select coalesce(sum(coalesce(credit, case when correct then 1 else 0 end)), 0), coalesce(sum(hints_used), 0)
+			from practice_answers where session_id=$1 and user_id=$2 and is_final`, sessionID, userID).Scan(&score, &hintsUsed)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load session summary"})
+			return
+		}
+
 		c.JSON(http.StatusOK, PracticeSessionSummaryResponse{
 			SessionID:    sessionID,
 			Total:        targetCount,
 			CorrectCount: correctCount,
 			Accuracy:     accuracy,
+			Score:        score,
+			HintsUsed:    hintsUsed,
 		})
 	})
 }
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// blueprintCandidate is a question drawn for a blueprint quota.
+type blueprintCandidate struct {
+	practiceCandidate
+	DifficultyRank int
+}
+
+// selectBlueprintQuestions fills every quota of a blueprint from the package's
+// published questions, in section order. A question is drawn at most once.
+// When the pool cannot fill a quota, the shortages are returned instead.
+func selectBlueprintQuestions(ctx context.Context, pool *pgxpool.Pool, base practiceFilter, sections []blueprint.Section) ([]practiceCandidate, []int, []blueprint.Shortage, error) {
+	var picked []practiceCandidate
+	var counts []int
+	shortages := []blueprint.Shortage{}
+	used := []string{}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for _, section := range sections {
+		var units [][]blueprintCandidate
+		var meta []blueprint.Unit
+		for qi, quota := range section.Quotas {
+			f := base
+			f.TopicID = quota.TopicID
+			f.DifficultyID = quota.DifficultyID
+			candidates, err := drawQuotaCandidates(ctx, pool, f, used, quota.Count)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if len(candidates) < quota.Count {
+				shortages = append(shortages, blueprint.Shortage{
+					Section:      section.Name,
+					Quota:        qi,
+					TopicID:      quota.TopicID,
+					DifficultyID: quota.DifficultyID,
+					Requested:    quota.Count,
+					Available:    len(candidates),
+				})
+				continue
+			}
+
+			// Group into units so stimulus groups stay together, then pack.
+			groups := [][]int{}
+			lastUnit := ""
+			for i, p := range candidates {
+				unit := p.ID
+				if p.StimulusID != nil {
+					unit = *p.StimulusID
+				}
+				if unit != lastUnit || len(groups) == 0 {
+					groups = append(groups, []int{})
+					lastUnit = unit
+				}
+				groups[len(groups)-1] = append(groups[len(groups)-1], i)
+			}
+			packed := packQuestionUnits(groups, quota.Count)
+			var current []blueprintCandidate
+			var currentUnit string
+			for _, i := range packed {
+				p := candidates[i]
+				unit := p.ID
+				if p.StimulusID != nil {
+					unit = *p.StimulusID
+				}
+				if current != nil && unit != currentUnit {
+					units = append(units, current)
+					meta = append(meta, blueprint.Unit{Quota: qi, DifficultyRank: current[0].DifficultyRank})
+					current = nil
+				}
+				current = append(current, p)
+				currentUnit = unit
+				used = append(used, p.ID)
+			}
+			if current != nil {
+				units = append(units, current)
+				meta = append(meta, blueprint.Unit{Quota: qi, DifficultyRank: current[0].DifficultyRank})
+			}
+		}
+
+		n := 0
+		for _, i := range blueprint.Order(meta, section.Ordering, rng) {
+			for _, p := range units[i] {
+				picked = append(picked, p.practiceCandidate)
+				n++
+			}
+		}
+		counts = append(counts, n)
+	}
+	if len(shortages) > 0 {
+		return nil, nil, shortages, nil
+	}
+	return picked, counts, nil, nil
+}
+
+// drawQuotaCandidates returns up to count units' worth of eligible questions
+// in random unit order, skipping questions already drawn. Every unit holds at
+// least one question, so fewer than count questions means the pool is short
+// and the result is everything available.
+func drawQuotaCandidates(ctx context.Context, pool *pgxpool.Pool, f practiceFilter, exclude []string, count int) ([]blueprintCandidate, error) {
+	args := []any{}
+	query := `with eligible as (` + eligiblePracticeSQL(&args, f)
+	args = append(args, exclude)
+	query += ` and q.id <> all($` + strconv.Itoa(len(args)) + `::text[])`
+	args = append(args, count)
+	query += `),
+		units as (
+		select coalesce(stimulus_id, id) as unit_id, random() as r
+		from eligible group by coalesce(stimulus_id, id)
+		order by r limit $` + strconv.Itoa(len(args)) + `)
+		select e.id, e.prompt, e.explanation_text, e.choice_id, e.stimulus_id, e.shuffle_choices, e.source_locale, coalesce(d.sort_order, 0)
+		from eligible e
+		join units u on u.unit_id=coalesce(e.stimulus_id, e.id)
+		left join question_bank_difficulties d on d.id=e.difficulty_id
+		order by u.r, e.stimulus_order, e.id`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []blueprintCandidate{}
+	for rows.Next() {
+		var p blueprintCandidate
+		if err := rows.Scan(&p.ID, &p.Prompt, &p.Explain, &p.CorrectID, &p.StimulusID, &p.Shuffle, &p.Locale, &p.DifficultyRank); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func decodeSessionSections(raw []byte) []blueprint.Boundary {
+	if len(raw) == 0 {
+		return nil
+	}
+	var out []blueprint.Boundary
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+// sectionDeadline is when the section holding idx closes in a timed session,
+// or nil when it has no limit of its own.
+func sectionDeadline(boundaries []blueprint.Boundary, idx int, sectionStartedAt *time.Time) *time.Time {
+	si := blueprint.SectionAt(boundaries, idx)
+	if si < 0 || boundaries[si].TimeLimitSeconds == nil || sectionStartedAt == nil {
+		return nil
+	}
+	d := sectionStartedAt.Add(time.Duration(*boundaries[si].TimeLimitSeconds) * time.Second)
+	return &d
+}
+
+// sectionTimeRemaining reports the seconds left in the current section of a
+// timed blueprint session.
+func sectionTimeRemaining(boundaries []blueprint.Boundary, idx int, sectionStartedAt *time.Time, now time.Time) *int {
+	d := sectionDeadline(boundaries, idx, sectionStartedAt)
+	if d == nil {
+		return nil
+	}
+	remaining := timing.Remaining(*d, now)
+	return &remaining
+}
+
+// closeExpiredSection moves a timed blueprint session whose current section
+// ran out of time to the start of the next section, crediting the current
+// question up to the section deadline. Answers get graceSeconds past the
+// deadline before the section closes. It returns the new index, or -1 when
+// the last section closed and the session finished. ok is false when the
+// section has time left.
+func closeExpiredSection(ctx context.Context, pool *pgxpool.Pool, userID, sessionID, navigation string, boundaries []blueprint.Boundary, order []string,
+	currentIndex int, sectionStartedAt *time.Time, timingsRaw []byte, questionStartedAt time.Time, graceSeconds int, now time.Time) (next int, ok bool) {
+	deadline := sectionDeadline(boundaries, currentIndex, sectionStartedAt)
+	if deadline == nil || now.Before(deadline.Add(time.Duration(graceSeconds)*time.Second)) {
+		return currentIndex, false
+	}
+	timings := creditQuestionTime(timingsRaw, order, currentIndex, questionStartedAt, *deadline)
+	b := boundaries[blueprint.SectionAt(boundaries, currentIndex)]
+	next = b.StartIndex + b.Count
+	if next >= len(order) {
+		finishExpiredPracticeSession(ctx, pool, userID, sessionID, navigation, timings)
+		return -1, true
+	}
+	timingsJSON, _ := json.Marshal(timings)
+	_, _ = pool.Exec(ctx, `update practice_sessions set current_index=$1, section_started_at=now(), current_question_started_at=now(), question_timings=$2, last_activity_at=now()
+		where id=$3 and user_id=$4`, next, timingsJSON, sessionID, userID)
+	return next, true
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+)
+
+type RevealPracticeHintRequest struct {
+	QuestionID string `json:"questionId"`
+}
+
+type PracticeHintsResponse struct {
+	QuestionID string `json:"questionId"`
+	// Hints are the hints revealed so far, in order.
+	Hints     []string `json:"hints"`
+	HintsUsed int      `json:"hintsUsed"`
+	HintCount int      `json:"hintCount"`
+	// HintPenalty is the credit a correct answer loses per hint.
+	HintPenalty float64 `json:"hintPenalty"`
+}
+
+// decodeHintsUsed reads a session's revealed hint counts by question id.
+func decodeHintsUsed(raw []byte) map[string]int {
+	used := map[string]int{}
+	_ = json.Unmarshal(raw, &used)
+	if used == nil {
+		used = map[string]int{}
+	}
+	return used
+}
+
+// loadQuestionHints returns the hints of the given questions in reveal order.
+func loadQuestionHints(ctx context.Context, pool *pgxpool.Pool, ids []string) (map[string][]string, error) {
+	rows, err := pool.Query(ctx, `select question_id, text from question_bank_hints where question_id = any($1) order by question_id asc, order_index asc`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string][]string{}
+	for rows.Next() {
+		var qid, text string
+		if err := rows.Scan(&qid, &text); err != nil {
+			return nil, err
+		}
+		out[qid] = append(out[qid], text)
+	}
+	return out, rows.Err()
+}
+
+func hintsResponse(q practiceQuestionSnapshot, used int, penalty float64) PracticeHintsResponse {
+	used = min(used, len(q.Hints))
+	return PracticeHintsResponse{
+		QuestionID:  q.ID,
+		Hints:       append([]string{}, q.Hints[:used]...),
+		HintsUsed:   used,
+		HintCount:   len(q.Hints),
+		HintPenalty: penalty,
+	}
+}
+
+func registerPracticeHintRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	// Reveals the next hint of a question. Every hint revealed before the
+	// answer costs a correct answer part of its credit.
+	r.POST("/practice-sessions/:sessionId/hints", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+		var req RevealPracticeHintRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.QuestionID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "questionId is required"})
+			return
+		}
+
+		ctx := context.Background()
+		// Time rules apply first, so a question whose time ran out gets no hint.
+		if _, err := loadPracticeSession(ctx, pool, userID, sessionID, false); errors.Is(err, errPracticeSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load session"})
+			return
+		}
+
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to reveal hint"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		var status, navigation string
+		var isTimed bool
+		var currentIndex int
+		var hintPenalty float64
+		var orderRaw, snapshotRaw, sectionsRaw, hintsUsedRaw []byte
+		err = tx.QueryRow(ctx, `select status, navigation, is_timed, current_index, question_order, questions_snapshot, sections, hints_used, hint_penalty
+			from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).
+			Scan(&status, &navigation, &isTimed, &currentIndex, &orderRaw, &snapshotRaw, &sectionsRaw, &hintsUsedRaw, &hintPenalty)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to reveal hint"})
+			return
+		}
+		if status != string(PracticeSessionActive) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "session not active"})
+			return
+		}
+
+		var order []string
+		_ = json.Unmarshal(orderRaw, &order)
+		var snapshot []practiceQuestionSnapshot
+		_ = json.Unmarshal(snapshotRaw, &snapshot)
+		idx := slices.Index(order, req.QuestionID)
+		if idx < 0 || idx >= len(snapshot) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "unknown question"})
+			return
+		}
+		if navigation == practiceNavigationFree {
+			if boundaries := decodeSessionSections(sectionsRaw); isTimed && boundaries != nil && blueprint.SectionAt(boundaries, idx) != blueprint.SectionAt(boundaries, currentIndex) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "question is not in the current section"})
+				return
+			}
+		} else if idx < currentIndex {
+			c.JSON(http.StatusConflict, gin.H{"message": "question was already answered", "currentIndex": currentIndex})
+			return
+		} else if idx > currentIndex {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "questionId mismatch"})
+			return
+		}
+
+		q := snapshot[idx]
+		used := decodeHintsUsed(hintsUsedRaw)
+		if used[q.ID] >= len(q.Hints) {
+			c.JSON(http.StatusConflict, gin.H{"message": "no hints left", "hintsUsed": used[q.ID], "hintCount": len(q.Hints)})
+			return
+		}
+		used[q.ID]++
+		usedJSON, _ := json.Marshal(used)
+		if _, err := tx.Exec(ctx, `update practice_sessions set hints_used=$1, last_activity_at=now() where id=$2`, usedJSON, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to reveal hint"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to reveal hint"})
+			return
+		}
+		c.JSON(http.StatusOK, hintsResponse(q, used[q.ID], hintPenalty))
+	})
+
+	// Hints already revealed for a question, e.g. after a reload; reveals nothing new.
+	r.GET("/practice-sessions/:sessionId/hints/:questionId", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		var snapshotRaw, hintsUsedRaw []byte
+		var hintPenalty float64
+		err := pool.QueryRow(context.Background(), `select questions_snapshot, hints_used, hint_penalty from practice_sessions where id=$1 and user_id=$2`, c.Param("sessionId"), userID).
+			Scan(&snapshotRaw, &hintsUsedRaw, &hintPenalty)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		var snapshot []practiceQuestionSnapshot
+		_ = json.Unmarshal(snapshotRaw, &snapshot)
+		for _, q := range snapshot {
+			if q.ID == c.Param("questionId") {
+				c.JSON(http.StatusOK, hintsResponse(q, decodeHintsUsed(hintsUsedRaw)[q.ID], hintPenalty))
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+	})
+}
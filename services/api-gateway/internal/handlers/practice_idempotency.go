@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// rowsQuerier is rowQuerier for multi-row queries.
+type rowsQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// answerIdempotencyKey reads the optional Idempotency-Key header of an answer
+// request. It responds 400 itself to an overlong key.
+func answerIdempotencyKey(c *gin.Context) (string, bool) {
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(key) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Idempotency-Key is too long"})
+		return "", false
+	}
+	return key, true
+}
+
+// replayAnswer writes the stored response of an earlier answer request sent
+// with key and reports whether there was one. Reusing a key for a different
+// answer gets 422.
+func replayAnswer(c *gin.Context, ctx context.Context, q rowQuerier, sessionID, key string, req SubmitPracticeAnswerRequest) bool {
+	if key == "" {
+		return false
+	}
+	var questionID, choiceID string
+	var response []byte
+	err := q.QueryRow(ctx, `select question_id, choice_id, response from practice_answer_requests where session_id=$1 and idempotency_key=$2`, sessionID, key).
+		Scan(&questionID, &choiceID, &response)
+	if err != nil {
+		return false
+	}
+	if questionID != req.QuestionID || choiceID != req.ChoiceID {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"message": "Idempotency-Key was already used for a different answer"})
+		return true
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+	return true
+}
+
+// storeAnswerResponse records the response to an answer request sent with
+// key, in the transaction that records the answer.
+func storeAnswerResponse(ctx context.Context, tx pgx.Tx, sessionID, key string, req SubmitPracticeAnswerRequest, response any) error {
+	if key == "" {
+		return nil
+	}
+	raw, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `insert into practice_answer_requests (session_id, idempotency_key, question_id, choice_id, response) values ($1,$2,$3,$4,$5)`,
+		sessionID, key, req.QuestionID, req.ChoiceID, raw)
+	return err
+}
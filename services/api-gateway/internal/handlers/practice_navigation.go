@@ -0,0 +1,496 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/grading"
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// In linear sessions students answer the question at current_index and see
+// the result right away. Free sessions let them move to any index, mark
+// questions for review and change answers; grading waits for submit.
+const (
+	practiceNavigationLinear = "linear"
+	practiceNavigationFree   = "free"
+)
+
+type PracticeNavigationItem struct {
+	Index            int     `json:"index"`
+	QuestionID       string  `json:"questionId"`
+	Answered         bool    `json:"answered"`
+	SelectedChoiceID *string `json:"selectedChoiceId"`
+	MarkedForReview  bool    `json:"markedForReview"`
+}
+
+type NavigatePracticeSessionRequest struct {
+	Index int `json:"index"`
+}
+
+type NavigatePracticeSessionResponse struct {
+	CurrentIndex     int               `json:"currentIndex"`
+	Question         *PracticeQuestion `json:"question"`
+	Stimulus         *PracticeStimulus `json:"stimulus,omitempty"`
+	SelectedChoiceID *string           `json:"selectedChoiceId"`
+	MarkedForReview  bool              `json:"markedForReview"`
+}
+
+type MarkPracticeQuestionRequest struct {
+	QuestionID string `json:"questionId"`
+	Marked     bool   `json:"marked"`
+}
+
+type MarkPracticeQuestionResponse struct {
+	MarkedQuestionIDs []string `json:"markedQuestionIds"`
+}
+
+// SavePracticeAnswerResponse answers a free-navigation answer: it is saved
+// but not graded until the session is submitted.
+type SavePracticeAnswerResponse struct {
+	QuestionID    string `json:"questionId"`
+	ChoiceID      string `json:"choiceId"`
+	Saved         bool   `json:"saved"`
+	AnsweredCount int    `json:"answeredCount"`
+}
+
+type SubmitPracticeSessionResponse struct {
+	SessionID    string  `json:"sessionId"`
+	Total        int     `json:"total"`
+	Answered     int     `json:"answered"`
+	CorrectCount int     `json:"correctCount"`
+	Accuracy     float64 `json:"accuracy"`
+}
+
+// latestPracticeAnswers maps each answered question of a session to the
+// student's current choice (the latest saved answer).
+func latestPracticeAnswers(ctx context.Context, pool *pgxpool.Pool, sessionID string) (map[string]string, error) {
+	rows, err := pool.Query(ctx, `select distinct on (question_id) question_id, choice_id from practice_answers
+		where session_id=$1 order by question_id, ts desc, id desc`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var qid, choiceID string
+		if err := rows.Scan(&qid, &choiceID); err != nil {
+			return nil, err
+		}
+		out[qid] = choiceID
+	}
+	return out, rows.Err()
+}
+
+// practiceNavigationItems lists a free-navigation session's questions in
+// order with their current answers and review marks.
+func practiceNavigationItems(ctx context.Context, pool *pgxpool.Pool, sessionID string, order []string, markedRaw []byte) ([]PracticeNavigationItem, error) {
+	answers, err := latestPracticeAnswers(ctx, pool, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	marked := markedSet(markedRaw)
+	items := make([]PracticeNavigationItem, 0, len(order))
+	for i, qid := range order {
+		item := PracticeNavigationItem{Index: i, QuestionID: qid, MarkedForReview: marked[qid]}
+		if choiceID, ok := answers[qid]; ok {
+			v := choiceID
+			item.Answered = true
+			item.SelectedChoiceID = &v
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func markedSet(raw []byte) map[string]bool {
+	var ids []string
+	_ = json.Unmarshal(raw, &ids)
+	out := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		out[id] = true
+	}
+	return out
+}
+
+// creditQuestionTime adds the time since startedAt to the question at index.
+func creditQuestionTime(timingsRaw []byte, order []string, index int, startedAt, now time.Time) map[string]int {
+	timings := map[string]int{}
+	if len(timingsRaw) > 0 {
+		_ = json.Unmarshal(timingsRaw, &timings)
+	}
+	if timings == nil {
+		timings = map[string]int{}
+	}
+	if index >= 0 && index < len(order) {
+		if spent := int(now.Sub(startedAt).Seconds()); spent > 0 {
+			timings[order[index]] += spent
+		}
+	}
+	return timings
+}
+
+// saveDraftAnswer records an answer in a free-navigation session. Every
+// change is kept as its own row; the session row is locked so a concurrent
+// submit cannot miss it.
+func saveDraftAnswer(c *gin.Context, ctx context.Context, pool *pgxpool.Pool, userID, sessionID, idempotencyKey string, snapshot []practiceQuestionSnapshot, req SubmitPracticeAnswerRequest) {
+	var q *practiceQuestionSnapshot
+	for i := range snapshot {
+		if snapshot[i].ID == req.QuestionID {
+			q = &snapshot[i]
+			break
+		}
+	}
+	if q == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "unknown question"})
+		return
+	}
+	choiceOK := false
+	for _, ch := range q.Choices {
+		if ch.ID == req.ChoiceID {
+			choiceOK = true
+			break
+		}
+	}
+	if !choiceOK {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid choice"})
+		return
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	var hintsUsedRaw []byte
+	if err := tx.QueryRow(ctx, `select status, hints_used from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).Scan(&status, &hintsUsedRaw); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+		return
+	}
+	if replayAnswer(c, ctx, tx, sessionID, idempotencyKey, req) {
+		return
+	}
+	if status != string(PracticeSessionActive) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "session not active"})
+		return
+	}
+	_, err = tx.Exec(ctx, `insert into practice_answers (session_id, user_id, question_id, choice_id, correct, explanation, is_final, hints_used, ts) values ($1,$2,$3,$4,$5,$6,false,$7,now())`,
+		sessionID, userID, q.ID, req.ChoiceID, req.ChoiceID == q.CorrectChoiceID, q.Explanation, decodeHintsUsed(hintsUsedRaw)[q.ID])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	var answered int
+	if err := tx.QueryRow(ctx, `select count(distinct question_id) from practice_answers where session_id=$1`, sessionID).Scan(&answered); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	if _, err := tx.Exec(ctx, `update practice_sessions set last_activity_at=now() where id=$1`, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	resp := SavePracticeAnswerResponse{QuestionID: q.ID, ChoiceID: req.ChoiceID, Saved: true, AnsweredCount: answered}
+	if err := storeAnswerResponse(ctx, tx, sessionID, idempotencyKey, req, resp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save answer"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// finishExpiredPracticeSession force-finishes a timed session whose limit has
+// passed, crediting the current question, and grades free-navigation answers.
+func finishExpiredPracticeSession(ctx context.Context, pool *pgxpool.Pool, userID, sessionID, navigation string, timings map[string]int) {
+	timingsJSON, _ := json.Marshal(timings)
+	_, _ = pool.Exec(ctx, `update practice_sessions set status=$1, question_timings=$2, last_activity_at=now() where id=$3 and user_id=$4`,
+		string(PracticeSessionFinished), timingsJSON, sessionID, userID)
+	if navigation == practiceNavigationFree {
+		_, _ = grading.Finalize(ctx, pool, userID, sessionID)
+		return
+	}
+	recordPracticeFinished(ctx, pool, userID, sessionID)
+}
+
+func registerPracticeNavigationRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireStudent := auth.RequirePortalAuth(pool, "student", "student")
+
+	r.POST("/practice-sessions/:sessionId/navigate", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+		var req NavigatePracticeSessionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+
+		ctx := context.Background()
+		var status, navigation string
+		var isTimed bool
+		var startedAt time.Time
+		var timeLimitSeconds *int
+		var currentIndex int
+		var orderRaw, snapshotRaw, timingsRaw, stimuliRaw, markedRaw []byte
+		var currentQuestionStartedAt time.Time
+		var shuffleSeed *int64
+		var sectionsRaw []byte
+		var sectionStartedAt *time.Time
+		var timingRaw []byte
+		err := pool.QueryRow(ctx, `select status, navigation, is_timed, started_at, time_limit_seconds, current_index, question_order, questions_snapshot,
+				question_timings, stimuli_snapshot, marked_question_ids, current_question_started_at, shuffle_seed, sections, section_started_at, timing
+			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
+			Scan(&status, &navigation, &isTimed, &startedAt, &timeLimitSeconds, &currentIndex, &orderRaw, &snapshotRaw,
+				&timingsRaw, &stimuliRaw, &markedRaw, &currentQuestionStartedAt, &shuffleSeed, &sectionsRaw, &sectionStartedAt, &timingRaw)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		if navigation != practiceNavigationFree {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "navigation is only available in free navigation sessions"})
+			return
+		}
+		if status != string(PracticeSessionActive) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "session not active"})
+			return
+		}
+
+		var order []string
+		_ = json.Unmarshal(orderRaw, &order)
+		now := time.Now().UTC()
+		timingSnap := timing.DecodeSnapshot(timingRaw)
+		if isTimed && timeLimitSeconds != nil && sessionTimeUp(timingSnap, startedAt, *timeLimitSeconds, now) {
+			finishExpiredPracticeSession(ctx, pool, userID, sessionID, navigation, creditQuestionTime(timingsRaw, order, currentIndex, currentQuestionStartedAt, now))
+			c.JSON(http.StatusBadRequest, gin.H{"message": "time is up"})
+			return
+		}
+		if req.Index < 0 || req.Index >= len(order) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "index out of range"})
+			return
+		}
+
+		// In timed blueprint sessions each section has its own clock: moving
+		// on to a later section closes the current one for good.
+		boundaries := decodeSessionSections(sectionsRaw)
+		sectionChange := ""
+		if isTimed && boundaries != nil {
+			if next, closed := closeExpiredSection(ctx, pool, userID, sessionID, navigation, boundaries, order, currentIndex, sectionStartedAt, timingsRaw, currentQuestionStartedAt, timingSnap.GraceSeconds, now); closed {
+				if next < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "time is up"})
+					return
+				}
+				c.JSON(http.StatusBadRequest, gin.H{"message": "section time is up", "currentIndex": next})
+				return
+			}
+			from, to := blueprint.SectionAt(boundaries, currentIndex), blueprint.SectionAt(boundaries, req.Index)
+			if to < from {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "earlier sections are closed"})
+				return
+			}
+			if to > from {
+				sectionChange = `, section_started_at=now()`
+			}
+		}
+
+		if req.Index != currentIndex {
+			timingsJSON, _ := json.Marshal(creditQuestionTime(timingsRaw, order, currentIndex, currentQuestionStartedAt, now))
+			_, err = pool.Exec(ctx, `update practice_sessions set current_index=$1, current_question_started_at=now(), question_timings=$2, last_activity_at=now()`+sectionChange+`
+				where id=$3 and user_id=$4 and status=$5`, req.Index, timingsJSON, sessionID, userID, string(PracticeSessionActive))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update session"})
+				return
+			}
+		}
+
+		var snapshot []practiceQuestionSnapshot
+		_ = json.Unmarshal(snapshotRaw, &snapshot)
+		var stimuli []PracticeStimulus
+		_ = json.Unmarshal(stimuliRaw, &stimuli)
+		answers, err := latestPracticeAnswers(ctx, pool, sessionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load answers"})
+			return
+		}
+		qid := order[req.Index]
+		var selected *string
+		if v, ok := answers[qid]; ok {
+			selected = &v
+		}
+
+		// Jumps may land inside a passage group the client has not seen.
+		forceStimulus := req.Index != currentIndex+1 || parseBoolQuery(c, "includeStimulus")
+		c.JSON(http.StatusOK, NavigatePracticeSessionResponse{
+			CurrentIndex:     req.Index,
+			Question:         loadSnapshotQuestion(snapshot, req.Index, shuffleSeed),
+			Stimulus:         stimulusForIndex(snapshot, stimuli, req.Index, forceStimulus),
+			SelectedChoiceID: selected,
+			MarkedForReview:  markedSet(markedRaw)[qid],
+		})
+	})
+
+	r.POST("/practice-sessions/:sessionId/marks", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+		var req MarkPracticeQuestionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		questionID := strings.TrimSpace(req.QuestionID)
+
+		ctx := context.Background()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update marks"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		var status, navigation string
+		var orderRaw, markedRaw []byte
+		err = tx.QueryRow(ctx, `select status, navigation, question_order, marked_question_ids from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).
+			Scan(&status, &navigation, &orderRaw, &markedRaw)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update marks"})
+			return
+		}
+		if navigation != practiceNavigationFree {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "marks are only available in free navigation sessions"})
+			return
+		}
+		if status == string(PracticeSessionFinished) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "session is finished"})
+			return
+		}
+
+		var order []string
+		_ = json.Unmarshal(orderRaw, &order)
+		marked := markedSet(markedRaw)
+		found := false
+		for _, qid := range order {
+			if qid == questionID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "unknown question"})
+			return
+		}
+		if req.Marked {
+			marked[questionID] = true
+		} else {
+			delete(marked, questionID)
+		}
+
+		// Keep marks in session order.
+		ids := make([]string, 0, len(marked))
+		for _, qid := range order {
+			if marked[qid] {
+				ids = append(ids, qid)
+			}
+		}
+		idsJSON, _ := json.Marshal(ids)
+		if _, err := tx.Exec(ctx, `update practice_sessions set marked_question_ids=$1, last_activity_at=now() where id=$2`, idsJSON, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update marks"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update marks"})
+			return
+		}
+		c.JSON(http.StatusOK, MarkPracticeQuestionResponse{MarkedQuestionIDs: ids})
+	})
+
+	// Submit grades the latest answer to every question. Unanswered questions
+	// count as wrong for accuracy.
+	r.POST("/practice-sessions/:sessionId/submit", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+
+		ctx := context.Background()
+		var status, navigation string
+		var currentIndex, targetCount int
+		var orderRaw, timingsRaw []byte
+		var currentQuestionStartedAt time.Time
+		err := pool.QueryRow(ctx, `select status, navigation, current_index, target_count, question_order, question_timings, current_question_started_at
+			from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).
+			Scan(&status, &navigation, &currentIndex, &targetCount, &orderRaw, &timingsRaw, &currentQuestionStartedAt)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		if navigation != practiceNavigationFree {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "submit is only available in free navigation sessions"})
+			return
+		}
+		if status == string(PracticeSessionFinished) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "session is finished"})
+			return
+		}
+
+		// Paused sessions already credited the current question when pausing.
+		if status == string(PracticeSessionActive) {
+			var order []string
+			_ = json.Unmarshal(orderRaw, &order)
+			timingsJSON, _ := json.Marshal(creditQuestionTime(timingsRaw, order, currentIndex, currentQuestionStartedAt, time.Now().UTC()))
+			if _, err := pool.Exec(ctx, `update practice_sessions set question_timings=$1 where id=$2 and user_id=$3`, timingsJSON, sessionID, userID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit session"})
+				return
+			}
+		}
+		if _, err := grading.Finalize(ctx, pool, userID, sessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit session"})
+			return
+		}
+
+		var answered, correctCount int
+		err = pool.QueryRow(ctx, `select count(*), count(*) filter (where correct) from practice_answers where session_id=$1 and is_final`, sessionID).
+			Scan(&answered, &correctCount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit session"})
+			return
+		}
+		accuracy := 0.0
+		if targetCount > 0 {
+			accuracy = float64(correctCount) / float64(targetCount)
+		}
+		c.JSON(http.StatusOK, SubmitPracticeSessionResponse{
+			SessionID:    sessionID,
+			Total:        targetCount,
+			Answered:     answered,
+			CorrectCount: correctCount,
+			Accuracy:     accuracy,
+		})
+	})
+}
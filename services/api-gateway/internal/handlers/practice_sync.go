@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/hints"
+	"github.com/ace-platform/api-gateway/internal/mastery"
+	"github.com/ace-platform/api-gateway/internal/srs"
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// maxSyncAnswers bounds one offline sync batch.
+const maxSyncAnswers = 200
+
+// Outcomes of a synced answer.
+const (
+	syncAnswerApplied   = "applied"
+	syncAnswerDuplicate = "duplicate"
+	syncAnswerConflict  = "conflict"
+	syncAnswerRejected  = "rejected"
+)
+
+// SyncPracticeAnswer is one answer from a client's offline queue.
+type SyncPracticeAnswer struct {
+	// Seq orders the queue; a seq already synced to the session is not
+	// applied again.
+	Seq        int64  `json:"seq"`
+	QuestionID string `json:"questionId"`
+	ChoiceID   string `json:"choiceId"`
+	// TS is when the student answered, by the client's clock (RFC3339).
+	TS *string `json:"ts"`
+	// DurationSeconds is the time the client measured on the question; without
+	// it the gap since the previous answer's ts is used.
+	DurationSeconds *int `json:"durationSeconds"`
+}
+
+type SyncPracticeAnswersRequest struct {
+	Answers []SyncPracticeAnswer `json:"answers"`
+}
+
+type SyncPracticeAnswerResult struct {
+	Seq        int64  `json:"seq"`
+	QuestionID string `json:"questionId"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	// Correct and Explanation are set for answers graded right away (linear
+	// sessions).
+	Correct         *bool   `json:"correct,omitempty"`
+	Explanation     *string `json:"explanation,omitempty"`
+	CreditedSeconds int     `json:"creditedSeconds"`
+}
+
+type SyncPracticeAnswersResponse struct {
+	Results []SyncPracticeAnswerResult `json:"results"`
+	Session PracticeSessionResponse    `json:"session"`
+}
+
+// syncedAnswer is an applied answer whose review deck update waits for commit.
+type syncedAnswer struct {
+	index      int
+	questionID string
+	correct    bool
+	seconds    int
+}
+
+// clientAnswerTime parses a client timestamp and clamps it between the
+// session's creation and now; clients' clocks are not trusted further.
+func clientAnswerTime(ts *string, createdAt, now time.Time) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *ts)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	if t.Before(createdAt) {
+		t = createdAt
+	}
+	if t.After(now) {
+		t = now
+	}
+	return &t
+}
+
+func registerPracticeSyncRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	// Applies a client's offline answer queue in order and returns the
+	// session as the server sees it. Each answer gets its own outcome; the
+	// batch as a whole only fails on malformed input.
+	r.POST("/practice-sessions/:sessionId/sync", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+		var req SyncPracticeAnswersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		if len(req.Answers) == 0 || len(req.Answers) > maxSyncAnswers {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "answers must hold between 1 and 200 items"})
+			return
+		}
+		for i, a := range req.Answers {
+			if a.Seq <= 0 || (i > 0 && a.Seq <= req.Answers[i-1].Seq) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "answers must be ordered by increasing positive seq"})
+				return
+			}
+			if a.DurationSeconds != nil && *a.DurationSeconds < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "durationSeconds cannot be negative"})
+				return
+			}
+		}
+
+		ctx := context.Background()
+		// Apply the server's clock first: a session or section that ran out
+		// while the client was offline is closed before any answer lands.
+		if _, err := loadPracticeSession(ctx, pool, userID, sessionID, false); errors.Is(err, errPracticeSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load session"})
+			return
+		}
+
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		var status, mode, source, navigation string
+		var isTimed bool
+		var createdAt, questionStartedAt time.Time
+		var targetCount, currentIndex, correctCount int
+		var hintPenalty float64
+		var orderRaw, snapshotRaw, timingsRaw, sectionsRaw, timingRaw, hintsUsedRaw []byte
+		var packageID, topicID, difficultyID, sessionLocale, tierID *string
+		err = tx.QueryRow(ctx, `select status, is_timed, created_at, target_count, current_index, correct_count, question_order, questions_snapshot, current_question_started_at, question_timings,
+				mode, package_id, source, topic_id, difficulty_id, locale, tier_id::text, navigation, sections, timing, hints_used, hint_penalty
+			from practice_sessions where id=$1 and user_id=$2 for update`, sessionID, userID).
+			Scan(&status, &isTimed, &createdAt, &targetCount, &currentIndex, &correctCount, &orderRaw, &snapshotRaw, &questionStartedAt, &timingsRaw,
+				&mode, &packageID, &source, &topicID, &difficultyID, &sessionLocale, &tierID, &navigation, &sectionsRaw, &timingRaw, &hintsUsedRaw, &hintPenalty)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+			return
+		}
+
+		var order []string
+		_ = json.Unmarshal(orderRaw, &order)
+		var snapshot []practiceQuestionSnapshot
+		_ = json.Unmarshal(snapshotRaw, &snapshot)
+		timings := map[string]int{}
+		_ = json.Unmarshal(timingsRaw, &timings)
+		if timings == nil {
+			timings = map[string]int{}
+		}
+		boundaries := decodeSessionSections(sectionsRaw)
+		timingSnap := timing.DecodeSnapshot(timingRaw)
+		hintsUsed := decodeHintsUsed(hintsUsedRaw)
+		tierPolicy, err := loadTierPolicy(ctx, pool, tierID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load tier policy"})
+			return
+		}
+		explanationFor := func(q practiceQuestionSnapshot) *string {
+			if !tierPolicy.AllowsExplanations() {
+				return nil
+			}
+			v := q.Explanation
+			return &v
+		}
+
+		now := time.Now().UTC()
+		free := navigation == practiceNavigationFree
+		startIndex := currentIndex
+		// Credited time cannot exceed what passed on the server since the
+		// current question started.
+		budget := max(0, int(now.Sub(questionStartedAt).Seconds()))
+		prevTS := questionStartedAt
+		extended := false
+		var applied []syncedAnswer
+
+		results := make([]SyncPracticeAnswerResult, 0, len(req.Answers))
+		for _, a := range req.Answers {
+			res := SyncPracticeAnswerResult{Seq: a.Seq, QuestionID: a.QuestionID}
+
+			var doneQuestionID, doneChoiceID string
+			var doneCorrect bool
+			err := tx.QueryRow(ctx, `select question_id, choice_id, correct from practice_answers where session_id=$1 and client_seq=$2`, sessionID, a.Seq).
+				Scan(&doneQuestionID, &doneChoiceID, &doneCorrect)
+			if err == nil {
+				if doneQuestionID != a.QuestionID || doneChoiceID != a.ChoiceID {
+					res.Status, res.Message = syncAnswerRejected, "seq was already synced with a different answer"
+				} else {
+					res.Status = syncAnswerDuplicate
+					if !free {
+						res.Correct = &doneCorrect
+						if idx := slices.Index(order, a.QuestionID); idx >= 0 && idx < len(snapshot) {
+							res.Explanation = explanationFor(snapshot[idx])
+						}
+					}
+				}
+				results = append(results, res)
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+				return
+			}
+
+			idx := slices.Index(order, a.QuestionID)
+			switch {
+			case status != string(PracticeSessionActive):
+				res.Status, res.Message = syncAnswerConflict, "session is "+status
+			case idx < 0 || idx >= len(snapshot):
+				res.Status, res.Message = syncAnswerRejected, "unknown question"
+			case !slices.ContainsFunc(snapshot[idx].Choices, func(ch PracticeQuestionChoice) bool { return ch.ID == a.ChoiceID }):
+				res.Status, res.Message = syncAnswerRejected, "invalid choice"
+			case !free && idx < currentIndex:
+				res.Status, res.Message = syncAnswerConflict, "question was already answered"
+			case !free && idx > currentIndex:
+				res.Status, res.Message = syncAnswerRejected, "answers must follow the session order"
+			case free && isTimed && boundaries != nil && blueprint.SectionAt(boundaries, idx) != blueprint.SectionAt(boundaries, currentIndex):
+				res.Status, res.Message = syncAnswerConflict, "question is not in the current section"
+			}
+			if res.Status != "" {
+				results = append(results, res)
+				continue
+			}
+
+			q := snapshot[idx]
+			answeredAt := clientAnswerTime(a.TS, createdAt, now)
+			seconds := 0
+			if a.DurationSeconds != nil {
+				seconds = *a.DurationSeconds
+			} else if answeredAt != nil {
+				seconds = max(0, int(answeredAt.Sub(prevTS).Seconds()))
+			}
+			if answeredAt != nil {
+				prevTS = *answeredAt
+			}
+			if limit := timingSnap.Limit(idx); limit > 0 && !free {
+				seconds = min(seconds, limit+timingSnap.GraceSeconds)
+			}
+			seconds = min(seconds, budget)
+			budget -= seconds
+			timings[q.ID] += seconds
+
+			correct := a.ChoiceID == q.CorrectChoiceID
+			// Free-navigation answers are drafts, credited when graded.
+			var credit *float64
+			if !free {
+				v := hints.Credit(correct, hintsUsed[q.ID], hintPenalty)
+				credit = &v
+			}
+			_, err = tx.Exec(ctx, `insert into practice_answers (session_id, user_id, question_id, choice_id, correct, explanation, is_final, client_seq, client_ts, hints_used, credit, ts)
+				values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,now())`,
+				sessionID, userID, q.ID, a.ChoiceID, correct, q.Explanation, !free, a.Seq, answeredAt, hintsUsed[q.ID], credit)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+				return
+			}
+			res.Status = syncAnswerApplied
+			res.CreditedSeconds = seconds
+
+			if !free {
+				res.Correct = &correct
+				res.Explanation = explanationFor(q)
+				if correct {
+					correctCount++
+				}
+				if err := mastery.Record(ctx, tx, userID, q.ID, correct, *credit); err != nil {
+					log.Printf("mastery: record answer for %s failed: %v", q.ID, err)
+				}
+				applied = append(applied, syncedAnswer{index: idx, questionID: q.ID, correct: correct, seconds: timings[q.ID]})
+				currentIndex++
+				if mode == practiceModeAdaptive && packageID != nil && currentIndex < targetCount && currentIndex >= len(order) {
+					filter := practiceFilter{PackageID: *packageID, TopicID: topicID, DifficultyID: difficultyID, Source: source, UserID: userID}
+					next, err := drawAdaptiveUnit(ctx, pool, tx, filter, sessionLocale, order, targetCount-currentIndex)
+					if err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to select questions"})
+						return
+					}
+					if len(next) == 0 {
+						targetCount = currentIndex
+					}
+					for _, nq := range next {
+						snapshot = append(snapshot, nq)
+						order = append(order, nq.ID)
+						extended = true
+					}
+				}
+				if currentIndex >= targetCount {
+					status = string(PracticeSessionFinished)
+				}
+			}
+			results = append(results, res)
+		}
+
+		if slices.ContainsFunc(results, func(r SyncPracticeAnswerResult) bool { return r.Status == syncAnswerApplied }) {
+			timingsJSON, _ := json.Marshal(timings)
+			args := []any{currentIndex, correctCount, status, timingsJSON, targetCount, sessionID, userID}
+			query := `update practice_sessions set current_index=$1, correct_count=$2, status=$3, question_timings=$4, target_count=$5, current_question_started_at=now(), last_activity_at=now()`
+			if boundaries != nil && blueprint.SectionAt(boundaries, currentIndex) != blueprint.SectionAt(boundaries, startIndex) {
+				query += `, section_started_at=now()`
+			}
+			if extended {
+				stimuli, err := loadPracticeStimuli(ctx, pool, snapshot)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load stimuli"})
+					return
+				}
+				orderJSON, _ := json.Marshal(order)
+				snapshotJSON, _ := json.Marshal(snapshot)
+				stimuliJSON, _ := json.Marshal(stimuli)
+				args = append(args, orderJSON, snapshotJSON, stimuliJSON)
+				query += `, question_order=$8, questions_snapshot=$9, stimuli_snapshot=$10`
+			}
+			if _, err := tx.Exec(ctx, query+` where id=$6 and user_id=$7`, args...); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+				return
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to sync answers"})
+			return
+		}
+
+		for _, a := range applied {
+			quality := srs.Grade(a.correct, a.seconds, timingSnap.Seconds(a.index))
+			if err := srs.RecordAnswer(ctx, pool, userID, a.questionID, quality, mode == practiceModeReview); err != nil {
+				log.Printf("srs: record answer for %s failed: %v", a.questionID, err)
+			}
+		}
+		if !free && status == string(PracticeSessionFinished) {
+			recordPracticeFinished(ctx, pool, userID, sessionID)
+		}
+
+		session, err := loadPracticeSession(ctx, pool, userID, sessionID, true)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load session"})
+			return
+		}
+		c.JSON(http.StatusOK, SyncPracticeAnswersResponse{Results: results, Session: session})
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,6 +12,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/timing"
 )
 
 type PracticeTemplate struct {
@@ -26,8 +29,13 @@ type PracticeTemplate struct {
 	TargetCount    int     `json:"targetCount"`
 	SortOrder      int     `json:"sortOrder"`
 	IsPublished    bool    `json:"isPublished"`
-	CreatedAt      string  `json:"createdAt"`
-	UpdatedAt      string  `json:"updatedAt"`
+	// Sections makes the template a multi-section blueprint; targetCount is
+	// then the blueprint total.
+	Sections []blueprint.Section `json:"sections,omitempty"`
+	// Timing paces timed sessions started from the template.
+	Timing    *timing.Policy `json:"timing,omitempty"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
 }
 
 type ListPracticeTemplatesResponse struct {
@@ -35,14 +43,16 @@ type ListPracticeTemplatesResponse struct {
 }
 
 type CreatePracticeTemplateRequest struct {
-	ExamPackageID string  `json:"examPackageId"`
-	Name          string  `json:"name"`
-	Section       string  `json:"section"`
-	TopicID       *string `json:"topicId"`
-	DifficultyID  *string `json:"difficultyId"`
-	IsTimed       bool    `json:"isTimed"`
-	TargetCount   int     `json:"targetCount"`
-	SortOrder     *int    `json:"sortOrder"`
+	ExamPackageID string           `json:"examPackageId"`
+	Name          string           `json:"name"`
+	Section       string           `json:"section"`
+	TopicID       *string          `json:"topicId"`
+	DifficultyID  *string          `json:"difficultyId"`
+	IsTimed       bool             `json:"isTimed"`
+	TargetCount   int              `json:"targetCount"`
+	SortOrder     *int             `json:"sortOrder"`
+	Sections      *json.RawMessage `json:"sections"`
+	Timing        *json.RawMessage `json:"timing"`
 }
 
 type UpdatePracticeTemplateRequest struct {
@@ -53,6 +63,11 @@ type UpdatePracticeTemplateRequest struct {
 	IsTimed      *bool   `json:"isTimed"`
 	TargetCount  *int    `json:"targetCount"`
 	SortOrder    *int    `json:"sortOrder"`
+	// Sections replaces the blueprint; null turns the template back into a
+	// single-section one.
+	Sections *json.RawMessage `json:"sections"`
+	// Timing replaces the timing policy; null removes it.
+	Timing *json.RawMessage `json:"timing"`
 }
 
 func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
@@ -94,6 +109,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				t.is_published,
 				t.created_at,
 				t.updated_at,
+				t.sections,
+				t.timing,
 				tp.name as topic_name,
 				d.display_name as difficulty_name
 			from practice_templates t
@@ -122,6 +139,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var isPublished bool
 			var createdAt time.Time
 			var updatedAt time.Time
+			var sectionsRaw []byte
+			var timingRaw []byte
 			var topicName *string
 			var difficultyName *string
 
@@ -138,6 +157,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				&isPublished,
 				&createdAt,
 				&updatedAt,
+				&sectionsRaw,
+				&timingRaw,
 				&topicName,
 				&difficultyName,
 			); err != nil {
@@ -160,6 +181,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				IsPublished:    isPublished,
 				CreatedAt:      createdAt.UTC().Format(time.RFC3339),
 				UpdatedAt:      updatedAt.UTC().Format(time.RFC3339),
+				Sections:       decodeTemplateSections(sectionsRaw),
+				Timing:         timing.Decode(timingRaw),
 			})
 		}
 
@@ -204,6 +227,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					t.is_published,
 					t.created_at,
 					t.updated_at,
+					t.sections,
+					t.timing,
 					tp.name as topic_name,
 					d.display_name as difficulty_name
 				from practice_templates t
@@ -230,6 +255,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				var isPublished bool
 				var createdAt time.Time
 				var updatedAt time.Time
+				var sectionsRaw []byte
+				var timingRaw []byte
 				var topicName *string
 				var difficultyName *string
 
@@ -246,6 +273,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					&isPublished,
 					&createdAt,
 					&updatedAt,
+					&sectionsRaw,
+					&timingRaw,
 					&topicName,
 					&difficultyName,
 				); err != nil {
@@ -268,6 +297,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					IsPublished:    isPublished,
 					CreatedAt:      createdAt.UTC().Format(time.RFC3339),
 					UpdatedAt:      updatedAt.UTC().Format(time.RFC3339),
+					Sections:       decodeTemplateSections(sectionsRaw),
+					Timing:         timing.Decode(timingRaw),
 				})
 			}
 
@@ -304,15 +335,32 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			if req.SortOrder != nil && *req.SortOrder >= 0 {
 				sortOrder = *req.SortOrder
 			}
+			var sectionsJSON []byte
+			if req.Sections != nil {
+				sections, err := blueprint.Parse(*req.Sections)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+					return
+				}
+				if sections != nil {
+					sectionsJSON, _ = json.Marshal(sections)
+					req.TargetCount = blueprint.Total(sections)
+				}
+			}
+
+			timingJSON, ok := parseTemplateTiming(c, req.Timing)
+			if !ok {
+				return
+			}
 
 			ctx := context.Background()
 			_, err := pool.Exec(ctx, `
 				insert into practice_templates (
 					exam_package_id, name, section, topic_id, difficulty_id, is_timed, target_count, sort_order, is_published,
-					created_by_user_id, updated_by_user_id
+					created_by_user_id, updated_by_user_id, sections, timing
 				)
-				values ($1,$2,$3,$4,$5,$6,$7,$8,false,$9,$9)`,
-				examPkgID, name, section, req.TopicID, req.DifficultyID, req.IsTimed, req.TargetCount, sortOrder, userID)
+				values ($1,$2,$3,$4,$5,$6,$7,$8,false,$9,$9,$10,$11)`,
+				examPkgID, name, section, req.TopicID, req.DifficultyID, req.IsTimed, req.TargetCount, sortOrder, userID, sectionsJSON, timingJSON)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to create template"})
 				return
@@ -333,6 +381,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					t.is_published,
 					t.created_at,
 					t.updated_at,
+					t.sections,
+					t.timing,
 					tp.name as topic_name,
 					d.display_name as difficulty_name
 				from practice_templates t
@@ -345,6 +395,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var out PracticeTemplate
 			var createdAt time.Time
 			var updatedAt time.Time
+			var sectionsRaw []byte
+			var timingRaw []byte
 			if err := row.Scan(
 				&out.ID,
 				&out.ExamPackageID,
@@ -358,6 +410,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				&out.IsPublished,
 				&createdAt,
 				&updatedAt,
+				&sectionsRaw,
+				&timingRaw,
 				&out.TopicName,
 				&out.DifficultyName,
 			); err != nil {
@@ -366,6 +420,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 			out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
 			out.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+			out.Sections = decodeTemplateSections(sectionsRaw)
+			out.Timing = timing.Decode(timingRaw)
 
 			c.JSON(http.StatusOK, out)
 		})
@@ -389,6 +445,26 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				return
 			}
 
+			// A blueprint's size is the sum of its quotas.
+			var sectionsJSON []byte
+			if req.Sections != nil {
+				sections, err := blueprint.Parse(*req.Sections)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+					return
+				}
+				if sections != nil {
+					sectionsJSON, _ = json.Marshal(sections)
+					total := blueprint.Total(sections)
+					req.TargetCount = &total
+				}
+			}
+
+			timingJSON, ok := parseTemplateTiming(c, req.Timing)
+			if !ok {
+				return
+			}
+
 			ctx := context.Background()
 
 			// Update with COALESCE to keep patch semantics.
@@ -402,6 +478,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					target_count = coalesce($7, target_count),
 					sort_order = coalesce($8, sort_order),
 					updated_by_user_id = $9,
+					sections = case when $10 then $11::json else sections end,
+					timing = case when $12 then $13::json else timing end,
 					updated_at = now()
 				where id=$1`,
 				id,
@@ -413,6 +491,10 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				req.TargetCount,
 				req.SortOrder,
 				userID,
+				req.Sections != nil,
+				sectionsJSON,
+				req.Timing != nil,
+				timingJSON,
 			)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to update template"})
@@ -433,6 +515,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					t.is_published,
 					t.created_at,
 					t.updated_at,
+					t.sections,
+					t.timing,
 					tp.name as topic_name,
 					d.display_name as difficulty_name
 				from practice_templates t
@@ -443,6 +527,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var out PracticeTemplate
 			var createdAt time.Time
 			var updatedAt time.Time
+			var sectionsRaw []byte
+			var timingRaw []byte
 			if err := row.Scan(
 				&out.ID,
 				&out.ExamPackageID,
@@ -456,6 +542,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				&out.IsPublished,
 				&createdAt,
 				&updatedAt,
+				&sectionsRaw,
+				&timingRaw,
 				&out.TopicName,
 				&out.DifficultyName,
 			); err != nil {
@@ -464,6 +552,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 			out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
 			out.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+			out.Sections = decodeTemplateSections(sectionsRaw)
+			out.Timing = timing.Decode(timingRaw)
 
 			c.JSON(http.StatusOK, out)
 		})
@@ -516,6 +606,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 						t.is_published,
 						t.created_at,
 						t.updated_at,
+						t.sections,
+						t.timing,
 						tp.name as topic_name,
 						d.display_name as difficulty_name
 					from practice_templates t
@@ -526,6 +618,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				var out PracticeTemplate
 				var createdAt time.Time
 				var updatedAt time.Time
+				var sectionsRaw []byte
+				var timingRaw []byte
 				if err := row.Scan(
 					&out.ID,
 					&out.ExamPackageID,
@@ -539,6 +633,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					&out.IsPublished,
 					&createdAt,
 					&updatedAt,
+					&sectionsRaw,
+					&timingRaw,
 					&out.TopicName,
 					&out.DifficultyName,
 				); err != nil {
@@ -547,6 +643,8 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				}
 				out.CreatedAt = createdAt.UTC().Format(time.RFC3339)
 				out.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+				out.Sections = decodeTemplateSections(sectionsRaw)
+				out.Timing = timing.Decode(timingRaw)
 
 				c.JSON(http.StatusOK, out)
 			}
@@ -557,6 +655,35 @@ func registerPracticeTemplateRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 	}
 }
 
+// decodeTemplateSections reads stored blueprint sections; they were validated
+// on write.
+func decodeTemplateSections(raw []byte) []blueprint.Section {
+	if len(raw) == 0 {
+		return nil
+	}
+	var sections []blueprint.Section
+	_ = json.Unmarshal(raw, &sections)
+	return sections
+}
+
+// parseTemplateTiming validates a timing policy from a template request and
+// returns it in canonical form (nil for none). It responds 400 itself.
+func parseTemplateTiming(c *gin.Context, raw *json.RawMessage) ([]byte, bool) {
+	if raw == nil {
+		return nil, true
+	}
+	p, err := timing.Parse(*raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return nil, false
+	}
+	if p == nil {
+		return nil, true
+	}
+	out, _ := json.Marshal(p)
+	return out, true
+}
+
 func nilIfEmptyPtr(in *string) *string {
 	if in == nil {
 		return nil
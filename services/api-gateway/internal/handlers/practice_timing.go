@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/blueprint"
+	"github.com/ace-platform/api-gateway/internal/timing"
+)
+
+// questionDifficulties returns the difficulty id of each question, "" for
+// questions without one, in the order given.
+func questionDifficulties(ctx context.Context, pool *pgxpool.Pool, ids []string) ([]string, error) {
+	rows, err := pool.Query(ctx, `select id, coalesce(difficulty_id::text, '') from question_bank_questions where id = any($1)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byID := map[string]string{}
+	for rows.Next() {
+		var id, difficultyID string
+		if err := rows.Scan(&id, &difficultyID); err != nil {
+			return nil, err
+		}
+		byID[id] = difficultyID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = byID[id]
+	}
+	return out, nil
+}
+
+// resolveSessionTiming resolves the timing of a timed session from its
+// template policy and, for blueprints, each section's override. It returns
+// the snapshot, the session limit, and the boundaries with each section's
+// limit filled in. Adaptive sessions draw as they go, so the questions beyond
+// order are paced at the default time.
+func resolveSessionTiming(ctx context.Context, pool *pgxpool.Pool, templatePolicy *timing.Policy, sections []blueprint.Section, boundaries []blueprint.Boundary, order []string, count int) (timing.Snapshot, int, []blueprint.Boundary, error) {
+	difficulties, err := questionDifficulties(ctx, pool, order)
+	if err != nil {
+		return timing.Snapshot{}, 0, nil, err
+	}
+	base := timing.Merge(templatePolicy, nil)
+	if boundaries == nil {
+		snap := timing.NewSnapshot(base, difficulties)
+		total := base.Total(difficulties)
+		if base.TotalSeconds == nil && count > len(order) {
+			total += (count - len(order)) * snap.DefaultSeconds
+		}
+		return snap, total, nil, nil
+	}
+
+	snap := timing.NewSnapshot(base, nil)
+	total := 0
+	out := make([]blueprint.Boundary, len(boundaries))
+	for i, b := range boundaries {
+		sectionPolicy := timing.Merge(templatePolicy, sections[i].Timing)
+		// The template total is the session's, not the section's.
+		sectionPolicy.TotalSeconds = nil
+		ids := difficulties[b.StartIndex : b.StartIndex+b.Count]
+		snap.Append(sectionPolicy, ids)
+
+		out[i] = b
+		if b.TimeLimitSeconds == nil && sections[i].Timing != nil && sections[i].Timing.TotalSeconds != nil {
+			limit := *sections[i].Timing.TotalSeconds
+			out[i].TimeLimitSeconds = &limit
+		}
+		if out[i].TimeLimitSeconds != nil {
+			total += *out[i].TimeLimitSeconds
+		} else {
+			total += sectionPolicy.Total(ids)
+		}
+	}
+	if base.TotalSeconds != nil {
+		total = *base.TotalSeconds
+	}
+	return snap, total, out, nil
+}
+
+// sessionTimeUp reports whether a timed session's limit has passed, grace
+// included.
+func sessionTimeUp(snap timing.Snapshot, startedAt time.Time, timeLimitSeconds int, now time.Time) bool {
+	return snap.Expired(startedAt.Add(time.Duration(timeLimitSeconds)*time.Second), now)
+}
+
+// sessionTimeRemaining is the time left in a timed session, without grace.
+func sessionTimeRemaining(startedAt time.Time, timeLimitSeconds int, now time.Time) *int {
+	r := timing.Remaining(startedAt.Add(time.Duration(timeLimitSeconds)*time.Second), now)
+	return &r
+}
+
+// advanceExpiredQuestions moves a linear timed session past the questions
+// whose hard limit ran out, leaving them unanswered and crediting each its
+// limit. Running past the last question finishes the session. It returns the
+// new index and question start, and whether anything moved.
+func advanceExpiredQuestions(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string, snap timing.Snapshot, boundaries []blueprint.Boundary, order []string,
+	targetCount, currentIndex int, questionStartedAt time.Time, timingsRaw []byte, now time.Time) (int, time.Time, bool) {
+	n := min(targetCount, len(order))
+	next, nextStartedAt, credited := snap.Advance(currentIndex, questionStartedAt, now, n)
+	if next == currentIndex {
+		return currentIndex, questionStartedAt, false
+	}
+	timings := map[string]int{}
+	_ = json.Unmarshal(timingsRaw, &timings)
+	if timings == nil {
+		timings = map[string]int{}
+	}
+	for idx, seconds := range credited {
+		timings[order[idx]] += seconds
+	}
+	timingsJSON, _ := json.Marshal(timings)
+
+	if next >= n {
+		_, _ = pool.Exec(ctx, `update practice_sessions set status=$1, current_index=$2, question_timings=$3, last_activity_at=now()
+			where id=$4 and user_id=$5`, string(PracticeSessionFinished), next, timingsJSON, sessionID, userID)
+		recordPracticeFinished(ctx, pool, userID, sessionID)
+		return next, nextStartedAt, true
+	}
+	query := `update practice_sessions set current_index=$1, current_question_started_at=$2, question_timings=$3, last_activity_at=now()`
+	if boundaries != nil && blueprint.SectionAt(boundaries, next) != blueprint.SectionAt(boundaries, currentIndex) {
+		query += `, section_started_at=$2`
+	}
+	_, _ = pool.Exec(ctx, query+` where id=$4 and user_id=$5`, next, nextStartedAt, timingsJSON, sessionID, userID)
+	return next, nextStartedAt, true
+}
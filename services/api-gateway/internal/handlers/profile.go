@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+	"github.com/ace-platform/api-gateway/internal/locale"
+)
+
+type UpdateStudentProfileRequest struct {
+	// Locale is the preferred content locale; an empty string clears it.
+	Locale *string `json:"locale"`
+	// Timezone is an IANA timezone name used for daily streaks; an empty
+	// string clears it (UTC).
+	Timezone *string `json:"timezone"`
+	// LeaderboardOptOut hides the student from every leaderboard.
+	LeaderboardOptOut *bool `json:"leaderboardOptOut"`
+	// LeaderboardName is the name shown on leaderboards; an empty string
+	// restores the default pseudonym.
+	LeaderboardName *string `json:"leaderboardName"`
+}
+
+func registerStudentProfileRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	r.PATCH("/student/profile", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		var req UpdateStudentProfileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		if req.Locale == nil && req.Timezone == nil && req.LeaderboardOptOut == nil && req.LeaderboardName == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "no fields to update"})
+			return
+		}
+		sets := []string{"updated_at=now()"}
+		args := []any{userID}
+		if req.Locale != nil {
+			var profileLocale *string
+			if raw := strings.TrimSpace(*req.Locale); raw != "" {
+				loc, ok := locale.Normalize(raw)
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid locale"})
+					return
+				}
+				profileLocale = &loc
+			}
+			args = append(args, profileLocale)
+			sets = append(sets, "locale="+sqlParam(len(args)))
+		}
+		if req.Timezone != nil {
+			var tz *string
+			if raw := strings.TrimSpace(*req.Timezone); raw != "" {
+				if !gamification.ValidTimezone(raw) {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid timezone"})
+					return
+				}
+				tz = &raw
+			}
+			args = append(args, tz)
+			sets = append(sets, "timezone="+sqlParam(len(args)))
+		}
+		if req.LeaderboardOptOut != nil {
+			args = append(args, *req.LeaderboardOptOut)
+			sets = append(sets, "leaderboard_opt_out="+sqlParam(len(args)))
+		}
+		if req.LeaderboardName != nil {
+			var name *string
+			if raw := strings.TrimSpace(*req.LeaderboardName); raw != "" {
+				v, ok := leaderboard.NormalizeName(raw)
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "leaderboard name must be 2 to 32 letters, digits, spaces, '_', '-' or '.'"})
+					return
+				}
+				name = &v
+			}
+			args = append(args, name)
+			sets = append(sets, "leaderboard_name="+sqlParam(len(args)))
+		}
+		var profileLocale, profileTimezone, leaderboardName *string
+		var leaderboardOptOut bool
+		err := pool.QueryRow(context.Background(), `update users set `+strings.Join(sets, ", ")+` where id=$1
+			returning locale, timezone, leaderboard_opt_out, leaderboard_name`, args...).
+			Scan(&profileLocale, &profileTimezone, &leaderboardOptOut, &leaderboardName)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update profile"})
+			return
+		}
+		displayName := leaderboard.DefaultName(userID)
+		if leaderboardName != nil {
+			displayName = *leaderboardName
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "locale": profileLocale, "timezone": profileTimezone,
+			"leaderboardOptOut": leaderboardOptOut, "leaderboardName": displayName})
+	})
+}
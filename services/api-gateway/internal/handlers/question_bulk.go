@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+)
+
+const (
+	bulkActionSetStatus     = "set_status"
+	bulkActionSetTopic      = "set_topic"
+	bulkActionSetDifficulty = "set_difficulty"
+	bulkActionMoveBank      = "move_bank"
+
+	maxBulkQuestions = 500
+)
+
+// BulkQuestionFilter selects questions by attribute; empty fields match everything.
+type BulkQuestionFilter struct {
+	QuestionBankID *string `json:"questionBankId"`
+	TopicID        *string `json:"topicId"`
+	DifficultyID   *string `json:"difficultyId"`
+	Status         *string `json:"status"`
+}
+
+type BulkQuestionActionRequest struct {
+	// Either IDs or Filter selects the questions (at most 500).
+	IDs    []string            `json:"ids"`
+	Filter *BulkQuestionFilter `json:"filter"`
+
+	// Action is one of set_status, set_topic, set_difficulty, move_bank.
+	Action string `json:"action"`
+
+	Status         *string `json:"status"`
+	TopicID        *string `json:"topicId"`
+	DifficultyID   *string `json:"difficultyId"`
+	QuestionBankID *string `json:"questionBankId"`
+}
+
+type BulkQuestionResult struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// TopicCleared is set when a move dropped a topic that belongs to the old bank.
+	TopicCleared bool `json:"topicCleared,omitempty"`
+}
+
+type BulkQuestionActionResponse struct {
+	Action    string               `json:"action"`
+	Matched   int                  `json:"matched"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BulkQuestionResult `json:"results"`
+}
+
+// bulkQuestionStatus reports whether v may be set in bulk. Review states are
+// left to submit-for-review and review decisions, which keep the review
+// round and reviewer rules.
+func bulkQuestionStatus(v string) bool {
+	switch QuestionStatus(v) {
+	case QuestionDraft, QuestionPublished, QuestionArchived:
+		return true
+	}
+	return false
+}
+
+func registerQuestionBulkRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	r.POST("/instructor/questions/bulk", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+
+		var req BulkQuestionActionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		if (len(req.IDs) == 0) == (req.Filter == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "provide either ids or filter"})
+			return
+		}
+		if len(req.IDs) > maxBulkQuestions {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "too many questions (max 500)"})
+			return
+		}
+
+		// Validate the action and its argument before touching any rows.
+		var column string
+		var value any
+		switch req.Action {
+		case bulkActionSetStatus:
+			status := strings.TrimSpace(stringValue(req.Status))
+			if !bulkQuestionStatus(status) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "status must be draft, published or archived"})
+				return
+			}
+			// Same rules as setStatus: only admins publish directly, and only
+			// questions with the required review approvals.
+			if QuestionStatus(status) == QuestionPublished && role != "admin" {
+				c.JSON(http.StatusForbidden, gin.H{"message": "forbidden"})
+				return
+			}
+			column, value = "status", status
+		case bulkActionSetTopic:
+			// An empty topicId clears the topic.
+			column, value = "topic_id", nilIfEmptyPtr(req.TopicID)
+		case bulkActionSetDifficulty:
+			difficultyID := strings.TrimSpace(stringValue(req.DifficultyID))
+			if difficultyID == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "difficultyId is required"})
+				return
+			}
+			column, value = "difficulty_id", difficultyID
+		case bulkActionMoveBank:
+			bankID := strings.TrimSpace(stringValue(req.QuestionBankID))
+			if bankID == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "questionBankId is required"})
+				return
+			}
+			column, value = "package_id", bankID
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "action must be set_status, set_topic, set_difficulty or move_bank"})
+			return
+		}
+
+		ctx := context.Background()
+		var topicBankID *string
+		switch req.Action {
+		case bulkActionSetTopic:
+			if value.(*string) != nil {
+				if err := pool.QueryRow(ctx, `select package_id from question_bank_topics where id=$1 and deleted_at is null`, *value.(*string)).Scan(&topicBankID); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "topic not found"})
+					return
+				}
+			}
+		case bulkActionSetDifficulty:
+			var exists bool
+			if err := pool.QueryRow(ctx, `select exists(select 1 from question_bank_difficulties where id=$1)`, value).Scan(&exists); err != nil || !exists {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "difficulty not found"})
+				return
+			}
+		case bulkActionMoveBank:
+			var exists bool
+			if err := pool.QueryRow(ctx, `select exists(select 1 from question_banks where id=$1 and deleted_at is null)`, value).Scan(&exists); err != nil || !exists {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "question bank not found"})
+				return
+			}
+		}
+
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to apply bulk action"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		// Resolve the selection, locking rows so the batch sees a stable view.
+		args := []any{}
+		where := []string{}
+		ids := req.IDs
+		if req.Filter != nil {
+			for _, f := range []struct {
+				column string
+				value  *string
+			}{
+				{"package_id", req.Filter.QuestionBankID},
+				{"topic_id", req.Filter.TopicID},
+				{"difficulty_id", req.Filter.DifficultyID},
+				{"status", req.Filter.Status},
+			} {
+				if v := nilIfEmptyPtr(f.value); v != nil {
+					args = append(args, *v)
+					where = append(where, f.column+"="+sqlParam(len(args)))
+				}
+			}
+			if len(where) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "filter must set at least one field"})
+				return
+			}
+			if role != "admin" {
+				args = append(args, userID)
+				where = append(where, "created_by_user_id="+sqlParam(len(args)))
+			}
+		} else {
+			args = append(args, ids)
+			where = append(where, "id = any("+sqlParam(len(args))+")")
+		}
+
+		type selected struct {
+			createdBy   *string
+			bankID      *string
+			topicBankID *string
+			stimulusID  *string
+			stimBankID  *string
+			publishable bool
+		}
+		rows, err := tx.Query(ctx, `select q.id, q.created_by_user_id, q.package_id, t.package_id, q.stimulus_id, st.question_bank_id,
+				q.status='`+string(QuestionPublished)+`' or `+approvedSQL("q")+`
+			from question_bank_questions q
+			left join question_bank_topics t on t.id=q.topic_id
+			left join question_stimuli st on st.id=q.stimulus_id
+			where q.deleted_at is null and `+strings.Join(prefixColumns(where, "q."), " and ")+`
+			order by q.id limit `+sqlParam(len(args)+1)+` for update of q`, append(args, maxBulkQuestions+1)...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to apply bulk action"})
+			return
+		}
+		found := map[string]selected{}
+		order := []string{}
+		for rows.Next() {
+			var id string
+			var s selected
+			if err := rows.Scan(&id, &s.createdBy, &s.bankID, &s.topicBankID, &s.stimulusID, &s.stimBankID, &s.publishable); err != nil {
+				rows.Close()
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to apply bulk action"})
+				return
+			}
+			found[id] = s
+			order = append(order, id)
+		}
+		rows.Close()
+		if len(found) > maxBulkQuestions {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "too many questions match the filter (max 500)"})
+			return
+		}
+		if req.Filter != nil {
+			ids = order
+		}
+
+		resp := BulkQuestionActionResponse{Action: req.Action, Matched: len(ids), Results: make([]BulkQuestionResult, 0, len(ids))}
+		seen := map[string]bool{}
+		for _, id := range ids {
+			res := BulkQuestionResult{ID: id}
+			s, ok := found[id]
+			switch {
+			case seen[id]:
+				continue
+			case !ok || (role != "admin" && (s.createdBy == nil || *s.createdBy != userID)):
+				// Same visibility as the single-question endpoints.
+				res.Error = "question not found"
+			case req.Action == bulkActionSetStatus && QuestionStatus(value.(string)) == QuestionPublished && !s.publishable:
+				res.Error = "question does not have the required review approvals"
+			case req.Action == bulkActionSetTopic && topicBankID != nil && (s.bankID == nil || *s.bankID != *topicBankID):
+				res.Error = "topic belongs to a different question bank"
+			case req.Action == bulkActionMoveBank && s.stimulusID != nil && (s.stimBankID == nil || *s.stimBankID != value.(string)):
+				res.Error = "question uses a stimulus from another question bank"
+			default:
+				set := []string{column + "=$1", "updated_at=now()", "updated_by_user_id=$2"}
+				if req.Action == bulkActionMoveBank && s.topicBankID != nil && *s.topicBankID != value.(string) {
+					set = append(set, "topic_id=null")
+					res.TopicCleared = true
+				}
+				// A savepoint per item keeps one bad row from aborting the batch.
+				sp, err := tx.Begin(ctx)
+				if err == nil {
+					_, err = sp.Exec(ctx, `update question_bank_questions set `+strings.Join(set, ", ")+` where id=$3`, value, userID, id)
+					if err == nil {
+						err = sp.Commit(ctx)
+					} else {
+						_ = sp.Rollback(ctx)
+					}
+				}
+				if err != nil {
+					res.Error = "update failed"
+					res.TopicCleared = false
+				} else {
+					res.OK = true
+				}
+			}
+			seen[id] = true
+			if res.OK {
+				resp.Succeeded++
+			} else {
+				resp.Failed++
+			}
+			resp.Results = append(resp.Results, res)
+		}
+		resp.Matched = len(resp.Results)
+
+		metadata := gin.H{"action": req.Action, "value": value, "matched": resp.Matched, "succeeded": resp.Succeeded, "failed": resp.Failed}
+		if req.Filter != nil {
+			metadata["filter"] = req.Filter
+		}
+		succeeded := make([]string, 0, resp.Succeeded)
+		for _, res := range resp.Results {
+			if res.OK {
+				succeeded = append(succeeded, res.ID)
+			}
+		}
+		metadata["questionIds"] = succeeded
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to apply bulk action"})
+			return
+		}
+
+		// One audit entry covers the whole batch.
+		audit(ctx, pool, userID, role, "instructor.questions.bulk."+req.Action, "question", "", metadata)
+
+		if req.Action == bulkActionSetStatus && QuestionStatus(value.(string)) == QuestionPublished {
+			for _, id := range succeeded {
+				notifyFixedQuestionReports(ctx, pool, id)
+			}
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+}
+
+func stringValue(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func prefixColumns(conds []string, prefix string) []string {
+	out := make([]string, len(conds))
+	for i, cond := range conds {
+		out[i] = prefix + cond
+	}
+	return out
+}
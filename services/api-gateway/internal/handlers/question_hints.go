@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/hints"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+type ReplaceHintsRequest struct {
+	// Hints in reveal order; an empty list removes them.
+	Hints []string `json:"hints"`
+}
+
+// insertQuestionHints validates and stores a question's hints in reveal
+// order. It writes the error response and returns false on failure.
+func insertQuestionHints(c *gin.Context, ctx context.Context, tx pgx.Tx, questionID string, texts []string) ([]string, bool) {
+	if len(texts) > hints.MaxPerQuestion {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "at most " + strconv.Itoa(hints.MaxPerQuestion) + " hints are allowed"})
+		return nil, false
+	}
+	out := make([]string, 0, len(texts))
+	for i, text := range texts {
+		res, ok := processContent(c, "hint", text)
+		if !ok {
+			return nil, false
+		}
+		if res.Normalized == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "hint text is required"})
+			return nil, false
+		}
+		_, err := tx.Exec(ctx, `insert into question_bank_hints (id, question_id, order_index, text) values ($1,$2,$3,$4)`, util.NewID("qh"), questionID, i, res.Normalized)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save hints"})
+			return nil, false
+		}
+		out = append(out, res.Normalized)
+	}
+	return out, true
+}
+
+func registerQuestionHintRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	// Replaces a question's hints. Sessions already started keep the hints
+	// they were drawn with.
+	r.PUT("/instructor/questions/:questionId/hints", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+
+		qid := c.Param("questionId")
+		var req ReplaceHintsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+
+		ctx := context.Background()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save hints"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		query := `update question_bank_questions set updated_at=now(), updated_by_user_id=$2, revision=revision+1 where id=$1`
+		if role != "admin" {
+			query += ` and created_by_user_id=$2`
+		}
+		cmd, err := tx.Exec(ctx, query, qid, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save hints"})
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+		if _, err := tx.Exec(ctx, `delete from question_bank_hints where question_id=$1`, qid); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save hints"})
+			return
+		}
+		saved, ok := insertQuestionHints(c, ctx, tx, qid, req.Hints)
+		if !ok {
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save hints"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"hints": saved})
+	})
+}
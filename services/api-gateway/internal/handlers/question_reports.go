@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+const (
+	questionReportOpen     = "open"
+	questionReportResolved = "resolved"
+
+	questionReportOutcomeFixed      = "fixed"
+	questionReportOutcomeNotAnError = "not_an_error"
+	questionReportOutcomeWontFix    = "wont_fix"
+
+	maxQuestionReportMessageLength = 2000
+)
+
+func validQuestionReportCategory(v string) bool {
+	switch v {
+	case "wrong_answer_key", "typo", "unclear", "broken_media", "other":
+		return true
+	}
+	return false
+}
+
+func validQuestionReportOutcome(v string) bool {
+	switch v {
+	case questionReportOutcomeFixed, questionReportOutcomeNotAnError, questionReportOutcomeWontFix:
+		return true
+	}
+	return false
+}
+
+type CreateQuestionReportRequest struct {
+	// Category is one of wrong_answer_key, typo, unclear, broken_media, other.
+	Category string `json:"category"`
+	Message  string `json:"message"`
+}
+
+type StudentQuestionReport struct {
+	ReportID   string  `json:"reportId"`
+	QuestionID string  `json:"questionId"`
+	SessionID  *string `json:"sessionId"`
+	Category   string  `json:"category"`
+	Message    string  `json:"message"`
+	Status     string  `json:"status"`
+	Outcome    *string `json:"outcome"`
+	CreatedAt  string  `json:"createdAt"`
+	ResolvedAt *string `json:"resolvedAt"`
+}
+
+type ListStudentQuestionReportsResponse struct {
+	Items   []StudentQuestionReport `json:"items"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+	HasMore bool                    `json:"hasMore"`
+}
+
+type QuestionReportItem struct {
+	ID               string         `json:"id"`
+	QuestionID       string         `json:"questionId"`
+	QuestionPath     string         `json:"questionPath"`
+	QuestionBankID   *string        `json:"questionBankId"`
+	QuestionPrompt   string         `json:"questionPrompt"`
+	QuestionStatus   string         `json:"questionStatus"`
+	Status           string         `json:"status"`
+	ReportCount      int            `json:"reportCount"`
+	Categories       map[string]int `json:"categories"`
+	FirstReportedAt  string         `json:"firstReportedAt"`
+	LastReportedAt   string         `json:"lastReportedAt"`
+	Outcome          *string        `json:"outcome"`
+	ResolutionNote   *string        `json:"resolutionNote"`
+	ResolvedByUserID *string        `json:"resolvedByUserId"`
+	ResolvedAt       *string        `json:"resolvedAt"`
+	NotifiedAt       *string        `json:"notifiedAt"`
+}
+
+type ListQuestionReportsResponse struct {
+	Items   []QuestionReportItem `json:"items"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	HasMore bool                 `json:"hasMore"`
+}
+
+type QuestionReportSubmission struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"userId"`
+	SessionID *string         `json:"sessionId"`
+	Category  string          `json:"category"`
+	Message   string          `json:"message"`
+	Snapshot  json.RawMessage `json:"questionSnapshot"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+type QuestionReportDetailResponse struct {
+	QuestionReportItem
+	Submissions []QuestionReportSubmission `json:"submissions"`
+}
+
+type ResolveQuestionReportRequest struct {
+	// Outcome is one of fixed, not_an_error, wont_fix.
+	Outcome string `json:"outcome"`
+	Note    string `json:"note"`
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC().Format(time.RFC3339)
+	return &v
+}
+
+const questionReportItemColumns = `r.id, r.question_id, q.package_id, q.prompt, q.status, r.status, r.report_count,
+	coalesce((select json_object_agg(x.category, x.n) from (select category, count(*) as n from question_report_submissions where report_id=r.id group by category) x), '{}'::json),
+	r.first_reported_at, r.last_reported_at, r.outcome, r.resolution_note, r.resolved_by_user_id, r.resolved_at, r.notified_at`
+
+func scanQuestionReportItem(row pgx.Row) (QuestionReportItem, error) {
+	var it QuestionReportItem
+	var categoriesRaw []byte
+	var firstAt time.Time
+	var lastAt time.Time
+	var resolvedAt *time.Time
+	var notifiedAt *time.Time
+	err := row.Scan(&it.ID, &it.QuestionID, &it.QuestionBankID, &it.QuestionPrompt, &it.QuestionStatus, &it.Status, &it.ReportCount,
+		&categoriesRaw, &firstAt, &lastAt, &it.Outcome, &it.ResolutionNote, &it.ResolvedByUserID, &resolvedAt, &notifiedAt)
+	if err != nil {
+		return it, err
+	}
+	it.QuestionPath = "/instructor/questions/" + it.QuestionID
+	it.Categories = map[string]int{}
+	_ = json.Unmarshal(categoriesRaw, &it.Categories)
+	it.FirstReportedAt = firstAt.UTC().Format(time.RFC3339)
+	it.LastReportedAt = lastAt.UTC().Format(time.RFC3339)
+	it.ResolvedAt = formatOptionalTime(resolvedAt)
+	it.NotifiedAt = formatOptionalTime(notifiedAt)
+	return it, nil
+}
+
+func registerQuestionReportRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	studentAuth := auth.RequirePortalAuth(pool, "student", "student")
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	// Students report the question as they saw it in a session (during practice or review).
+	r.POST("/practice-sessions/:sessionId/questions/:questionId/report", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		sessionID := c.Param("sessionId")
+		questionID := c.Param("questionId")
+
+		var req CreateQuestionReportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		category := strings.TrimSpace(req.Category)
+		if !validQuestionReportCategory(category) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid category"})
+			return
+		}
+		message := strings.TrimSpace(req.Message)
+		if len(message) > maxQuestionReportMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "message is too long"})
+			return
+		}
+
+		ctx := context.Background()
+		var snapshotRaw []byte
+		if err := pool.QueryRow(ctx, `select questions_snapshot from practice_sessions where id=$1 and user_id=$2`, sessionID, userID).Scan(&snapshotRaw); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return
+		}
+		var snapshot []practiceQuestionSnapshot
+		_ = json.Unmarshal(snapshotRaw, &snapshot)
+		var reported *practiceQuestionSnapshot
+		for i := range snapshot {
+			if snapshot[i].ID == questionID {
+				reported = &snapshot[i]
+				break
+			}
+		}
+		if reported == nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found in session"})
+			return
+		}
+		// Keep the question as the student saw it; triage compares it with the current version.
+		reportedJSON, _ := json.Marshal(reported)
+
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit report"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		var exists bool
+		if err := tx.QueryRow(ctx, `select exists(select 1 from question_bank_questions where id=$1)`, questionID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "question cannot be reported"})
+			return
+		}
+
+		// Reports are deduplicated per question: join the open report or start one.
+		var reportID string
+		err = tx.QueryRow(ctx, `insert into question_reports (id, question_id, status) values ($1,$2,$3)
+			on conflict (question_id) where status='open' do update set last_reported_at=question_reports.last_reported_at
+			returning id`, util.NewID("qrp"), questionID, questionReportOpen).Scan(&reportID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit report"})
+			return
+		}
+
+		var inserted bool
+		err = tx.QueryRow(ctx, `insert into question_report_submissions (id, report_id, user_id, session_id, category, message, question_snapshot)
+			values ($1,$2,$3,$4,$5,$6,$7)
+			on conflict (report_id, user_id) do update set category=excluded.category, message=excluded.message,
+				session_id=excluded.session_id, question_snapshot=excluded.question_snapshot, updated_at=now()
+			returning (xmax = 0)`, util.NewID("qrs"), reportID, userID, sessionID, category, message, reportedJSON).Scan(&inserted)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit report"})
+			return
+		}
+		increment := 0
+		if inserted {
+			increment = 1
+		}
+		if _, err := tx.Exec(ctx, `update question_reports set report_count=report_count+$2, last_reported_at=now() where id=$1`, reportID, increment); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit report"})
+			return
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to submit report"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "reportId": reportID})
+	})
+
+	r.GET("/student/question-reports", studentAuth, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		limit, offset := parseListParams(c)
+
+		rows, err := pool.Query(context.Background(), `select r.id, r.question_id, s.session_id, s.category, s.message, r.status, r.outcome, s.created_at, r.resolved_at
+			from question_report_submissions s join question_reports r on r.id=s.report_id
+			where s.user_id=$1 order by s.created_at desc limit $2 offset $3`, userID, limit+1, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list reports"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]StudentQuestionReport, 0, limit)
+		for rows.Next() {
+			var it StudentQuestionReport
+			var createdAt time.Time
+			var resolvedAt *time.Time
+			if err := rows.Scan(&it.ReportID, &it.QuestionID, &it.SessionID, &it.Category, &it.Message, &it.Status, &it.Outcome, &createdAt, &resolvedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list reports"})
+				return
+			}
+			it.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			it.ResolvedAt = formatOptionalTime(resolvedAt)
+			items = append(items, it)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListStudentQuestionReportsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// Triage queue. Instructors see reports on their own questions; admins see all.
+	// Filters: questionBankId, status (default open), category.
+	r.GET("/instructor/question-reports", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		limit, offset := parseListParams(c)
+
+		status := strings.TrimSpace(c.Query("status"))
+		if status == "" {
+			status = questionReportOpen
+		}
+		args := []any{}
+		where := []string{liveQuestionSQL("q")}
+		if status != "all" {
+			args = append(args, status)
+			where = append(where, "r.status="+sqlParam(len(args)))
+		}
+		if v := strings.TrimSpace(c.Query("questionBankId")); v != "" {
+			args = append(args, v)
+			where = append(where, "q.package_id="+sqlParam(len(args)))
+		}
+		if v := strings.TrimSpace(c.Query("category")); v != "" {
+			args = append(args, v)
+			where = append(where, "exists (select 1 from question_report_submissions s where s.report_id=r.id and s.category="+sqlParam(len(args))+")")
+		}
+		if role != "admin" {
+			args = append(args, userID)
+			where = append(where, "q.created_by_user_id="+sqlParam(len(args)))
+		}
+
+		query := `select ` + questionReportItemColumns + `
+			from question_reports r join question_bank_questions q on q.id=r.question_id
+			where ` + strings.Join(where, " and ") + `
+			order by r.report_count desc, r.last_reported_at desc
+			limit ` + sqlParam(len(args)+1) + ` offset ` + sqlParam(len(args)+2)
+		args = append(args, limit+1, offset)
+
+		rows, err := pool.Query(context.Background(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list reports"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]QuestionReportItem, 0, limit)
+		for rows.Next() {
+			it, err := scanQuestionReportItem(rows)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list reports"})
+				return
+			}
+			items = append(items, it)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListQuestionReportsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	r.GET("/instructor/question-reports/:reportId", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		reportID := c.Param("reportId")
+		ctx := context.Background()
+
+		query := `select ` + questionReportItemColumns + ` from question_reports r join question_bank_questions q on q.id=r.question_id where r.id=$1`
+		args := []any{reportID}
+		if role != "admin" {
+			query += ` and q.created_by_user_id=$2`
+			args = append(args, userID)
+		}
+		item, err := scanQuestionReportItem(pool.QueryRow(ctx, query, args...))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "report not found"})
+			return
+		}
+
+		rows, err := pool.Query(ctx, `select id, user_id, session_id, category, message, question_snapshot, created_at
+			from question_report_submissions where report_id=$1 order by created_at asc`, reportID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load report"})
+			return
+		}
+		defer rows.Close()
+
+		resp := QuestionReportDetailResponse{QuestionReportItem: item, Submissions: []QuestionReportSubmission{}}
+		for rows.Next() {
+			var s QuestionReportSubmission
+			var snapshot []byte
+			var createdAt time.Time
+			if err := rows.Scan(&s.ID, &s.UserID, &s.SessionID, &s.Category, &s.Message, &snapshot, &createdAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load report"})
+				return
+			}
+			if len(snapshot) == 0 {
+				snapshot = []byte("null")
+			}
+			s.Snapshot = json.RawMessage(snapshot)
+			s.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			resp.Submissions = append(resp.Submissions, s)
+		}
+		c.JSON(http.StatusOK, resp)
+	})
+
+	r.POST("/instructor/question-reports/:reportId/resolve", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		reportID := c.Param("reportId")
+
+		var req ResolveQuestionReportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		outcome := strings.TrimSpace(req.Outcome)
+		if !validQuestionReportOutcome(outcome) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "outcome must be fixed, not_an_error or wont_fix"})
+			return
+		}
+		note := strings.TrimSpace(req.Note)
+
+		ctx := context.Background()
+		var questionID string
+		query := `select r.question_id from question_reports r join question_bank_questions q on q.id=r.question_id where r.id=$1`
+		args := []any{reportID}
+		if role != "admin" {
+			query += ` and q.created_by_user_id=$2`
+			args = append(args, userID)
+		}
+		if err := pool.QueryRow(ctx, query, args...).Scan(&questionID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "report not found"})
+			return
+		}
+
+		cmd, err := pool.Exec(ctx, `update question_reports set status=$1, outcome=$2, resolution_note=$3, resolved_by_user_id=$4, resolved_at=now()
+			where id=$5 and status=$6`, questionReportResolved, outcome, note, userID, reportID, questionReportOpen)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to resolve report"})
+			return
+		}
+		if cmd.RowsAffected() == 0 {
+			c.JSON(http.StatusConflict, gin.H{"message": "report is already resolved"})
+			return
+		}
+
+		// A fix on an already-published question is live now; otherwise
+		// reporters hear about it when the question is next published.
+		if outcome == questionReportOutcomeFixed {
+			notifyFixedQuestionReports(ctx, pool, questionID)
+		}
+
+		audit(ctx, pool, userID, role, "instructor.question_reports.resolve", "question_report", reportID, gin.H{"questionId": questionID, "outcome": outcome})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,753 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+const (
+	reviewDecisionApprove        = "approve"
+	reviewDecisionRequestChanges = "request_changes"
+)
+
+// SLA states reported by the review queue. An item is due soon once it has used
+// three quarters of its SLA.
+const (
+	reviewSLAOK      = "ok"
+	reviewSLADueSoon = "due_soon"
+	reviewSLAOverdue = "overdue"
+)
+
+type QuestionReviewComment struct {
+	ID               string  `json:"id"`
+	ParentID         *string `json:"parentId"`
+	Revision         int     `json:"revision"`
+	Round            int     `json:"round"`
+	Anchor           *string `json:"anchor"`
+	AuthorUserID     string  `json:"authorUserId"`
+	AuthorEmail      string  `json:"authorEmail"`
+	Body             string  `json:"body"`
+	IsResolved       bool    `json:"isResolved"`
+	ResolvedByUserID *string `json:"resolvedByUserId"`
+	ResolvedAt       *string `json:"resolvedAt"`
+	CreatedAt        string  `json:"createdAt"`
+}
+
+type QuestionReviewDecision struct {
+	ReviewerUserID string `json:"reviewerUserId"`
+	ReviewerEmail  string `json:"reviewerEmail"`
+	Round          int    `json:"round"`
+	Revision       int    `json:"revision"`
+	Decision       string `json:"decision"`
+	Note           string `json:"note"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type QuestionReviewer struct {
+	UserID     string `json:"userId"`
+	Email      string `json:"email"`
+	AssignedAt string `json:"assignedAt"`
+}
+
+type QuestionReviewResponse struct {
+	QuestionID           string                   `json:"questionId"`
+	Status               QuestionStatus           `json:"status"`
+	Revision             int                      `json:"revision"`
+	Round                int                      `json:"round"`
+	SubmittedForReviewAt *string                  `json:"submittedForReviewAt"`
+	RequiredApprovals    int                      `json:"requiredApprovals"`
+	Approvals            int                      `json:"approvals"`
+	UnresolvedCount      int                      `json:"unresolvedCount"`
+	Reviewers            []QuestionReviewer       `json:"reviewers"`
+	Decisions            []QuestionReviewDecision `json:"decisions"`
+	Comments             []QuestionReviewComment  `json:"comments"`
+}
+
+type CreateQuestionReviewCommentRequest struct {
+	Body     string  `json:"body"`
+	ParentID *string `json:"parentId"`
+	// Anchor names the part of the question a thread is about: "prompt",
+	// "explanation", "choices" or "choice:<choiceId>".
+	Anchor *string `json:"anchor"`
+}
+
+type AssignQuestionReviewerRequest struct {
+	UserID string `json:"userId"`
+}
+
+type UpdateQuestionBankReviewPolicyRequest struct {
+	// RequiredApprovals overrides QUESTION_REVIEW_REQUIRED_APPROVALS; null restores the default.
+	RequiredApprovals *int `json:"requiredApprovals"`
+}
+
+type ReviewQueueItem struct {
+	QuestionID           string   `json:"questionId"`
+	QuestionBankID       *string  `json:"questionBankId"`
+	QuestionBankName     *string  `json:"questionBankName"`
+	Prompt               string   `json:"prompt"`
+	Status               string   `json:"status"`
+	CreatedByUserID      *string  `json:"createdByUserId"`
+	Revision             int      `json:"revision"`
+	Round                int      `json:"round"`
+	SubmittedForReviewAt string   `json:"submittedForReviewAt"`
+	AgeHours             int      `json:"ageHours"`
+	SLAHours             int      `json:"slaHours"`
+	SLADueAt             string   `json:"slaDueAt"`
+	SLAState             string   `json:"slaState"`
+	Approvals            int      `json:"approvals"`
+	RequiredApprovals    int      `json:"requiredApprovals"`
+	UnresolvedComments   int      `json:"unresolvedComments"`
+	ReviewerUserIDs      []string `json:"reviewerUserIds"`
+}
+
+type ReviewQueueResponse struct {
+	Items   []ReviewQueueItem `json:"items"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	HasMore bool              `json:"hasMore"`
+}
+
+func defaultRequiredApprovals() int {
+	n := envInt("QUESTION_REVIEW_REQUIRED_APPROVALS", 1)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func reviewSLAHours() int {
+	n := envInt("QUESTION_REVIEW_SLA_HOURS", 48)
+	if n < 1 {
+		return 48
+	}
+	return n
+}
+
+func requiredApprovals(bankOverride *int) int {
+	if bankOverride != nil && *bankOverride >= 1 {
+		return *bankOverride
+	}
+	return defaultRequiredApprovals()
+}
+
+// approvedSQL is a condition on a question_bank_questions row (alias q) that
+// holds when its current revision has the approvals its bank requires in the
+// current review round, the bar the approve decision publishes at.
+func approvedSQL(q string) string {
+	return `((select count(*) from question_review_decisions d
+		where d.question_id=` + q + `.id and d.round=` + q + `.review_round and d.revision=` + q + `.revision and d.decision='` + reviewDecisionApprove + `')
+		>= coalesce((select b.required_approvals from question_banks b where b.id=` + q + `.package_id and b.required_approvals >= 1), ` + strconv.Itoa(defaultRequiredApprovals()) + `))`
+}
+
+func reviewSLAState(age time.Duration, slaHours int) string {
+	sla := time.Duration(slaHours) * time.Hour
+	switch {
+	case age >= sla:
+		return reviewSLAOverdue
+	case age*4 >= sla*3:
+		return reviewSLADueSoon
+	}
+	return reviewSLAOK
+}
+
+func validReviewAnchor(anchor string) bool {
+	switch anchor {
+	case "prompt", "explanation", "choices", "stimulus":
+		return true
+	}
+	return strings.HasPrefix(anchor, "choice:") && len(anchor) > len("choice:")
+}
+
+// canAccessQuestionReview: admins see every thread, instructors only their own questions'.
+func canAccessQuestionReview(ctx context.Context, pool *pgxpool.Pool, questionID string, userID string, role string) (bool, bool) {
+	var createdBy *string
+	if err := pool.QueryRow(ctx, `select created_by_user_id from question_bank_questions where id=$1`, questionID).Scan(&createdBy); err != nil {
+		return false, false
+	}
+	if role == "admin" {
+		return true, true
+	}
+	return true, createdBy != nil && *createdBy == userID
+}
+
+func registerQuestionReviewRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+	requireAdmin := auth.RequirePortalAuth(pool, "admin", "admin")
+
+	// Review threads (authors and admins).
+	{
+		r.GET("/instructor/questions/:questionId/review", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			qid := c.Param("questionId")
+			ctx := context.Background()
+
+			found, allowed := canAccessQuestionReview(ctx, pool, qid, userID, role)
+			if !found || !allowed {
+				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+				return
+			}
+
+			var status string
+			var revision int
+			var round int
+			var submittedAt *time.Time
+			var bankRequired *int
+			err := pool.QueryRow(ctx, `select q.status, q.revision, q.review_round, q.submitted_for_review_at, b.required_approvals
+				from question_bank_questions q left join question_banks b on b.id=q.package_id where q.id=$1`, qid).
+				Scan(&status, &revision, &round, &submittedAt, &bankRequired)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+				return
+			}
+
+			resp := QuestionReviewResponse{
+				QuestionID:        qid,
+				Status:            QuestionStatus(status),
+				Revision:          revision,
+				Round:             round,
+				RequiredApprovals: requiredApprovals(bankRequired),
+				Reviewers:         []QuestionReviewer{},
+				Decisions:         []QuestionReviewDecision{},
+				Comments:          []QuestionReviewComment{},
+			}
+			if submittedAt != nil {
+				v := submittedAt.UTC().Format(time.RFC3339)
+				resp.SubmittedForReviewAt = &v
+			}
+
+			rows, err := pool.Query(ctx, `select a.reviewer_user_id, u.email, a.assigned_at
+				from question_review_assignments a join users u on u.id=a.reviewer_user_id
+				where a.question_id=$1 order by a.assigned_at asc`, qid)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load reviewers"})
+				return
+			}
+			for rows.Next() {
+				var rv QuestionReviewer
+				var assignedAt time.Time
+				if err := rows.Scan(&rv.UserID, &rv.Email, &assignedAt); err != nil {
+					rows.Close()
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load reviewers"})
+					return
+				}
+				rv.AssignedAt = assignedAt.UTC().Format(time.RFC3339)
+				resp.Reviewers = append(resp.Reviewers, rv)
+			}
+			rows.Close()
+
+			rows, err = pool.Query(ctx, `select d.reviewer_user_id, u.email, d.round, d.revision, d.decision, d.note, d.created_at
+				from question_review_decisions d join users u on u.id=d.reviewer_user_id
+				where d.question_id=$1 order by d.round desc, d.created_at asc`, qid)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load decisions"})
+				return
+			}
+			for rows.Next() {
+				var d QuestionReviewDecision
+				var createdAt time.Time
+				if err := rows.Scan(&d.ReviewerUserID, &d.ReviewerEmail, &d.Round, &d.Revision, &d.Decision, &d.Note, &createdAt); err != nil {
+					rows.Close()
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load decisions"})
+					return
+				}
+				d.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+				if d.Round == round && d.Revision == revision && d.Decision == reviewDecisionApprove {
+					resp.Approvals++
+				}
+				resp.Decisions = append(resp.Decisions, d)
+			}
+			rows.Close()
+
+			rows, err = pool.Query(ctx, `select c.id, c.parent_id, c.revision, c.round, c.anchor, c.author_user_id, u.email, c.body, c.is_resolved, c.resolved_by_user_id, c.resolved_at, c.created_at
+				from question_review_comments c join users u on u.id=c.author_user_id
+				where c.question_id=$1 order by c.created_at asc`, qid)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load comments"})
+				return
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var cm QuestionReviewComment
+				var resolvedAt *time.Time
+				var createdAt time.Time
+				if err := rows.Scan(&cm.ID, &cm.ParentID, &cm.Revision, &cm.Round, &cm.Anchor, &cm.AuthorUserID, &cm.AuthorEmail, &cm.Body, &cm.IsResolved, &cm.ResolvedByUserID, &resolvedAt, &createdAt); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load comments"})
+					return
+				}
+				if resolvedAt != nil {
+					v := resolvedAt.UTC().Format(time.RFC3339)
+					cm.ResolvedAt = &v
+				}
+				cm.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+				if cm.ParentID == nil && !cm.IsResolved {
+					resp.UnresolvedCount++
+				}
+				resp.Comments = append(resp.Comments, cm)
+			}
+
+			c.JSON(http.StatusOK, resp)
+		})
+
+		r.POST("/instructor/questions/:questionId/review-comments", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			qid := c.Param("questionId")
+
+			var req CreateQuestionReviewCommentRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+			body := strings.TrimSpace(req.Body)
+			if body == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "body is required"})
+				return
+			}
+			anchor := nilIfEmptyPtr(req.Anchor)
+			if anchor != nil && !validReviewAnchor(*anchor) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid anchor"})
+				return
+			}
+
+			ctx := context.Background()
+			found, allowed := canAccessQuestionReview(ctx, pool, qid, userID, role)
+			if !found || !allowed {
+				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+				return
+			}
+
+			// Replies attach to the thread root and inherit its anchor.
+			parentID := nilIfEmptyPtr(req.ParentID)
+			if parentID != nil {
+				var rootID string
+				var rootAnchor *string
+				err := pool.QueryRow(ctx, `select coalesce(p.parent_id, p.id), coalesce(r.anchor, p.anchor)
+					from question_review_comments p left join question_review_comments r on r.id=p.parent_id
+					where p.id=$1 and p.question_id=$2`, *parentID, qid).Scan(&rootID, &rootAnchor)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "parent comment not found"})
+					return
+				}
+				parentID = &rootID
+				anchor = rootAnchor
+			}
+
+			id := util.NewID("rvc")
+			var revision int
+			var round int
+			var createdAt time.Time
+			err := pool.QueryRow(ctx, `insert into question_review_comments (id, question_id, parent_id, revision, round, anchor, author_user_id, body)
+				select $1, q.id, $3, q.revision, q.review_round, $4, $5, $6 from question_bank_questions q where q.id=$2
+				returning revision, round, created_at`, id, qid, parentID, anchor, userID, body).Scan(&revision, &round, &createdAt)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add comment"})
+				return
+			}
+			if parentID != nil {
+				// A reply reopens a resolved thread.
+				_, _ = pool.Exec(ctx, `update question_review_comments set is_resolved=false, resolved_by_user_id=null, resolved_at=null, updated_at=now() where id=$1`, *parentID)
+			}
+
+			c.JSON(http.StatusOK, QuestionReviewComment{
+				ID:           id,
+				ParentID:     parentID,
+				Revision:     revision,
+				Round:        round,
+				Anchor:       anchor,
+				AuthorUserID: userID,
+				Body:         body,
+				CreatedAt:    createdAt.UTC().Format(time.RFC3339),
+			})
+		})
+
+		setResolved := func(resolved bool) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				userID, ok := auth.GetUserID(c)
+				if !ok {
+					c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+					return
+				}
+				role, _ := auth.GetRole(c)
+				qid := c.Param("questionId")
+				ctx := context.Background()
+
+				found, allowed := canAccessQuestionReview(ctx, pool, qid, userID, role)
+				if !found || !allowed {
+					c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+					return
+				}
+
+				query := `update question_review_comments set is_resolved=true, resolved_by_user_id=$3, resolved_at=now(), updated_at=now()
+					where id=$1 and question_id=$2 and parent_id is null`
+				args := []any{c.Param("commentId"), qid, userID}
+				if !resolved {
+					query = `update question_review_comments set is_resolved=false, resolved_by_user_id=null, resolved_at=null, updated_at=now()
+						where id=$1 and question_id=$2 and parent_id is null`
+					args = args[:2]
+				}
+				cmd, err := pool.Exec(ctx, query, args...)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update comment"})
+					return
+				}
+				if cmd.RowsAffected() == 0 {
+					c.JSON(http.StatusNotFound, gin.H{"message": "thread not found"})
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{"ok": true})
+			}
+		}
+
+		r.POST("/instructor/questions/:questionId/review-comments/:commentId/resolve", requireInstructorOrAdmin, setResolved(true))
+		r.POST("/instructor/questions/:questionId/review-comments/:commentId/reopen", requireInstructorOrAdmin, setResolved(false))
+	}
+
+	// Admin reviewers: decisions, assignment, policy and the queue.
+	{
+		decide := func(decision string) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				userID, ok := auth.GetUserID(c)
+				if !ok {
+					c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+					return
+				}
+				role, _ := auth.GetRole(c)
+				qid := c.Param("questionId")
+
+				var body struct {
+					Note string `json:"note"`
+				}
+				_ = c.ShouldBindJSON(&body)
+				note := strings.TrimSpace(body.Note)
+
+				ctx := context.Background()
+				tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record review"})
+					return
+				}
+				defer func() { _ = tx.Rollback(ctx) }()
+
+				var status string
+				var revision int
+				var round int
+				var bankRequired *int
+				err = tx.QueryRow(ctx, `select q.status, q.revision, q.review_round, b.required_approvals
+					from question_bank_questions q left join question_banks b on b.id=q.package_id
+					where q.id=$1 for update of q`, qid).Scan(&status, &revision, &round, &bankRequired)
+				if err != nil {
+					c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+					return
+				}
+				if status != string(QuestionInReview) {
+					c.JSON(http.StatusConflict, gin.H{"message": "question is not in review"})
+					return
+				}
+
+				_, err = tx.Exec(ctx, `insert into question_review_decisions (question_id, reviewer_user_id, round, revision, decision, note)
+					values ($1,$2,$3,$4,$5,$6)
+					on conflict (question_id, round, reviewer_user_id) do update
+					set revision=excluded.revision, decision=excluded.decision, note=excluded.note, created_at=now()`,
+					qid, userID, round, revision, decision, note)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record review"})
+					return
+				}
+
+				required := requiredApprovals(bankRequired)
+				approvals := 0
+				newStatus := status
+				if decision == reviewDecisionApprove {
+					// Only approvals of the current revision in the current round count.
+					if err := tx.QueryRow(ctx, `select count(*) from question_review_decisions where question_id=$1 and round=$2 and revision=$3 and decision=$4`,
+						qid, round, revision, reviewDecisionApprove).Scan(&approvals); err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to approve"})
+						return
+					}
+					if approvals >= required {
+						newStatus = string(QuestionPublished)
+						_, err = tx.Exec(ctx, `update question_bank_questions set status=$1, review_note='', updated_at=now(), updated_by_user_id=$2 where id=$3`, newStatus, userID, qid)
+					}
+				} else {
+					newStatus = string(QuestionNeedsChanges)
+					_, err = tx.Exec(ctx, `update question_bank_questions set status=$1, review_note=$2, updated_at=now(), updated_by_user_id=$3 where id=$4`, newStatus, note, userID, qid)
+					if err == nil && note != "" {
+						_, err = tx.Exec(ctx, `insert into question_review_comments (id, question_id, revision, round, author_user_id, body) values ($1,$2,$3,$4,$5,$6)`,
+							util.NewID("rvc"), qid, revision, round, userID, note)
+					}
+				}
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record review"})
+					return
+				}
+
+				if err := tx.Commit(ctx); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to record review"})
+					return
+				}
+
+				if newStatus == string(QuestionPublished) {
+					notifyFixedQuestionReports(ctx, pool, qid)
+				}
+				audit(ctx, pool, userID, role, "admin.questions."+decision, "question", qid, gin.H{
+					"round":     round,
+					"revision":  revision,
+					"approvals": approvals,
+					"required":  required,
+					"status":    newStatus,
+				})
+				c.JSON(http.StatusOK, gin.H{"ok": true, "status": newStatus, "approvals": approvals, "requiredApprovals": required})
+			}
+		}
+
+		r.POST("/admin/questions/:questionId/approve", requireAdmin, decide(reviewDecisionApprove))
+		r.POST("/admin/questions/:questionId/request-changes", requireAdmin, decide(reviewDecisionRequestChanges))
+
+		r.POST("/admin/questions/:questionId/reviewers", requireAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			qid := c.Param("questionId")
+
+			var req AssignQuestionReviewerRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+			reviewerID := strings.TrimSpace(req.UserID)
+			if reviewerID == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "userId is required"})
+				return
+			}
+
+			ctx := context.Background()
+			var reviewerRole string
+			if err := pool.QueryRow(ctx, `select role from users where id=$1 and deleted_at is null`, reviewerID).Scan(&reviewerRole); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "unknown user"})
+				return
+			}
+			if reviewerRole != "admin" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "reviewers must be admins"})
+				return
+			}
+
+			cmd, err := pool.Exec(ctx, `insert into question_review_assignments (question_id, reviewer_user_id, assigned_by_user_id)
+				select id, $2, $3 from question_bank_questions where id=$1
+				on conflict (question_id, reviewer_user_id) do nothing`, qid, reviewerID, userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to assign reviewer"})
+				return
+			}
+			if cmd.RowsAffected() > 0 {
+				audit(ctx, pool, userID, role, "admin.question_reviewers.assign", "question", qid, gin.H{"reviewerUserId": reviewerID})
+			}
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		r.DELETE("/admin/questions/:questionId/reviewers/:userId", requireAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			qid := c.Param("questionId")
+			reviewerID := c.Param("userId")
+
+			ctx := context.Background()
+			cmd, err := pool.Exec(ctx, `delete from question_review_assignments where question_id=$1 and reviewer_user_id=$2`, qid, reviewerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to unassign reviewer"})
+				return
+			}
+			if cmd.RowsAffected() == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"message": "assignment not found"})
+				return
+			}
+			audit(ctx, pool, userID, role, "admin.question_reviewers.unassign", "question", qid, gin.H{"reviewerUserId": reviewerID})
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		r.PUT("/admin/question-banks/:questionBankId/review-policy", requireAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			bankID := c.Param("questionBankId")
+
+			var req UpdateQuestionBankReviewPolicyRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+			if req.RequiredApprovals != nil && (*req.RequiredApprovals < 1 || *req.RequiredApprovals > 10) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "requiredApprovals must be between 1 and 10"})
+				return
+			}
+
+			ctx := context.Background()
+			cmd, err := pool.Exec(ctx, `update question_banks set required_approvals=$1, updated_at=now() where id=$2`, req.RequiredApprovals, bankID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update review policy"})
+				return
+			}
+			if cmd.RowsAffected() == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"message": "question bank not found"})
+				return
+			}
+			audit(ctx, pool, userID, role, "admin.question_banks.review_policy.update", "question_bank", bankID, req)
+			c.JSON(http.StatusOK, gin.H{"ok": true, "requiredApprovals": requiredApprovals(req.RequiredApprovals)})
+		})
+
+		// Review queue, oldest submission first.
+		// Filters: questionBankId, assignee (userId | "me" | "unassigned"), minAgeHours, maxAgeHours, slaState.
+		r.GET("/admin/review-queue", requireAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			limit, offset := parseListParams(c)
+			slaHours := reviewSLAHours()
+
+			args := []any{string(QuestionInReview)}
+			where := []string{"q.status=$1", liveQuestionSQL("q")}
+			if v := strings.TrimSpace(c.Query("questionBankId")); v != "" {
+				args = append(args, v)
+				where = append(where, "q.package_id="+sqlParam(len(args)))
+			}
+			switch assignee := strings.TrimSpace(c.Query("assignee")); assignee {
+			case "":
+			case "unassigned":
+				where = append(where, "not exists (select 1 from question_review_assignments a where a.question_id=q.id)")
+			default:
+				if assignee == "me" {
+					assignee = userID
+				}
+				args = append(args, assignee)
+				where = append(where, "exists (select 1 from question_review_assignments a where a.question_id=q.id and a.reviewer_user_id="+sqlParam(len(args))+")")
+			}
+			for _, f := range []struct {
+				key string
+				op  string
+			}{{"minAgeHours", "<="}, {"maxAgeHours", ">="}} {
+				raw := strings.TrimSpace(c.Query(f.key))
+				if raw == "" {
+					continue
+				}
+				hours, err := strconv.Atoi(raw)
+				if err != nil || hours < 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid " + f.key})
+					return
+				}
+				args = append(args, hours)
+				where = append(where, "coalesce(q.submitted_for_review_at, q.updated_at, q.created_at) "+f.op+" now() - ("+sqlParam(len(args))+" * interval '1 hour')")
+			}
+			slaFilter := strings.TrimSpace(c.Query("slaState"))
+			switch slaFilter {
+			case "", reviewSLAOK, reviewSLADueSoon, reviewSLAOverdue:
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid slaState"})
+				return
+			}
+
+			args = append(args, defaultRequiredApprovals())
+			defaultRequiredParam := sqlParam(len(args))
+			query := `select q.id, q.package_id, b.name, q.prompt, q.status, q.created_by_user_id, q.revision, q.review_round,
+					coalesce(q.submitted_for_review_at, q.updated_at, q.created_at),
+					coalesce(b.required_approvals, ` + defaultRequiredParam + `),
+					(select count(*) from question_review_decisions d where d.question_id=q.id and d.round=q.review_round and d.revision=q.revision and d.decision='approve'),
+					(select count(*) from question_review_comments rc where rc.question_id=q.id and rc.parent_id is null and rc.is_resolved=false),
+					coalesce((select json_agg(a.reviewer_user_id order by a.assigned_at) from question_review_assignments a where a.question_id=q.id), '[]'::json)
+				from question_bank_questions q
+				left join question_banks b on b.id=q.package_id
+				where ` + strings.Join(where, " and ") + `
+				order by coalesce(q.submitted_for_review_at, q.updated_at, q.created_at) asc, q.id asc`
+
+			// SLA state depends on the clock, so it is filtered after the age-ordered scan.
+			if slaFilter == "" {
+				args = append(args, limit+1, offset)
+				query += ` limit ` + sqlParam(len(args)-1) + ` offset ` + sqlParam(len(args))
+			}
+
+			ctx := context.Background()
+			rows, err := pool.Query(ctx, query, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load review queue"})
+				return
+			}
+			defer rows.Close()
+
+			now := time.Now().UTC()
+			items := make([]ReviewQueueItem, 0, limit)
+			skipped := 0
+			for rows.Next() {
+				var it ReviewQueueItem
+				var submittedAt time.Time
+				var reviewersRaw []byte
+				if err := rows.Scan(&it.QuestionID, &it.QuestionBankID, &it.QuestionBankName, &it.Prompt, &it.Status, &it.CreatedByUserID, &it.Revision, &it.Round,
+					&submittedAt, &it.RequiredApprovals, &it.Approvals, &it.UnresolvedComments, &reviewersRaw); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load review queue"})
+					return
+				}
+				age := now.Sub(submittedAt.UTC())
+				if age < 0 {
+					age = 0
+				}
+				it.SubmittedForReviewAt = submittedAt.UTC().Format(time.RFC3339)
+				it.AgeHours = int(age.Hours())
+				it.SLAHours = slaHours
+				it.SLADueAt = submittedAt.UTC().Add(time.Duration(slaHours) * time.Hour).Format(time.RFC3339)
+				it.SLAState = reviewSLAState(age, slaHours)
+				it.ReviewerUserIDs = []string{}
+				_ = json.Unmarshal(reviewersRaw, &it.ReviewerUserIDs)
+
+				if slaFilter != "" {
+					if it.SLAState != slaFilter {
+						continue
+					}
+					if skipped < offset {
+						skipped++
+						continue
+					}
+				}
+				items = append(items, it)
+				if len(items) == limit+1 {
+					break
+				}
+			}
+
+			hasMore := false
+			if len(items) > limit {
+				hasMore = true
+				items = items[:limit]
+			}
+			c.JSON(http.StatusOK, ReviewQueueResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+		})
+	}
+}
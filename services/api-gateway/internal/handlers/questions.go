@@ -12,6 +12,10 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/content"
+	"github.com/ace-platform/api-gateway/internal/irt"
+	"github.com/ace-platform/api-gateway/internal/itemstats"
+	"github.com/ace-platform/api-gateway/internal/locale"
 	"github.com/ace-platform/api-gateway/internal/util"
 )
 
@@ -34,7 +38,13 @@ type QuestionBank struct {
 	ID           string  `json:"id"`
 	Name         string  `json:"name"`
 	ExamPackageID *string `json:"examPackageId"`
+	// ExamPackageIDs lists every exam package the bank is attached to.
+	ExamPackageIDs []string `json:"examPackageIds"`
 	IsHidden     bool    `json:"isHidden"`
+	// SourceLocale is the language the bank's questions and topics are written in.
+	SourceLocale string  `json:"sourceLocale"`
+	// ClonedFromID is the bank this one was cloned from (instructor listing only).
+	ClonedFromID *string `json:"clonedFromId,omitempty"`
 	CreatedAt    string  `json:"createdAt"`
 }
 
@@ -43,6 +53,7 @@ type QuestionTopic struct {
 	QuestionBankID *string `json:"questionBankId"`
 	Name      string  `json:"name"`
 	IsHidden  bool    `json:"isHidden"`
+	Locale    string  `json:"locale,omitempty"`
 	CreatedAt string  `json:"createdAt"`
 }
 
@@ -64,6 +75,7 @@ type PublicQuestionListItem struct {
 	TopicID      *string `json:"topicId"`
 	DifficultyID string  `json:"difficultyId"`
 	Prompt       string  `json:"prompt"`
+	Locale       string  `json:"locale,omitempty"`
 }
 
 type ListQuestionsResponse struct {
@@ -80,6 +92,7 @@ type PublicQuestionResponse struct {
 	DifficultyID string                  `json:"difficultyId"`
 	Prompt       string                  `json:"prompt"`
 	Choices      []PracticeQuestionChoice `json:"choices"`
+	Locale       string                  `json:"locale"`
 }
 
 type InstructorQuestionResponse struct {
@@ -92,6 +105,14 @@ type InstructorQuestionResponse struct {
 	Status         QuestionStatus          `json:"status"`
 	CorrectChoiceID string                 `json:"correctChoiceId"`
 	Choices        []PracticeQuestionChoice `json:"choices"`
+	StimulusID     *string                 `json:"stimulusId"`
+	StimulusOrder  int                     `json:"stimulusOrder"`
+	ShuffleChoices bool                    `json:"shuffleChoices"`
+	PinnedChoiceIDs []string               `json:"pinnedChoiceIds"`
+	// Hints are revealed to students one at a time, in this order.
+	Hints          []string                `json:"hints"`
+	Stats          *itemstats.Stored       `json:"stats"`
+	IRT            *irt.Params             `json:"irt"`
 	CreatedByUserID string                 `json:"createdByUserId"`
 	UpdatedByUserID string                 `json:"updatedByUserId"`
 	CreatedAt      string                  `json:"createdAt"`
@@ -106,8 +127,17 @@ type CreateQuestionRequest struct {
 	Explanation  string  `json:"explanation"`
 	Choices      []struct {
 		Text string `json:"text"`
+		// Pinned keeps the choice in its authored position when shuffling,
+		// e.g. "All of the above" as the last choice.
+		Pinned bool `json:"pinned"`
 	} `json:"choices"`
 	CorrectChoiceIndex int `json:"correctChoiceIndex"`
+	StimulusID   *string `json:"stimulusId"`
+	StimulusOrder *int   `json:"stimulusOrder"`
+	// ShuffleChoices defaults to true.
+	ShuffleChoices *bool `json:"shuffleChoices"`
+	// Hints in reveal order, at most hints.MaxPerQuestion.
+	Hints []string `json:"hints"`
 }
 
 type UpdateQuestionRequest struct {
@@ -116,18 +146,46 @@ type UpdateQuestionRequest struct {
 	DifficultyID *string `json:"difficultyId"`
 	Prompt       *string `json:"prompt"`
 	Explanation  *string `json:"explanation"`
+	// StimulusID attaches the question to a stimulus; an empty string detaches it.
+	StimulusID   *string `json:"stimulusId"`
+	StimulusOrder *int   `json:"stimulusOrder"`
+	ShuffleChoices *bool `json:"shuffleChoices"`
 }
 
 type ReplaceChoicesRequest struct {
 	Choices []struct {
-		Text string `json:"text"`
+		Text   string `json:"text"`
+		Pinned bool   `json:"pinned"`
 	} `json:"choices"`
 	CorrectChoiceIndex int `json:"correctChoiceIndex"`
 }
 
+type QuestionPreviewRequest struct {
+	Prompt      string `json:"prompt"`
+	Explanation string `json:"explanation"`
+	Choices     []struct {
+		Text string `json:"text"`
+	} `json:"choices"`
+}
+
+type QuestionPreviewChoice struct {
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+type QuestionPreviewResponse struct {
+	Prompt          string                  `json:"prompt"`
+	PromptHTML      string                  `json:"promptHtml"`
+	Explanation     string                  `json:"explanation"`
+	ExplanationHTML string                  `json:"explanationHtml"`
+	Choices         []QuestionPreviewChoice `json:"choices"`
+}
+
 type CreateQuestionBankRequest struct {
 	Name         string `json:"name"`
 	ExamPackageID string `json:"examPackageId"`
+	// SourceLocale defaults to "en".
+	SourceLocale string `json:"sourceLocale"`
 }
 
 type CreateQuestionTopicRequest struct {
@@ -139,6 +197,7 @@ type UpdateQuestionBankRequest struct {
 	Name         *string `json:"name"`
 	ExamPackageID *string `json:"examPackageId"`
 	IsHidden      *bool   `json:"isHidden"`
+	SourceLocale  *string `json:"sourceLocale"`
 }
 
 type UpdateQuestionTopicRequest struct {
@@ -230,11 +289,34 @@ func authRequireRolesAndAudiences(pool *pgxpool.Pool, allowedRoles []string, all
 	}
 }
 
+// processContent runs authored text through the content pipeline. On failure it
+// writes a 400 naming the offending field.
+func processContent(c *gin.Context, field string, s string) (content.Result, bool) {
+	res, err := content.Process(s)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid " + field + ": " + err.Error()})
+		return content.Result{}, false
+	}
+	return res, true
+}
+
 func sqlParam(n int) string {
 	return fmt.Sprintf("$%d", n)
 }
 
 func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	registerStimulusRoutes(r, pool)
+	registerQuestionReviewRoutes(r, pool)
+	registerItemStatsRoutes(r, pool)
+	registerIRTCalibrationRoutes(r, pool)
+	registerQuestionReportRoutes(r, pool)
+	registerQuestionBulkRoutes(r, pool)
+	registerExamPackageBankRoutes(r, pool)
+	registerTrashRoutes(r, pool)
+	registerTranslationRoutes(r, pool)
+	registerStudentProfileRoutes(r, pool)
+	registerCloneJobRoutes(r, pool)
+	registerQuestionHintRoutes(r, pool)
 	// Public/student read endpoints
 	{
 		r.GET("/questions", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
@@ -244,7 +326,7 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			difficultyID := strings.TrimSpace(c.Query("difficultyId"))
 
 			args := []any{}
-			where := []string{"q.status='published'"}
+			where := []string{"q.status='published'", liveQuestionSQL("q")}
 
 			if questionBankID != "" {
 				where = append(where, "q.package_id="+sqlParam(len(args)+1))
@@ -259,15 +341,16 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				args = append(args, difficultyID)
 			}
 
-			query := `select q.id, q.package_id, q.topic_id, q.difficulty_id, q.prompt
-				from question_bank_questions q`
+			query := `select q.id, q.package_id, q.topic_id, q.difficulty_id, q.prompt, coalesce(b.source_locale, 'en')
+				from question_bank_questions q left join question_banks b on b.id=q.package_id`
 			if len(where) > 0 {
 				query += " where " + strings.Join(where, " and ")
 			}
 			query += " order by q.created_at desc limit " + sqlParam(len(args)+1) + " offset " + sqlParam(len(args)+2)
 			args = append(args, limit+1, offset)
 
-			rows, err := pool.Query(context.Background(), query, args...)
+			ctx := context.Background()
+			rows, err := pool.Query(ctx, query, args...)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list questions"})
 				return
@@ -281,15 +364,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				var top *string
 				var diff string
 				var prompt string
-				if err := rows.Scan(&id, &pkg, &top, &diff, &prompt); err != nil {
+				var sourceLocale string
+				if err := rows.Scan(&id, &pkg, &top, &diff, &prompt, &sourceLocale); err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list questions"})
 					return
 				}
-				items = append(items, PublicQuestionListItem{ID: id, QuestionBankID: pkg, TopicID: top, DifficultyID: diff, Prompt: prompt})
+				items = append(items, PublicQuestionListItem{ID: id, QuestionBankID: pkg, TopicID: top, DifficultyID: diff, Prompt: prompt, Locale: sourceLocale})
 				if len(items) == limit+1 {
 					break
 				}
 			}
+			rows.Close()
 
 			hasMore := false
 			if len(items) > limit {
@@ -297,6 +382,23 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				items = items[:limit]
 			}
 
+			userID, _ := auth.GetUserID(c)
+			sources := map[string]string{}
+			for _, item := range items {
+				sources[item.ID] = item.Locale
+			}
+			translations, err := loadTranslations(ctx, pool, translationEntityQuestion, sources, requestLocales(ctx, pool, c, userID))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+				return
+			}
+			for i := range items {
+				if tr, ok := translations[items[i].ID]; ok {
+					items[i].Prompt = localizedText(items[i].Prompt, tr.Fields.Prompt)
+					items[i].Locale = tr.Locale
+				}
+			}
+
 			c.JSON(http.StatusOK, ListQuestionsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
 		})
 
@@ -309,8 +411,11 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var top *string
 			var diff string
 			var prompt string
-			err := pool.QueryRow(ctx, `select id, package_id, topic_id, difficulty_id, prompt from question_bank_questions where id=$1 and status='published'`, qid).
-				Scan(&id, &pkg, &top, &diff, &prompt)
+			var sourceLocale string
+			err := pool.QueryRow(ctx, `select q.id, q.package_id, q.topic_id, q.difficulty_id, q.prompt, coalesce(b.source_locale, 'en')
+				from question_bank_questions q left join question_banks b on b.id=q.package_id
+				where q.id=$1 and q.status='published' and `+liveQuestionSQL("q"), qid).
+				Scan(&id, &pkg, &top, &diff, &prompt, &sourceLocale)
 			if err != nil {
 				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
 				return
@@ -333,8 +438,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				}
 				choices = append(choices, PracticeQuestionChoice{ID: cid, Text: text})
 			}
+			rows.Close()
 
-			c.JSON(http.StatusOK, PublicQuestionResponse{ID: id, QuestionBankID: pkg, TopicID: top, DifficultyID: diff, Prompt: prompt, Choices: choices})
+			userID, _ := auth.GetUserID(c)
+			localized := []practiceQuestionSnapshot{{ID: id, Prompt: prompt, Choices: choices}}
+			if err := localizeQuestionSnapshots(ctx, pool, localized, map[string]string{id: sourceLocale}, requestLocales(ctx, pool, c, userID)); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+				return
+			}
+			q := localized[0]
+			c.Header("Content-Language", q.Locale)
+			c.JSON(http.StatusOK, PublicQuestionResponse{ID: id, QuestionBankID: pkg, TopicID: top, DifficultyID: diff, Prompt: q.Prompt, Choices: q.Choices, Locale: q.Locale})
 		})
 	}
 
@@ -345,15 +459,16 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				select
 					p.id,
 					p.name,
-					(
-						select min(m.exam_package_id::text)
+					coalesce((
+						select array_agg(m.exam_package_id::text order by m.exam_package_id::text)
 						from exam_package_question_bank_packages m
 						where m.question_bank_package_id=p.id
-					) as exam_package_id,
+					), '{}') as exam_package_ids,
 					p.is_hidden,
+					p.source_locale,
 					p.created_at
 				from question_banks p
-				where p.is_hidden=false
+				where p.is_hidden=false and p.deleted_at is null
 				order by p.name asc`)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
@@ -363,14 +478,19 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			items := []QuestionBank{}
 			for rows.Next() {
 				var id, name string
-				var examPackageID *string
+				var examPackageIDs []string
 				var hidden bool
+				var sourceLocale string
 				var createdAt time.Time
-				if err := rows.Scan(&id, &name, &examPackageID, &hidden, &createdAt); err != nil {
+				if err := rows.Scan(&id, &name, &examPackageIDs, &hidden, &sourceLocale, &createdAt); err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
 					return
 				}
-				items = append(items, QuestionBank{ID: id, Name: name, ExamPackageID: examPackageID, IsHidden: hidden, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
+				var examPackageID *string
+				if len(examPackageIDs) > 0 {
+					examPackageID = &examPackageIDs[0]
+				}
+				items = append(items, QuestionBank{ID: id, Name: name, ExamPackageID: examPackageID, ExamPackageIDs: examPackageIDs, IsHidden: hidden, SourceLocale: sourceLocale, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
 			}
 			c.JSON(http.StatusOK, ListQuestionBanksResponse{Items: items})
 		})
@@ -378,16 +498,18 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		r.GET("/question-topics", auth.RequirePortalAuth(pool, "student", "student"), func(c *gin.Context) {
 			questionBankID := strings.TrimSpace(c.Query("questionBankId"))
 			args := []any{}
-			query := `select id, package_id, name, is_hidden, created_at from question_bank_topics`
-			where := []string{"is_hidden=false"}
+			query := `select question_bank_topics.id, question_bank_topics.package_id, question_bank_topics.name, question_bank_topics.is_hidden, question_bank_topics.created_at, coalesce(b.source_locale, 'en')
+				from question_bank_topics left join question_banks b on b.id=question_bank_topics.package_id`
+			where := []string{"question_bank_topics.is_hidden=false", liveTopicSQL}
 			if questionBankID != "" {
-				where = append(where, "package_id=$1")
+				where = append(where, "question_bank_topics.package_id=$1")
 				args = append(args, questionBankID)
 			}
 			query += " where " + strings.Join(where, " and ")
-			query += " order by name asc"
+			query += " order by question_bank_topics.name asc"
 
-			rows, err := pool.Query(context.Background(), query, args...)
+			ctx := context.Background()
+			rows, err := pool.Query(ctx, query, args...)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list topics"})
 				return
@@ -400,11 +522,30 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				var name string
 				var hidden bool
 				var createdAt time.Time
-				if err := rows.Scan(&id, &pkg, &name, &hidden, &createdAt); err != nil {
+				var sourceLocale string
+				if err := rows.Scan(&id, &pkg, &name, &hidden, &createdAt, &sourceLocale); err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list topics"})
 					return
 				}
-				items = append(items, QuestionTopic{ID: id, QuestionBankID: pkg, Name: name, IsHidden: hidden, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
+				items = append(items, QuestionTopic{ID: id, QuestionBankID: pkg, Name: name, IsHidden: hidden, Locale: sourceLocale, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
+			}
+			rows.Close()
+
+			userID, _ := auth.GetUserID(c)
+			sources := map[string]string{}
+			for _, item := range items {
+				sources[item.ID] = item.Locale
+			}
+			translations, err := loadTranslations(ctx, pool, translationEntityTopic, sources, requestLocales(ctx, pool, c, userID))
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load translations"})
+				return
+			}
+			for i := range items {
+				if tr, ok := translations[items[i].ID]; ok {
+					items[i].Name = localizedText(items[i].Name, tr.Fields.Name)
+					items[i].Locale = tr.Locale
+				}
 			}
 			c.JSON(http.StatusOK, ListQuestionTopicsResponse{Items: items})
 		})
@@ -462,6 +603,15 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "unknown exam package"})
 				return
 			}
+			sourceLocale := locale.Default
+			if strings.TrimSpace(req.SourceLocale) != "" {
+				loc, ok := locale.Normalize(req.SourceLocale)
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid sourceLocale"})
+					return
+				}
+				sourceLocale = loc
+			}
 			pkgID := util.NewID("pkg")
 
 			ctx := context.Background()
@@ -472,7 +622,7 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 			defer func() { _ = tx.Rollback(ctx) }()
 
-			_, err = tx.Exec(ctx, `insert into question_banks (id, name, created_by_user_id) values ($1,$2,$3)`, pkgID, name, userID)
+			_, err = tx.Exec(ctx, `insert into question_banks (id, name, created_by_user_id, source_locale) values ($1,$2,$3,$4)`, pkgID, name, userID, sourceLocale)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to create question bank"})
 				return
@@ -495,14 +645,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				select
 					p.id,
 					p.name,
-					(
-						select min(m.exam_package_id::text)
+					coalesce((
+						select array_agg(m.exam_package_id::text order by m.exam_package_id::text)
 						from exam_package_question_bank_packages m
 						where m.question_bank_package_id=p.id
-					) as exam_package_id,
+					), '{}') as exam_package_ids,
 					p.is_hidden,
+					p.source_locale,
+					p.cloned_from_id,
 					p.created_at
 				from question_banks p
+				where p.deleted_at is null
 				order by p.name asc`)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
@@ -512,14 +665,20 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			items := []QuestionBank{}
 			for rows.Next() {
 				var id, name string
-				var examPackageID *string
+				var examPackageIDs []string
 				var hidden bool
+				var sourceLocale string
+				var clonedFromID *string
 				var createdAt time.Time
-				if err := rows.Scan(&id, &name, &examPackageID, &hidden, &createdAt); err != nil {
+				if err := rows.Scan(&id, &name, &examPackageIDs, &hidden, &sourceLocale, &clonedFromID, &createdAt); err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list question banks"})
 					return
 				}
-				items = append(items, QuestionBank{ID: id, Name: name, ExamPackageID: examPackageID, IsHidden: hidden, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
+				var examPackageID *string
+				if len(examPackageIDs) > 0 {
+					examPackageID = &examPackageIDs[0]
+				}
+				items = append(items, QuestionBank{ID: id, Name: name, ExamPackageID: examPackageID, ExamPackageIDs: examPackageIDs, IsHidden: hidden, SourceLocale: sourceLocale, ClonedFromID: clonedFromID, CreatedAt: createdAt.UTC().Format(time.RFC3339)})
 			}
 			c.JSON(http.StatusOK, ListQuestionBanksResponse{Items: items})
 		})
@@ -556,6 +715,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				args = append(args, *req.IsHidden)
 				idx++
 			}
+			if req.SourceLocale != nil {
+				// A translation in the new source locale is never picked over the source text.
+				loc, ok := locale.Normalize(*req.SourceLocale)
+				if !ok {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid sourceLocale"})
+					return
+				}
+				set = append(set, "source_locale="+sqlParam(idx))
+				args = append(args, loc)
+				idx++
+			}
 			// examPackageId updates are handled by syncing the mapping table below.
 
 			if len(set) == 0 {
@@ -615,13 +785,19 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			c.JSON(http.StatusOK, gin.H{"success": true})
 		})
 
+		// Deleting moves the bank to the trash; see trash.go for restore/purge.
 		r.DELETE("/instructor/question-banks/:questionBankId", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
 			pid := strings.TrimSpace(c.Param("questionBankId"))
 			if pid == "" {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "questionBankId is required"})
 				return
 			}
-			ct, err := pool.Exec(context.Background(), `delete from question_banks where id=$1`, pid)
+			ct, err := pool.Exec(context.Background(), `update question_banks set deleted_at=now(), deleted_by_user_id=$2 where id=$1 and deleted_at is null`, pid, userID)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to delete question bank"})
 				return
@@ -662,9 +838,9 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		r.GET("/instructor/question-topics", requireInstructorOrAdmin, func(c *gin.Context) {
 			questionBankID := strings.TrimSpace(c.Query("questionBankId"))
 			args := []any{}
-			query := `select id, package_id, name, is_hidden, created_at from question_bank_topics`
+			query := `select id, package_id, name, is_hidden, created_at from question_bank_topics where ` + liveTopicSQL
 			if questionBankID != "" {
-				query += " where package_id=$1"
+				query += " and package_id=$1"
 				args = append(args, questionBankID)
 			}
 			query += " order by name asc"
@@ -740,12 +916,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		})
 
 		r.DELETE("/instructor/question-topics/:topicId", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
 			tid := strings.TrimSpace(c.Param("topicId"))
 			if tid == "" {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "topicId is required"})
 				return
 			}
-			ct, err := pool.Exec(context.Background(), `delete from question_bank_topics where id=$1`, tid)
+			ct, err := pool.Exec(context.Background(), `update question_bank_topics set deleted_at=now(), deleted_by_user_id=$2 where id=$1 and deleted_at is null`, tid, userID)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to delete topic"})
 				return
@@ -822,8 +1003,16 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
 				return
 			}
-			req.Prompt = strings.TrimSpace(req.Prompt)
-			req.Explanation = strings.TrimSpace(req.Explanation)
+			prompt, ok := processContent(c, "prompt", req.Prompt)
+			if !ok {
+				return
+			}
+			explanation, ok := processContent(c, "explanation", req.Explanation)
+			if !ok {
+				return
+			}
+			req.Prompt = prompt.Normalized
+			req.Explanation = explanation.Normalized
 			if req.Prompt == "" {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "prompt is required"})
 				return
@@ -842,6 +1031,13 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 
 			ctx := context.Background()
+			var trashed bool
+			_ = pool.QueryRow(ctx, `select exists(select 1 from question_banks where id=$1 and deleted_at is not null)
+				or exists(select 1 from question_bank_topics where id=$2 and deleted_at is not null)`, nilIfEmptyPtr(req.QuestionBankID), nilIfEmptyPtr(req.TopicID)).Scan(&trashed)
+			if trashed {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "question bank or topic is in the trash"})
+				return
+			}
 			tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create question"})
@@ -849,11 +1045,40 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			}
 			defer func() { _ = tx.Rollback(ctx) }()
 
+			// A grouped question lives in its stimulus's bank and, unless told
+			// otherwise, goes after the group's existing questions.
+			stimulusID := nilIfEmptyPtr(req.StimulusID)
+			stimulusOrder := 0
+			if stimulusID != nil {
+				var stimulusBankID string
+				if err := tx.QueryRow(ctx, `select question_bank_id from question_stimuli where id=$1`, *stimulusID).Scan(&stimulusBankID); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "stimulus not found"})
+					return
+				}
+				if req.QuestionBankID == nil {
+					req.QuestionBankID = &stimulusBankID
+				} else if *req.QuestionBankID != stimulusBankID {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "stimulus belongs to a different question bank"})
+					return
+				}
+				if req.StimulusOrder != nil {
+					stimulusOrder = *req.StimulusOrder
+				} else if err := tx.QueryRow(ctx, `select coalesce(max(stimulus_order)+1, 0) from question_bank_questions where stimulus_id=$1`, *stimulusID).Scan(&stimulusOrder); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create question"})
+					return
+				}
+			}
+
+			shuffleChoices := true
+			if req.ShuffleChoices != nil {
+				shuffleChoices = *req.ShuffleChoices
+			}
+
 			questionID := util.NewID("qst")
 			now := time.Now().UTC()
-			_, err = tx.Exec(ctx, `insert into question_bank_questions (id, package_id, topic_id, difficulty_id, prompt, explanation_text, status, created_by_user_id, updated_by_user_id)
-				values ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
-				questionID, req.QuestionBankID, req.TopicID, req.DifficultyID, req.Prompt, req.Explanation, string(QuestionDraft), userID, userID)
+			_, err = tx.Exec(ctx, `insert into question_bank_questions (id, package_id, topic_id, difficulty_id, prompt, explanation_text, status, created_by_user_id, updated_by_user_id, stimulus_id, stimulus_order, shuffle_choices)
+				values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)`,
+				questionID, req.QuestionBankID, req.TopicID, req.DifficultyID, req.Prompt, req.Explanation, string(QuestionDraft), userID, userID, stimulusID, stimulusOrder, shuffleChoices)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "failed to create question"})
 				return
@@ -861,20 +1086,28 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 			choices := make([]PracticeQuestionChoice, 0, len(req.Choices))
 			choiceIDs := make([]string, 0, len(req.Choices))
+			pinnedChoiceIDs := []string{}
 			for i, ch := range req.Choices {
-				text := strings.TrimSpace(ch.Text)
+				res, ok := processContent(c, "choice", ch.Text)
+				if !ok {
+					return
+				}
+				text := res.Normalized
 				if text == "" {
 					c.JSON(http.StatusBadRequest, gin.H{"message": "choice text is required"})
 					return
 				}
 				choiceID := util.NewID("ch")
-				_, err = tx.Exec(ctx, `insert into question_bank_choices (id, question_id, order_index, text) values ($1,$2,$3,$4)`, choiceID, questionID, i, text)
+				_, err = tx.Exec(ctx, `insert into question_bank_choices (id, question_id, order_index, text, is_pinned) values ($1,$2,$3,$4,$5)`, choiceID, questionID, i, text, ch.Pinned)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create choices"})
 					return
 				}
 				choices = append(choices, PracticeQuestionChoice{ID: choiceID, Text: text})
 				choiceIDs = append(choiceIDs, choiceID)
+				if ch.Pinned {
+					pinnedChoiceIDs = append(pinnedChoiceIDs, choiceID)
+				}
 			}
 
 			correctChoiceID := choiceIDs[req.CorrectChoiceIndex]
@@ -883,6 +1116,10 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to set correct choice"})
 				return
 			}
+			hintTexts, ok := insertQuestionHints(c, ctx, tx, questionID, req.Hints)
+			if !ok {
+				return
+			}
 
 			if err := tx.Commit(ctx); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create question"})
@@ -899,6 +1136,11 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				Status:          QuestionDraft,
 				CorrectChoiceID: correctChoiceID,
 				Choices:         choices,
+				StimulusID:      stimulusID,
+				StimulusOrder:   stimulusOrder,
+				ShuffleChoices:  shuffleChoices,
+				PinnedChoiceIDs: pinnedChoiceIDs,
+				Hints:           hintTexts,
 				CreatedByUserID: userID,
 				UpdatedByUserID: userID,
 				CreatedAt:       now.Format(time.RFC3339),
@@ -906,6 +1148,38 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			})
 		})
 
+		// Renders question content exactly as it would be stored, without saving.
+		r.POST("/instructor/questions/preview", requireInstructorOrAdmin, func(c *gin.Context) {
+			var req QuestionPreviewRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+			prompt, ok := processContent(c, "prompt", req.Prompt)
+			if !ok {
+				return
+			}
+			explanation, ok := processContent(c, "explanation", req.Explanation)
+			if !ok {
+				return
+			}
+			choices := make([]QuestionPreviewChoice, 0, len(req.Choices))
+			for _, ch := range req.Choices {
+				res, ok := processContent(c, "choice", ch.Text)
+				if !ok {
+					return
+				}
+				choices = append(choices, QuestionPreviewChoice{Text: res.Normalized, HTML: res.HTML})
+			}
+			c.JSON(http.StatusOK, QuestionPreviewResponse{
+				Prompt:          prompt.Normalized,
+				PromptHTML:      prompt.HTML,
+				Explanation:     explanation.Normalized,
+				ExplanationHTML: explanation.HTML,
+				Choices:         choices,
+			})
+		})
+
 		r.GET("/instructor/questions", requireInstructorOrAdmin, func(c *gin.Context) {
 			limit, offset := parseListParams(c)
 			status := strings.TrimSpace(c.Query("status"))
@@ -914,7 +1188,7 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			difficultyID := strings.TrimSpace(c.Query("difficultyId"))
 
 			args := []any{}
-			where := []string{"1=1"}
+			where := []string{liveQuestionSQL("q")}
 			if status != "" {
 				where = append(where, "q.status="+sqlParam(len(args)+1))
 				args = append(args, status)
@@ -931,6 +1205,9 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				where = append(where, "q.difficulty_id="+sqlParam(len(args)+1))
 				args = append(args, difficultyID)
 			}
+			if parseBoolQuery(c, "flagged") {
+				where = append(where, "exists (select 1 from question_item_stats s where s.question_id=q.id and s.is_flagged)")
+			}
 
 			query := `select q.id, q.package_id, q.topic_id, q.difficulty_id, q.prompt
 				from question_bank_questions q where ` + strings.Join(where, " and ") +
@@ -984,9 +1261,12 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var updatedBy string
 			var createdAt time.Time
 			var updatedAt time.Time
-			err := pool.QueryRow(ctx, `select id, package_id, topic_id, difficulty_id, prompt, explanation_text, status, created_by_user_id, updated_by_user_id, created_at, updated_at
+			var stimulusID *string
+			var stimulusOrder int
+			var shuffleChoices bool
+			err := pool.QueryRow(ctx, `select id, package_id, topic_id, difficulty_id, prompt, explanation_text, status, created_by_user_id, updated_by_user_id, created_at, updated_at, stimulus_id, stimulus_order, shuffle_choices
 				from question_bank_questions where id=$1`, qid).
-				Scan(&id, &pkg, &top, &diff, &prompt, &explanation, &status, &createdBy, &updatedBy, &createdAt, &updatedAt)
+				Scan(&id, &pkg, &top, &diff, &prompt, &explanation, &status, &createdBy, &updatedBy, &createdAt, &updatedAt, &stimulusID, &stimulusOrder, &shuffleChoices)
 			if err != nil {
 				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
 				return
@@ -995,7 +1275,7 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			var correctChoiceID string
 			_ = pool.QueryRow(ctx, `select choice_id from question_bank_correct_choice where question_id=$1`, id).Scan(&correctChoiceID)
 
-			rows, err := pool.Query(ctx, `select id, text from question_bank_choices where question_id=$1 order by order_index asc`, id)
+			rows, err := pool.Query(ctx, `select id, text, is_pinned from question_bank_choices where question_id=$1 order by order_index asc`, id)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
 				return
@@ -1003,14 +1283,45 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 			defer rows.Close()
 
 			choices := make([]PracticeQuestionChoice, 0)
+			pinnedChoiceIDs := []string{}
 			for rows.Next() {
 				var cid string
 				var text string
-				if err := rows.Scan(&cid, &text); err != nil {
+				var pinned bool
+				if err := rows.Scan(&cid, &text, &pinned); err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load choices"})
 					return
 				}
 				choices = append(choices, PracticeQuestionChoice{ID: cid, Text: text})
+				if pinned {
+					pinnedChoiceIDs = append(pinnedChoiceIDs, cid)
+				}
+			}
+
+			hintsByQ, err := loadQuestionHints(ctx, pool, []string{id})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load hints"})
+				return
+			}
+			hintTexts := hintsByQ[id]
+			if hintTexts == nil {
+				hintTexts = []string{}
+			}
+
+			stats, err := itemstats.Load(ctx, pool, id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load item statistics"})
+				return
+			}
+
+			irtParams, err := irt.LoadParams(ctx, pool, id, true)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load irt parameters"})
+				return
+			}
+			var currentIRT *irt.Params
+			if len(irtParams) > 0 {
+				currentIRT = &irtParams[0]
 			}
 
 			c.JSON(http.StatusOK, InstructorQuestionResponse{
@@ -1023,6 +1334,13 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				Status:          QuestionStatus(status),
 				CorrectChoiceID: correctChoiceID,
 				Choices:         choices,
+				StimulusID:      stimulusID,
+				StimulusOrder:   stimulusOrder,
+				ShuffleChoices:  shuffleChoices,
+				PinnedChoiceIDs: pinnedChoiceIDs,
+				Hints:           hintTexts,
+				Stats:           stats,
+				IRT:             currentIRT,
 				CreatedByUserID: createdBy,
 				UpdatedByUserID: updatedBy,
 				CreatedAt:       createdAt.UTC().Format(time.RFC3339),
@@ -1064,13 +1382,54 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				idx++
 			}
 			if req.Prompt != nil {
+				res, ok := processContent(c, "prompt", *req.Prompt)
+				if !ok {
+					return
+				}
+				if res.Normalized == "" {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "prompt is required"})
+					return
+				}
 				set = append(set, "prompt="+sqlParam(idx))
-				args = append(args, strings.TrimSpace(*req.Prompt))
+				args = append(args, res.Normalized)
 				idx++
 			}
 			if req.Explanation != nil {
+				res, ok := processContent(c, "explanation", *req.Explanation)
+				if !ok {
+					return
+				}
 				set = append(set, "explanation_text="+sqlParam(idx))
-				args = append(args, strings.TrimSpace(*req.Explanation))
+				args = append(args, res.Normalized)
+				idx++
+			}
+
+			ctx := context.Background()
+			if req.StimulusID != nil {
+				stimulusID := nilIfEmptyPtr(req.StimulusID)
+				if stimulusID != nil {
+					// The stimulus must live in the question's (possibly new) bank.
+					var sameBank bool
+					if err := pool.QueryRow(ctx, `select exists(
+							select 1 from question_stimuli s join question_bank_questions q on q.id=$2
+							where s.id=$1 and s.question_bank_id=coalesce($3, q.package_id))`,
+						*stimulusID, qid, req.QuestionBankID).Scan(&sameBank); err != nil || !sameBank {
+						c.JSON(http.StatusBadRequest, gin.H{"message": "stimulus not found in question bank"})
+						return
+					}
+				}
+				set = append(set, "stimulus_id="+sqlParam(idx))
+				args = append(args, stimulusID)
+				idx++
+			}
+			if req.StimulusOrder != nil {
+				set = append(set, "stimulus_order="+sqlParam(idx))
+				args = append(args, *req.StimulusOrder)
+				idx++
+			}
+			if req.ShuffleChoices != nil {
+				set = append(set, "shuffle_choices="+sqlParam(idx))
+				args = append(args, *req.ShuffleChoices)
 				idx++
 			}
 
@@ -1078,8 +1437,11 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				c.JSON(http.StatusBadRequest, gin.H{"message": "no updates"})
 				return
 			}
+			if req.Prompt != nil || req.Explanation != nil {
+				// Content edits invalidate approvals given to the previous revision.
+				set = append(set, "revision=revision+1")
+			}
 
-			ctx := context.Background()
 			query := "update question_bank_questions set " + strings.Join(set, ", ") + " where id=$1"
 			if role != "admin" {
 				query += " and created_by_user_id=$2"
@@ -1154,13 +1516,17 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 
 			choiceIDs := make([]string, 0, len(req.Choices))
 			for i, ch := range req.Choices {
-				text := strings.TrimSpace(ch.Text)
+				res, ok := processContent(c, "choice", ch.Text)
+				if !ok {
+					return
+				}
+				text := res.Normalized
 				if text == "" {
 					c.JSON(http.StatusBadRequest, gin.H{"message": "choice text is required"})
 					return
 				}
 				choiceID := util.NewID("ch")
-				_, err = tx.Exec(ctx, `insert into question_bank_choices (id, question_id, order_index, text) values ($1,$2,$3,$4)`, choiceID, qid, i, text)
+				_, err = tx.Exec(ctx, `insert into question_bank_choices (id, question_id, order_index, text, is_pinned) values ($1,$2,$3,$4,$5)`, choiceID, qid, i, text, ch.Pinned)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update choices"})
 					return
@@ -1176,7 +1542,7 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				return
 			}
 
-			_, _ = tx.Exec(ctx, `update question_bank_questions set updated_at=now(), updated_by_user_id=$2 where id=$1`, qid, userID)
+			_, _ = tx.Exec(ctx, `update question_bank_questions set updated_at=now(), updated_by_user_id=$2, revision=revision+1 where id=$1`, qid, userID)
 
 			if err := tx.Commit(ctx); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update choices"})
@@ -1202,25 +1568,13 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				role, _ := auth.GetRole(c)
 				isAdmin := role == "admin" || scope == "admin"
 
-				ctx := context.Background()
-				tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete question"})
-					return
-				}
-				defer func() { _ = tx.Rollback(ctx) }()
-
-				// Delete dependent records first.
-				_, _ = tx.Exec(ctx, `delete from question_bank_correct_choice where question_id=$1`, qid)
-				_, _ = tx.Exec(ctx, `delete from question_bank_choices where question_id=$1`, qid)
-
-				query := `delete from question_bank_questions where id=$1`
-				args := []any{qid}
+				// Questions are only trashed: practice answers keep referencing them.
+				query := `update question_bank_questions set deleted_at=now(), deleted_by_user_id=$2 where id=$1 and deleted_at is null`
+				args := []any{qid, userID}
 				if !isAdmin {
 					query += ` and created_by_user_id=$2`
-					args = append(args, userID)
 				}
-				cmd, err := tx.Exec(ctx, query, args...)
+				cmd, err := pool.Exec(context.Background(), query, args...)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete question"})
 					return
@@ -1230,11 +1584,6 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					return
 				}
 
-				if err := tx.Commit(ctx); err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete question"})
-					return
-				}
-
 				c.JSON(http.StatusOK, gin.H{"ok": true})
 			}
 		}
@@ -1252,20 +1601,37 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 					return
 				}
 				qid := c.Param("questionId")
-				query := `update question_bank_questions set status=$1, updated_at=now(), updated_by_user_id=$2 where id=$3`
+				// Trashed questions keep their status until restored.
+				query := `update question_bank_questions set status=$1, updated_at=now(), updated_by_user_id=$2 where id=$3 and ` + liveQuestionSQL("question_bank_questions")
 				args := []any{string(status), userID, qid}
 				if role != "admin" {
 					query += " and created_by_user_id=$2"
 				}
+				if status == QuestionPublished {
+					// Publishing needs the approvals review requires, as when the
+					// last approve decision publishes.
+					query += " and (status=$1 or " + approvedSQL("question_bank_questions") + ")"
+				}
 				cmd, err := pool.Exec(context.Background(), query, args...)
 				if err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update status"})
 					return
 				}
 				if cmd.RowsAffected() == 0 {
+					var exists bool
+					if status == QuestionPublished {
+						_ = pool.QueryRow(context.Background(), `select exists(select 1 from question_bank_questions where id=$1 and `+liveQuestionSQL("question_bank_questions")+`)`, qid).Scan(&exists)
+					}
+					if exists {
+						c.JSON(http.StatusConflict, gin.H{"message": "question does not have the required review approvals"})
+						return
+					}
 					c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
 					return
 				}
+				if status == QuestionPublished {
+					notifyFixedQuestionReports(context.Background(), pool, qid)
+				}
 				c.JSON(http.StatusOK, gin.H{"ok": true})
 			}
 		}
@@ -1280,7 +1646,12 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 				role, _ := auth.GetRole(c)
 				qid := c.Param("questionId")
 
-				query := `update question_bank_questions set status=$1, review_note='', updated_at=now(), updated_by_user_id=$2 where id=$3`
+				// Resubmitting after requested changes opens a new review round; earlier
+				// approvals stay on record but no longer count.
+				query := `update question_bank_questions set status=$1, review_note='', updated_at=now(), updated_by_user_id=$2,
+					review_round=case when status=$1 then review_round else review_round+1 end,
+					submitted_for_review_at=case when status=$1 then coalesce(submitted_for_review_at, now()) else now() end
+					where id=$3`
 				args := []any{string(QuestionInReview), userID, qid}
 				if role != "admin" {
 					query += " and created_by_user_id=$2"
@@ -1303,49 +1674,6 @@ func RegisterQuestionRoutes(r *gin.Engine, pool *pgxpool.Pool) {
 		r.DELETE("/admin/questions/:questionId", requireAdmin, deleteQuestion("admin"))
 		r.DELETE("/instructor/questions/:questionId", requireInstructorOrAdmin, deleteQuestion("instructor"))
 
-		r.POST("/admin/questions/:questionId/approve", requireAdmin, func(c *gin.Context) {
-			userID, ok := auth.GetUserID(c)
-			if !ok {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
-				return
-			}
-			qid := c.Param("questionId")
-			cmd, err := pool.Exec(context.Background(), `update question_bank_questions set status=$1, review_note='', updated_at=now(), updated_by_user_id=$2 where id=$3`, string(QuestionPublished), userID, qid)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to approve"})
-				return
-			}
-			if cmd.RowsAffected() == 0 {
-				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"ok": true})
-		})
-
-		r.POST("/admin/questions/:questionId/request-changes", requireAdmin, func(c *gin.Context) {
-			userID, ok := auth.GetUserID(c)
-			if !ok {
-				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
-				return
-			}
-			qid := c.Param("questionId")
-			var body struct {
-				Note string `json:"note"`
-			}
-			_ = c.ShouldBindJSON(&body)
-			note := strings.TrimSpace(body.Note)
-			cmd, err := pool.Exec(context.Background(), `update question_bank_questions set status=$1, review_note=$2, updated_at=now(), updated_by_user_id=$3 where id=$4`, string(QuestionNeedsChanges), note, userID, qid)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to request changes"})
-				return
-			}
-			if cmd.RowsAffected() == 0 {
-				c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"ok": true})
-		})
-
 		r.POST("/instructor/questions/:questionId/publish", requireInstructorOrAdmin, setStatus(QuestionPublished))
 		r.POST("/instructor/questions/:questionId/archive", requireInstructorOrAdmin, setStatus(QuestionArchived))
 		r.POST("/instructor/questions/:questionId/draft", requireInstructorOrAdmin, setStatus(QuestionDraft))
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/srs"
+)
+
+type ReviewDeckItem struct {
+	QuestionID     string  `json:"questionId"`
+	Source         string  `json:"source"`
+	EaseFactor     float64 `json:"easeFactor"`
+	IntervalDays   int     `json:"intervalDays"`
+	Repetitions    int     `json:"repetitions"`
+	Lapses         int     `json:"lapses"`
+	DueAt          string  `json:"dueAt"`
+	IsDue          bool    `json:"isDue"`
+	LastReviewedAt *string `json:"lastReviewedAt"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+type ListReviewDeckResponse struct {
+	Items   []ReviewDeckItem `json:"items"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasMore bool             `json:"hasMore"`
+}
+
+type AddReviewDeckItemRequest struct {
+	QuestionID string `json:"questionId"`
+}
+
+type ReviewDueCount struct {
+	ExamPackageID string  `json:"examPackageId"`
+	Due           int     `json:"due"`
+	Total         int     `json:"total"`
+	NextDueAt     *string `json:"nextDueAt"`
+}
+
+type ReviewDueCountsResponse struct {
+	Items []ReviewDueCount `json:"items"`
+}
+
+// reviewDeckLiveSQL limits deck items (alias d) to questions students can
+// still practice: published, live and in a visible, live bank.
+const reviewDeckLiveSQL = `exists (select 1 from question_bank_questions dq join question_banks db on db.id=dq.package_id
+	where dq.id=d.question_id and dq.status='published' and dq.deleted_at is null and db.deleted_at is null and db.is_hidden=false)`
+
+func registerReviewDeckRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireStudent := auth.RequirePortalAuth(pool, "student", "student")
+
+	// Due counts cover every enrolled package, including those with an empty deck.
+	r.GET("/student/review-deck/due-counts", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		rows, err := pool.Query(context.Background(), `
+			select e.exam_package_id::text,
+				count(distinct d.question_id) filter (where d.due_at <= now()),
+				count(distinct d.question_id),
+				min(d.due_at)
+			from user_exam_package_enrollments e
+			left join (
+				exam_package_question_bank_packages m
+				join question_bank_questions q on q.package_id=m.question_bank_package_id
+				join review_deck_items d on d.question_id=q.id
+			) on m.exam_package_id=e.exam_package_id and d.user_id=e.user_id and `+reviewDeckLiveSQL+`
+			where e.user_id=$1
+			group by e.exam_package_id
+			order by e.exam_package_id`, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to count review items"})
+			return
+		}
+		defer rows.Close()
+
+		items := []ReviewDueCount{}
+		for rows.Next() {
+			var item ReviewDueCount
+			var nextDueAt *time.Time
+			if err := rows.Scan(&item.ExamPackageID, &item.Due, &item.Total, &nextDueAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to count review items"})
+				return
+			}
+			if nextDueAt != nil {
+				v := nextDueAt.UTC().Format(time.RFC3339)
+				item.NextDueAt = &v
+			}
+			items = append(items, item)
+		}
+		c.JSON(http.StatusOK, ReviewDueCountsResponse{Items: items})
+	})
+
+	r.GET("/student/review-deck", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		limit, offset := parseListParams(c)
+
+		args := []any{userID}
+		where := []string{"d.user_id=$1", reviewDeckLiveSQL}
+		if examPackageID := strings.TrimSpace(c.Query("examPackageId")); examPackageID != "" {
+			args = append(args, examPackageID)
+			where = append(where, `exists (select 1 from question_bank_questions pq
+				join exam_package_question_bank_packages m on m.question_bank_package_id=pq.package_id
+				where pq.id=d.question_id and m.exam_package_id::text=`+sqlParam(len(args))+`)`)
+		}
+		if parseBoolQuery(c, "due") {
+			where = append(where, "d.due_at <= now()")
+		}
+		args = append(args, limit+1, offset)
+		query := `select d.question_id, d.source, d.ease_factor, d.interval_days, d.repetitions, d.lapses, d.due_at, d.due_at <= now(), d.last_reviewed_at, d.created_at
+			from review_deck_items d
+			where ` + strings.Join(where, " and ") + `
+			order by d.due_at asc, d.question_id asc
+			limit ` + sqlParam(len(args)-1) + ` offset ` + sqlParam(len(args))
+
+		rows, err := pool.Query(context.Background(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list review deck"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]ReviewDeckItem, 0, limit)
+		for rows.Next() {
+			var item ReviewDeckItem
+			var dueAt, createdAt time.Time
+			var lastReviewedAt *time.Time
+			if err := rows.Scan(&item.QuestionID, &item.Source, &item.EaseFactor, &item.IntervalDays, &item.Repetitions, &item.Lapses,
+				&dueAt, &item.IsDue, &lastReviewedAt, &createdAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list review deck"})
+				return
+			}
+			item.DueAt = dueAt.UTC().Format(time.RFC3339)
+			item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			if lastReviewedAt != nil {
+				v := lastReviewedAt.UTC().Format(time.RFC3339)
+				item.LastReviewedAt = &v
+			}
+			items = append(items, item)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListReviewDeckResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// Students can add any published question themselves; it is due right away.
+	r.POST("/student/review-deck", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		var req AddReviewDeckItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		questionID := strings.TrimSpace(req.QuestionID)
+		if questionID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "questionId is required"})
+			return
+		}
+
+		ctx := context.Background()
+		var exists bool
+		err := pool.QueryRow(ctx, `select exists(select 1 from question_bank_questions q join question_banks b on b.id=q.package_id
+			where q.id=$1 and q.status=$2 and q.deleted_at is null and b.deleted_at is null and b.is_hidden=false and `+enrolledQuestionSQL("q", "$3")+`)`,
+			questionID, string(QuestionPublished), userID).Scan(&exists)
+		if err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"message": "question not found"})
+			return
+		}
+
+		added, err := srs.Enroll(ctx, pool, userID, questionID, srs.SourceBookmarked)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to add review item"})
+			return
+		}
+		if !added {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+
+	r.DELETE("/student/review-deck/:questionId", requireStudent, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		ct, err := pool.Exec(context.Background(), `delete from review_deck_items where user_id=$1 and question_id=$2`, userID, c.Param("questionId"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to remove review item"})
+			return
+		}
+		if ct.RowsAffected() == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"message": "review item not found"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+)
+
+// recordPracticeFinished credits a finished practice session to the
+// student's coins and leaderboards. Both are best-effort: a failure is logged
+// and never fails the request, and both are idempotent, so every finish path
+// may call it.
+func recordPracticeFinished(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) {
+	if err := gamification.PracticeFinished(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("gamification: award practice session %s failed: %v", sessionID, err)
+	}
+	if err := leaderboard.RecordPractice(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("leaderboard: record practice session %s failed: %v", sessionID, err)
+	}
+}
+
+// recordExamSubmitted is recordPracticeFinished for exam sessions.
+func recordExamSubmitted(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) {
+	if err := gamification.ExamSubmitted(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("gamification: award exam session %s failed: %v", sessionID, err)
+	}
+	if err := leaderboard.RecordExam(ctx, pool, userID, sessionID); err != nil {
+		log.Printf("leaderboard: record exam session %s failed: %v", sessionID, err)
+	}
+}
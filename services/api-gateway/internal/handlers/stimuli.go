@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+// QuestionStimulus is a passage and/or media item shared by a group of questions
+// (e.g. an IELTS or SAT reading passage).
+type QuestionStimulus struct {
+	ID             string  `json:"id"`
+	QuestionBankID string  `json:"questionBankId"`
+	Title          string  `json:"title"`
+	Passage        string  `json:"passage"`
+	MediaURL       *string `json:"mediaUrl"`
+	MediaType      *string `json:"mediaType"`
+	QuestionCount  int     `json:"questionCount"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+type ListQuestionStimuliResponse struct {
+	Items   []QuestionStimulus `json:"items"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	HasMore bool               `json:"hasMore"`
+}
+
+type CreateQuestionStimulusRequest struct {
+	QuestionBankID string  `json:"questionBankId"`
+	Title          string  `json:"title"`
+	Passage        string  `json:"passage"`
+	MediaURL       *string `json:"mediaUrl"`
+	MediaType      *string `json:"mediaType"`
+}
+
+type UpdateQuestionStimulusRequest struct {
+	Title     *string `json:"title"`
+	Passage   *string `json:"passage"`
+	MediaURL  *string `json:"mediaUrl"`
+	MediaType *string `json:"mediaType"`
+}
+
+// PracticeStimulus is the student-facing view of a stimulus inside a session.
+type PracticeStimulus struct {
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Passage   string  `json:"passage"`
+	MediaURL  *string `json:"mediaUrl,omitempty"`
+	MediaType *string `json:"mediaType,omitempty"`
+}
+
+func validStimulusMediaType(v string) bool {
+	switch v {
+	case "image", "audio", "video":
+		return true
+	}
+	return false
+}
+
+func validStimulusMediaURL(v string) bool {
+	lower := strings.ToLower(v)
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://") || (strings.HasPrefix(v, "/") && !strings.HasPrefix(v, "//"))
+}
+
+func registerStimulusRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	{
+		r.POST("/instructor/stimuli", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+
+			var req CreateQuestionStimulusRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+			req.QuestionBankID = strings.TrimSpace(req.QuestionBankID)
+			if req.QuestionBankID == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "questionBankId is required"})
+				return
+			}
+			passage, ok := processContent(c, "passage", req.Passage)
+			if !ok {
+				return
+			}
+			mediaURL := nilIfEmptyPtr(req.MediaURL)
+			mediaType := nilIfEmptyPtr(req.MediaType)
+			if passage.Normalized == "" && mediaURL == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "passage or mediaUrl is required"})
+				return
+			}
+			if mediaURL != nil && !validStimulusMediaURL(*mediaURL) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid mediaUrl"})
+				return
+			}
+			if mediaURL != nil && (mediaType == nil || !validStimulusMediaType(*mediaType)) {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "mediaType must be image, audio or video"})
+				return
+			}
+
+			ctx := context.Background()
+			var exists bool
+			if err := pool.QueryRow(ctx, `select exists(select 1 from question_banks where id=$1 and deleted_at is null)`, req.QuestionBankID).Scan(&exists); err != nil || !exists {
+				c.JSON(http.StatusNotFound, gin.H{"message": "question bank not found"})
+				return
+			}
+
+			id := util.NewID("stm")
+			now := time.Now().UTC()
+			title := strings.TrimSpace(req.Title)
+			_, err := pool.Exec(ctx, `insert into question_stimuli (id, question_bank_id, title, passage, media_url, media_type, created_by_user_id, updated_by_user_id, created_at, updated_at)
+				values ($1,$2,$3,$4,$5,$6,$7,$7,$8,$8)`,
+				id, req.QuestionBankID, title, passage.Normalized, mediaURL, mediaType, userID, now)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to create stimulus"})
+				return
+			}
+
+			c.JSON(http.StatusOK, QuestionStimulus{
+				ID:             id,
+				QuestionBankID: req.QuestionBankID,
+				Title:          title,
+				Passage:        passage.Normalized,
+				MediaURL:       mediaURL,
+				MediaType:      mediaType,
+				CreatedAt:      now.Format(time.RFC3339),
+				UpdatedAt:      now.Format(time.RFC3339),
+			})
+		})
+
+		r.GET("/instructor/stimuli", requireInstructorOrAdmin, func(c *gin.Context) {
+			limit, offset := parseListParams(c)
+			questionBankID := strings.TrimSpace(c.Query("questionBankId"))
+
+			args := []any{}
+			where := []string{"1=1"}
+			if questionBankID != "" {
+				where = append(where, "s.question_bank_id="+sqlParam(len(args)+1))
+				args = append(args, questionBankID)
+			}
+			query := `select s.id, s.question_bank_id, s.title, s.passage, s.media_url, s.media_type, s.created_at, coalesce(s.updated_at, s.created_at),
+					(select count(*) from question_bank_questions q where q.stimulus_id=s.id)
+				from question_stimuli s where ` + strings.Join(where, " and ") +
+				` order by s.created_at desc limit ` + sqlParam(len(args)+1) + ` offset ` + sqlParam(len(args)+2)
+			args = append(args, limit+1, offset)
+
+			rows, err := pool.Query(context.Background(), query, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list stimuli"})
+				return
+			}
+			defer rows.Close()
+
+			items := make([]QuestionStimulus, 0, limit)
+			for rows.Next() {
+				var s QuestionStimulus
+				var createdAt time.Time
+				var updatedAt time.Time
+				if err := rows.Scan(&s.ID, &s.QuestionBankID, &s.Title, &s.Passage, &s.MediaURL, &s.MediaType, &createdAt, &updatedAt, &s.QuestionCount); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list stimuli"})
+					return
+				}
+				s.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+				s.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+				items = append(items, s)
+			}
+
+			hasMore := false
+			if len(items) > limit {
+				hasMore = true
+				items = items[:limit]
+			}
+			c.JSON(http.StatusOK, ListQuestionStimuliResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+		})
+
+		r.GET("/instructor/stimuli/:stimulusId", requireInstructorOrAdmin, func(c *gin.Context) {
+			var s QuestionStimulus
+			var createdAt time.Time
+			var updatedAt time.Time
+			err := pool.QueryRow(context.Background(), `select s.id, s.question_bank_id, s.title, s.passage, s.media_url, s.media_type, s.created_at, coalesce(s.updated_at, s.created_at),
+					(select count(*) from question_bank_questions q where q.stimulus_id=s.id)
+				from question_stimuli s where s.id=$1`, c.Param("stimulusId")).
+				Scan(&s.ID, &s.QuestionBankID, &s.Title, &s.Passage, &s.MediaURL, &s.MediaType, &createdAt, &updatedAt, &s.QuestionCount)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"message": "stimulus not found"})
+				return
+			}
+			s.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			s.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+			c.JSON(http.StatusOK, s)
+		})
+
+		r.PATCH("/instructor/stimuli/:stimulusId", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+
+			role, _ := auth.GetRole(c)
+
+			var req UpdateQuestionStimulusRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+				return
+			}
+
+			set := []string{"updated_at=now()", "updated_by_user_id=$2"}
+			args := []any{c.Param("stimulusId"), userID}
+			if req.Title != nil {
+				set = append(set, "title="+sqlParam(len(args)+1))
+				args = append(args, strings.TrimSpace(*req.Title))
+			}
+			if req.Passage != nil {
+				passage, ok := processContent(c, "passage", *req.Passage)
+				if !ok {
+					return
+				}
+				set = append(set, "passage="+sqlParam(len(args)+1))
+				args = append(args, passage.Normalized)
+			}
+			if req.MediaURL != nil {
+				mediaURL := nilIfEmptyPtr(req.MediaURL)
+				if mediaURL != nil && !validStimulusMediaURL(*mediaURL) {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "invalid mediaUrl"})
+					return
+				}
+				set = append(set, "media_url="+sqlParam(len(args)+1))
+				args = append(args, mediaURL)
+			}
+			if req.MediaType != nil {
+				mediaType := nilIfEmptyPtr(req.MediaType)
+				if mediaType != nil && !validStimulusMediaType(*mediaType) {
+					c.JSON(http.StatusBadRequest, gin.H{"message": "mediaType must be image, audio or video"})
+					return
+				}
+				set = append(set, "media_type="+sqlParam(len(args)+1))
+				args = append(args, mediaType)
+			}
+			if len(set) == 2 {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "no updates"})
+				return
+			}
+
+			query := `update question_stimuli set ` + strings.Join(set, ", ") + ` where id=$1`
+			if role != "admin" {
+				query += " and created_by_user_id=$2"
+			}
+			cmd, err := pool.Exec(context.Background(), query, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to update stimulus"})
+				return
+			}
+			if cmd.RowsAffected() == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"message": "stimulus not found"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		r.DELETE("/instructor/stimuli/:stimulusId", requireInstructorOrAdmin, func(c *gin.Context) {
+			userID, ok := auth.GetUserID(c)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+				return
+			}
+			role, _ := auth.GetRole(c)
+			ctx := context.Background()
+			sid := c.Param("stimulusId")
+
+			var inUse bool
+			if err := pool.QueryRow(ctx, `select exists(select 1 from question_bank_questions where stimulus_id=$1)`, sid).Scan(&inUse); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete stimulus"})
+				return
+			}
+			if inUse {
+				c.JSON(http.StatusConflict, gin.H{"message": "stimulus is referenced by questions"})
+				return
+			}
+
+			query := `delete from question_stimuli where id=$1`
+			args := []any{sid}
+			if role != "admin" {
+				query += " and created_by_user_id=$2"
+				args = append(args, userID)
+			}
+			cmd, err := pool.Exec(ctx, query, args...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete stimulus"})
+				return
+			}
+			if cmd.RowsAffected() == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"message": "stimulus not found"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+	}
+}
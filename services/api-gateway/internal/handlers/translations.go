@@ -0,0 +1,563 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+	"github.com/ace-platform/api-gateway/internal/locale"
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+type TranslationStatus string
+
+const (
+	TranslationPending  TranslationStatus = "pending"
+	TranslationApproved TranslationStatus = "approved"
+	TranslationRejected TranslationStatus = "rejected"
+)
+
+const (
+	translationEntityQuestion    = "question"
+	translationEntityTopic       = "topic"
+	translationEntityExamPackage = "exam_package"
+)
+
+// TranslationFields holds the translated text of one entity. Which fields
+// apply depends on the entity type: prompt/explanation/choices for
+// questions, name for topics, name/subtitle/overview for exam packages.
+type TranslationFields struct {
+	Prompt      *string           `json:"prompt,omitempty"`
+	Explanation *string           `json:"explanation,omitempty"`
+	Choices     map[string]string `json:"choices,omitempty"`
+	Name        *string           `json:"name,omitempty"`
+	Subtitle    *string           `json:"subtitle,omitempty"`
+	Overview    *string           `json:"overview,omitempty"`
+}
+
+type ContentTranslation struct {
+	ID           string            `json:"id"`
+	EntityType   string            `json:"entityType"`
+	EntityID     string            `json:"entityId"`
+	Locale       string            `json:"locale"`
+	SourceLocale string            `json:"sourceLocale"`
+	Fields       TranslationFields `json:"fields"`
+	Status       TranslationStatus `json:"status"`
+	// Stale is set when the question changed after the translation was written.
+	// Stale translations are not served to students.
+	Stale            bool    `json:"stale"`
+	ReviewNote       string  `json:"reviewNote"`
+	CreatedByUserID  string  `json:"createdByUserId"`
+	UpdatedByUserID  string  `json:"updatedByUserId"`
+	ReviewedByUserID *string `json:"reviewedByUserId"`
+	ReviewedAt       *string `json:"reviewedAt"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+type ListContentTranslationsResponse struct {
+	Items   []ContentTranslation `json:"items"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	HasMore bool                 `json:"hasMore"`
+}
+
+type ReviewTranslationRequest struct {
+	Decision string `json:"decision"`
+	Note     string `json:"note"`
+}
+
+// translationSourceSQL resolves the source locale and, for questions, the
+// current revision of the entity a content_translations row (alias t) belongs to.
+const translationSourceSQL = `
+	left join question_bank_questions tq on t.entity_type='question' and tq.id=t.entity_id
+	left join question_banks tqb on tqb.id=tq.package_id
+	left join question_bank_topics tt on t.entity_type='topic' and tt.id=t.entity_id
+	left join question_banks ttb on ttb.id=tt.package_id
+	left join exam_packages tep on t.entity_type='exam_package' and tep.id::text=t.entity_id`
+
+const translationSourceLocaleSQL = `coalesce(tqb.source_locale, ttb.source_locale, tep.source_locale, 'en')`
+
+// requestLocales returns the caller's locale preferences in lookup order:
+// the profile locale when set, then the Accept-Language header.
+func requestLocales(ctx context.Context, pool *pgxpool.Pool, c *gin.Context, userID string) []string {
+	preferred := []string{}
+	if userID != "" {
+		var profileLocale *string
+		if err := pool.QueryRow(ctx, `select locale from users where id=$1`, userID).Scan(&profileLocale); err == nil && profileLocale != nil {
+			preferred = append(preferred, *profileLocale)
+		}
+	}
+	preferred = append(preferred, locale.ParseAcceptLanguage(c.GetHeader("Accept-Language"))...)
+	return locale.Candidates(preferred)
+}
+
+type localizedContent struct {
+	Locale string
+	Fields TranslationFields
+}
+
+// loadTranslations picks, per entity, the approved translation to serve for
+// the given candidates. sources maps entity ids to their source locale;
+// entities served in their source locale are absent from the result.
+func loadTranslations(ctx context.Context, pool *pgxpool.Pool, entityType string, sources map[string]string, candidates []string) (map[string]localizedContent, error) {
+	out := map[string]localizedContent{}
+	if len(sources) == 0 || len(candidates) == 0 {
+		return out, nil
+	}
+	ids := make([]string, 0, len(sources))
+	for id := range sources {
+		ids = append(ids, id)
+	}
+
+	rows, err := pool.Query(ctx, `select t.entity_id, t.locale, t.fields from content_translations t `+translationSourceSQL+`
+		where t.entity_type=$1 and t.entity_id = any($2) and t.locale = any($3) and t.status=$4
+			and (t.entity_type<>'question' or t.source_revision=tq.revision)`,
+		entityType, ids, candidates, string(TranslationApproved))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byEntity := map[string]map[string]TranslationFields{}
+	for rows.Next() {
+		var entityID, loc string
+		var raw []byte
+		if err := rows.Scan(&entityID, &loc, &raw); err != nil {
+			return nil, err
+		}
+		var fields TranslationFields
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			continue
+		}
+		if byEntity[entityID] == nil {
+			byEntity[entityID] = map[string]TranslationFields{}
+		}
+		byEntity[entityID][loc] = fields
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for entityID, translations := range byEntity {
+		available := make([]string, 0, len(translations))
+		for loc := range translations {
+			available = append(available, loc)
+		}
+		picked := locale.Pick(candidates, sources[entityID], available)
+		if fields, ok := translations[picked]; ok {
+			out[entityID] = localizedContent{Locale: picked, Fields: fields}
+		}
+	}
+	return out, nil
+}
+
+// localizeQuestionSnapshots replaces question text with the best approved
+// translation and records the locale each question was captured in.
+func localizeQuestionSnapshots(ctx context.Context, pool *pgxpool.Pool, snapshot []practiceQuestionSnapshot, sources map[string]string, candidates []string) error {
+	translations, err := loadTranslations(ctx, pool, translationEntityQuestion, sources, candidates)
+	if err != nil {
+		return err
+	}
+	for i := range snapshot {
+		q := &snapshot[i]
+		q.Locale = sources[q.ID]
+		tr, ok := translations[q.ID]
+		if !ok {
+			continue
+		}
+		q.Locale = tr.Locale
+		q.Prompt = localizedText(q.Prompt, tr.Fields.Prompt)
+		q.Explanation = localizedText(q.Explanation, tr.Fields.Explanation)
+		for j, ch := range q.Choices {
+			if text, ok := tr.Fields.Choices[ch.ID]; ok {
+				q.Choices[j].Text = text
+			}
+		}
+	}
+	return nil
+}
+
+// localizedText returns the translated text when present.
+func localizedText(source string, translated *string) string {
+	if translated == nil || *translated == "" {
+		return source
+	}
+	return *translated
+}
+
+// validateTranslationFields checks the fields against the entity and
+// normalizes question content. It writes the error response itself.
+func validateTranslationFields(c *gin.Context, entityType string, fields TranslationFields, choiceIDs []string) (TranslationFields, bool) {
+	out := TranslationFields{}
+	switch entityType {
+	case translationEntityQuestion:
+		if fields.Name != nil || fields.Subtitle != nil || fields.Overview != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "question translations accept prompt, explanation and choices"})
+			return out, false
+		}
+		if fields.Prompt == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "prompt is required"})
+			return out, false
+		}
+		res, ok := processContent(c, "prompt", *fields.Prompt)
+		if !ok {
+			return out, false
+		}
+		if res.Normalized == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "prompt is required"})
+			return out, false
+		}
+		out.Prompt = &res.Normalized
+		if fields.Explanation != nil {
+			res, ok := processContent(c, "explanation", *fields.Explanation)
+			if !ok {
+				return out, false
+			}
+			out.Explanation = &res.Normalized
+		}
+		// Every current choice must be translated so students never see a mix.
+		if len(fields.Choices) != len(choiceIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "every choice must be translated"})
+			return out, false
+		}
+		out.Choices = map[string]string{}
+		for _, id := range choiceIDs {
+			text, ok := fields.Choices[id]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "every choice must be translated"})
+				return out, false
+			}
+			res, ok := processContent(c, "choice", text)
+			if !ok {
+				return out, false
+			}
+			if res.Normalized == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "choice text is required"})
+				return out, false
+			}
+			out.Choices[id] = res.Normalized
+		}
+	case translationEntityTopic:
+		if fields.Prompt != nil || fields.Explanation != nil || fields.Choices != nil || fields.Subtitle != nil || fields.Overview != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "topic translations accept name only"})
+			return out, false
+		}
+		if fields.Name == nil || strings.TrimSpace(*fields.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "name is required"})
+			return out, false
+		}
+		name := strings.TrimSpace(*fields.Name)
+		out.Name = &name
+	case translationEntityExamPackage:
+		if fields.Prompt != nil || fields.Explanation != nil || fields.Choices != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "exam package translations accept name, subtitle and overview"})
+			return out, false
+		}
+		if fields.Name == nil || strings.TrimSpace(*fields.Name) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "name is required"})
+			return out, false
+		}
+		name := strings.TrimSpace(*fields.Name)
+		out.Name = &name
+		if fields.Subtitle != nil {
+			v := strings.TrimSpace(*fields.Subtitle)
+			out.Subtitle = &v
+		}
+		if fields.Overview != nil {
+			v := strings.TrimSpace(*fields.Overview)
+			out.Overview = &v
+		}
+	}
+	return out, true
+}
+
+func registerTranslationRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	r.GET("/instructor/translations", requireInstructorOrAdmin, func(c *gin.Context) {
+		limit, offset := parseListParams(c)
+		args := []any{}
+		where := []string{"true"}
+		for _, f := range []struct{ param, column string }{
+			{"entityType", "t.entity_type"},
+			{"entityId", "t.entity_id"},
+			{"status", "t.status"},
+		} {
+			if v := strings.TrimSpace(c.Query(f.param)); v != "" {
+				args = append(args, v)
+				where = append(where, f.column+"="+sqlParam(len(args)))
+			}
+		}
+		if raw := strings.TrimSpace(c.Query("locale")); raw != "" {
+			loc, ok := locale.Normalize(raw)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "invalid locale"})
+				return
+			}
+			args = append(args, loc)
+			where = append(where, "t.locale="+sqlParam(len(args)))
+		}
+		if parseBoolQuery(c, "stale") {
+			where = append(where, "t.entity_type='question' and t.source_revision is distinct from tq.revision")
+		}
+		args = append(args, limit+1, offset)
+
+		rows, err := pool.Query(context.Background(), `
+			select t.id, t.entity_type, t.entity_id, t.locale, `+translationSourceLocaleSQL+`, t.fields, t.status,
+				(t.entity_type='question' and t.source_revision is distinct from tq.revision),
+				t.review_note, t.created_by_user_id, t.updated_by_user_id, t.reviewed_by_user_id, t.reviewed_at, t.created_at, t.updated_at
+			from content_translations t `+translationSourceSQL+`
+			where `+strings.Join(where, " and ")+`
+			order by t.updated_at desc, t.id
+			limit `+sqlParam(len(args)-1)+` offset `+sqlParam(len(args)), args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list translations"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]ContentTranslation, 0, limit)
+		for rows.Next() {
+			var item ContentTranslation
+			var raw []byte
+			var status string
+			var reviewedAt *time.Time
+			var createdAt, updatedAt time.Time
+			if err := rows.Scan(&item.ID, &item.EntityType, &item.EntityID, &item.Locale, &item.SourceLocale, &raw, &status, &item.Stale,
+				&item.ReviewNote, &item.CreatedByUserID, &item.UpdatedByUserID, &item.ReviewedByUserID, &reviewedAt, &createdAt, &updatedAt); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list translations"})
+				return
+			}
+			_ = json.Unmarshal(raw, &item.Fields)
+			item.Status = TranslationStatus(status)
+			if reviewedAt != nil {
+				v := reviewedAt.UTC().Format(time.RFC3339)
+				item.ReviewedAt = &v
+			}
+			item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+			item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+			items = append(items, item)
+		}
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListContentTranslationsResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// Saving a translation (new or edited) always sends it back to review; until
+	// it is approved again students get the source text.
+	r.PUT("/instructor/translations/:entityType/:entityId/:locale", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		entityType := c.Param("entityType")
+		entityID := strings.TrimSpace(c.Param("entityId"))
+		loc, ok := locale.Normalize(c.Param("locale"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid locale"})
+			return
+		}
+
+		var fields TranslationFields
+		if err := c.ShouldBindJSON(&fields); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+
+		ctx := context.Background()
+		var sourceLocale string
+		var sourceRevision *int
+		choiceIDs := []string{}
+		var err error
+		// Instructors translate only the questions and topics they may edit;
+		// exam packages are admin-only.
+		args := []any{entityID}
+		ownerSQL := func(table string) string {
+			if role == "admin" {
+				return ""
+			}
+			args = append(args, userID)
+			return " and " + table + ".created_by_user_id=$2"
+		}
+		switch entityType {
+		case translationEntityQuestion:
+			var revision int
+			err = pool.QueryRow(ctx, `select coalesce(b.source_locale, 'en'), q.revision from question_bank_questions q
+				left join question_banks b on b.id=q.package_id
+				where q.id=$1 and `+liveQuestionSQL("q")+ownerSQL("q"), args...).Scan(&sourceLocale, &revision)
+			if err == nil {
+				sourceRevision = &revision
+				err = pool.QueryRow(ctx, `select coalesce(array_agg(id order by order_index), '{}') from question_bank_choices where question_id=$1`, entityID).Scan(&choiceIDs)
+			}
+		case translationEntityTopic:
+			err = pool.QueryRow(ctx, `select coalesce(b.source_locale, 'en') from question_bank_topics
+				left join question_banks b on b.id=question_bank_topics.package_id
+				where question_bank_topics.id=$1 and `+liveTopicSQL+ownerSQL("question_bank_topics"), args...).Scan(&sourceLocale)
+		case translationEntityExamPackage:
+			if role != "admin" {
+				c.JSON(http.StatusForbidden, gin.H{"message": "only admins can translate exam packages"})
+				return
+			}
+			err = pool.QueryRow(ctx, `select source_locale from exam_packages where id::text=$1`, entityID).Scan(&sourceLocale)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "entityType must be question, topic or exam_package"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "content not found"})
+			return
+		}
+		if loc == sourceLocale {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "locale is the source locale; edit the content itself"})
+			return
+		}
+
+		fields, ok = validateTranslationFields(c, entityType, fields, choiceIDs)
+		if !ok {
+			return
+		}
+		fieldsJSON, _ := json.Marshal(fields)
+
+		var id string
+		err = pool.QueryRow(ctx, `insert into content_translations (id, entity_type, entity_id, locale, fields, source_revision, status, created_by_user_id, updated_by_user_id)
+			values ($1,$2,$3,$4,$5,$6,$7,$8,$8)
+			on conflict (entity_type, entity_id, locale) do update set
+				fields=excluded.fields,
+				source_revision=excluded.source_revision,
+				status=excluded.status,
+				review_note='',
+				reviewed_by_user_id=null,
+				reviewed_at=null,
+				updated_by_user_id=excluded.updated_by_user_id,
+				updated_at=now()
+			returning id`,
+			util.NewID("tr"), entityType, entityID, loc, fieldsJSON, sourceRevision, string(TranslationPending), userID).Scan(&id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to save translation"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.translations.save", "content_translation", id,
+			gin.H{"entityType": entityType, "entityId": entityID, "locale": loc})
+		c.JSON(http.StatusOK, gin.H{"id": id, "status": TranslationPending})
+	})
+
+	// Translators cannot approve their own work; admins can.
+	r.POST("/instructor/translations/:translationId/review", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		translationID := c.Param("translationId")
+
+		var req ReviewTranslationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid json body"})
+			return
+		}
+		var status TranslationStatus
+		switch req.Decision {
+		case "approve":
+			status = TranslationApproved
+		case "reject":
+			status = TranslationRejected
+			if strings.TrimSpace(req.Note) == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "note is required when rejecting"})
+				return
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": "decision must be approve or reject"})
+			return
+		}
+
+		ctx := context.Background()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to review translation"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		var entityType, entityID, loc, updatedBy string
+		var stale bool
+		err = tx.QueryRow(ctx, `select t.entity_type, t.entity_id, t.locale, t.updated_by_user_id,
+				(t.entity_type='question' and t.source_revision is distinct from tq.revision)
+			from content_translations t `+translationSourceSQL+`
+			where t.id=$1 for update of t`, translationID).Scan(&entityType, &entityID, &loc, &updatedBy, &stale)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"message": "translation not found"})
+			return
+		}
+		if updatedBy == userID && role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"message": "cannot review your own translation"})
+			return
+		}
+		if stale && status == TranslationApproved {
+			c.JSON(http.StatusConflict, gin.H{"message": "question changed since this translation was written"})
+			return
+		}
+
+		_, err = tx.Exec(ctx, `update content_translations set status=$2, review_note=$3, reviewed_by_user_id=$4, reviewed_at=now() where id=$1`,
+			translationID, string(status), strings.TrimSpace(req.Note), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to review translation"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to review translation"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.translations."+req.Decision, "content_translation", translationID,
+			gin.H{"entityType": entityType, "entityId": entityID, "locale": loc})
+		c.JSON(http.StatusOK, gin.H{"ok": true, "status": status})
+	})
+
+	r.DELETE("/instructor/translations/:translationId", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+
+		translationID := c.Param("translationId")
+
+		query := `delete from content_translations where id=$1`
+		args := []any{translationID}
+		if role != "admin" {
+			query += " and created_by_user_id=$2"
+			args = append(args, userID)
+		}
+		query += " returning entity_type, entity_id, locale"
+		ctx := context.Background()
+		var entityType, entityID, loc string
+		err := pool.QueryRow(ctx, query, args...).Scan(&entityType, &entityID, &loc)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "translation not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to delete translation"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.translations.delete", "content_translation", translationID,
+			gin.H{"entityType": entityType, "entityId": entityID, "locale": loc})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/auth"
+)
+
+const (
+	trashTypeQuestion      = "question"
+	trashTypeQuestionBank  = "question_bank"
+	trashTypeQuestionTopic = "question_topic"
+)
+
+// TrashItem is a soft-deleted question, question bank or topic.
+type TrashItem struct {
+	Type            string  `json:"type"`
+	ID              string  `json:"id"`
+	Title           string  `json:"title"`
+	QuestionBankID  *string `json:"questionBankId"`
+	CreatedByUserID *string `json:"createdByUserId"`
+	DeletedAt       string  `json:"deletedAt"`
+	DeletedByUserID *string `json:"deletedByUserId"`
+	// Purgeable is false while practice answers or exam responses reference
+	// the item.
+	Purgeable bool `json:"purgeable"`
+}
+
+type ListTrashResponse struct {
+	Items   []TrashItem `json:"items"`
+	Limit   int         `json:"limit"`
+	Offset  int         `json:"offset"`
+	HasMore bool        `json:"hasMore"`
+}
+
+// liveQuestionSQL is the predicate for questions that are neither trashed
+// themselves nor in a trashed bank. alias is the question_bank_questions alias.
+func liveQuestionSQL(alias string) string {
+	return alias + ".deleted_at is null and not exists (select 1 from question_banks tb where tb.id=" + alias + ".package_id and tb.deleted_at is not null)"
+}
+
+// liveTopicSQL is the predicate for topics that are neither trashed
+// themselves nor in a trashed bank (unaliased question_bank_topics).
+const liveTopicSQL = "question_bank_topics.deleted_at is null and not exists (select 1 from question_banks tb where tb.id=question_bank_topics.package_id and tb.deleted_at is not null)"
+
+// answeredSQL is the predicate for questions that students answered, in
+// practice or in an exam. Exam sessions keep responses in their snapshot,
+// keyed by question id, so they hold no foreign key that would stop a purge.
+// alias is the question_bank_questions alias.
+func answeredSQL(alias string) string {
+	return "(exists (select 1 from practice_answers a where a.question_id=" + alias + ".id)" +
+		" or exists (select 1 from exam_sessions s where (s.snapshot->'responses')::jsonb ? " + alias + ".id))"
+}
+
+// trashSources maps each trash type to a query yielding
+// (type, id, title, bank id, created by, deleted at, deleted by, answered).
+var trashSources = map[string]string{
+	trashTypeQuestion: `select 'question', q.id, left(q.prompt, 200), q.package_id, q.created_by_user_id, q.deleted_at, q.deleted_by_user_id,
+			` + answeredSQL("q") + `
+		from question_bank_questions q where q.deleted_at is not null`,
+	trashTypeQuestionBank: `select 'question_bank', b.id, b.name, b.id, b.created_by_user_id, b.deleted_at, b.deleted_by_user_id,
+			exists (select 1 from question_bank_questions q where q.package_id=b.id and ` + answeredSQL("q") + `)
+		from question_banks b where b.deleted_at is not null`,
+	trashTypeQuestionTopic: `select 'question_topic', t.id, t.name, t.package_id, t.created_by_user_id, t.deleted_at, t.deleted_by_user_id,
+			exists (select 1 from question_bank_questions q where q.topic_id=t.id and ` + answeredSQL("q") + `)
+		from question_bank_topics t where t.deleted_at is not null`,
+}
+
+// trashTables maps trash types to their table.
+var trashTables = map[string]string{
+	trashTypeQuestion:      "question_bank_questions",
+	trashTypeQuestionBank:  "question_banks",
+	trashTypeQuestionTopic: "question_bank_topics",
+}
+
+// purgeQuestions permanently deletes questions and everything hanging off them.
+// Callers must have checked that no student answered them (answeredSQL).
+func purgeQuestions(ctx context.Context, tx pgx.Tx, ids []string) error {
+	for _, stmt := range []string{
+		`delete from question_bank_correct_choice where question_id = any($1)`,
+		`delete from question_bank_choices where question_id = any($1)`,
+		`delete from question_bank_hints where question_id = any($1)`,
+		`delete from question_review_comments where question_id = any($1)`,
+		`delete from question_review_decisions where question_id = any($1)`,
+		`delete from question_review_assignments where question_id = any($1)`,
+		`delete from question_report_submissions where report_id in (select id from question_reports where question_id = any($1))`,
+		`delete from question_reports where question_id = any($1)`,
+		`delete from content_translations where entity_type='question' and entity_id = any($1)`,
+		`delete from question_bank_questions where id = any($1)`,
+	} {
+		if _, err := tx.Exec(ctx, stmt, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerTrashRoutes(r *gin.Engine, pool *pgxpool.Pool) {
+	requireInstructorOrAdmin := authRequireRolesAndAudiences(pool, []string{"instructor", "admin"}, []string{"instructor", "admin"})
+
+	r.GET("/instructor/trash", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		limit, offset := parseListParams(c)
+
+		itemType := strings.TrimSpace(c.Query("type"))
+		parts := []string{}
+		for _, t := range []string{trashTypeQuestion, trashTypeQuestionBank, trashTypeQuestionTopic} {
+			if itemType == "" || itemType == t {
+				parts = append(parts, trashSources[t])
+			}
+		}
+		if len(parts) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "type must be question, question_bank or question_topic"})
+			return
+		}
+
+		args := []any{}
+		query := `select * from (` + strings.Join(parts, " union all ") + `) x(type, id, title, bank_id, created_by, deleted_at, deleted_by, answered)`
+		where := []string{}
+		// Instructors only see what they created or trashed themselves.
+		if role != "admin" {
+			args = append(args, userID)
+			where = append(where, "(x.created_by="+sqlParam(len(args))+" or x.deleted_by="+sqlParam(len(args))+")")
+		}
+		if v := strings.TrimSpace(c.Query("questionBankId")); v != "" {
+			args = append(args, v)
+			where = append(where, "x.bank_id="+sqlParam(len(args)))
+		}
+		if len(where) > 0 {
+			query += " where " + strings.Join(where, " and ")
+		}
+		query += " order by x.deleted_at desc, x.id limit " + sqlParam(len(args)+1) + " offset " + sqlParam(len(args)+2)
+		args = append(args, limit+1, offset)
+
+		rows, err := pool.Query(context.Background(), query, args...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list trash"})
+			return
+		}
+		defer rows.Close()
+
+		items := make([]TrashItem, 0, limit)
+		for rows.Next() {
+			var item TrashItem
+			var deletedAt time.Time
+			var answered bool
+			if err := rows.Scan(&item.Type, &item.ID, &item.Title, &item.QuestionBankID, &item.CreatedByUserID, &deletedAt, &item.DeletedByUserID, &answered); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to list trash"})
+				return
+			}
+			item.DeletedAt = deletedAt.UTC().Format(time.RFC3339)
+			item.Purgeable = !answered
+			items = append(items, item)
+		}
+		rows.Close()
+
+		hasMore := false
+		if len(items) > limit {
+			hasMore = true
+			items = items[:limit]
+		}
+		c.JSON(http.StatusOK, ListTrashResponse{Items: items, Limit: limit, Offset: offset, HasMore: hasMore})
+	})
+
+	// loadTrashed locks a trashed item and checks the caller may act on it.
+	// It writes the error response itself and returns false on failure.
+	loadTrashed := func(c *gin.Context, ctx context.Context, tx pgx.Tx, itemType, id, userID, role string) (bankID *string, ok bool) {
+		table, known := trashTables[itemType]
+		if !known {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "type must be question, question_bank or question_topic"})
+			return nil, false
+		}
+		bankColumn := "package_id"
+		if itemType == trashTypeQuestionBank {
+			bankColumn = "null::text"
+		}
+		var createdBy, deletedBy *string
+		var deletedAt *time.Time
+		err := tx.QueryRow(ctx, `select created_by_user_id, deleted_by_user_id, deleted_at, `+bankColumn+` from `+table+` where id=$1 for update`, id).
+			Scan(&createdBy, &deletedBy, &deletedAt, &bankID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "item not found"})
+			return nil, false
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to load item"})
+			return nil, false
+		}
+		owns := (createdBy != nil && *createdBy == userID) || (deletedBy != nil && *deletedBy == userID)
+		if role != "admin" && !owns {
+			c.JSON(http.StatusNotFound, gin.H{"message": "item not found"})
+			return nil, false
+		}
+		if deletedAt == nil {
+			c.JSON(http.StatusConflict, gin.H{"message": "item is not in the trash"})
+			return nil, false
+		}
+		return bankID, true
+	}
+
+	r.POST("/instructor/trash/:itemType/:itemId/restore", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		itemType := c.Param("itemType")
+		id := strings.TrimSpace(c.Param("itemId"))
+
+		ctx := context.Background()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to restore item"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		bankID, ok := loadTrashed(c, ctx, tx, itemType, id, userID, role)
+		if !ok {
+			return
+		}
+		if bankID != nil {
+			var bankTrashed bool
+			_ = tx.QueryRow(ctx, `select deleted_at is not null from question_banks where id=$1`, *bankID).Scan(&bankTrashed)
+			if bankTrashed {
+				c.JSON(http.StatusConflict, gin.H{"message": "restore the question bank first"})
+				return
+			}
+		}
+
+		if _, err := tx.Exec(ctx, `update `+trashTables[itemType]+` set deleted_at=null, deleted_by_user_id=null, updated_at=now() where id=$1`, id); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				c.JSON(http.StatusConflict, gin.H{"message": "a topic with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to restore item"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to restore item"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.trash.restore", itemType, id, nil)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	// Purging is permanent and refused while practice answers or exam responses
+	// reference the item (for banks and topics: any of their questions).
+	r.DELETE("/instructor/trash/:itemType/:itemId", requireInstructorOrAdmin, func(c *gin.Context) {
+		userID, ok := auth.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "unauthorized"})
+			return
+		}
+		role, _ := auth.GetRole(c)
+		itemType := c.Param("itemType")
+		id := strings.TrimSpace(c.Param("itemId"))
+
+		ctx := context.Background()
+		tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to purge item"})
+			return
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		if _, ok := loadTrashed(c, ctx, tx, itemType, id, userID, role); !ok {
+			return
+		}
+
+		var questionFilter string
+		switch itemType {
+		case trashTypeQuestion:
+			questionFilter = "id=$1"
+		case trashTypeQuestionBank:
+			questionFilter = "package_id=$1"
+		case trashTypeQuestionTopic:
+			questionFilter = "topic_id=$1"
+		}
+		var answered bool
+		if err := tx.QueryRow(ctx, `select exists (select 1 from question_bank_questions q where q.`+questionFilter+` and `+answeredSQL("q")+`)`, id).Scan(&answered); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to purge item"})
+			return
+		}
+		if answered {
+			c.JSON(http.StatusConflict, gin.H{"message": "practice answers or exam responses reference this item; it can only stay in the trash"})
+			return
+		}
+
+		purged := 0
+		switch itemType {
+		case trashTypeQuestion:
+			err = purgeQuestions(ctx, tx, []string{id})
+			purged = 1
+		case trashTypeQuestionBank:
+			var ids []string
+			if err = tx.QueryRow(ctx, `select coalesce(array_agg(id), '{}') from question_bank_questions where package_id=$1`, id).Scan(&ids); err == nil {
+				err = purgeQuestions(ctx, tx, ids)
+				purged = len(ids)
+			}
+			for _, stmt := range []string{
+				`update question_bank_questions set topic_id=null where topic_id in (select id from question_bank_topics where package_id=$1)`,
+				`update practice_templates set topic_id=null where topic_id in (select id from question_bank_topics where package_id=$1)`,
+				`delete from content_translations where entity_type='topic' and entity_id in (select id from question_bank_topics where package_id=$1)`,
+				`delete from question_bank_topics where package_id=$1`,
+				`delete from question_stimuli where question_bank_id=$1`,
+				`delete from question_banks where id=$1`,
+			} {
+				if err != nil {
+					break
+				}
+				_, err = tx.Exec(ctx, stmt, id)
+			}
+		case trashTypeQuestionTopic:
+			for _, stmt := range []string{
+				`update question_bank_questions set topic_id=null where topic_id=$1`,
+				`update practice_templates set topic_id=null where topic_id=$1`,
+				`delete from content_translations where entity_type='topic' and entity_id=$1`,
+				`delete from question_bank_topics where id=$1`,
+			} {
+				if _, err = tx.Exec(ctx, stmt, id); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"message": "failed to purge item"})
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "failed to purge item"})
+			return
+		}
+		audit(ctx, pool, userID, role, "instructor.trash.purge", itemType, id, gin.H{"questionsPurged": purged})
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+}
@@ -0,0 +1,21 @@
+// Package hints scores practice answers given with progressive hints.
+//
+// A question may carry up to MaxPerQuestion hints, revealed one at a time on
+// request. Each revealed hint costs a correct answer a share of its credit:
+// with penalty p, a correct answer after n hints earns max(0, 1 - n*p). Wrong
+// answers earn nothing either way.
+package hints
+
+// MaxPerQuestion bounds the hints an instructor may author per question.
+const MaxPerQuestion = 5
+
+// DefaultPenalty is the credit lost per hint when the tier sets none.
+const DefaultPenalty = 0.25
+
+// Credit is what an answer earns, in [0, 1], after used hints.
+func Credit(correct bool, used int, penalty float64) float64 {
+	if !correct {
+		return 0
+	}
+	return max(0, 1-float64(max(used, 0))*penalty)
+}
@@ -0,0 +1,25 @@
+package hints
+
+import "testing"
+
+func TestCredit(t *testing.T) {
+    cases := []struct {
+        correct bool
+        used    int
+        penalty float64
+        want    float64
+    }{
+        {true, 0, DefaultPenalty, 1},
+        {true, 1, DefaultPenalty, 0.75},
+        {true, 3, DefaultPenalty, 0.25},
+        {true, 5, DefaultPenalty, 0},
+        {true, 2, 0, 1},
+        {false, 0, DefaultPenalty, 0},
+        {false, 2, DefaultPenalty, 0},
+    }
+    for _, tc := range cases {
+        if got := Credit(tc.correct, tc.used, tc.penalty); got != tc.want {
+            t.Fatalf("Credit(%v, %d, %v) = %v, want %v", tc.correct, tc.used, tc.penalty, got, tc.want)
+        }
+    }
+}
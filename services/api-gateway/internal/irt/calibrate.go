@@ -0,0 +1,257 @@
+package irt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/util"
+)
+
+const (
+	CalibrationRunning   = "running"
+	CalibrationCompleted = "completed"
+	CalibrationFailed    = "failed"
+)
+
+var ErrCalibrationRunning = errors.New("irt: a calibration is already running for this exam package")
+
+// DefaultMinResponses is the minimum number of scored responses a question
+// needs to be calibrated (IRT_MIN_RESPONSES, default 200).
+func DefaultMinResponses() int {
+	if v := strings.TrimSpace(os.Getenv("IRT_MIN_RESPONSES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 200
+}
+
+// CalibrationTimeout is how long a calibration may stay running before a new
+// start treats it as abandoned, e.g. by a crashed process
+// (IRT_CALIBRATION_TIMEOUT_MINUTES, default 120).
+func CalibrationTimeout() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("IRT_CALIBRATION_TIMEOUT_MINUTES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Minute
+		}
+	}
+	return 2 * time.Hour
+}
+
+type CalibrationRequest struct {
+	ExamPackageID   string
+	Model           Model
+	MinResponses    int
+	CreatedByUserID *string
+}
+
+// StartCalibration records a running calibration for the exam package and returns its id.
+// Running calibrations older than CalibrationTimeout are marked failed first.
+func StartCalibration(ctx context.Context, pool *pgxpool.Pool, req CalibrationRequest) (string, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Serialize starts per package so two requests cannot both pass the running check.
+	if _, err := tx.Exec(ctx, `select pg_advisory_xact_lock(hashtext('irt_calibration:' || $1))`, req.ExamPackageID); err != nil {
+		return "", err
+	}
+	// A run that outlived the timeout died with its process; fail it so it
+	// does not block the package forever.
+	if _, err := tx.Exec(ctx, `update irt_calibrations set status=$3, error='timed out', completed_at=now()
+		where exam_package_id=$1 and status=$2 and created_at < now() - $4 * interval '1 second'`,
+		req.ExamPackageID, CalibrationRunning, CalibrationFailed, CalibrationTimeout().Seconds()); err != nil {
+		return "", err
+	}
+	var running bool
+	if err := tx.QueryRow(ctx, `select exists(select 1 from irt_calibrations where exam_package_id=$1 and status=$2)`, req.ExamPackageID, CalibrationRunning).Scan(&running); err != nil {
+		return "", err
+	}
+	if running {
+		return "", ErrCalibrationRunning
+	}
+
+	id := util.NewID("cal")
+	_, err = tx.Exec(ctx, `insert into irt_calibrations (id, exam_package_id, model, status, min_responses, created_by_user_id) values ($1,$2,$3,$4,$5,$6)`,
+		id, req.ExamPackageID, string(req.Model), CalibrationRunning, req.MinResponses, req.CreatedByUserID)
+	if err != nil {
+		return "", err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// RunCalibration fits the model for a calibration created by StartCalibration
+// and stores a new parameter version for every question that met the
+// response threshold. The calibration row records the outcome either way.
+func RunCalibration(ctx context.Context, pool *pgxpool.Pool, calibrationID string, req CalibrationRequest) error {
+	err := runCalibration(ctx, pool, calibrationID, req)
+	if err != nil {
+		_, _ = pool.Exec(context.Background(), `update irt_calibrations set status=$1, error=$2, completed_at=now() where id=$3`,
+			CalibrationFailed, err.Error(), calibrationID)
+	}
+	return err
+}
+
+func runCalibration(ctx context.Context, pool *pgxpool.Pool, calibrationID string, req CalibrationRequest) error {
+	// First scored attempt per student per question, from practice answers and
+	// finished exam sessions of the package. Exam snapshots carry their own
+	// scoring in responses[itemId].correct.
+	rows, err := pool.Query(ctx, `select distinct on (x.user_id, x.question_id) x.user_id, x.question_id, x.correct
+		from (
+			select pa.user_id, pa.question_id, pa.correct, pa.ts
+			from practice_answers pa
+			join practice_sessions ps on ps.id=pa.session_id
+			where ps.package_id=$1 and pa.is_final
+			union all
+			select s.user_id, r.key, (r.value->>'correct')::boolean, coalesce(s.submitted_at, s.updated_at, s.created_at)
+			from exam_sessions s
+			cross join lateral json_each(case when json_typeof(s.snapshot->'responses')='object' then s.snapshot->'responses' else '{}'::json end) r
+			where s.exam_package_id=$1 and s.status='finished' and s.invalidated_at is null
+				and (r.value->>'correct') in ('true','false')
+		) x
+		join question_bank_questions q on q.id=x.question_id
+		join exam_package_question_bank_packages m on m.question_bank_package_id=q.package_id and m.exam_package_id=$1
+		order by x.user_id, x.question_id, x.ts asc`, req.ExamPackageID)
+	if err != nil {
+		return fmt.Errorf("load responses: %w", err)
+	}
+	type raw struct {
+		user     string
+		question string
+		correct  bool
+	}
+	var all []raw
+	perItem := map[string]int{}
+	for rows.Next() {
+		var r raw
+		if err := rows.Scan(&r.user, &r.question, &r.correct); err != nil {
+			rows.Close()
+			return fmt.Errorf("load responses: %w", err)
+		}
+		all = append(all, r)
+		perItem[r.question]++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load responses: %w", err)
+	}
+
+	personIdx := map[string]int{}
+	itemIdx := map[string]int{}
+	var itemIDs []string
+	var responses []Response
+	for _, r := range all {
+		if perItem[r.question] < req.MinResponses {
+			continue
+		}
+		j, ok := itemIdx[r.question]
+		if !ok {
+			j = len(itemIDs)
+			itemIdx[r.question] = j
+			itemIDs = append(itemIDs, r.question)
+		}
+		p, ok := personIdx[r.user]
+		if !ok {
+			p = len(personIdx)
+			personIdx[r.user] = p
+		}
+		responses = append(responses, Response{Person: p, Item: j, Correct: r.correct})
+	}
+	skipped := len(perItem) - len(itemIDs)
+	if len(itemIDs) == 0 {
+		return fmt.Errorf("no questions have at least %d responses", req.MinResponses)
+	}
+
+	res, err := Fit(responses, len(personIdx), len(itemIDs), Options{Model: req.Model})
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch := &pgx.Batch{}
+	for j, est := range res.Items {
+		var seC *float64
+		if est.SEC != nil {
+			seC = finite(*est.SEC)
+		}
+		batch.Queue(`insert into question_irt_params (question_id, calibration_id, version, model, a, b, c, se_a, se_b, se_c, n)
+			values ($1, $2, (select coalesce(max(version), 0) + 1 from question_irt_params where question_id=$1), $3, $4, $5, $6, $7, $8, $9, $10)`,
+			itemIDs[j], calibrationID, string(req.Model), est.A, est.B, est.C, finite(est.SEA), finite(est.SEB), seC, est.N)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("store parameters: %w", err)
+	}
+	_, err = tx.Exec(ctx, `update irt_calibrations set status=$1, items_calibrated=$2, items_skipped=$3, persons=$4, responses=$5, iterations=$6, converged=$7, completed_at=now() where id=$8`,
+		CalibrationCompleted, len(itemIDs), skipped, len(personIdx), len(responses), res.Iterations, res.Converged, calibrationID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func finite(v float64) *float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// Params is a stored parameter version for one question.
+type Params struct {
+	Version       int      `json:"version"`
+	CalibrationID string   `json:"calibrationId"`
+	Model         Model    `json:"model"`
+	A             float64  `json:"a"`
+	B             float64  `json:"b"`
+	C             float64  `json:"c"`
+	SEA           *float64 `json:"seA"`
+	SEB           *float64 `json:"seB"`
+	SEC           *float64 `json:"seC"`
+	N             int      `json:"n"`
+	CreatedAt     string   `json:"createdAt"`
+}
+
+// LoadParams returns a question's parameter versions, newest first. With
+// latestOnly it returns at most the current version.
+func LoadParams(ctx context.Context, pool *pgxpool.Pool, questionID string, latestOnly bool) ([]Params, error) {
+	query := `select version, calibration_id, model, a, b, c, se_a, se_b, se_c, n, created_at
+		from question_irt_params where question_id=$1 order by version desc`
+	if latestOnly {
+		query += ` limit 1`
+	}
+	rows, err := pool.Query(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Params{}
+	for rows.Next() {
+		var p Params
+		var model string
+		var createdAt time.Time
+		if err := rows.Scan(&p.Version, &p.CalibrationID, &model, &p.A, &p.B, &p.C, &p.SEA, &p.SEB, &p.SEC, &p.N, &createdAt); err != nil {
+			return nil, err
+		}
+		p.Model = Model(model)
+		p.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,437 @@
+// Package irt fits logistic item response models (2PL, optionally 3PL) to
+// dichotomous responses.
+//
+// Estimation is marginal maximum likelihood via EM (Bock-Aitkin) over a fixed
+// quadrature of a standard normal ability distribution. Weak priors on the
+// item parameters (log a ~ N(0,0.5), b ~ N(0,2), logit c ~ N(logit 0.2, 1))
+// keep items answered (in)correctly by everyone from diverging. The model
+// matches the web exam engine: P = c + (1-c) / (1 + exp(-a(theta-b))).
+package irt
+
+import (
+	"errors"
+	"math"
+)
+
+type Model string
+
+const (
+	Model2PL Model = "2pl"
+	Model3PL Model = "3pl"
+)
+
+var ErrNoData = errors.New("irt: no responses to calibrate")
+
+// Response is a scored answer by person Person to item Item (both zero-based indexes).
+type Response struct {
+	Person  int
+	Item    int
+	Correct bool
+}
+
+type Options struct {
+	Model         Model
+	MaxIterations int
+	Tolerance     float64
+}
+
+// ItemEstimate holds fitted parameters and their standard errors. A standard
+// error is +Inf when the data do not identify the parameter.
+type ItemEstimate struct {
+	A   float64
+	B   float64
+	C   float64
+	SEA float64
+	SEB float64
+	// SEC is nil for 2PL fits, where c is fixed at zero.
+	SEC *float64
+	N   int
+}
+
+type Result struct {
+	Items []ItemEstimate
+	// Thetas are expected a posteriori ability estimates.
+	Thetas     []float64
+	Iterations int
+	Converged  bool
+}
+
+// Prob is the probability of a correct response under the 3PL model (c=0 gives 2PL).
+func Prob(theta, a, b, c float64) float64 {
+	return c + (1-c)*logistic(a*(theta-b))
+}
+
+const (
+	priorLogASD  = 0.5
+	priorBSD     = 2.0
+	priorLogitC  = -1.3862943611198906 // logit(0.2)
+	priorLogitSD = 1.0
+	minProb      = 1e-9
+
+	quadPoints = 41
+	quadRange  = 5.0
+)
+
+// Fit estimates item parameters and abilities for nPersons persons and nItems items.
+func Fit(responses []Response, nPersons, nItems int, opts Options) (Result, error) {
+	if len(responses) == 0 || nPersons == 0 || nItems == 0 {
+		return Result{}, ErrNoData
+	}
+	if opts.Model == "" {
+		opts.Model = Model2PL
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 200
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-4
+	}
+	threePL := opts.Model == Model3PL
+	dim := 2
+	if threePL {
+		dim = 3
+	}
+
+	nodes, weights := quadrature()
+	byItem := make([][]int, nItems)
+	byPerson := make([][]int, nPersons)
+	for i, r := range responses {
+		byItem[r.Item] = append(byItem[r.Item], i)
+		byPerson[r.Person] = append(byPerson[r.Person], i)
+	}
+
+	// Item parameters on the unconstrained scale: log a, b, logit c.
+	params := make([][]float64, nItems)
+	for j := range params {
+		params[j] = []float64{0, initialB(responses, byItem[j]), priorLogitC}
+	}
+	cOf := func(p []float64) float64 {
+		if !threePL {
+			return 0
+		}
+		return logistic(p[2])
+	}
+
+	// Expected counts at each node: attempts (n) and correct answers (r) per item.
+	n := make([][]float64, nItems)
+	r := make([][]float64, nItems)
+	for j := range n {
+		n[j] = make([]float64, quadPoints)
+		r[j] = make([]float64, quadPoints)
+	}
+	post := make([]float64, quadPoints)
+
+	estep := func() {
+		for j := range n {
+			for q := range n[j] {
+				n[j][q], r[j][q] = 0, 0
+			}
+		}
+		for _, idx := range byPerson {
+			if len(idx) == 0 {
+				continue
+			}
+			posterior(responses, idx, params, cOf, nodes, weights, post)
+			for _, i := range idx {
+				resp := responses[i]
+				for q, w := range post {
+					n[resp.Item][q] += w
+					if resp.Correct {
+						r[resp.Item][q] += w
+					}
+				}
+			}
+		}
+	}
+
+	res := Result{}
+	for iter := 1; iter <= opts.MaxIterations; iter++ {
+		estep()
+
+		maxDelta := 0.0
+		for j := range params {
+			if len(byItem[j]) == 0 {
+				continue
+			}
+			f := itemObjective(nodes, n[j], r[j], params[j], threePL, true)
+			next := append([]float64(nil), params[j][:dim]...)
+			for k := 0; k < 5; k++ {
+				next = newtonStep(f, next)
+			}
+			for k := 0; k < dim; k++ {
+				maxDelta = math.Max(maxDelta, math.Abs(next[k]-params[j][k]))
+				params[j][k] = next[k]
+			}
+		}
+
+		res.Iterations = iter
+		if maxDelta < opts.Tolerance {
+			res.Converged = true
+			break
+		}
+	}
+	estep()
+
+	res.Items = make([]ItemEstimate, nItems)
+	for j, q := range params {
+		est := ItemEstimate{A: math.Exp(q[0]), B: q[1], C: cOf(q), N: len(byItem[j])}
+		if len(byItem[j]) > 0 {
+			// Information from the expected complete-data likelihood, mapped
+			// back to the natural scale with the delta method.
+			se := standardErrors(itemObjective(nodes, n[j], r[j], q, threePL, false), q[:dim])
+			est.SEA = est.A * se[0]
+			est.SEB = se[1]
+			if threePL {
+				v := est.C * (1 - est.C) * se[2]
+				est.SEC = &v
+			}
+		}
+		res.Items[j] = est
+	}
+
+	res.Thetas = make([]float64, nPersons)
+	for p, idx := range byPerson {
+		posterior(responses, idx, params, cOf, nodes, weights, post)
+		for q, w := range post {
+			res.Thetas[p] += w * nodes[q]
+		}
+	}
+	return res, nil
+}
+
+// quadrature returns equally spaced nodes with normalized standard normal weights.
+func quadrature() ([]float64, []float64) {
+	nodes := make([]float64, quadPoints)
+	weights := make([]float64, quadPoints)
+	total := 0.0
+	for q := range nodes {
+		nodes[q] = -quadRange + 2*quadRange*float64(q)/float64(quadPoints-1)
+		weights[q] = math.Exp(-0.5 * nodes[q] * nodes[q])
+		total += weights[q]
+	}
+	for q := range weights {
+		weights[q] /= total
+	}
+	return nodes, weights
+}
+
+// posterior fills post with a person's normalized posterior over the nodes.
+func posterior(responses []Response, idx []int, params [][]float64, cOf func([]float64) float64, nodes, weights, post []float64) {
+	logs := make([]float64, len(nodes))
+	maxLog := math.Inf(-1)
+	for q, theta := range nodes {
+		ll := math.Log(weights[q])
+		for _, i := range idx {
+			resp := responses[i]
+			p := params[resp.Item]
+			ll += logBernoulli(Prob(theta, math.Exp(p[0]), p[1], cOf(p)), resp.Correct)
+		}
+		logs[q] = ll
+		maxLog = math.Max(maxLog, ll)
+	}
+	total := 0.0
+	for q, ll := range logs {
+		post[q] = math.Exp(ll - maxLog)
+		total += post[q]
+	}
+	for q := range post {
+		post[q] /= total
+	}
+}
+
+// itemObjective is the expected complete-data log likelihood of one item (plus
+// its log prior when withPrior is set) as a function of its unconstrained parameters.
+func itemObjective(nodes, n, r, current []float64, threePL, withPrior bool) func([]float64) float64 {
+	return func(x []float64) float64 {
+		logitC := current[2]
+		if threePL {
+			logitC = x[2]
+		}
+		a, b, c := math.Exp(x[0]), x[1], 0.0
+		if threePL {
+			c = logistic(logitC)
+		}
+		ll := 0.0
+		for q, theta := range nodes {
+			if n[q] == 0 {
+				continue
+			}
+			p := math.Min(math.Max(Prob(theta, a, b, c), minProb), 1-minProb)
+			ll += r[q]*math.Log(p) + (n[q]-r[q])*math.Log(1-p)
+		}
+		if withPrior {
+			ll += logNormal(x[0], 0, priorLogASD) + logNormal(b, 0, priorBSD)
+			if threePL {
+				ll += logNormal(logitC, priorLogitC, priorLogitSD)
+			}
+		}
+		return ll
+	}
+}
+
+func initialB(responses []Response, idx []int) float64 {
+	if len(idx) == 0 {
+		return 0
+	}
+	correct := 0.5
+	for _, i := range idx {
+		if responses[i].Correct {
+			correct++
+		}
+	}
+	p := correct / (float64(len(idx)) + 1)
+	return -math.Log(p / (1 - p))
+}
+
+func logBernoulli(p float64, correct bool) float64 {
+	p = math.Min(math.Max(p, minProb), 1-minProb)
+	if correct {
+		return math.Log(p)
+	}
+	return math.Log(1 - p)
+}
+
+func logNormal(x, mean, sd float64) float64 {
+	z := (x - mean) / sd
+	return -0.5 * z * z
+}
+
+func logistic(x float64) float64 {
+	if x > 35 {
+		return 1
+	}
+	if x < -35 {
+		return 0
+	}
+	return 1 / (1 + math.Exp(-x))
+}
+
+const diffStep = 1e-4
+
+// newtonStep takes one damped Newton step uphill on f, falling back to
+// gradient ascent when the Hessian is not negative definite.
+func newtonStep(f func([]float64) float64, x []float64) []float64 {
+	g, h := derivatives(f, x)
+	dir, ok := solve(negate(h), g)
+	if !ok || dot(dir, g) <= 0 {
+		dir = g
+	}
+	base := f(x)
+	step := 1.0
+	for k := 0; k < 20; k++ {
+		cand := make([]float64, len(x))
+		for i := range x {
+			d := step * dir[i]
+			// Bound each move to keep early iterations stable.
+			d = math.Max(-1, math.Min(1, d))
+			cand[i] = x[i] + d
+		}
+		if f(cand) >= base {
+			return cand
+		}
+		step /= 2
+	}
+	return x
+}
+
+func standardErrors(f func([]float64) float64, x []float64) []float64 {
+	_, h := derivatives(f, x)
+	n := len(x)
+	se := make([]float64, n)
+	info := negate(h)
+	for i := 0; i < n; i++ {
+		e := make([]float64, n)
+		e[i] = 1
+		col, ok := solve(info, e)
+		if !ok || col[i] <= 0 {
+			se[i] = math.Inf(1)
+			continue
+		}
+		se[i] = math.Sqrt(col[i])
+	}
+	return se
+}
+
+func derivatives(f func([]float64) float64, x []float64) ([]float64, [][]float64) {
+	n := len(x)
+	at := func(di, dj int, si, sj float64) float64 {
+		y := append([]float64(nil), x...)
+		y[di] += si
+		if dj >= 0 {
+			y[dj] += sj
+		}
+		return f(y)
+	}
+	f0 := f(x)
+	g := make([]float64, n)
+	h := make([][]float64, n)
+	for i := 0; i < n; i++ {
+		h[i] = make([]float64, n)
+		fp := at(i, -1, diffStep, 0)
+		fm := at(i, -1, -diffStep, 0)
+		g[i] = (fp - fm) / (2 * diffStep)
+		h[i][i] = (fp - 2*f0 + fm) / (diffStep * diffStep)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			v := (at(i, j, diffStep, diffStep) - at(i, j, diffStep, -diffStep) - at(i, j, -diffStep, diffStep) + at(i, j, -diffStep, -diffStep)) / (4 * diffStep * diffStep)
+			h[i][j] = v
+			h[j][i] = v
+		}
+	}
+	return g, h
+}
+
+// solve returns x with m·x = v using Gaussian elimination with partial pivoting.
+func solve(m [][]float64, v []float64) ([]float64, bool) {
+	n := len(v)
+	a := make([][]float64, n)
+	for i := range m {
+		a[i] = append(append([]float64(nil), m[i]...), v[i])
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			factor := a[r][col] / a[col][col]
+			for k := col; k <= n; k++ {
+				a[r][k] -= factor * a[col][k]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = a[i][n] / a[i][i]
+	}
+	return x, true
+}
+
+func negate(m [][]float64) [][]float64 {
+	out := make([][]float64, len(m))
+	for i := range m {
+		out[i] = make([]float64, len(m[i]))
+		for j := range m[i] {
+			out[i][j] = -m[i][j]
+		}
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	s := 0.0
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
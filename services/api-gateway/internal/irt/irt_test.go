@@ -0,0 +1,69 @@
+package irt
+
+import (
+    "math"
+    "math/rand"
+    "testing"
+)
+
+func simulate(rng *rand.Rand, nPersons int, a, b, c []float64) []Response {
+    var out []Response
+    for p := 0; p < nPersons; p++ {
+        theta := rng.NormFloat64()
+        for j := range a {
+            out = append(out, Response{Person: p, Item: j, Correct: rng.Float64() < Prob(theta, a[j], b[j], c[j])})
+        }
+    }
+    return out
+}
+
+func TestFit2PLRecoversParameters(t *testing.T) {
+    rng := rand.New(rand.NewSource(7))
+    a := []float64{0.8, 1.0, 1.2, 1.5, 0.9, 1.1}
+    b := []float64{-1.5, -0.5, 0, 0.5, 1.0, 1.5}
+    c := make([]float64, len(a))
+    responses := simulate(rng, 1500, a, b, c)
+
+    res, err := Fit(responses, 1500, len(a), Options{Model: Model2PL})
+    if err != nil {
+        t.Fatalf("Fit error: %v", err)
+    }
+    if !res.Converged {
+        t.Fatalf("did not converge after %d iterations", res.Iterations)
+    }
+    for j, est := range res.Items {
+        if math.Abs(est.B-b[j]) > 0.35 {
+            t.Fatalf("item %d: b=%.3f, want about %.3f", j, est.B, b[j])
+        }
+        if math.Abs(est.A-a[j]) > 0.45 {
+            t.Fatalf("item %d: a=%.3f, want about %.3f", j, est.A, a[j])
+        }
+        if est.N != 1500 || est.SEA <= 0 || est.SEB <= 0 || est.SEC != nil {
+            t.Fatalf("item %d: unexpected sample size or standard errors: %+v", j, est)
+        }
+    }
+}
+
+func TestFit3PLEstimatesGuessing(t *testing.T) {
+    rng := rand.New(rand.NewSource(11))
+    a := []float64{1.2, 1.4, 1.0, 1.3}
+    b := []float64{-0.5, 0, 0.5, 1.0}
+    c := []float64{0.2, 0.2, 0.2, 0.2}
+    responses := simulate(rng, 1500, a, b, c)
+
+    res, err := Fit(responses, 1500, len(a), Options{Model: Model3PL})
+    if err != nil {
+        t.Fatalf("Fit error: %v", err)
+    }
+    for j, est := range res.Items {
+        if est.SEC == nil || est.C <= 0 || est.C > 0.45 {
+            t.Fatalf("item %d: implausible c=%.3f (se=%v)", j, est.C, est.SEC)
+        }
+    }
+}
+
+func TestFitWithoutResponses(t *testing.T) {
+    if _, err := Fit(nil, 0, 0, Options{}); err != ErrNoData {
+        t.Fatalf("expected ErrNoData, got %v", err)
+    }
+}
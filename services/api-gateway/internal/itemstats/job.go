@@ -0,0 +1,77 @@
+package itemstats
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// advisoryLockKey keeps concurrent gateway replicas from running the nightly job twice.
+const advisoryLockKey int64 = 0x17e5_7a75
+
+// RunNightly recomputes statistics for all answered questions once a day at
+// ITEM_STATS_HOUR_UTC (default 3). A negative hour disables the job. It blocks
+// until ctx is cancelled.
+func RunNightly(ctx context.Context, pool *pgxpool.Pool) {
+	hour := 3
+	if v := strings.TrimSpace(os.Getenv("ITEM_STATS_HOUR_UTC")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n > 23 {
+			log.Printf("itemstats: invalid ITEM_STATS_HOUR_UTC %q, using %d", v, hour)
+		} else {
+			hour = n
+		}
+	}
+	if hour < 0 {
+		return
+	}
+
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := runLocked(ctx, pool); err != nil {
+			log.Printf("itemstats: nightly run failed: %v", err)
+		}
+	}
+}
+
+func runLocked(ctx context.Context, pool *pgxpool.Pool) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `select pg_try_advisory_lock($1)`, advisoryLockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), `select pg_advisory_unlock($1)`, advisoryLockKey) }()
+
+	started := time.Now()
+	n, err := Recompute(ctx, pool, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("itemstats: recomputed %d questions in %s", n, time.Since(started).Round(time.Millisecond))
+	return nil
+}
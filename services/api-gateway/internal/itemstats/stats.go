@@ -0,0 +1,193 @@
+// Package itemstats computes classical item statistics (difficulty,
+// discrimination, timing and distractor analysis) from practice responses.
+package itemstats
+
+import (
+	"math"
+	"sort"
+)
+
+// Flags raised on items that need an author's attention.
+const (
+	FlagLowDiscrimination        = "low_discrimination"
+	FlagNegativeDiscrimination   = "negative_discrimination"
+	FlagDistractorOutperformsKey = "distractor_outperforms_key"
+)
+
+const (
+	// LowDiscriminationThreshold is the point-biserial below which an item is
+	// considered to separate strong and weak students poorly.
+	LowDiscriminationThreshold = 0.2
+
+	// minDistractorCount keeps a handful of lucky picks from flagging a distractor.
+	minDistractorCount = 5
+)
+
+// Response is one student's answer to an item within a practice session.
+type Response struct {
+	ChoiceID string
+	Correct  bool
+	// RestScore is the proportion correct on the other items of the same
+	// session, so the item does not correlate with itself.
+	RestScore   float64
+	TimeSeconds *int
+}
+
+type Choice struct {
+	ID    string
+	IsKey bool
+}
+
+type ChoiceStat struct {
+	ChoiceID  string   `json:"choiceId"`
+	IsKey     bool     `json:"isKey"`
+	Count     int      `json:"count"`
+	Rate      float64  `json:"rate"`
+	MeanScore *float64 `json:"meanScore"`
+	// PointBiserial correlates picking this choice with the rest score; it
+	// should be positive for the key and negative for distractors.
+	PointBiserial *float64 `json:"pointBiserial"`
+}
+
+type Stats struct {
+	Attempts        int          `json:"attempts"`
+	CorrectCount    int          `json:"correctCount"`
+	PValue          *float64     `json:"pValue"`
+	PointBiserial   *float64     `json:"pointBiserial"`
+	MeanTimeSeconds *float64     `json:"meanTimeSeconds"`
+	Choices         []ChoiceStat `json:"choices"`
+	Flags           []string     `json:"flags"`
+}
+
+// Compute derives item statistics from responses. Flags are only raised once
+// the item has at least minAttempts responses.
+func Compute(choices []Choice, responses []Response, minAttempts int) Stats {
+	st := Stats{Attempts: len(responses), Choices: []ChoiceStat{}, Flags: []string{}}
+
+	byChoice := map[string]int{}
+	for i, ch := range choices {
+		byChoice[ch.ID] = i
+		st.Choices = append(st.Choices, ChoiceStat{ChoiceID: ch.ID, IsKey: ch.IsKey})
+	}
+
+	scores := make([]float64, len(responses))
+	correct := make([]bool, len(responses))
+	timeTotal := 0
+	timed := 0
+	for i, r := range responses {
+		scores[i] = r.RestScore
+		correct[i] = r.Correct
+		if r.Correct {
+			st.CorrectCount++
+		}
+		if r.TimeSeconds != nil && *r.TimeSeconds >= 0 {
+			timeTotal += *r.TimeSeconds
+			timed++
+		}
+		// Answers to choices that have since been replaced still count.
+		if _, ok := byChoice[r.ChoiceID]; !ok {
+			byChoice[r.ChoiceID] = len(st.Choices)
+			st.Choices = append(st.Choices, ChoiceStat{ChoiceID: r.ChoiceID, IsKey: r.Correct})
+		}
+		st.Choices[byChoice[r.ChoiceID]].Count++
+	}
+
+	if st.Attempts == 0 {
+		return st
+	}
+	p := float64(st.CorrectCount) / float64(st.Attempts)
+	st.PValue = &p
+	st.PointBiserial = pointBiserial(correct, scores)
+	if timed > 0 {
+		m := float64(timeTotal) / float64(timed)
+		st.MeanTimeSeconds = &m
+	}
+
+	picked := make([]bool, len(responses))
+	for i := range st.Choices {
+		cs := &st.Choices[i]
+		cs.Rate = float64(cs.Count) / float64(st.Attempts)
+		sum := 0.0
+		for j, r := range responses {
+			picked[j] = r.ChoiceID == cs.ChoiceID
+			if picked[j] {
+				sum += r.RestScore
+			}
+		}
+		if cs.Count > 0 {
+			m := sum / float64(cs.Count)
+			cs.MeanScore = &m
+		}
+		cs.PointBiserial = pointBiserial(picked, scores)
+	}
+
+	if st.Attempts >= minAttempts {
+		st.Flags = flags(st)
+	}
+	return st
+}
+
+func flags(st Stats) []string {
+	out := []string{}
+	if st.PointBiserial != nil {
+		if *st.PointBiserial < LowDiscriminationThreshold {
+			out = append(out, FlagLowDiscrimination)
+		}
+		if *st.PointBiserial < 0 {
+			out = append(out, FlagNegativeDiscrimination)
+		}
+	}
+
+	var keyMean *float64
+	for _, cs := range st.Choices {
+		if cs.IsKey && cs.MeanScore != nil {
+			keyMean = cs.MeanScore
+		}
+	}
+	for _, cs := range st.Choices {
+		if cs.IsKey || cs.Count < minDistractorCount || cs.MeanScore == nil {
+			continue
+		}
+		if keyMean == nil || *cs.MeanScore > *keyMean {
+			out = append(out, FlagDistractorOutperformsKey)
+			break
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// pointBiserial returns the correlation between a dichotomous variable and a
+// continuous score, or nil when either has no variance.
+func pointBiserial(x []bool, y []float64) *float64 {
+	n := len(x)
+	if n < 2 || len(y) != n {
+		return nil
+	}
+	var sum, sum1 float64
+	n1 := 0
+	for i := range x {
+		sum += y[i]
+		if x[i] {
+			sum1 += y[i]
+			n1++
+		}
+	}
+	if n1 == 0 || n1 == n {
+		return nil
+	}
+	mean := sum / float64(n)
+	var ss float64
+	for _, v := range y {
+		ss += (v - mean) * (v - mean)
+	}
+	sd := math.Sqrt(ss / float64(n))
+	if sd == 0 {
+		return nil
+	}
+	m1 := sum1 / float64(n1)
+	m0 := (sum - sum1) / float64(n-n1)
+	p := float64(n1) / float64(n)
+	r := (m1 - m0) / sd * math.Sqrt(p*(1-p))
+	return &r
+}
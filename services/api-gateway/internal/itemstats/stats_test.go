@@ -0,0 +1,78 @@
+package itemstats
+
+import (
+    "math"
+    "testing"
+)
+
+func intPtr(v int) *int { return &v }
+
+func hasFlag(st Stats, flag string) bool {
+    for _, f := range st.Flags {
+        if f == flag {
+            return true
+        }
+    }
+    return false
+}
+
+func TestComputeDiscriminatingItem(t *testing.T) {
+    choices := []Choice{{ID: "a", IsKey: true}, {ID: "b"}, {ID: "c"}}
+    responses := []Response{
+        {ChoiceID: "a", Correct: true, RestScore: 0.9, TimeSeconds: intPtr(30)},
+        {ChoiceID: "a", Correct: true, RestScore: 0.8, TimeSeconds: intPtr(40)},
+        {ChoiceID: "a", Correct: true, RestScore: 0.7},
+        {ChoiceID: "b", Correct: false, RestScore: 0.3, TimeSeconds: intPtr(50)},
+        {ChoiceID: "b", Correct: false, RestScore: 0.2},
+    }
+
+    st := Compute(choices, responses, 5)
+    if st.Attempts != 5 || st.CorrectCount != 3 {
+        t.Fatalf("unexpected counts: attempts=%d correct=%d", st.Attempts, st.CorrectCount)
+    }
+    if st.PValue == nil || math.Abs(*st.PValue-0.6) > 1e-9 {
+        t.Fatalf("unexpected p-value: %v", st.PValue)
+    }
+    if st.PointBiserial == nil || *st.PointBiserial < 0.9 {
+        t.Fatalf("expected strong discrimination, got %v", st.PointBiserial)
+    }
+    if st.MeanTimeSeconds == nil || *st.MeanTimeSeconds != 40 {
+        t.Fatalf("unexpected mean time: %v", st.MeanTimeSeconds)
+    }
+    if len(st.Choices) != 3 || st.Choices[2].Count != 0 || st.Choices[2].MeanScore != nil {
+        t.Fatalf("unexpected choice stats: %+v", st.Choices)
+    }
+    if math.Abs(st.Choices[1].Rate-0.4) > 1e-9 {
+        t.Fatalf("unexpected distractor rate: %v", st.Choices[1].Rate)
+    }
+    if len(st.Flags) != 0 {
+        t.Fatalf("unexpected flags: %v", st.Flags)
+    }
+}
+
+func TestComputeFlagsDistractorOutperformingKey(t *testing.T) {
+    choices := []Choice{{ID: "a", IsKey: true}, {ID: "b"}}
+    var responses []Response
+    for i := 0; i < 5; i++ {
+        responses = append(responses, Response{ChoiceID: "a", Correct: true, RestScore: 0.3})
+        responses = append(responses, Response{ChoiceID: "b", Correct: false, RestScore: 0.8})
+    }
+
+    st := Compute(choices, responses, 10)
+    for _, flag := range []string{FlagDistractorOutperformsKey, FlagLowDiscrimination, FlagNegativeDiscrimination} {
+        if !hasFlag(st, flag) {
+            t.Fatalf("missing flag %q in %v", flag, st.Flags)
+        }
+    }
+
+    if st = Compute(choices, responses, 11); len(st.Flags) != 0 {
+        t.Fatalf("flags raised below the attempt threshold: %v", st.Flags)
+    }
+}
+
+func TestComputeWithoutResponses(t *testing.T) {
+    st := Compute([]Choice{{ID: "a", IsKey: true}}, nil, 1)
+    if st.PValue != nil || st.PointBiserial != nil || len(st.Flags) != 0 {
+        t.Fatalf("unexpected stats for an unanswered item: %+v", st)
+    }
+}
@@ -0,0 +1,150 @@
+package itemstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// minSessionAnswers is the smallest session whose answers are used; shorter
+// sessions give too noisy a rest score to correlate against.
+const minSessionAnswers = 5
+
+// Stored is a computed row from question_item_stats.
+type Stored struct {
+	Stats
+	IsFlagged  bool   `json:"isFlagged"`
+	ComputedAt string `json:"computedAt"`
+}
+
+// MinAttempts is the number of responses an item needs before it can be flagged.
+func MinAttempts() int {
+	if v := strings.TrimSpace(os.Getenv("ITEM_STATS_MIN_ATTEMPTS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 30
+}
+
+// Recompute recalculates and stores statistics for the given questions, or for
+// every answered question when questionIDs is nil. It returns the number of
+// questions written.
+func Recompute(ctx context.Context, pool *pgxpool.Pool, questionIDs []string) (int, error) {
+	choices := map[string][]Choice{}
+	rows, err := pool.Query(ctx, `select c.question_id, c.id, (cc.choice_id is not null)
+		from question_bank_choices c
+		left join question_bank_correct_choice cc on cc.question_id=c.question_id and cc.choice_id=c.id
+		where ($1::text[] is null or c.question_id = any($1))
+		order by c.question_id, c.order_index`, questionIDs)
+	if err != nil {
+		return 0, fmt.Errorf("load choices: %w", err)
+	}
+	for rows.Next() {
+		var qid string
+		var ch Choice
+		if err := rows.Scan(&qid, &ch.ID, &ch.IsKey); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("load choices: %w", err)
+		}
+		choices[qid] = append(choices[qid], ch)
+	}
+	rows.Close()
+
+	// First answer per question per session; rest score excludes the item itself.
+	rows, err = pool.Query(ctx, `with a as (
+			select distinct on (pa.session_id, pa.question_id) pa.session_id, pa.question_id, pa.choice_id, pa.correct
+			from practice_answers pa
+			where pa.is_final and ($1::text[] is null or pa.session_id in (select session_id from practice_answers where question_id = any($1)))
+			order by pa.session_id, pa.question_id, pa.ts asc, pa.id asc
+		), s as (
+			select session_id, count(*) as n, count(*) filter (where correct) as c from a group by session_id
+		)
+		select a.question_id, a.choice_id, a.correct, s.n, s.c, nullif(ps.question_timings->>a.question_id, '')::int
+		from a
+		join s on s.session_id=a.session_id
+		join practice_sessions ps on ps.id=a.session_id
+		where s.n >= $2 and ($1::text[] is null or a.question_id = any($1))
+		order by a.question_id`, questionIDs, minSessionAnswers)
+	if err != nil {
+		return 0, fmt.Errorf("load responses: %w", err)
+	}
+	responses := map[string][]Response{}
+	for rows.Next() {
+		var qid string
+		var r Response
+		var n int
+		var c int
+		if err := rows.Scan(&qid, &r.ChoiceID, &r.Correct, &n, &c, &r.TimeSeconds); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("load responses: %w", err)
+		}
+		if r.Correct {
+			c--
+		}
+		r.RestScore = float64(c) / float64(n-1)
+		responses[qid] = append(responses[qid], r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("load responses: %w", err)
+	}
+
+	targets := questionIDs
+	if targets == nil {
+		for qid := range responses {
+			targets = append(targets, qid)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	minAttempts := MinAttempts()
+	batch := &pgx.Batch{}
+	for _, qid := range targets {
+		st := Compute(choices[qid], responses[qid], minAttempts)
+		choiceJSON, _ := json.Marshal(st.Choices)
+		flagsJSON, _ := json.Marshal(st.Flags)
+		batch.Queue(`insert into question_item_stats (question_id, attempts, correct_count, p_value, point_biserial, mean_time_seconds, choice_stats, flags, is_flagged, computed_at)
+			select id, $2, $3, $4, $5, $6, $7, $8, $9, now() from question_bank_questions where id=$1
+			on conflict (question_id) do update set attempts=excluded.attempts, correct_count=excluded.correct_count, p_value=excluded.p_value,
+				point_biserial=excluded.point_biserial, mean_time_seconds=excluded.mean_time_seconds, choice_stats=excluded.choice_stats,
+				flags=excluded.flags, is_flagged=excluded.is_flagged, computed_at=excluded.computed_at`,
+			qid, st.Attempts, st.CorrectCount, st.PValue, st.PointBiserial, st.MeanTimeSeconds, choiceJSON, flagsJSON, len(st.Flags) > 0)
+	}
+	if err := pool.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("store stats: %w", err)
+	}
+	return len(targets), nil
+}
+
+// Load returns the stored statistics for a question, or nil if none were computed yet.
+func Load(ctx context.Context, pool *pgxpool.Pool, questionID string) (*Stored, error) {
+	var s Stored
+	var choiceRaw []byte
+	var flagsRaw []byte
+	var computedAt time.Time
+	err := pool.QueryRow(ctx, `select attempts, correct_count, p_value, point_biserial, mean_time_seconds, choice_stats, flags, is_flagged, computed_at
+		from question_item_stats where question_id=$1`, questionID).
+		Scan(&s.Attempts, &s.CorrectCount, &s.PValue, &s.PointBiserial, &s.MeanTimeSeconds, &choiceRaw, &flagsRaw, &s.IsFlagged, &computedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Choices = []ChoiceStat{}
+	s.Flags = []string{}
+	_ = json.Unmarshal(choiceRaw, &s.Choices)
+	_ = json.Unmarshal(flagsRaw, &s.Flags)
+	s.ComputedAt = computedAt.UTC().Format(time.RFC3339)
+	return &s, nil
+}
@@ -0,0 +1,84 @@
+// Package leaderboard ranks students by their practice results over weekly,
+// monthly and all-time windows, per exam package, practice template or
+// cohort.
+//
+// Boards are maintained incrementally: each finished practice session or
+// submitted exam is recorded once and added to the running totals of every
+// board it belongs to, so reading a board never scans session tables.
+// Points are the credit of graded practice answers; exams are graded in the
+// browser, so submitted exams are counted but earn no points. Ties go to the
+// student who spent less time on practice; exam time does not count.
+package leaderboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ace-platform/api-gateway/internal/policy"
+)
+
+// Windows.
+const (
+	WindowWeek  = "week"
+	WindowMonth = "month"
+	WindowAll   = "all"
+)
+
+// Board scopes. Cohort boards are the global board filtered to the members
+// of a cohort, so they follow membership changes.
+const (
+	ScopePackage  = "package"
+	ScopeTemplate = "template"
+	ScopeCohort   = "cohort"
+	scopeGlobal   = "global"
+)
+
+// Display names are 2 to 32 letters, digits, spaces, '_', '-' or '.'.
+const (
+	MinNameLength = 2
+	MaxNameLength = 32
+)
+
+// ValidWindow reports whether w is a known window.
+func ValidWindow(w string) bool {
+	return w == WindowWeek || w == WindowMonth || w == WindowAll
+}
+
+// PeriodStart is the first day (UTC) of the window containing t: the quota
+// week's Monday, the first of the month, or the epoch for all-time boards.
+func PeriodStart(window string, t time.Time) time.Time {
+	t = t.UTC()
+	switch window {
+	case WindowWeek:
+		return policy.WeekStart(t)
+	case WindowMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// DefaultName is the pseudonym shown for students who have not chosen a
+// display name. It is stable per student and reveals nothing about them.
+func DefaultName(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return "Student " + strings.ToUpper(hex.EncodeToString(sum[:3]))
+}
+
+// NormalizeName trims a chosen display name and reports whether it is
+// acceptable.
+func NormalizeName(name string) (string, bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	n := len([]rune(name))
+	if n < MinNameLength || n > MaxNameLength {
+		return "", false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '_' && r != '-' && r != '.' {
+			return "", false
+		}
+	}
+	return name, true
+}
@@ -0,0 +1,52 @@
+package leaderboard
+
+import (
+    "testing"
+    "time"
+)
+
+func TestPeriodStart(t *testing.T) {
+    // A Sunday evening in New York is already Monday in UTC.
+    ny, _ := time.LoadLocation("America/New_York")
+    at := time.Date(2026, 3, 15, 22, 0, 0, 0, ny)
+    cases := map[string]string{
+        WindowWeek:  "2026-03-16",
+        WindowMonth: "2026-03-01",
+        WindowAll:   "1970-01-01",
+    }
+    for window, want := range cases {
+        if got := PeriodStart(window, at).Format(time.DateOnly); got != want {
+            t.Fatalf("%s: expected %s, got %s", window, want, got)
+        }
+    }
+    if ValidWindow("day") || !ValidWindow(WindowWeek) {
+        t.Fatalf("unexpected window validation")
+    }
+}
+
+func TestDefaultName(t *testing.T) {
+    a, b := DefaultName("usr_1"), DefaultName("usr_2")
+    if a != DefaultName("usr_1") {
+        t.Fatalf("default names should be stable")
+    }
+    if a == b {
+        t.Fatalf("expected different names, got %q twice", a)
+    }
+    if len(a) != len("Student ")+6 {
+        t.Fatalf("unexpected default name %q", a)
+    }
+}
+
+func TestNormalizeName(t *testing.T) {
+    if got, ok := NormalizeName("  Night   Owl_42 "); !ok || got != "Night Owl_42" {
+        t.Fatalf("expected %q, got %q (%v)", "Night Owl_42", got, ok)
+    }
+    if got, ok := NormalizeName("Zoë"); !ok || got != "Zoë" {
+        t.Fatalf("letters outside ASCII should be allowed, got %q (%v)", got, ok)
+    }
+    for _, name := range []string{"", "x", "a@b.com", "<script>", "abcdefghijklmnopqrstuvwxyz0123456"} {
+        if _, ok := NormalizeName(name); ok {
+            t.Fatalf("expected %q to be rejected", name)
+        }
+    }
+}
@@ -0,0 +1,209 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// result is one finished practice session or submitted exam.
+type result struct {
+	userID        string
+	source        string
+	sourceID      string
+	examPackageID *string
+	templateID    *string
+	score         float64
+	seconds       int
+	finishedAt    time.Time
+}
+
+// Entry is a student's line on a board.
+type Entry struct {
+	UserID          string
+	Rank            int
+	DisplayName     string
+	Score           float64
+	DurationSeconds int64
+	Sessions        int
+	Exams           int
+}
+
+// Board is the top of a board and the caller's own line.
+type Board struct {
+	PeriodStart time.Time
+	Items       []Entry
+	// Me is the caller's line, whether or not it is in Items; nil when the
+	// caller has no result in the window or has opted out.
+	Me       *Entry
+	OptedOut bool
+}
+
+// Query selects a board. ScopeID is an exam package, practice template or
+// cohort id.
+type Query struct {
+	Scope   string
+	ScopeID string
+	Window  string
+	At      time.Time
+	UserID  string
+	Limit   int
+}
+
+// RecordPractice adds a finished practice session to its boards: the global
+// board, its exam package's and its template's. Sessions without a graded
+// answer are skipped. Recording a session twice is a no-op.
+func RecordPractice(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) error {
+	var status string
+	var timingsRaw []byte
+	var answered int
+	r := result{userID: userID, source: "practice", sourceID: sessionID}
+	err := pool.QueryRow(ctx, `select s.status, s.package_id::text, s.template_id::text, coalesce(s.last_activity_at, s.created_at), s.question_timings,
+			(select count(*) from practice_answers a where a.session_id=s.id and a.is_final),
+			(select coalesce(sum(coalesce(a.credit, case when a.correct then 1 else 0 end)), 0) from practice_answers a where a.session_id=s.id and a.is_final)
+		from practice_sessions s where s.id=$1 and s.user_id=$2`, sessionID, userID).
+		Scan(&status, &r.examPackageID, &r.templateID, &r.finishedAt, &timingsRaw, &answered, &r.score)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "finished" || answered == 0 {
+		return nil
+	}
+	timings := map[string]int{}
+	_ = json.Unmarshal(timingsRaw, &timings)
+	for _, s := range timings {
+		r.seconds += max(0, s)
+	}
+	return record(ctx, pool, r)
+}
+
+// RecordExam adds a submitted exam session to the global board and its exam
+// package's, like RecordPractice. It counts the exam but adds no points, and
+// so no time: a quick blank exam must not win a tie.
+func RecordExam(ctx context.Context, pool *pgxpool.Pool, userID, sessionID string) error {
+	var status string
+	var createdAt time.Time
+	var submittedAt *time.Time
+	r := result{userID: userID, source: "exam", sourceID: sessionID}
+	err := pool.QueryRow(ctx, `select status, exam_package_id::text, created_at, submitted_at from exam_sessions where user_id=$1 and id=$2`, userID, sessionID).
+		Scan(&status, &r.examPackageID, &createdAt, &submittedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "finished" || submittedAt == nil {
+		return nil
+	}
+	r.finishedAt = *submittedAt
+	r.seconds = max(0, int(submittedAt.Sub(createdAt).Seconds()))
+	return record(ctx, pool, r)
+}
+
+func record(ctx context.Context, pool *pgxpool.Pool, r result) error {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, `insert into leaderboard_results (user_id, source, source_id, exam_package_id, template_id, score, duration_seconds, finished_at)
+		values ($1,$2,$3,$4,$5,$6,$7,$8) on conflict do nothing`,
+		r.userID, r.source, r.sourceID, r.examPackageID, r.templateID, r.score, r.seconds, r.finishedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
+
+	type scope struct{ kind, id string }
+	scopes := []scope{{scopeGlobal, ""}}
+	if r.examPackageID != nil {
+		scopes = append(scopes, scope{ScopePackage, *r.examPackageID})
+	}
+	if r.templateID != nil {
+		scopes = append(scopes, scope{ScopeTemplate, *r.templateID})
+	}
+	sessions, exams, seconds := 1, 0, r.seconds
+	if r.source == "exam" {
+		sessions, exams, seconds = 0, 1, 0
+	}
+	for _, s := range scopes {
+		for _, w := range []string{WindowWeek, WindowMonth, WindowAll} {
+			_, err := tx.Exec(ctx, `insert into leaderboard_scores (scope_type, scope_id, period, period_start, user_id, score, duration_seconds, sessions, exams)
+				values ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+				on conflict (scope_type, scope_id, period, period_start, user_id) do update set
+					score=leaderboard_scores.score+excluded.score,
+					duration_seconds=leaderboard_scores.duration_seconds+excluded.duration_seconds,
+					sessions=leaderboard_scores.sessions+excluded.sessions,
+					exams=leaderboard_scores.exams+excluded.exams,
+					updated_at=now()`,
+				s.kind, s.id, w, PeriodStart(w, r.finishedAt), r.userID, r.score, seconds, sessions, exams)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Load reads a board. Opted-out and deleted students are left out and do
+// not take up ranks; equal scores and times share a rank.
+func Load(ctx context.Context, pool *pgxpool.Pool, q Query) (Board, error) {
+	board := Board{PeriodStart: PeriodStart(q.Window, q.At), Items: []Entry{}}
+	if err := pool.QueryRow(ctx, `select leaderboard_opt_out from users where id=$1`, q.UserID).Scan(&board.OptedOut); err != nil {
+		return Board{}, err
+	}
+
+	scopeType, scopeID := q.Scope, q.ScopeID
+	var cohortID *string
+	if q.Scope == ScopeCohort {
+		scopeType, scopeID = scopeGlobal, ""
+		cohortID = &q.ScopeID
+	}
+	rows, err := pool.Query(ctx, `with board as (
+			select s.user_id, s.score, s.duration_seconds, s.sessions, s.exams, u.leaderboard_name,
+				rank() over (order by s.score desc, s.duration_seconds asc) as rank,
+				row_number() over (order by s.score desc, s.duration_seconds asc, s.user_id) as pos
+			from leaderboard_scores s join users u on u.id=s.user_id
+			where s.scope_type=$1 and s.scope_id=$2 and s.period=$3 and s.period_start=$4
+				and not u.leaderboard_opt_out and u.deleted_at is null
+				and ($5::text is null or exists (select 1 from cohort_members m where m.cohort_id=$5 and m.user_id=s.user_id))
+		)
+		select user_id, rank, leaderboard_name, score, duration_seconds, sessions, exams, pos
+		from board where pos <= $6 or user_id=$7 order by pos`,
+		scopeType, scopeID, q.Window, board.PeriodStart, cohortID, q.Limit, q.UserID)
+	if err != nil {
+		return Board{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e Entry
+		var name *string
+		var pos int
+		if err := rows.Scan(&e.UserID, &e.Rank, &name, &e.Score, &e.DurationSeconds, &e.Sessions, &e.Exams, &pos); err != nil {
+			return Board{}, err
+		}
+		e.DisplayName = DefaultName(e.UserID)
+		if name != nil {
+			e.DisplayName = *name
+		}
+		if e.UserID == q.UserID {
+			me := e
+			board.Me = &me
+		}
+		if pos <= q.Limit {
+			board.Items = append(board.Items, e)
+		}
+	}
+	return board, rows.Err()
+}
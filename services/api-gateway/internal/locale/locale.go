@@ -0,0 +1,165 @@
+// Package locale normalizes language tags and negotiates which locale to
+// serve content in.
+//
+// Tags are a practical subset of BCP 47: a 2-3 letter language, an optional
+// 4 letter script and an optional 2 letter or 3 digit region ("bn",
+// "bn-BD", "zh-Hant-TW"). Matching follows the RFC 4647 lookup scheme: each
+// preferred tag is tried as-is and then with its trailing subtags removed.
+package locale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the source locale assumed for content that does not declare one.
+const Default = "en"
+
+// Normalize returns the canonical form of tag ("BN_bd" -> "bn-BD") and
+// whether it is a tag this package understands.
+func Normalize(tag string) (string, bool) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+	parts := strings.Split(tag, "-")
+	if len(parts) > 3 {
+		return "", false
+	}
+
+	lang := strings.ToLower(parts[0])
+	if len(lang) < 2 || len(lang) > 3 || !isAlpha(lang) {
+		return "", false
+	}
+	out := []string{lang}
+
+	rest := parts[1:]
+	if len(rest) > 0 && len(rest[0]) == 4 && isAlpha(rest[0]) {
+		script := strings.ToUpper(rest[0][:1]) + strings.ToLower(rest[0][1:])
+		out = append(out, script)
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		region := rest[0]
+		switch {
+		case len(region) == 2 && isAlpha(region):
+			out = append(out, strings.ToUpper(region))
+		case len(region) == 3 && isDigit(region):
+			out = append(out, region)
+		default:
+			return "", false
+		}
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		return "", false
+	}
+	return strings.Join(out, "-"), true
+}
+
+// ParseAcceptLanguage returns the tags of an Accept-Language header ordered
+// by descending quality. Invalid tags, "*" and q=0 entries are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	entries := []entry{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag, ok := Normalize(fields[0])
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil {
+				v = 0
+			}
+			q = v
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{tag: tag, q: q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.tag)
+	}
+	return out
+}
+
+// Candidates expands preferred tags into the lookup order: each tag followed
+// by its truncations ("zh-Hant-TW", "zh-Hant", "zh"), without duplicates.
+func Candidates(preferred []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, tag := range preferred {
+		for tag != "" {
+			if !seen[tag] {
+				seen[tag] = true
+				out = append(out, tag)
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return out
+}
+
+// Pick chooses the locale to serve from content written in source with
+// translations available in the given locales. It returns source when the
+// source language comes first in the candidates or nothing else matches.
+func Pick(candidates []string, source string, available []string) string {
+	sourceBase := base(source)
+	has := map[string]bool{}
+	for _, tag := range available {
+		has[tag] = true
+	}
+	for _, tag := range candidates {
+		if tag == source || tag == sourceBase {
+			return source
+		}
+		if has[tag] {
+			return tag
+		}
+	}
+	return source
+}
+
+func base(tag string) string {
+	if i := strings.Index(tag, "-"); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
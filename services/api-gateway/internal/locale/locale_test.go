@@ -0,0 +1,62 @@
+package locale
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestNormalize(t *testing.T) {
+    cases := map[string]string{
+        "bn":         "bn",
+        "BN_bd":      "bn-BD",
+        "zh-hant-tw": "zh-Hant-TW",
+        "es-419":     "es-419",
+        " ar ":       "ar",
+    }
+    for in, want := range cases {
+        got, ok := Normalize(in)
+        if !ok || got != want {
+            t.Fatalf("Normalize(%q) = %q, %v; want %q", in, got, ok, want)
+        }
+    }
+    for _, in := range []string{"", "*", "e", "english", "en-US-x-foo", "en-U1"} {
+        if got, ok := Normalize(in); ok {
+            t.Fatalf("Normalize(%q) = %q, expected invalid", in, got)
+        }
+    }
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+    got := ParseAcceptLanguage("en-US;q=0.5, bn-BD, *;q=0.1, ar;q=0.8, fr;q=0, bad_tag_x")
+    want := []string{"bn-BD", "ar", "en-US"}
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("unexpected order: %v", got)
+    }
+    if got := ParseAcceptLanguage(""); len(got) != 0 {
+        t.Fatalf("expected no tags, got %v", got)
+    }
+}
+
+func TestCandidates(t *testing.T) {
+    got := Candidates([]string{"zh-Hant-TW", "bn-BD", "zh"})
+    want := []string{"zh-Hant-TW", "zh-Hant", "zh", "bn-BD", "bn"}
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("unexpected candidates: %v", got)
+    }
+}
+
+func TestPick(t *testing.T) {
+    available := []string{"bn", "ar"}
+    if got := Pick(Candidates([]string{"bn-BD"}), "en", available); got != "bn" {
+        t.Fatalf("expected bn via truncation, got %q", got)
+    }
+    if got := Pick(Candidates([]string{"en-GB", "bn"}), "en", available); got != "en" {
+        t.Fatalf("expected source language to win when preferred first, got %q", got)
+    }
+    if got := Pick(Candidates([]string{"fr"}), "en", available); got != "en" {
+        t.Fatalf("expected fallback to source, got %q", got)
+    }
+    if got := Pick(nil, "en-US", available); got != "en-US" {
+        t.Fatalf("expected source with no preferences, got %q", got)
+    }
+}
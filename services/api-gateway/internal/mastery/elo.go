@@ -0,0 +1,60 @@
+// Package mastery estimates per-topic student ability for adaptive practice.
+//
+// Abilities and question difficulties share the logit scale of the IRT
+// model: a student with ability theta answers a question of difficulty b
+// correctly with probability 1 / (1 + exp(-(theta-b))). Both are updated
+// after every answer with an Elo rule whose step size shrinks as more
+// answers are seen, so new students and new questions settle quickly and
+// established estimates stay stable.
+package mastery
+
+import "math"
+
+// TargetCorrect is the success rate adaptive practice aims for: hard enough
+// to be useful, easy enough not to discourage.
+const TargetCorrect = 0.7
+
+const (
+	kMax   = 0.8
+	kMin   = 0.1
+	kDecay = 0.05
+)
+
+// Expected is the probability that ability answers difficulty correctly.
+func Expected(ability, difficulty float64) float64 {
+	return 1 / (1 + math.Exp(-(ability - difficulty)))
+}
+
+// K is the Elo step size after the given number of previous attempts.
+func K(attempts int) float64 {
+	return math.Max(kMin, kMax/(1+kDecay*float64(attempts)))
+}
+
+// Update returns the new ability and difficulty after one answer. Each side
+// moves by its own step size; attempts are the counts before this answer.
+func Update(ability float64, abilityAttempts int, difficulty float64, difficultyAttempts int, correct bool) (float64, float64) {
+	score := 0.0
+	if correct {
+		score = 1
+	}
+	return UpdateScore(ability, abilityAttempts, difficulty, difficultyAttempts, score)
+}
+
+// UpdateScore is Update for an answer worth score in [0, 1], such as a
+// correct answer given with hints.
+func UpdateScore(ability float64, abilityAttempts int, difficulty float64, difficultyAttempts int, score float64) (float64, float64) {
+	surprise := score - Expected(ability, difficulty)
+	return ability + K(abilityAttempts)*surprise, difficulty - K(difficultyAttempts)*surprise
+}
+
+// TargetDifficulty is the difficulty a student of the given ability answers
+// correctly with probability TargetCorrect.
+func TargetDifficulty(ability float64) float64 {
+	return ability - math.Log(TargetCorrect/(1-TargetCorrect))
+}
+
+// Level summarizes ability as the expected success rate on a question of
+// average difficulty, in [0, 1].
+func Level(ability float64) float64 {
+	return Expected(ability, 0)
+}
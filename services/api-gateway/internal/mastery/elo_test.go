@@ -0,0 +1,76 @@
+package mastery
+
+import (
+    "math"
+    "math/rand"
+    "testing"
+)
+
+func TestExpected(t *testing.T) {
+    if got := Expected(0, 0); math.Abs(got-0.5) > 1e-9 {
+        t.Fatalf("expected 0.5 for matched ability and difficulty, got %v", got)
+    }
+    if Expected(1, 0) <= Expected(0, 0) || Expected(0, 1) >= Expected(0, 0) {
+        t.Fatalf("expected probability to rise with ability and fall with difficulty")
+    }
+}
+
+func TestUpdateDirection(t *testing.T) {
+    a, d := Update(0, 0, 0, 0, true)
+    if a <= 0 || d >= 0 {
+        t.Fatalf("correct answer should raise ability and lower difficulty, got %v %v", a, d)
+    }
+    a, d = Update(0, 0, 0, 0, false)
+    if a >= 0 || d <= 0 {
+        t.Fatalf("wrong answer should lower ability and raise difficulty, got %v %v", a, d)
+    }
+    // An expected result moves the estimate less than a surprising one.
+    easy, _ := Update(0, 10, -3, 10, true)
+    hard, _ := Update(0, 10, 3, 10, true)
+    if easy >= hard {
+        t.Fatalf("solving a hard question should count for more: easy=%v hard=%v", easy, hard)
+    }
+}
+
+func TestKShrinks(t *testing.T) {
+    if K(0) != kMax {
+        t.Fatalf("expected K(0) = %v, got %v", kMax, K(0))
+    }
+    if K(20) >= K(5) {
+        t.Fatalf("expected step size to shrink with attempts")
+    }
+    if K(100000) != kMin {
+        t.Fatalf("expected step size floor %v, got %v", kMin, K(100000))
+    }
+}
+
+func TestTargetDifficulty(t *testing.T) {
+    for _, ability := range []float64{-2, 0, 1.5} {
+        if got := Expected(ability, TargetDifficulty(ability)); math.Abs(got-TargetCorrect) > 1e-9 {
+            t.Fatalf("expected %v success at target difficulty, got %v", TargetCorrect, got)
+        }
+    }
+}
+
+func TestUpdateConverges(t *testing.T) {
+    rng := rand.New(rand.NewSource(3))
+    const trueAbility = 1.2
+    ability := 0.0
+    for i := 0; i < 400; i++ {
+        difficulty := rng.NormFloat64()
+        correct := rng.Float64() < Expected(trueAbility, difficulty)
+        // Question difficulties are treated as known here.
+        ability, _ = Update(ability, i, difficulty, 1<<20, correct)
+    }
+    if math.Abs(ability-trueAbility) > 0.5 {
+        t.Fatalf("expected ability near %v, got %v", trueAbility, ability)
+    }
+}
+
+func TestUpdateScoreHintedCountsLess(t *testing.T) {
+    full, _ := Update(0, 5, 0, 5, true)
+    hinted, _ := UpdateScore(0, 5, 0, 5, 0.75)
+    if hinted >= full || hinted <= 0 {
+        t.Fatalf("a partially credited answer should raise ability less than a full one: full=%v hinted=%v", full, hinted)
+    }
+}
@@ -0,0 +1,88 @@
+package mastery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DifficultySQL is the current difficulty of the question alias.id: its
+// Elo rating, seeded from the latest IRT b until it has been rated, else 0.
+func DifficultySQL(alias string) string {
+	return `coalesce(
+		(select r.rating from question_elo_ratings r where r.question_id=` + alias + `.id),
+		(select p.b from question_irt_params p where p.question_id=` + alias + `.id order by p.version desc limit 1),
+		0)`
+}
+
+// Beginner is a *pgxpool.Pool or a pgx.Tx.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Record updates the student's mastery of the question's topic and the
+// question's difficulty after one answer. Credit, in [0, 1], is what the
+// answer counts for in the ratings: 1 for a plain correct answer, less when
+// hints were used. Questions without a topic only update their difficulty.
+// Unknown questions are ignored.
+//
+// Given the transaction that records the answer, Record runs in a savepoint
+// of it: the update commits or rolls back with the answer it counts, and a
+// failed update leaves the transaction usable.
+func Record(ctx context.Context, db Beginner, userID, questionID string, correct bool, credit float64) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `insert into question_elo_ratings (question_id, rating)
+		select q.id, `+DifficultySQL("q")+` from question_bank_questions q where q.id=$1
+		on conflict (question_id) do nothing`, questionID)
+	if err != nil {
+		return fmt.Errorf("seed question rating: %w", err)
+	}
+
+	var topicID *string
+	var difficulty float64
+	var difficultyAttempts int
+	err = tx.QueryRow(ctx, `select q.topic_id, r.rating, r.attempts
+		from question_bank_questions q join question_elo_ratings r on r.question_id=q.id
+		where q.id=$1 for update of r`, questionID).Scan(&topicID, &difficulty, &difficultyAttempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ability, abilityAttempts := 0.0, 0
+	if topicID != nil {
+		_, err = tx.Exec(ctx, `insert into user_topic_mastery (user_id, topic_id) values ($1,$2) on conflict (user_id, topic_id) do nothing`, userID, *topicID)
+		if err != nil {
+			return fmt.Errorf("seed mastery: %w", err)
+		}
+		err = tx.QueryRow(ctx, `select rating, attempts from user_topic_mastery where user_id=$1 and topic_id=$2 for update`, userID, *topicID).
+			Scan(&ability, &abilityAttempts)
+		if err != nil {
+			return err
+		}
+	}
+
+	newAbility, newDifficulty := UpdateScore(ability, abilityAttempts, difficulty, difficultyAttempts, credit)
+
+	if _, err := tx.Exec(ctx, `update question_elo_ratings set rating=$2, attempts=attempts+1, updated_at=now() where question_id=$1`, questionID, newDifficulty); err != nil {
+		return err
+	}
+	if topicID != nil {
+		_, err = tx.Exec(ctx, `update user_topic_mastery
+			set rating=$3, attempts=attempts+1, correct_count=correct_count + case when $4 then 1 else 0 end, updated_at=now()
+			where user_id=$1 and topic_id=$2`, userID, *topicID, newAbility, correct)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
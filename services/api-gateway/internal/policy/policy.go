@@ -0,0 +1,113 @@
+// Package policy is the typed form of an exam package tier's policy: what the
+// students enrolled on that tier may do.
+//
+// Every field is optional and an absent field never restricts anything, so
+// the empty object {} is an unrestricted tier. Quotas are counted per
+// calendar week, starting Monday 00:00 UTC.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Policy struct {
+	// Session quotas per exam package and week. 0 blocks the session kind.
+	MaxPracticeSessionsPerWeek *int `json:"maxPracticeSessionsPerWeek,omitempty"`
+	MaxExamSessionsPerWeek     *int `json:"maxExamSessionsPerWeek,omitempty"`
+	// MaxQuestionsPerSession caps the size of a practice session.
+	MaxQuestionsPerSession *int `json:"maxQuestionsPerSession,omitempty"`
+	// Feature switches; nil means allowed.
+	TimedPractice *bool `json:"timedPractice,omitempty"`
+	Explanations  *bool `json:"explanations,omitempty"`
+	Review        *bool `json:"review,omitempty"`
+	// HintPenalty is the credit a correct practice answer loses per hint
+	// used, between 0 and 1; nil uses the default.
+	HintPenalty *float64 `json:"hintPenalty,omitempty"`
+}
+
+// Parse decodes and validates a policy written by an admin. Unknown fields,
+// wrong types and out-of-range values are errors; empty input is {}.
+func Parse(raw []byte) (Policy, error) {
+	var p Policy
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return p, nil
+	}
+	if raw[0] != '{' {
+		return p, errors.New("policy must be a json object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy: %v", err)
+	}
+	if dec.More() {
+		return Policy{}, errors.New("invalid policy: trailing data")
+	}
+	return p, p.Validate()
+}
+
+// Decode reads a stored policy. Stored policies predate validation, so
+// Decode keeps every known field that is valid on its own and drops the rest:
+// an unknown key or a bad switch must not lift the tier's quotas.
+func Decode(raw []byte) Policy {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return Policy{}
+	}
+	valid := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		one, _ := json.Marshal(map[string]json.RawMessage{key: value})
+		var p Policy
+		if err := json.Unmarshal(one, &p); err == nil && p.Validate() == nil {
+			valid[key] = value
+		}
+	}
+	var p Policy
+	kept, _ := json.Marshal(valid)
+	_ = json.Unmarshal(kept, &p)
+	return p
+}
+
+// Validate checks value ranges.
+func (p Policy) Validate() error {
+	if p.MaxPracticeSessionsPerWeek != nil && *p.MaxPracticeSessionsPerWeek < 0 {
+		return errors.New("maxPracticeSessionsPerWeek must not be negative")
+	}
+	if p.MaxExamSessionsPerWeek != nil && *p.MaxExamSessionsPerWeek < 0 {
+		return errors.New("maxExamSessionsPerWeek must not be negative")
+	}
+	if p.MaxQuestionsPerSession != nil && *p.MaxQuestionsPerSession < 1 {
+		return errors.New("maxQuestionsPerSession must be at least 1")
+	}
+	if p.HintPenalty != nil && (*p.HintPenalty < 0 || *p.HintPenalty > 1) {
+		return errors.New("hintPenalty must be between 0 and 1")
+	}
+	return nil
+}
+
+func (p Policy) AllowsTimedPractice() bool { return p.TimedPractice == nil || *p.TimedPractice }
+func (p Policy) AllowsExplanations() bool  { return p.Explanations == nil || *p.Explanations }
+func (p Policy) AllowsReview() bool        { return p.Review == nil || *p.Review }
+
+// Remaining is what is left of a quota after used sessions; nil when the
+// quota is unlimited.
+func Remaining(limit *int, used int) *int {
+	if limit == nil {
+		return nil
+	}
+	v := max(0, *limit-used)
+	return &v
+}
+
+// WeekStart is the start of the quota week containing t.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
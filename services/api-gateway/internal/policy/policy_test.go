@@ -0,0 +1,85 @@
+package policy
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParse(t *testing.T) {
+    p, err := Parse([]byte(`{"maxPracticeSessionsPerWeek": 3, "review": false}`))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if p.MaxPracticeSessionsPerWeek == nil || *p.MaxPracticeSessionsPerWeek != 3 {
+        t.Fatalf("unexpected quota %v", p.MaxPracticeSessionsPerWeek)
+    }
+    if p.AllowsReview() || !p.AllowsExplanations() || !p.AllowsTimedPractice() {
+        t.Fatalf("unexpected switches %+v", p)
+    }
+
+    if p, err := Parse(nil); err != nil || p.MaxExamSessionsPerWeek != nil {
+        t.Fatalf("empty policy should be unrestricted, got %+v, %v", p, err)
+    }
+}
+
+func TestParseRejects(t *testing.T) {
+    for _, raw := range []string{
+        `[]`,
+        `{"maxSessions": 3}`,
+        `{"review": "no"}`,
+        `{"maxExamSessionsPerWeek": -1}`,
+        `{"maxQuestionsPerSession": 0}`,
+        `{"hintPenalty": 1.5}`,
+        `{} {}`,
+    } {
+        if _, err := Parse([]byte(raw)); err == nil {
+            t.Fatalf("expected %s to be rejected", raw)
+        }
+    }
+}
+
+func TestDecodeIsLenient(t *testing.T) {
+    p := Decode([]byte(`{"legacy": true}`))
+    if p.MaxPracticeSessionsPerWeek != nil || !p.AllowsReview() {
+        t.Fatalf("invalid stored policy should be unrestricted, got %+v", p)
+    }
+    if p := Decode([]byte(`not json`)); p.MaxExamSessionsPerWeek != nil {
+        t.Fatalf("unreadable stored policy should be unrestricted, got %+v", p)
+    }
+}
+
+func TestDecodeKeepsKnownFields(t *testing.T) {
+    p := Decode([]byte(`{"legacy": true, "maxPracticeSessionsPerWeek": 3, "maxExamSessionsPerWeek": 1, "review": "no", "hintPenalty": 2}`))
+    if p.MaxPracticeSessionsPerWeek == nil || *p.MaxPracticeSessionsPerWeek != 3 || p.MaxExamSessionsPerWeek == nil || *p.MaxExamSessionsPerWeek != 1 {
+        t.Fatalf("quotas should survive unknown and invalid fields, got %+v", p)
+    }
+    if !p.AllowsReview() || p.HintPenalty != nil {
+        t.Fatalf("invalid fields should be dropped, got %+v", p)
+    }
+}
+
+func TestRemaining(t *testing.T) {
+    if Remaining(nil, 5) != nil {
+        t.Fatalf("unlimited quota should have no remaining count")
+    }
+    limit := 3
+    if got := *Remaining(&limit, 1); got != 2 {
+        t.Fatalf("Remaining = %d, want 2", got)
+    }
+    if got := *Remaining(&limit, 7); got != 0 {
+        t.Fatalf("Remaining = %d, want 0", got)
+    }
+}
+
+func TestWeekStart(t *testing.T) {
+    monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+    for _, at := range []time.Time{
+        monday,
+        time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC),
+        time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC),
+    } {
+        if got := WeekStart(at); !got.Equal(monday) {
+            t.Fatalf("WeekStart(%v) = %v, want %v", at, got, monday)
+        }
+    }
+}
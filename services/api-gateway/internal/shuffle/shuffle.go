@@ -0,0 +1,46 @@
+// Package shuffle produces deterministic per-session choice orders.
+//
+// A session stores a single seed; each question's order is derived from that
+// seed and the question id, so the same session always shows (and reviews)
+// the same order without persisting every permutation.
+package shuffle
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
+	mrand "math/rand/v2"
+)
+
+// NewSeed returns a random seed for a new session.
+func NewSeed() int64 {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return int64(binary.LittleEndian.Uint64(b[:]) >> 1)
+}
+
+// Order returns a permutation of [0, n): out[i] is the canonical index shown
+// at display position i. Pinned canonical positions (pinned[i] == true) keep
+// their place, so "All of the above" authored last stays last. Indexes beyond
+// len(pinned) are unpinned.
+func Order(seed int64, key string, n int, pinned []bool) []int {
+	out := make([]int, n)
+	free := make([]int, 0, n)
+	for i := range out {
+		out[i] = i
+		if i >= len(pinned) || !pinned[i] {
+			free = append(free, i)
+		}
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	rng := mrand.New(mrand.NewPCG(uint64(seed), h.Sum64()))
+
+	vals := append([]int(nil), free...)
+	rng.Shuffle(len(vals), func(i, j int) { vals[i], vals[j] = vals[j], vals[i] })
+	for i, pos := range free {
+		out[pos] = vals[i]
+	}
+	return out
+}
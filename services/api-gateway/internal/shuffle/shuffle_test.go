@@ -0,0 +1,65 @@
+package shuffle
+
+import (
+    "sort"
+    "testing"
+)
+
+func isPermutation(order []int) bool {
+    seen := append([]int(nil), order...)
+    sort.Ints(seen)
+    for i, v := range seen {
+        if v != i {
+            return false
+        }
+    }
+    return true
+}
+
+func TestOrderIsDeterministicPermutation(t *testing.T) {
+    a := Order(42, "qst_1", 5, nil)
+    b := Order(42, "qst_1", 5, nil)
+    if !isPermutation(a) {
+        t.Fatalf("not a permutation: %v", a)
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            t.Fatalf("same seed and key gave different orders: %v vs %v", a, b)
+        }
+    }
+}
+
+func TestOrderVariesBySeedAndKey(t *testing.T) {
+    base := Order(1, "qst_1", 5, nil)
+    differs := func(o []int) bool {
+        for i := range o {
+            if o[i] != base[i] {
+                return true
+            }
+        }
+        return false
+    }
+    seedDiffers, keyDiffers := false, false
+    for s := int64(2); s < 50 && !seedDiffers; s++ {
+        seedDiffers = differs(Order(s, "qst_1", 5, nil))
+    }
+    for k := 0; k < 50 && !keyDiffers; k++ {
+        keyDiffers = differs(Order(1, "qst_"+string(rune('a'+k%26))+string(rune('a'+k/26)), 5, nil))
+    }
+    if !seedDiffers || !keyDiffers {
+        t.Fatalf("expected orders to vary (seed=%v key=%v)", seedDiffers, keyDiffers)
+    }
+}
+
+func TestOrderKeepsPinnedPositions(t *testing.T) {
+    pinned := []bool{false, false, false, true}
+    for s := int64(0); s < 100; s++ {
+        o := Order(s, "qst_1", 4, pinned)
+        if !isPermutation(o) {
+            t.Fatalf("not a permutation: %v", o)
+        }
+        if o[3] != 3 {
+            t.Fatalf("pinned choice moved: %v", o)
+        }
+    }
+}
@@ -0,0 +1,76 @@
+// Package srs schedules review-deck items with the SM-2 algorithm.
+//
+// Each answer is graded 0-5 from its correctness and response time; grades
+// below 3 are lapses that restart the item at a one day interval, higher
+// grades grow the interval by the item's ease factor.
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultEase = 2.5
+	MinEase     = 1.3
+)
+
+// Card is the scheduling state of one deck item.
+type Card struct {
+	Ease         float64
+	IntervalDays int
+	Repetitions  int
+	Lapses       int
+}
+
+// NewCard is the state of an item that has not been reviewed yet.
+func NewCard() Card {
+	return Card{Ease: DefaultEase}
+}
+
+// Grade turns an answer into an SM-2 quality. Wrong answers are 1; right
+// answers are 5 when quick, 4 within expectedSeconds and 3 when slower.
+// A non-positive seconds (unknown) counts as within the expected time.
+func Grade(correct bool, seconds, expectedSeconds int) int {
+	switch {
+	case !correct:
+		return 1
+	case seconds > 0 && seconds*3 <= expectedSeconds:
+		return 5
+	case seconds <= expectedSeconds:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// Schedule returns the card after a review graded quality (0-5).
+func Schedule(c Card, quality int) Card {
+	quality = max(0, min(5, quality))
+	if c.Ease == 0 {
+		c.Ease = DefaultEase
+	}
+	if quality < 3 {
+		c.Repetitions = 0
+		c.IntervalDays = 1
+		c.Lapses++
+	} else {
+		switch c.Repetitions {
+		case 0:
+			c.IntervalDays = 1
+		case 1:
+			c.IntervalDays = 6
+		default:
+			c.IntervalDays = int(math.Round(float64(c.IntervalDays) * c.Ease))
+		}
+		c.Repetitions++
+	}
+	miss := float64(5 - quality)
+	c.Ease = math.Max(MinEase, c.Ease+0.1-miss*(0.08+miss*0.02))
+	return c
+}
+
+// Due is when a card reviewed at reviewedAt comes up again.
+func (c Card) Due(reviewedAt time.Time) time.Time {
+	return reviewedAt.AddDate(0, 0, c.IntervalDays)
+}
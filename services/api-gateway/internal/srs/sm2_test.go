@@ -0,0 +1,64 @@
+package srs
+
+import (
+    "testing"
+    "time"
+)
+
+func TestGrade(t *testing.T) {
+    cases := []struct {
+        correct bool
+        seconds int
+        want    int
+    }{
+        {false, 5, 1},
+        {true, 10, 5},
+        {true, 45, 4},
+        {true, 0, 4},
+        {true, 120, 3},
+    }
+    for _, tc := range cases {
+        if got := Grade(tc.correct, tc.seconds, 60); got != tc.want {
+            t.Fatalf("Grade(%v, %d) = %d, want %d", tc.correct, tc.seconds, got, tc.want)
+        }
+    }
+}
+
+func TestScheduleIntervals(t *testing.T) {
+    c := NewCard()
+    var intervals []int
+    for i := 0; i < 4; i++ {
+        c = Schedule(c, 4)
+        intervals = append(intervals, c.IntervalDays)
+    }
+    if intervals[0] != 1 || intervals[1] != 6 || intervals[2] != 15 || intervals[3] != 38 {
+        t.Fatalf("unexpected intervals %v", intervals)
+    }
+    if c.Ease != DefaultEase {
+        t.Fatalf("quality 4 should keep the ease factor, got %v", c.Ease)
+    }
+}
+
+func TestScheduleLapse(t *testing.T) {
+    c := Card{Ease: 2.5, IntervalDays: 15, Repetitions: 3}
+    c = Schedule(c, 1)
+    if c.IntervalDays != 1 || c.Repetitions != 0 || c.Lapses != 1 {
+        t.Fatalf("lapse should restart the card, got %+v", c)
+    }
+    if c.Ease >= 2.5 {
+        t.Fatalf("lapse should lower ease, got %v", c.Ease)
+    }
+    for i := 0; i < 10; i++ {
+        c = Schedule(c, 0)
+    }
+    if c.Ease != MinEase {
+        t.Fatalf("expected ease floor %v, got %v", MinEase, c.Ease)
+    }
+}
+
+func TestDue(t *testing.T) {
+    at := time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)
+    if got := (Card{IntervalDays: 6}).Due(at); !got.Equal(time.Date(2026, 2, 5, 12, 0, 0, 0, time.UTC)) {
+        t.Fatalf("unexpected due date %v", got)
+    }
+}
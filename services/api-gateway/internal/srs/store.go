@@ -0,0 +1,69 @@
+package srs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// How an item got into the deck.
+const (
+	SourceMissed     = "missed"
+	SourceBookmarked = "bookmarked"
+)
+
+// RecordAnswer updates the student's deck after an answer graded quality.
+// In review sessions every answer reschedules its item. Elsewhere only
+// lapses matter: a missed question is enrolled (or, if already in the deck,
+// restarted) and correct answers leave the deck alone.
+func RecordAnswer(ctx context.Context, pool *pgxpool.Pool, userID, questionID string, quality int, review bool) error {
+	if !review && quality >= 3 {
+		return nil
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	card := NewCard()
+	err = tx.QueryRow(ctx, `select ease_factor, interval_days, repetitions, lapses from review_deck_items where user_id=$1 and question_id=$2 for update`,
+		userID, questionID).Scan(&card.Ease, &card.IntervalDays, &card.Repetitions, &card.Lapses)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if review {
+			// Removed from the deck while the session was running.
+			return nil
+		}
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	card = Schedule(card, quality)
+	_, err = tx.Exec(ctx, `insert into review_deck_items (user_id, question_id, source, ease_factor, interval_days, repetitions, lapses, due_at, last_reviewed_at)
+		values ($1,$2,$3,$4,$5,$6,$7, now() + make_interval(days => $5), now())
+		on conflict (user_id, question_id) do update set
+			ease_factor=excluded.ease_factor, interval_days=excluded.interval_days, repetitions=excluded.repetitions, lapses=excluded.lapses,
+			due_at=excluded.due_at, last_reviewed_at=excluded.last_reviewed_at, updated_at=now()`,
+		userID, questionID, SourceMissed, card.Ease, card.IntervalDays, card.Repetitions, card.Lapses)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Enroll adds a question to the student's deck, due immediately. It reports
+// whether the question was added (false if it was already in the deck).
+func Enroll(ctx context.Context, pool *pgxpool.Pool, userID, questionID, source string) (bool, error) {
+	ct, err := pool.Exec(ctx, `insert into review_deck_items (user_id, question_id, source, ease_factor, due_at)
+		values ($1,$2,$3,$4, now())
+		on conflict (user_id, question_id) do nothing`, userID, questionID, source, DefaultEase)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() == 1, nil
+}
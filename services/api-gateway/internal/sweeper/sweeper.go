@@ -0,0 +1,354 @@
+// Package sweeper finalizes sessions students walked away from: timed
+// practice sessions past their time limit (grading free-navigation answers),
+// and exam sessions whose heartbeats stopped. Each transition records a
+// session event and an audit entry with the "system" role.
+//
+// Every gateway replica runs the sweeper; a Postgres advisory lock lets only
+// one of them sweep at a time.
+package sweeper
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ace-platform/api-gateway/internal/gamification"
+	"github.com/ace-platform/api-gateway/internal/grading"
+	"github.com/ace-platform/api-gateway/internal/leaderboard"
+)
+
+// AdvisoryLockKey is the Postgres advisory lock a sweep holds, which keeps
+// concurrent gateway replicas from sweeping at once.
+const AdvisoryLockKey int64 = 0x5e55_1075
+
+// What happens to an exam session whose heartbeats stopped.
+const (
+	ActionSubmit  = "submit"
+	ActionAbandon = "abandon"
+)
+
+const batchSize = 200
+
+type Config struct {
+	// Interval between sweeps; zero disables the sweeper.
+	Interval time.Duration
+	// HeartbeatTimeout is the heartbeat gap after which an active exam
+	// session is considered stale.
+	HeartbeatTimeout time.Duration
+	// StaleExamAction is ActionSubmit or ActionAbandon.
+	StaleExamAction string
+}
+
+// ConfigFromEnv reads SESSION_SWEEP_INTERVAL_SECONDS (default 60, 0 or less
+// disables), EXAM_HEARTBEAT_TIMEOUT_SECONDS (default 1800) and
+// EXAM_STALE_ACTION (submit or abandon, default submit).
+func ConfigFromEnv() Config {
+	cfg := Config{Interval: time.Minute, HeartbeatTimeout: 30 * time.Minute, StaleExamAction: ActionSubmit}
+	if v := strings.TrimSpace(os.Getenv("SESSION_SWEEP_INTERVAL_SECONDS")); v != "" {
+		if n, err := strconv.Atoi(v); err != nil {
+			log.Printf("sweeper: invalid SESSION_SWEEP_INTERVAL_SECONDS %q, using %s", v, cfg.Interval)
+		} else {
+			cfg.Interval = time.Duration(max(0, n)) * time.Second
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("EXAM_HEARTBEAT_TIMEOUT_SECONDS")); v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			log.Printf("sweeper: invalid EXAM_HEARTBEAT_TIMEOUT_SECONDS %q, using %s", v, cfg.HeartbeatTimeout)
+		} else {
+			cfg.HeartbeatTimeout = time.Duration(n) * time.Second
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("EXAM_STALE_ACTION"))); v != "" {
+		if v != ActionSubmit && v != ActionAbandon {
+			log.Printf("sweeper: invalid EXAM_STALE_ACTION %q, using %s", v, cfg.StaleExamAction)
+		} else {
+			cfg.StaleExamAction = v
+		}
+	}
+	return cfg
+}
+
+// Result counts the sessions one sweep finalized.
+type Result struct {
+	PracticeExpired int
+	ExamsSubmitted  int
+	ExamsAbandoned  int
+}
+
+// Run sweeps every cfg.Interval until ctx is cancelled.
+func Run(ctx context.Context, pool *pgxpool.Pool, cfg Config) {
+	if cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		res, _, err := TrySweep(ctx, pool, cfg)
+		if err != nil {
+			log.Printf("sweeper: sweep failed: %v", err)
+			continue
+		}
+		if res != (Result{}) {
+			log.Printf("sweeper: expired %d practice sessions, submitted %d and abandoned %d exam sessions",
+				res.PracticeExpired, res.ExamsSubmitted, res.ExamsAbandoned)
+		}
+	}
+}
+
+// TrySweep runs Sweep under AdvisoryLockKey. It reports false, sweeping
+// nothing, when another sweep holds the lock.
+func TrySweep(ctx context.Context, pool *pgxpool.Pool, cfg Config) (Result, bool, error) {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return Result{}, false, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `select pg_try_advisory_lock($1)`, AdvisoryLockKey).Scan(&locked); err != nil {
+		return Result{}, false, err
+	}
+	if !locked {
+		return Result{}, false, nil
+	}
+	defer func() { _, _ = conn.Exec(context.Background(), `select pg_advisory_unlock($1)`, AdvisoryLockKey) }()
+
+	res, err := Sweep(ctx, pool, cfg)
+	return res, true, err
+}
+
+// Sweep finalizes every overdue session, in batches. Callers other than Run
+// and TrySweep must make sure no other sweep is running.
+func Sweep(ctx context.Context, pool *pgxpool.Pool, cfg Config) (Result, error) {
+	var res Result
+	for {
+		n, err := expirePracticeBatch(ctx, pool)
+		res.PracticeExpired += n
+		if err != nil {
+			return res, err
+		}
+		if n < batchSize {
+			break
+		}
+	}
+	for {
+		n, err := closeStaleExamBatch(ctx, pool, cfg)
+		if cfg.StaleExamAction == ActionAbandon {
+			res.ExamsAbandoned += n
+		} else {
+			res.ExamsSubmitted += n
+		}
+		if err != nil {
+			return res, err
+		}
+		if n < batchSize {
+			break
+		}
+	}
+	return res, nil
+}
+
+// expirePracticeBatch finishes up to batchSize timed practice sessions whose
+// time limit has passed.
+func expirePracticeBatch(ctx context.Context, pool *pgxpool.Pool) (int, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	type expired struct {
+		ID, UserID        string
+		Deadline          time.Time
+		CurrentIndex      int
+		OrderRaw          []byte
+		TimingsRaw        []byte
+		QuestionStartedAt *time.Time
+		Navigation        string
+	}
+	rows, err := tx.Query(ctx, `select id, user_id, started_at + make_interval(secs => time_limit_seconds), current_index, question_order, question_timings, current_question_started_at, navigation
+		from practice_sessions
+		where status='active' and is_timed and time_limit_seconds is not null
+			and started_at + make_interval(secs => time_limit_seconds + coalesce((timing->>'graceSeconds')::int, 0)) <= now()
+		order by started_at asc
+		limit $1
+		for update skip locked`, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	var sessions []expired
+	for rows.Next() {
+		var s expired
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Deadline, &s.CurrentIndex, &s.OrderRaw, &s.TimingsRaw, &s.QuestionStartedAt, &s.Navigation); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		sessions = append(sessions, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, s := range sessions {
+		var order []string
+		_ = json.Unmarshal(s.OrderRaw, &order)
+		timings := map[string]int{}
+		_ = json.Unmarshal(s.TimingsRaw, &timings)
+		if s.QuestionStartedAt != nil {
+			timings = CreditCurrentQuestion(timings, order, s.CurrentIndex, *s.QuestionStartedAt, s.Deadline)
+		}
+		timingsJSON, _ := json.Marshal(timings)
+
+		if _, err := tx.Exec(ctx, `update practice_sessions set status='finished', question_timings=$2, last_activity_at=now() where id=$1`, s.ID, timingsJSON); err != nil {
+			return 0, err
+		}
+		deadline := s.Deadline.UTC().Format(time.RFC3339)
+		payload, _ := json.Marshal(map[string]any{"deadline": deadline})
+		if _, err := tx.Exec(ctx, `insert into practice_session_events (session_id, user_id, event_type, payload) values ($1,$2,'expired',$3)`,
+			s.ID, s.UserID, payload); err != nil {
+			return 0, err
+		}
+		if err := audit(ctx, tx, "practice.expire", "practice_session", s.ID, map[string]any{"userId": s.UserID, "deadline": deadline}); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	// Free-navigation answers are graded once the session is finished;
+	// grading awards the session, linear sessions are awarded directly.
+	for _, s := range sessions {
+		if s.Navigation != "free" {
+			if err := gamification.PracticeFinished(ctx, pool, s.UserID, s.ID); err != nil {
+				log.Printf("sweeper: award practice session %s failed: %v", s.ID, err)
+			}
+			if err := leaderboard.RecordPractice(ctx, pool, s.UserID, s.ID); err != nil {
+				log.Printf("sweeper: record practice session %s failed: %v", s.ID, err)
+			}
+			continue
+		}
+		if _, err := grading.Finalize(ctx, pool, s.UserID, s.ID); err != nil {
+			log.Printf("sweeper: grade practice session %s failed: %v", s.ID, err)
+		}
+	}
+	return len(sessions), nil
+}
+
+// closeStaleExamBatch submits or abandons up to batchSize active exam
+// sessions whose last heartbeat is older than cfg.HeartbeatTimeout.
+// Auto-submitted sessions count as submitted at their last heartbeat.
+func closeStaleExamBatch(ctx context.Context, pool *pgxpool.Pool, cfg Config) (int, error) {
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	timeoutSeconds := int(cfg.HeartbeatTimeout.Seconds())
+	rows, err := tx.Query(ctx, `select user_id, id, last_heartbeat_at
+		from exam_sessions
+		where status='active' and last_heartbeat_at < now() - make_interval(secs => $1)
+		order by last_heartbeat_at asc
+		limit $2
+		for update skip locked`, timeoutSeconds, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	type stale struct {
+		UserID, ID      string
+		LastHeartbeatAt time.Time
+	}
+	var sessions []stale
+	for rows.Next() {
+		var s stale
+		if err := rows.Scan(&s.UserID, &s.ID, &s.LastHeartbeatAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		sessions = append(sessions, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	update := `update exam_sessions set status='finished', updated_at=now(), submitted_at=coalesce(submitted_at, last_heartbeat_at) where user_id=$1 and id=$2`
+	eventType, action := "auto_submitted", "exam.auto_submit"
+	if cfg.StaleExamAction == ActionAbandon {
+		update = `update exam_sessions set status='abandoned', updated_at=now(), abandoned_at=now() where user_id=$1 and id=$2`
+		eventType, action = "abandoned", "exam.abandon"
+	}
+	for _, s := range sessions {
+		if _, err := tx.Exec(ctx, update, s.UserID, s.ID); err != nil {
+			return 0, err
+		}
+		meta := map[string]any{
+			"lastHeartbeatAt":         s.LastHeartbeatAt.UTC().Format(time.RFC3339),
+			"heartbeatTimeoutSeconds": timeoutSeconds,
+		}
+		payload, _ := json.Marshal(meta)
+		if _, err := tx.Exec(ctx, `insert into exam_session_events (user_id, session_id, event_type, payload) values ($1,$2,$3,$4)`,
+			s.UserID, s.ID, eventType, payload); err != nil {
+			return 0, err
+		}
+		if err := audit(ctx, tx, action, "exam_session", s.UserID+":"+s.ID, meta); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	if cfg.StaleExamAction != ActionAbandon {
+		for _, s := range sessions {
+			if err := gamification.ExamSubmitted(ctx, pool, s.UserID, s.ID); err != nil {
+				log.Printf("sweeper: award exam session %s failed: %v", s.ID, err)
+			}
+			if err := leaderboard.RecordExam(ctx, pool, s.UserID, s.ID); err != nil {
+				log.Printf("sweeper: record exam session %s failed: %v", s.ID, err)
+			}
+		}
+	}
+	return len(sessions), nil
+}
+
+func audit(ctx context.Context, tx pgx.Tx, action, targetType, targetID string, metadata map[string]any) error {
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `insert into audit_log (actor_user_id, actor_role, action, target_type, target_id, metadata) values (null,'system',$1,$2,$3,$4)`,
+		action, targetType, targetID, payload)
+	return err
+}
+
+// CreditCurrentQuestion adds the time spent on the question at index, from
+// startedAt until end, to timings. A session that ran past its deadline is
+// only credited up to the deadline.
+func CreditCurrentQuestion(timings map[string]int, order []string, index int, startedAt, end time.Time) map[string]int {
+	if timings == nil {
+		timings = map[string]int{}
+	}
+	if index < 0 || index >= len(order) {
+		return timings
+	}
+	spent := int(end.Sub(startedAt).Seconds())
+	if spent > 0 {
+		timings[order[index]] += spent
+	}
+	return timings
+}
@@ -0,0 +1,50 @@
+package sweeper
+
+import (
+    "testing"
+    "time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+    t.Setenv("SESSION_SWEEP_INTERVAL_SECONDS", "15")
+    t.Setenv("EXAM_HEARTBEAT_TIMEOUT_SECONDS", "600")
+    t.Setenv("EXAM_STALE_ACTION", "Abandon")
+    cfg := ConfigFromEnv()
+    if cfg.Interval != 15*time.Second || cfg.HeartbeatTimeout != 10*time.Minute || cfg.StaleExamAction != ActionAbandon {
+        t.Fatalf("unexpected config %+v", cfg)
+    }
+}
+
+func TestConfigFromEnvFallsBack(t *testing.T) {
+    t.Setenv("SESSION_SWEEP_INTERVAL_SECONDS", "-1")
+    t.Setenv("EXAM_HEARTBEAT_TIMEOUT_SECONDS", "soon")
+    t.Setenv("EXAM_STALE_ACTION", "delete")
+    cfg := ConfigFromEnv()
+    if cfg.Interval != 0 {
+        t.Fatalf("negative interval should disable the sweeper, got %s", cfg.Interval)
+    }
+    if cfg.HeartbeatTimeout != 30*time.Minute || cfg.StaleExamAction != ActionSubmit {
+        t.Fatalf("invalid values should keep the defaults, got %+v", cfg)
+    }
+}
+
+func TestCreditCurrentQuestion(t *testing.T) {
+    started := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+    order := []string{"q1", "q2"}
+
+    got := CreditCurrentQuestion(map[string]int{"q1": 30}, order, 1, started, started.Add(45*time.Second))
+    if got["q1"] != 30 || got["q2"] != 45 {
+        t.Fatalf("unexpected timings %v", got)
+    }
+
+    // The question started after the deadline (clock skew): nothing to credit.
+    got = CreditCurrentQuestion(nil, order, 0, started, started.Add(-5*time.Second))
+    if len(got) != 0 {
+        t.Fatalf("expected no timings, got %v", got)
+    }
+
+    got = CreditCurrentQuestion(map[string]int{}, order, 2, started, started.Add(time.Minute))
+    if len(got) != 0 {
+        t.Fatalf("out of range index should credit nothing, got %v", got)
+    }
+}
@@ -0,0 +1,305 @@
+// Package timing holds the pacing rules of timed practice: how long a
+// session, a section or a single question may take, whether a question
+// auto-advances when its time runs out, and how much grace late answers get.
+//
+// Policies are written on templates and blueprint sections; a section's
+// policy overrides its template's field by field. When a timed session
+// starts, the policy is resolved against the drawn questions into a Snapshot
+// that the server enforces for the life of the session.
+package timing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultSecondsPerQuestion paces timed sessions without a policy.
+const DefaultSecondsPerQuestion = 60
+
+// MaxSeconds bounds every duration in a policy.
+const MaxSeconds = 6 * 60 * 60
+
+type Policy struct {
+	// TotalSeconds is the time for the whole session (or section); nil sums
+	// the per-question times.
+	TotalSeconds *int `json:"totalSeconds,omitempty"`
+	// SecondsPerQuestion is the time per question; DifficultySeconds
+	// overrides it for questions of a difficulty, keyed by difficulty id.
+	SecondsPerQuestion *int           `json:"secondsPerQuestion,omitempty"`
+	DifficultySeconds  map[string]int `json:"difficultySeconds,omitempty"`
+	// AutoAdvance makes the per-question time a hard limit: when it runs out
+	// the question is left unanswered and the session moves on.
+	AutoAdvance *bool `json:"autoAdvance,omitempty"`
+	// GraceSeconds is how late an answer may arrive after a deadline and
+	// still count.
+	GraceSeconds *int `json:"graceSeconds,omitempty"`
+}
+
+// Parse decodes and validates a policy written by an instructor. Empty input
+// or null means no policy (nil, nil).
+func Parse(raw []byte) (*Policy, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	if raw[0] != '{' {
+		return nil, errors.New("timing must be a json object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid timing: %v", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid timing: trailing data")
+	}
+	return &p, p.Validate()
+}
+
+// Decode reads a stored policy; stored policies were validated on write.
+func Decode(raw []byte) *Policy {
+	p, err := Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// Validate checks that every duration is positive (grace may be zero) and
+// at most MaxSeconds.
+func (p Policy) Validate() error {
+	check := func(name string, v *int, allowZero bool) error {
+		if v == nil {
+			return nil
+		}
+		lowest := 1
+		if allowZero {
+			lowest = 0
+		}
+		if *v < lowest || *v > MaxSeconds {
+			return fmt.Errorf("timing %s must be between %d and %d seconds", name, lowest, MaxSeconds)
+		}
+		return nil
+	}
+	if err := check("totalSeconds", p.TotalSeconds, false); err != nil {
+		return err
+	}
+	if err := check("secondsPerQuestion", p.SecondsPerQuestion, false); err != nil {
+		return err
+	}
+	if err := check("graceSeconds", p.GraceSeconds, true); err != nil {
+		return err
+	}
+	for id, v := range p.DifficultySeconds {
+		if id == "" {
+			return errors.New("timing difficultySeconds keys must be difficulty ids")
+		}
+		if err := check("difficultySeconds."+id, &v, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge returns base with the fields set in override applied on top.
+// Difficulty times are merged per difficulty.
+func Merge(base, override *Policy) Policy {
+	var out Policy
+	if base != nil {
+		out = *base
+		out.DifficultySeconds = nil
+		for id, v := range base.DifficultySeconds {
+			out.setDifficulty(id, v)
+		}
+	}
+	if override == nil {
+		return out
+	}
+	if override.TotalSeconds != nil {
+		out.TotalSeconds = override.TotalSeconds
+	}
+	if override.SecondsPerQuestion != nil {
+		out.SecondsPerQuestion = override.SecondsPerQuestion
+	}
+	if override.AutoAdvance != nil {
+		out.AutoAdvance = override.AutoAdvance
+	}
+	if override.GraceSeconds != nil {
+		out.GraceSeconds = override.GraceSeconds
+	}
+	for id, v := range override.DifficultySeconds {
+		out.setDifficulty(id, v)
+	}
+	return out
+}
+
+func (p *Policy) setDifficulty(id string, v int) {
+	if p.DifficultySeconds == nil {
+		p.DifficultySeconds = map[string]int{}
+	}
+	p.DifficultySeconds[id] = v
+}
+
+// QuestionSeconds is the time for one question of the given difficulty
+// ("" when it has none).
+func (p Policy) QuestionSeconds(difficultyID string) int {
+	if v, ok := p.DifficultySeconds[difficultyID]; ok && difficultyID != "" {
+		return v
+	}
+	if p.SecondsPerQuestion != nil {
+		return *p.SecondsPerQuestion
+	}
+	return DefaultSecondsPerQuestion
+}
+
+// Total is the time for questions of the given difficulties: TotalSeconds
+// when set, otherwise the sum of their question times.
+func (p Policy) Total(difficultyIDs []string) int {
+	if p.TotalSeconds != nil {
+		return *p.TotalSeconds
+	}
+	n := 0
+	for _, id := range difficultyIDs {
+		n += p.QuestionSeconds(id)
+	}
+	return n
+}
+
+func (p Policy) Grace() int {
+	if p.GraceSeconds == nil {
+		return 0
+	}
+	return *p.GraceSeconds
+}
+
+func (p Policy) AutoAdvances() bool {
+	return p.AutoAdvance != nil && *p.AutoAdvance
+}
+
+// Snapshot is the timing a session was started with.
+type Snapshot struct {
+	GraceSeconds int `json:"graceSeconds"`
+	// QuestionSeconds holds each question's time, by index in question_order,
+	// and QuestionLimits its hard limit (0 when it does not auto-advance).
+	// Questions appended later (adaptive sessions) use the defaults.
+	QuestionSeconds []int `json:"questionSeconds,omitempty"`
+	QuestionLimits  []int `json:"questionLimits,omitempty"`
+	DefaultSeconds  int   `json:"defaultSeconds"`
+	DefaultLimit    int   `json:"defaultLimit,omitempty"`
+}
+
+// Append adds questions of the given difficulties, paced by p.
+func (s *Snapshot) Append(p Policy, difficultyIDs []string) {
+	for _, id := range difficultyIDs {
+		seconds := p.QuestionSeconds(id)
+		limit := 0
+		if p.AutoAdvances() {
+			limit = seconds
+		}
+		s.QuestionSeconds = append(s.QuestionSeconds, seconds)
+		s.QuestionLimits = append(s.QuestionLimits, limit)
+	}
+}
+
+// NewSnapshot resolves a policy for the questions of a session, given their
+// difficulties in order.
+func NewSnapshot(p Policy, difficultyIDs []string) Snapshot {
+	s := Snapshot{GraceSeconds: p.Grace(), DefaultSeconds: p.QuestionSeconds("")}
+	if p.AutoAdvances() {
+		s.DefaultLimit = s.DefaultSeconds
+	}
+	s.Append(p, difficultyIDs)
+	return s
+}
+
+// DecodeSnapshot reads a stored snapshot. Sessions started before timing
+// policies use the default pacing without grace.
+func DecodeSnapshot(raw []byte) Snapshot {
+	s := Snapshot{DefaultSeconds: DefaultSecondsPerQuestion}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &s)
+	}
+	if s.DefaultSeconds <= 0 {
+		s.DefaultSeconds = DefaultSecondsPerQuestion
+	}
+	return s
+}
+
+// Seconds is the time for the question at idx.
+func (s Snapshot) Seconds(idx int) int {
+	if idx >= 0 && idx < len(s.QuestionSeconds) {
+		return s.QuestionSeconds[idx]
+	}
+	return s.DefaultSeconds
+}
+
+// Limit is the hard limit of the question at idx, 0 when there is none.
+func (s Snapshot) Limit(idx int) int {
+	if idx >= 0 && idx < len(s.QuestionLimits) {
+		return s.QuestionLimits[idx]
+	}
+	if idx >= len(s.QuestionSeconds) {
+		return s.DefaultLimit
+	}
+	return 0
+}
+
+// HasLimits reports whether any question auto-advances.
+func (s Snapshot) HasLimits() bool {
+	if s.DefaultLimit > 0 {
+		return true
+	}
+	for _, l := range s.QuestionLimits {
+		if l > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether a deadline has passed, grace included.
+func (s Snapshot) Expired(deadline, now time.Time) bool {
+	return !now.Before(deadline.Add(time.Duration(s.GraceSeconds) * time.Second))
+}
+
+// Remaining is the time left until deadline, without grace, never negative.
+func Remaining(deadline, now time.Time) int {
+	return max(0, int(deadline.Sub(now).Seconds()))
+}
+
+// QuestionRemaining is the time left on the hard limit of the question at
+// idx, or nil when it has none.
+func (s Snapshot) QuestionRemaining(idx int, startedAt, now time.Time) *int {
+	limit := s.Limit(idx)
+	if limit <= 0 {
+		return nil
+	}
+	r := Remaining(startedAt.Add(time.Duration(limit)*time.Second), now)
+	return &r
+}
+
+// Advance skips the questions whose hard limit ran out, starting with the
+// question at idx started at startedAt, and returns the index and start of the
+// question the student is now on. Each skipped question is credited its full
+// limit. n is the number of questions.
+func (s Snapshot) Advance(idx int, startedAt, now time.Time, n int) (int, time.Time, map[int]int) {
+	credited := map[int]int{}
+	for idx < n {
+		limit := s.Limit(idx)
+		if limit <= 0 {
+			break
+		}
+		deadline := startedAt.Add(time.Duration(limit) * time.Second)
+		if !s.Expired(deadline, now) {
+			break
+		}
+		credited[idx] = limit
+		startedAt = deadline
+		idx++
+	}
+	return idx, startedAt, credited
+}
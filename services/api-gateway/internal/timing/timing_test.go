@@ -0,0 +1,105 @@
+package timing
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParse(t *testing.T) {
+    p, err := Parse([]byte(`{"secondsPerQuestion": 90, "difficultySeconds": {"hard": 150}, "autoAdvance": true, "graceSeconds": 5}`))
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if p.QuestionSeconds("hard") != 150 || p.QuestionSeconds("easy") != 90 || p.QuestionSeconds("") != 90 {
+        t.Fatalf("unexpected question seconds %+v", p)
+    }
+    if !p.AutoAdvances() || p.Grace() != 5 {
+        t.Fatalf("unexpected switches %+v", p)
+    }
+    if p.Total([]string{"hard", "easy", ""}) != 330 {
+        t.Fatalf("unexpected total %d", p.Total([]string{"hard", "easy", ""}))
+    }
+
+    if p, err := Parse([]byte(`null`)); err != nil || p != nil {
+        t.Fatalf("null should mean no policy, got %+v, %v", p, err)
+    }
+}
+
+func TestParseRejects(t *testing.T) {
+    for _, raw := range []string{
+        `[]`,
+        `{"secondsPerQuestion": 0}`,
+        `{"totalSeconds": -5}`,
+        `{"graceSeconds": -1}`,
+        `{"difficultySeconds": {"hard": 0}}`,
+        `{"secondsPerQuestion": 999999}`,
+        `{"perQuestion": 60}`,
+    } {
+        if _, err := Parse([]byte(raw)); err == nil {
+            t.Fatalf("expected %s to be rejected", raw)
+        }
+    }
+}
+
+func TestMerge(t *testing.T) {
+    ninety, thirty, zero := 90, 30, 0
+    yes := true
+    base := &Policy{SecondsPerQuestion: &ninety, DifficultySeconds: map[string]int{"hard": 120, "easy": 45}, GraceSeconds: &thirty}
+    section := &Policy{DifficultySeconds: map[string]int{"hard": 200}, AutoAdvance: &yes, GraceSeconds: &zero}
+
+    m := Merge(base, section)
+    if m.QuestionSeconds("hard") != 200 || m.QuestionSeconds("easy") != 45 || m.QuestionSeconds("medium") != 90 {
+        t.Fatalf("unexpected merged seconds %+v", m)
+    }
+    if !m.AutoAdvances() || m.Grace() != 0 {
+        t.Fatalf("unexpected merged switches %+v", m)
+    }
+    if base.DifficultySeconds["hard"] != 120 {
+        t.Fatalf("merge must not modify its inputs")
+    }
+    if Merge(nil, nil).QuestionSeconds("") != DefaultSecondsPerQuestion {
+        t.Fatalf("empty policy should use the default pace")
+    }
+}
+
+func TestAdvance(t *testing.T) {
+    s := Snapshot{GraceSeconds: 2, QuestionSeconds: []int{10, 20, 30, 40}, QuestionLimits: []int{10, 20, 30, 0}, DefaultSeconds: 60}
+    start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    if idx, _, credited := s.Advance(0, start, start.Add(11*time.Second), 4); idx != 0 || len(credited) != 0 {
+        t.Fatalf("grace should keep question 0 open, got %d %v", idx, credited)
+    }
+    idx, at, credited := s.Advance(0, start, start.Add(35*time.Second), 4)
+    if idx != 2 || !at.Equal(start.Add(30*time.Second)) || credited[0] != 10 || credited[1] != 20 {
+        t.Fatalf("unexpected advance %d %s %v", idx, at, credited)
+    }
+    if idx, _, _ := s.Advance(0, start, start.Add(time.Hour), 4); idx != 3 {
+        t.Fatalf("expected to stop at the question without a limit, got %d", idx)
+    }
+    if r := s.QuestionRemaining(1, start, start.Add(5*time.Second)); r == nil || *r != 15 {
+        t.Fatalf("unexpected remaining %v", r)
+    }
+    if s.QuestionRemaining(3, start, start) != nil {
+        t.Fatalf("question without a limit has no remaining time")
+    }
+}
+
+func TestNewSnapshot(t *testing.T) {
+    thirty := 30
+    yes := true
+    s := NewSnapshot(Policy{SecondsPerQuestion: &thirty, DifficultySeconds: map[string]int{"hard": 50}, AutoAdvance: &yes}, []string{"hard", ""})
+    if s.Seconds(0) != 50 || s.Limit(0) != 50 || s.Seconds(1) != 30 || s.Limit(5) != 30 || !s.HasLimits() {
+        t.Fatalf("unexpected snapshot %+v", s)
+    }
+    s.Append(Policy{}, []string{""})
+    if s.Seconds(2) != DefaultSecondsPerQuestion || s.Limit(2) != 0 {
+        t.Fatalf("appended question should use its own policy, got %+v", s)
+    }
+}
+
+func TestDecodeSnapshot(t *testing.T) {
+    s := DecodeSnapshot(nil)
+    if s.Seconds(4) != DefaultSecondsPerQuestion || s.GraceSeconds != 0 || s.HasLimits() {
+        t.Fatalf("unexpected legacy snapshot %+v", s)
+    }
+}
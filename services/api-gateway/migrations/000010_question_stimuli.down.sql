@@ -0,0 +1,17 @@
+-- 000010_question_stimuli.down.sql
+-- Purpose: Drop shared stimuli.
+-- Risk: fast.
+-- Reversible: yes (destructive).
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS stimuli_snapshot;
+
+DROP INDEX IF EXISTS idx_question_bank_questions_stimulus_id_stimulus_order;
+ALTER TABLE question_bank_questions DROP CONSTRAINT IF EXISTS fk_question_bank_questions_stimulus_id;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS stimulus_order;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS stimulus_id;
+
+DROP INDEX IF EXISTS idx_question_stimuli_question_bank_id;
+ALTER TABLE question_stimuli DROP CONSTRAINT IF EXISTS fk_question_stimuli_updated_by_user_id;
+ALTER TABLE question_stimuli DROP CONSTRAINT IF EXISTS fk_question_stimuli_created_by_user_id;
+ALTER TABLE question_stimuli DROP CONSTRAINT IF EXISTS fk_question_stimuli_question_bank_id;
+DROP TABLE IF EXISTS question_stimuli;
@@ -0,0 +1,60 @@
+-- 000010_question_stimuli.up.sql
+-- Purpose: Shared stimuli (reading passages / media) referenced by grouped questions.
+-- Risk: low (new table + nullable columns).
+-- Reversible: yes (drops table/columns; destructive).
+
+CREATE TABLE IF NOT EXISTS question_stimuli (
+  id text PRIMARY KEY,
+  question_bank_id text NOT NULL,
+  title text NOT NULL DEFAULT '',
+  passage text NOT NULL DEFAULT '',
+  media_url text,
+  media_type text,
+  created_by_user_id text,
+  updated_by_user_id text,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_stimuli_question_bank_id') THEN
+    ALTER TABLE question_stimuli
+      ADD CONSTRAINT fk_question_stimuli_question_bank_id
+      FOREIGN KEY (question_bank_id) REFERENCES question_banks(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_stimuli_created_by_user_id') THEN
+    ALTER TABLE question_stimuli
+      ADD CONSTRAINT fk_question_stimuli_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_stimuli_updated_by_user_id') THEN
+    ALTER TABLE question_stimuli
+      ADD CONSTRAINT fk_question_stimuli_updated_by_user_id
+      FOREIGN KEY (updated_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_stimuli_question_bank_id
+  ON question_stimuli (question_bank_id);
+
+-- Questions optionally belong to a stimulus; stimulus_order is the authored position in the group.
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS stimulus_id text;
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS stimulus_order integer NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_questions_stimulus_id') THEN
+    ALTER TABLE question_bank_questions
+      ADD CONSTRAINT fk_question_bank_questions_stimulus_id
+      FOREIGN KEY (stimulus_id) REFERENCES question_stimuli(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_bank_questions_stimulus_id_stimulus_order
+  ON question_bank_questions (stimulus_id, stimulus_order);
+
+-- Practice sessions snapshot the stimuli of their selected questions alongside questions_snapshot.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS stimuli_snapshot json;
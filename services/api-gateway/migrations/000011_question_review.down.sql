@@ -0,0 +1,30 @@
+-- 000011_question_review.down.sql
+-- Purpose: Drop threaded question review.
+-- Risk: fast.
+-- Reversible: yes (destructive; review threads are lost, review_note is untouched).
+
+DROP INDEX IF EXISTS idx_question_bank_questions_status_submitted_for_review_at;
+
+DROP INDEX IF EXISTS idx_question_review_comments_question_id_created_at;
+ALTER TABLE question_review_comments DROP CONSTRAINT IF EXISTS fk_question_review_comments_resolved_by_user_id;
+ALTER TABLE question_review_comments DROP CONSTRAINT IF EXISTS fk_question_review_comments_author_user_id;
+ALTER TABLE question_review_comments DROP CONSTRAINT IF EXISTS fk_question_review_comments_parent_id;
+ALTER TABLE question_review_comments DROP CONSTRAINT IF EXISTS fk_question_review_comments_question_id;
+DROP TABLE IF EXISTS question_review_comments;
+
+DROP INDEX IF EXISTS idx_question_review_decisions_question_id_round_reviewer_unique;
+ALTER TABLE question_review_decisions DROP CONSTRAINT IF EXISTS fk_question_review_decisions_reviewer_user_id;
+ALTER TABLE question_review_decisions DROP CONSTRAINT IF EXISTS fk_question_review_decisions_question_id;
+DROP TABLE IF EXISTS question_review_decisions;
+
+DROP INDEX IF EXISTS idx_question_review_assignments_reviewer_user_id;
+ALTER TABLE question_review_assignments DROP CONSTRAINT IF EXISTS fk_question_review_assignments_assigned_by_user_id;
+ALTER TABLE question_review_assignments DROP CONSTRAINT IF EXISTS fk_question_review_assignments_reviewer_user_id;
+ALTER TABLE question_review_assignments DROP CONSTRAINT IF EXISTS fk_question_review_assignments_question_id;
+DROP TABLE IF EXISTS question_review_assignments;
+
+ALTER TABLE question_banks DROP COLUMN IF EXISTS required_approvals;
+
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS submitted_for_review_at;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS review_round;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS revision;
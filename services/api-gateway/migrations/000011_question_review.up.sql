@@ -0,0 +1,130 @@
+-- 000011_question_review.up.sql
+-- Purpose: Threaded question review (revisions, rounds, reviewer assignment, decisions, comments).
+-- Risk: low (new tables + defaulted columns).
+-- Reversible: yes (drops tables/columns; destructive).
+
+-- revision increments on every content edit; review_round increments on every submit-for-review.
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS revision integer NOT NULL DEFAULT 1;
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS review_round integer NOT NULL DEFAULT 0;
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS submitted_for_review_at timestamp;
+
+-- Per-bank override of QUESTION_REVIEW_REQUIRED_APPROVALS (NULL = use the default).
+ALTER TABLE question_banks ADD COLUMN IF NOT EXISTS required_approvals integer;
+
+CREATE TABLE IF NOT EXISTS question_review_assignments (
+  question_id text NOT NULL,
+  reviewer_user_id text NOT NULL,
+  assigned_by_user_id text,
+  assigned_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (question_id, reviewer_user_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_assignments_question_id') THEN
+    ALTER TABLE question_review_assignments
+      ADD CONSTRAINT fk_question_review_assignments_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_assignments_reviewer_user_id') THEN
+    ALTER TABLE question_review_assignments
+      ADD CONSTRAINT fk_question_review_assignments_reviewer_user_id
+      FOREIGN KEY (reviewer_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_assignments_assigned_by_user_id') THEN
+    ALTER TABLE question_review_assignments
+      ADD CONSTRAINT fk_question_review_assignments_assigned_by_user_id
+      FOREIGN KEY (assigned_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_review_assignments_reviewer_user_id
+  ON question_review_assignments (reviewer_user_id);
+
+CREATE TABLE IF NOT EXISTS question_review_decisions (
+  id bigserial PRIMARY KEY,
+  question_id text NOT NULL,
+  reviewer_user_id text NOT NULL,
+  round integer NOT NULL,
+  revision integer NOT NULL,
+  decision text NOT NULL,
+  note text NOT NULL DEFAULT '',
+  created_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_decisions_question_id') THEN
+    ALTER TABLE question_review_decisions
+      ADD CONSTRAINT fk_question_review_decisions_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_decisions_reviewer_user_id') THEN
+    ALTER TABLE question_review_decisions
+      ADD CONSTRAINT fk_question_review_decisions_reviewer_user_id
+      FOREIGN KEY (reviewer_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+-- One standing decision per reviewer per round (later decisions overwrite).
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_review_decisions_question_id_round_reviewer_unique
+  ON question_review_decisions (question_id, round, reviewer_user_id);
+
+CREATE TABLE IF NOT EXISTS question_review_comments (
+  id text PRIMARY KEY,
+  question_id text NOT NULL,
+  parent_id text,
+  revision integer NOT NULL,
+  round integer NOT NULL,
+  anchor text,
+  author_user_id text NOT NULL,
+  body text NOT NULL,
+  is_resolved boolean NOT NULL DEFAULT false,
+  resolved_by_user_id text,
+  resolved_at timestamp,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_comments_question_id') THEN
+    ALTER TABLE question_review_comments
+      ADD CONSTRAINT fk_question_review_comments_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_comments_parent_id') THEN
+    ALTER TABLE question_review_comments
+      ADD CONSTRAINT fk_question_review_comments_parent_id
+      FOREIGN KEY (parent_id) REFERENCES question_review_comments(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_comments_author_user_id') THEN
+    ALTER TABLE question_review_comments
+      ADD CONSTRAINT fk_question_review_comments_author_user_id
+      FOREIGN KEY (author_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_review_comments_resolved_by_user_id') THEN
+    ALTER TABLE question_review_comments
+      ADD CONSTRAINT fk_question_review_comments_resolved_by_user_id
+      FOREIGN KEY (resolved_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_review_comments_question_id_created_at
+  ON question_review_comments (question_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_question_bank_questions_status_submitted_for_review_at
+  ON question_bank_questions (status, submitted_for_review_at);
+
+-- Carry forward existing single-note reviews as resolved comments so history is not lost.
+INSERT INTO question_review_comments (id, question_id, revision, round, author_user_id, body, is_resolved, created_at)
+SELECT 'rvc_' || md5(q.id || ':legacy'), q.id, q.revision, q.review_round, q.updated_by_user_id, q.review_note, q.status <> 'needs_changes', coalesce(q.updated_at, q.created_at)
+FROM question_bank_questions q
+WHERE coalesce(q.review_note, '') <> '' AND q.updated_by_user_id IS NOT NULL
+ON CONFLICT (id) DO NOTHING;
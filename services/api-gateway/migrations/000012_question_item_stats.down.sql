@@ -0,0 +1,10 @@
+-- 000012_question_item_stats.down.sql
+-- Purpose: Drop stored item statistics.
+-- Risk: fast.
+-- Reversible: yes (statistics are recomputed by the nightly job).
+
+DROP INDEX IF EXISTS idx_practice_answers_question_id;
+
+DROP INDEX IF EXISTS idx_question_item_stats_is_flagged;
+ALTER TABLE question_item_stats DROP CONSTRAINT IF EXISTS fk_question_item_stats_question_id;
+DROP TABLE IF EXISTS question_item_stats;
@@ -0,0 +1,31 @@
+-- 000012_question_item_stats.up.sql
+-- Purpose: Store per-question item statistics (difficulty, discrimination, timing, distractor analysis).
+-- Risk: low (new table).
+-- Reversible: yes (drops table; recomputable).
+
+CREATE TABLE IF NOT EXISTS question_item_stats (
+  question_id text PRIMARY KEY,
+  attempts integer NOT NULL DEFAULT 0,
+  correct_count integer NOT NULL DEFAULT 0,
+  p_value double precision,
+  point_biserial double precision,
+  mean_time_seconds double precision,
+  choice_stats json,
+  flags json,
+  is_flagged boolean NOT NULL DEFAULT false,
+  computed_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_item_stats_question_id') THEN
+    ALTER TABLE question_item_stats
+      ADD CONSTRAINT fk_question_item_stats_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_item_stats_is_flagged ON question_item_stats (is_flagged);
+
+-- Supports the per-question session lookup used by on-demand recomputation.
+CREATE INDEX IF NOT EXISTS idx_practice_answers_question_id ON practice_answers (question_id);
@@ -0,0 +1,15 @@
+-- 000013_irt_calibration.down.sql
+-- Purpose: Drop IRT calibrations and item parameters.
+-- Risk: fast.
+-- Reversible: yes (destructive; parameters can be recalibrated).
+
+DROP INDEX IF EXISTS idx_question_irt_params_calibration_id;
+DROP INDEX IF EXISTS idx_question_irt_params_question_id_version_unique;
+ALTER TABLE question_irt_params DROP CONSTRAINT IF EXISTS fk_question_irt_params_calibration_id;
+ALTER TABLE question_irt_params DROP CONSTRAINT IF EXISTS fk_question_irt_params_question_id;
+DROP TABLE IF EXISTS question_irt_params;
+
+DROP INDEX IF EXISTS idx_irt_calibrations_exam_package_id_created_at;
+ALTER TABLE irt_calibrations DROP CONSTRAINT IF EXISTS fk_irt_calibrations_created_by_user_id;
+ALTER TABLE irt_calibrations DROP CONSTRAINT IF EXISTS fk_irt_calibrations_exam_package_id;
+DROP TABLE IF EXISTS irt_calibrations;
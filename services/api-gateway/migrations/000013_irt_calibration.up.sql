@@ -0,0 +1,74 @@
+-- 000013_irt_calibration.up.sql
+-- Purpose: Store IRT calibration runs and versioned per-question item parameters.
+-- Risk: low (new tables).
+-- Reversible: yes (drops tables; destructive).
+
+CREATE TABLE IF NOT EXISTS irt_calibrations (
+  id text PRIMARY KEY,
+  exam_package_id uuid NOT NULL,
+  model text NOT NULL,
+  status text NOT NULL,
+  min_responses integer NOT NULL,
+  items_calibrated integer NOT NULL DEFAULT 0,
+  items_skipped integer NOT NULL DEFAULT 0,
+  persons integer NOT NULL DEFAULT 0,
+  responses integer NOT NULL DEFAULT 0,
+  iterations integer NOT NULL DEFAULT 0,
+  converged boolean NOT NULL DEFAULT false,
+  error text,
+  created_by_user_id text,
+  created_at timestamp NOT NULL DEFAULT now(),
+  completed_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_irt_calibrations_exam_package_id') THEN
+    ALTER TABLE irt_calibrations
+      ADD CONSTRAINT fk_irt_calibrations_exam_package_id
+      FOREIGN KEY (exam_package_id) REFERENCES exam_packages(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_irt_calibrations_created_by_user_id') THEN
+    ALTER TABLE irt_calibrations
+      ADD CONSTRAINT fk_irt_calibrations_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_irt_calibrations_exam_package_id_created_at ON irt_calibrations (exam_package_id, created_at);
+
+-- Each calibration that covers a question appends a new version; the highest version is current.
+CREATE TABLE IF NOT EXISTS question_irt_params (
+  id bigserial PRIMARY KEY,
+  question_id text NOT NULL,
+  calibration_id text NOT NULL,
+  version integer NOT NULL,
+  model text NOT NULL,
+  a double precision NOT NULL,
+  b double precision NOT NULL,
+  c double precision NOT NULL DEFAULT 0,
+  se_a double precision,
+  se_b double precision,
+  se_c double precision,
+  n integer NOT NULL,
+  created_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_irt_params_question_id') THEN
+    ALTER TABLE question_irt_params
+      ADD CONSTRAINT fk_question_irt_params_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_irt_params_calibration_id') THEN
+    ALTER TABLE question_irt_params
+      ADD CONSTRAINT fk_question_irt_params_calibration_id
+      FOREIGN KEY (calibration_id) REFERENCES irt_calibrations(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_irt_params_question_id_version_unique ON question_irt_params (question_id, version);
+CREATE INDEX IF NOT EXISTS idx_question_irt_params_calibration_id ON question_irt_params (calibration_id);
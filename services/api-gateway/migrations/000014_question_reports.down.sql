@@ -0,0 +1,21 @@
+-- 000014_question_reports.down.sql
+-- Purpose: Drop question reports and notifications.
+-- Risk: fast.
+-- Reversible: yes (destructive).
+
+DROP INDEX IF EXISTS idx_user_notifications_user_id_created_at;
+ALTER TABLE user_notifications DROP CONSTRAINT IF EXISTS fk_user_notifications_user_id;
+DROP TABLE IF EXISTS user_notifications;
+
+DROP INDEX IF EXISTS idx_question_report_submissions_user_id_created_at;
+DROP INDEX IF EXISTS idx_question_report_submissions_report_id_user_id_unique;
+ALTER TABLE question_report_submissions DROP CONSTRAINT IF EXISTS fk_question_report_submissions_session_id;
+ALTER TABLE question_report_submissions DROP CONSTRAINT IF EXISTS fk_question_report_submissions_user_id;
+ALTER TABLE question_report_submissions DROP CONSTRAINT IF EXISTS fk_question_report_submissions_report_id;
+DROP TABLE IF EXISTS question_report_submissions;
+
+DROP INDEX IF EXISTS idx_question_reports_status_last_reported_at;
+DROP INDEX IF EXISTS idx_question_reports_question_id_open_unique;
+ALTER TABLE question_reports DROP CONSTRAINT IF EXISTS fk_question_reports_resolved_by_user_id;
+ALTER TABLE question_reports DROP CONSTRAINT IF EXISTS fk_question_reports_question_id;
+DROP TABLE IF EXISTS question_reports;
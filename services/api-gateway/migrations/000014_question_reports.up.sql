@@ -0,0 +1,99 @@
+-- 000014_question_reports.up.sql
+-- Purpose: Student problem reports on questions (deduplicated per question), triage, and in-app notifications.
+-- Risk: low (new tables).
+-- Reversible: yes (drops tables; destructive).
+
+-- One open report per question collects every student's submission until it is resolved.
+CREATE TABLE IF NOT EXISTS question_reports (
+  id text PRIMARY KEY,
+  question_id text NOT NULL,
+  status text NOT NULL DEFAULT 'open',
+  report_count integer NOT NULL DEFAULT 0,
+  first_reported_at timestamp NOT NULL DEFAULT now(),
+  last_reported_at timestamp NOT NULL DEFAULT now(),
+  outcome text,
+  resolution_note text,
+  resolved_by_user_id text,
+  resolved_at timestamp,
+  notified_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_reports_question_id') THEN
+    ALTER TABLE question_reports
+      ADD CONSTRAINT fk_question_reports_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_reports_resolved_by_user_id') THEN
+    ALTER TABLE question_reports
+      ADD CONSTRAINT fk_question_reports_resolved_by_user_id
+      FOREIGN KEY (resolved_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_reports_question_id_open_unique ON question_reports (question_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_question_reports_status_last_reported_at ON question_reports (status, last_reported_at);
+
+COMMENT ON COLUMN question_reports.status IS 'check (status in (''open'',''resolved''))';
+COMMENT ON COLUMN question_reports.outcome IS 'check (outcome in (''fixed'',''not_an_error'',''wont_fix''))';
+
+CREATE TABLE IF NOT EXISTS question_report_submissions (
+  id text PRIMARY KEY,
+  report_id text NOT NULL,
+  user_id text NOT NULL,
+  session_id text,
+  category text NOT NULL,
+  message text NOT NULL DEFAULT '',
+  question_snapshot json,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_report_submissions_report_id') THEN
+    ALTER TABLE question_report_submissions
+      ADD CONSTRAINT fk_question_report_submissions_report_id
+      FOREIGN KEY (report_id) REFERENCES question_reports(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_report_submissions_user_id') THEN
+    ALTER TABLE question_report_submissions
+      ADD CONSTRAINT fk_question_report_submissions_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_report_submissions_session_id') THEN
+    ALTER TABLE question_report_submissions
+      ADD CONSTRAINT fk_question_report_submissions_session_id
+      FOREIGN KEY (session_id) REFERENCES practice_sessions(id);
+  END IF;
+END $$;
+
+-- A student reporting the same question again updates their submission.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_report_submissions_report_id_user_id_unique ON question_report_submissions (report_id, user_id);
+CREATE INDEX IF NOT EXISTS idx_question_report_submissions_user_id_created_at ON question_report_submissions (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS user_notifications (
+  id text PRIMARY KEY,
+  user_id text NOT NULL,
+  type text NOT NULL,
+  title text NOT NULL,
+  body text NOT NULL DEFAULT '',
+  data json,
+  created_at timestamp NOT NULL DEFAULT now(),
+  read_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_notifications_user_id') THEN
+    ALTER TABLE user_notifications
+      ADD CONSTRAINT fk_user_notifications_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user_id_created_at ON user_notifications (user_id, created_at);
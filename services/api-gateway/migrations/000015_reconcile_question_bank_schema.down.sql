@@ -0,0 +1,80 @@
+-- 000015_reconcile_question_bank_schema.down.sql
+-- Purpose: Restore the pre-reconciliation question bank schema.
+-- Risk: medium (renames columns/tables; drops the mapping table).
+-- Reversible: yes (destructive: banks keep only one exam package; unscoped topics are dropped).
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS question_order;
+
+-- Keep one exam package per bank in the legacy column before dropping the mapping.
+UPDATE question_banks b
+SET exam_package_id = (
+  SELECT m.exam_package_id FROM exam_package_question_bank_packages m
+  WHERE m.question_bank_package_id = b.id
+  ORDER BY m.created_at, m.exam_package_id
+  LIMIT 1)
+WHERE b.exam_package_id IS NULL;
+
+DROP INDEX IF EXISTS idx_exam_package_question_bank_packages_question_bank_package_id;
+ALTER TABLE exam_package_question_bank_packages DROP CONSTRAINT IF EXISTS fk_exam_package_question_bank_packages_created_by_user_id;
+ALTER TABLE exam_package_question_bank_packages DROP CONSTRAINT IF EXISTS fk_exam_package_question_bank_packages_question_bank_package_id;
+ALTER TABLE exam_package_question_bank_packages DROP CONSTRAINT IF EXISTS fk_exam_package_question_bank_packages_exam_package_id;
+DROP TABLE IF EXISTS exam_package_question_bank_packages;
+
+COMMENT ON COLUMN question_banks.exam_package_id IS NULL;
+
+ALTER INDEX IF EXISTS idx_question_bank_questions_package_id_topic_id_difficulty_id
+  RENAME TO idx_question_bank_questions_question_bank_id_topic_id_difficulty_id;
+ALTER INDEX IF EXISTS idx_question_bank_questions_package_id
+  RENAME TO idx_question_bank_questions_question_bank_id;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_questions_package_id') THEN
+    ALTER TABLE question_bank_questions
+      RENAME CONSTRAINT fk_question_bank_questions_package_id TO fk_question_bank_questions_question_bank_id;
+  END IF;
+
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name='question_bank_questions' AND column_name='package_id'
+  ) THEN
+    ALTER TABLE question_bank_questions RENAME COLUMN package_id TO question_bank_id;
+  END IF;
+END $$;
+
+-- Topics go back to exam-package scope via their bank's package.
+DROP INDEX IF EXISTS idx_question_bank_topics_package_id_name_unique;
+ALTER TABLE question_bank_topics DROP CONSTRAINT IF EXISTS fk_question_bank_topics_package_id;
+
+UPDATE question_bank_topics t
+SET package_id = (SELECT b.exam_package_id::text FROM question_banks b WHERE b.id = t.package_id);
+
+UPDATE question_bank_questions q SET topic_id = NULL
+WHERE topic_id IN (SELECT id FROM question_bank_topics WHERE package_id IS NULL);
+UPDATE practice_templates p SET topic_id = NULL
+WHERE topic_id IN (SELECT id FROM question_bank_topics WHERE package_id IS NULL);
+DELETE FROM question_bank_topics WHERE package_id IS NULL;
+
+ALTER TABLE question_bank_topics ALTER COLUMN package_id TYPE uuid USING package_id::uuid;
+ALTER TABLE question_bank_topics ALTER COLUMN package_id SET NOT NULL;
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_topics_created_by_user_id') THEN
+    ALTER TABLE question_bank_topics
+      RENAME CONSTRAINT fk_question_bank_topics_created_by_user_id TO fk_question_topics_created_by_user_id;
+  END IF;
+
+  IF to_regclass('question_topics') IS NULL THEN
+    ALTER TABLE question_bank_topics RENAME TO question_topics;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_topics_package_id') THEN
+    ALTER TABLE question_topics
+      ADD CONSTRAINT fk_question_topics_package_id
+      FOREIGN KEY (package_id) REFERENCES exam_packages(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_topics_package_id_name_unique
+  ON question_topics (package_id, name);
@@ -0,0 +1,146 @@
+-- 000015_reconcile_question_bank_schema.up.sql
+-- Purpose: Align the question bank schema with the handlers: bank-scoped question_bank_topics,
+--          question_bank_questions.package_id, the exam package <-> question bank mapping table,
+--          and practice_sessions.question_order.
+-- Risk: medium (renames columns/tables and rewrites topic scoping; takes short exclusive locks).
+-- Reversible: yes (renames back; topics re-scoped to the bank's exam package).
+
+-- Topics belong to a question bank, not directly to an exam package.
+DO $$
+BEGIN
+  IF to_regclass('question_bank_topics') IS NULL AND to_regclass('question_topics') IS NOT NULL THEN
+    ALTER TABLE question_topics RENAME TO question_bank_topics;
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS question_bank_topics (
+  id text PRIMARY KEY,
+  package_id text,
+  name text NOT NULL,
+  created_by_user_id text,
+  is_hidden boolean NOT NULL DEFAULT false,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp
+);
+
+ALTER TABLE question_bank_topics DROP CONSTRAINT IF EXISTS fk_question_topics_package_id;
+DROP INDEX IF EXISTS idx_question_topics_package_id_name_unique;
+
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name='question_bank_topics' AND column_name='package_id' AND data_type='uuid'
+  ) THEN
+    ALTER TABLE question_bank_topics ALTER COLUMN package_id TYPE text USING package_id::text;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_topics_created_by_user_id') THEN
+    ALTER TABLE question_bank_topics
+      RENAME CONSTRAINT fk_question_topics_created_by_user_id TO fk_question_bank_topics_created_by_user_id;
+  END IF;
+END $$;
+
+ALTER TABLE question_bank_topics ALTER COLUMN package_id DROP NOT NULL;
+
+-- Re-scope exam-package topics to that package's first bank; topics with no bank become unscoped.
+UPDATE question_bank_topics t
+SET package_id = (SELECT min(b.id) FROM question_banks b WHERE b.exam_package_id::text = t.package_id)
+WHERE t.package_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM question_banks b WHERE b.id = t.package_id);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_topics_package_id') THEN
+    ALTER TABLE question_bank_topics
+      ADD CONSTRAINT fk_question_bank_topics_package_id
+      FOREIGN KEY (package_id) REFERENCES question_banks(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_topics_created_by_user_id') THEN
+    ALTER TABLE question_bank_topics
+      ADD CONSTRAINT fk_question_bank_topics_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_bank_topics_package_id_name_unique
+  ON question_bank_topics (package_id, name);
+
+-- Questions reference their bank through package_id.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name='question_bank_questions' AND column_name='question_bank_id'
+  ) AND NOT EXISTS (
+    SELECT 1 FROM information_schema.columns
+    WHERE table_name='question_bank_questions' AND column_name='package_id'
+  ) THEN
+    ALTER TABLE question_bank_questions RENAME COLUMN question_bank_id TO package_id;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_questions_question_bank_id') THEN
+    ALTER TABLE question_bank_questions
+      RENAME CONSTRAINT fk_question_bank_questions_question_bank_id TO fk_question_bank_questions_package_id;
+  END IF;
+END $$;
+
+ALTER INDEX IF EXISTS idx_question_bank_questions_question_bank_id
+  RENAME TO idx_question_bank_questions_package_id;
+ALTER INDEX IF EXISTS idx_question_bank_questions_question_bank_id_topic_id_difficulty_id
+  RENAME TO idx_question_bank_questions_package_id_topic_id_difficulty_id;
+
+-- Exam packages and question banks are many-to-many.
+CREATE TABLE IF NOT EXISTS exam_package_question_bank_packages (
+  exam_package_id uuid NOT NULL,
+  question_bank_package_id text NOT NULL,
+  created_by_user_id text,
+  created_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (exam_package_id, question_bank_package_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_exam_package_question_bank_packages_exam_package_id') THEN
+    ALTER TABLE exam_package_question_bank_packages
+      ADD CONSTRAINT fk_exam_package_question_bank_packages_exam_package_id
+      FOREIGN KEY (exam_package_id) REFERENCES exam_packages(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_exam_package_question_bank_packages_question_bank_package_id') THEN
+    ALTER TABLE exam_package_question_bank_packages
+      ADD CONSTRAINT fk_exam_package_question_bank_packages_question_bank_package_id
+      FOREIGN KEY (question_bank_package_id) REFERENCES question_banks(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_exam_package_question_bank_packages_created_by_user_id') THEN
+    ALTER TABLE exam_package_question_bank_packages
+      ADD CONSTRAINT fk_exam_package_question_bank_packages_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_exam_package_question_bank_packages_question_bank_package_id
+  ON exam_package_question_bank_packages (question_bank_package_id);
+
+-- question_banks.exam_package_id is legacy: seed the mapping from it and stop requiring it.
+INSERT INTO exam_package_question_bank_packages (exam_package_id, question_bank_package_id)
+SELECT exam_package_id, id FROM question_banks WHERE exam_package_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE question_banks ALTER COLUMN exam_package_id DROP NOT NULL;
+
+COMMENT ON COLUMN question_banks.exam_package_id IS 'legacy; use exam_package_question_bank_packages';
+
+-- Practice sessions store the selected question ids in presentation order.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS question_order json;
+
+UPDATE practice_sessions s
+SET question_order = coalesce(
+  (SELECT json_agg(q->>'id') FROM json_array_elements(s.questions_snapshot) q),
+  '[]'::json)
+WHERE s.question_order IS NULL;
+
+ALTER TABLE practice_sessions ALTER COLUMN question_order SET DEFAULT '[]'::json;
+ALTER TABLE practice_sessions ALTER COLUMN question_order SET NOT NULL;
@@ -0,0 +1,23 @@
+-- 000016_soft_delete.down.sql
+-- Purpose: Drop soft deletion columns.
+-- Risk: fast.
+-- Reversible: yes (destructive: trashed rows become live; fails if a trashed topic duplicates a live name).
+
+DROP INDEX IF EXISTS idx_question_bank_topics_package_id_name_live_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_bank_topics_package_id_name_unique
+  ON question_bank_topics (package_id, name);
+
+DROP INDEX IF EXISTS idx_question_bank_topics_deleted_at;
+DROP INDEX IF EXISTS idx_question_banks_deleted_at;
+DROP INDEX IF EXISTS idx_question_bank_questions_deleted_at;
+
+ALTER TABLE question_bank_topics DROP CONSTRAINT IF EXISTS fk_question_bank_topics_deleted_by_user_id;
+ALTER TABLE question_banks DROP CONSTRAINT IF EXISTS fk_question_banks_deleted_by_user_id;
+ALTER TABLE question_bank_questions DROP CONSTRAINT IF EXISTS fk_question_bank_questions_deleted_by_user_id;
+
+ALTER TABLE question_bank_topics DROP COLUMN IF EXISTS deleted_by_user_id;
+ALTER TABLE question_bank_topics DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE question_banks DROP COLUMN IF EXISTS deleted_by_user_id;
+ALTER TABLE question_banks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS deleted_by_user_id;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,44 @@
+-- 000016_soft_delete.up.sql
+-- Purpose: Soft deletion (trash) for questions, question banks and topics.
+-- Risk: low (nullable columns; topic name uniqueness becomes partial).
+-- Reversible: yes (drops columns; trashed rows become live again).
+
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS deleted_by_user_id text;
+ALTER TABLE question_banks ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE question_banks ADD COLUMN IF NOT EXISTS deleted_by_user_id text;
+ALTER TABLE question_bank_topics ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE question_bank_topics ADD COLUMN IF NOT EXISTS deleted_by_user_id text;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_questions_deleted_by_user_id') THEN
+    ALTER TABLE question_bank_questions
+      ADD CONSTRAINT fk_question_bank_questions_deleted_by_user_id
+      FOREIGN KEY (deleted_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_banks_deleted_by_user_id') THEN
+    ALTER TABLE question_banks
+      ADD CONSTRAINT fk_question_banks_deleted_by_user_id
+      FOREIGN KEY (deleted_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_topics_deleted_by_user_id') THEN
+    ALTER TABLE question_bank_topics
+      ADD CONSTRAINT fk_question_bank_topics_deleted_by_user_id
+      FOREIGN KEY (deleted_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_bank_questions_deleted_at
+  ON question_bank_questions (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_question_banks_deleted_at
+  ON question_banks (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_question_bank_topics_deleted_at
+  ON question_bank_topics (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A trashed topic must not block creating a new topic with the same name.
+DROP INDEX IF EXISTS idx_question_bank_topics_package_id_name_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_bank_topics_package_id_name_live_unique
+  ON question_bank_topics (package_id, name) WHERE deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- 000017_choice_shuffle.down.sql
+-- Purpose: Drop choice shuffle settings and session seeds.
+-- Risk: fast.
+-- Reversible: yes (destructive: shuffle settings are lost).
+
+ALTER TABLE exam_sessions DROP COLUMN IF EXISTS shuffle_seed;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS shuffle_seed;
+ALTER TABLE question_bank_choices DROP COLUMN IF EXISTS is_pinned;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS shuffle_choices;
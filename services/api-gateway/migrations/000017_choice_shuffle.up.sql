@@ -0,0 +1,14 @@
+-- 000017_choice_shuffle.up.sql
+-- Purpose: Per-question choice shuffle settings and per-session shuffle seeds.
+-- Risk: low (columns with defaults; existing sessions keep canonical order).
+-- Reversible: yes (drops columns).
+
+-- shuffle_choices=false shows choices in order_index order.
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS shuffle_choices boolean NOT NULL DEFAULT true;
+
+-- Pinned choices keep their order_index position when the rest are shuffled (e.g. "All of the above").
+ALTER TABLE question_bank_choices ADD COLUMN IF NOT EXISTS is_pinned boolean NOT NULL DEFAULT false;
+
+-- NULL seed means no shuffling (sessions created before this migration).
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS shuffle_seed bigint;
+ALTER TABLE exam_sessions ADD COLUMN IF NOT EXISTS shuffle_seed bigint;
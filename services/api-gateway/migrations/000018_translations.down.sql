@@ -0,0 +1,11 @@
+-- 000018_translations.down.sql
+-- Purpose: Drop content translations and locale columns.
+-- Risk: fast.
+-- Reversible: yes (destructive: translations and locale preferences are lost).
+
+DROP TABLE IF EXISTS content_translations;
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS locale;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+ALTER TABLE exam_packages DROP COLUMN IF EXISTS source_locale;
+ALTER TABLE question_banks DROP COLUMN IF EXISTS source_locale;
@@ -0,0 +1,63 @@
+-- 000018_translations.up.sql
+-- Purpose: Per-locale translations of question, topic and exam package content with review status; source and user locales.
+-- Risk: low (new table; columns with defaults).
+-- Reversible: yes (drops table and columns; destructive).
+
+-- Source language of a bank's questions/topics and of an exam package's metadata.
+ALTER TABLE question_banks ADD COLUMN IF NOT EXISTS source_locale text NOT NULL DEFAULT 'en';
+ALTER TABLE exam_packages ADD COLUMN IF NOT EXISTS source_locale text NOT NULL DEFAULT 'en';
+
+-- Preferred content locale from the user profile; NULL falls back to Accept-Language.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale text;
+
+-- Locale the session was started in; individual questions record their own locale in questions_snapshot.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS locale text;
+
+-- One translation per entity and locale. fields holds the translated text:
+--   question:     {"prompt", "explanation", "choices": {"<choice id>": "<text>"}}
+--   topic:        {"name"}
+--   exam_package: {"name", "subtitle", "overview"}
+-- source_revision is the question revision the translation was written against.
+CREATE TABLE IF NOT EXISTS content_translations (
+  id text PRIMARY KEY,
+  entity_type text NOT NULL,
+  entity_id text NOT NULL,
+  locale text NOT NULL,
+  fields json NOT NULL,
+  source_revision integer,
+  status text NOT NULL DEFAULT 'pending',
+  review_note text NOT NULL DEFAULT '',
+  created_by_user_id text NOT NULL,
+  updated_by_user_id text NOT NULL,
+  reviewed_by_user_id text,
+  reviewed_at timestamp,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_content_translations_created_by_user_id') THEN
+    ALTER TABLE content_translations
+      ADD CONSTRAINT fk_content_translations_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_content_translations_updated_by_user_id') THEN
+    ALTER TABLE content_translations
+      ADD CONSTRAINT fk_content_translations_updated_by_user_id
+      FOREIGN KEY (updated_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_content_translations_reviewed_by_user_id') THEN
+    ALTER TABLE content_translations
+      ADD CONSTRAINT fk_content_translations_reviewed_by_user_id
+      FOREIGN KEY (reviewed_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_translations_entity_locale_unique ON content_translations (entity_type, entity_id, locale);
+CREATE INDEX IF NOT EXISTS idx_content_translations_status_updated_at ON content_translations (status, updated_at);
+
+COMMENT ON COLUMN content_translations.entity_type IS 'check (entity_type in (''question'',''topic'',''exam_package''))';
+COMMENT ON COLUMN content_translations.status IS 'check (status in (''pending'',''approved'',''rejected''))';
@@ -0,0 +1,19 @@
+-- 000019_clone_jobs.down.sql
+-- Purpose: Drop clone jobs and lineage columns.
+-- Risk: fast.
+-- Reversible: yes (destructive: copies remain but lose their lineage).
+
+DROP TABLE IF EXISTS clone_jobs;
+
+DROP INDEX IF EXISTS idx_exam_packages_cloned_from_id;
+DROP INDEX IF EXISTS idx_question_bank_questions_cloned_from_id;
+DROP INDEX IF EXISTS idx_question_banks_cloned_from_id;
+
+ALTER TABLE practice_templates DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE exam_package_tiers DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE exam_packages DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE question_bank_choices DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE question_bank_questions DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE question_stimuli DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE question_bank_topics DROP COLUMN IF EXISTS cloned_from_id;
+ALTER TABLE question_banks DROP COLUMN IF EXISTS cloned_from_id;
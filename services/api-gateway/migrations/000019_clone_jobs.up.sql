@@ -0,0 +1,49 @@
+-- 000019_clone_jobs.up.sql
+-- Purpose: Deep-copy jobs for question banks and exam packages, and lineage pointers from copies to their originals.
+-- Risk: low (new table; nullable columns).
+-- Reversible: yes (drops table and columns; destructive).
+
+-- Lineage columns have no foreign keys so purging an original keeps its copies.
+ALTER TABLE question_banks ADD COLUMN IF NOT EXISTS cloned_from_id text;
+ALTER TABLE question_bank_topics ADD COLUMN IF NOT EXISTS cloned_from_id text;
+ALTER TABLE question_stimuli ADD COLUMN IF NOT EXISTS cloned_from_id text;
+ALTER TABLE question_bank_questions ADD COLUMN IF NOT EXISTS cloned_from_id text;
+ALTER TABLE question_bank_choices ADD COLUMN IF NOT EXISTS cloned_from_id text;
+ALTER TABLE exam_packages ADD COLUMN IF NOT EXISTS cloned_from_id uuid;
+ALTER TABLE exam_package_tiers ADD COLUMN IF NOT EXISTS cloned_from_id uuid;
+ALTER TABLE practice_templates ADD COLUMN IF NOT EXISTS cloned_from_id uuid;
+
+CREATE INDEX IF NOT EXISTS idx_question_banks_cloned_from_id ON question_banks (cloned_from_id) WHERE cloned_from_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_question_bank_questions_cloned_from_id ON question_bank_questions (cloned_from_id) WHERE cloned_from_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_exam_packages_cloned_from_id ON exam_packages (cloned_from_id) WHERE cloned_from_id IS NOT NULL;
+
+-- target_id is set once the copy's root row exists; copied_items/total_items report progress.
+CREATE TABLE IF NOT EXISTS clone_jobs (
+  id text PRIMARY KEY,
+  kind text NOT NULL,
+  source_id text NOT NULL,
+  target_id text,
+  status text NOT NULL,
+  options json NOT NULL DEFAULT '{}',
+  total_items integer NOT NULL DEFAULT 0,
+  copied_items integer NOT NULL DEFAULT 0,
+  error text,
+  created_by_user_id text NOT NULL,
+  created_at timestamp NOT NULL DEFAULT now(),
+  completed_at timestamp
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_clone_jobs_created_by_user_id') THEN
+    ALTER TABLE clone_jobs
+      ADD CONSTRAINT fk_clone_jobs_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_clone_jobs_created_by_user_id_created_at ON clone_jobs (created_by_user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_clone_jobs_kind_source_id ON clone_jobs (kind, source_id);
+
+COMMENT ON COLUMN clone_jobs.kind IS 'check (kind in (''question_bank'',''exam_package''))';
+COMMENT ON COLUMN clone_jobs.status IS 'check (status in (''running'',''completed'',''failed''))';
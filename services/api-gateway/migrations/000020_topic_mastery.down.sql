@@ -0,0 +1,9 @@
+-- 000020_topic_mastery.down.sql
+-- Purpose: Drop topic mastery and question difficulty ratings.
+-- Risk: fast.
+-- Reversible: yes (destructive: mastery history is lost; adaptive sessions read as standard).
+
+DROP TABLE IF EXISTS question_elo_ratings;
+DROP TABLE IF EXISTS user_topic_mastery;
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS mode;
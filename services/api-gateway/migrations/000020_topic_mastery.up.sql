@@ -0,0 +1,51 @@
+-- 000020_topic_mastery.up.sql
+-- Purpose: Per-user topic mastery and question difficulty ratings for adaptive practice.
+-- Risk: low (new tables; new column with a default).
+-- Reversible: yes (drops tables and column).
+
+-- Adaptive sessions pick each next question from the student's current mastery.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS mode text NOT NULL DEFAULT 'standard';
+COMMENT ON COLUMN practice_sessions.mode IS 'check (mode in (''standard'',''adaptive''))';
+
+-- rating is an Elo ability on the IRT logit scale (0 = answers an average question half the time).
+CREATE TABLE IF NOT EXISTS user_topic_mastery (
+  user_id text NOT NULL,
+  topic_id text NOT NULL,
+  rating double precision NOT NULL DEFAULT 0,
+  attempts integer NOT NULL DEFAULT 0,
+  correct_count integer NOT NULL DEFAULT 0,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, topic_id)
+);
+
+-- rating is an Elo difficulty on the same scale, seeded from the latest IRT b when one exists.
+CREATE TABLE IF NOT EXISTS question_elo_ratings (
+  question_id text PRIMARY KEY,
+  rating double precision NOT NULL DEFAULT 0,
+  attempts integer NOT NULL DEFAULT 0,
+  updated_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_topic_mastery_user_id') THEN
+    ALTER TABLE user_topic_mastery
+      ADD CONSTRAINT fk_user_topic_mastery_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_topic_mastery_topic_id') THEN
+    ALTER TABLE user_topic_mastery
+      ADD CONSTRAINT fk_user_topic_mastery_topic_id
+      FOREIGN KEY (topic_id) REFERENCES question_bank_topics(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_elo_ratings_question_id') THEN
+    ALTER TABLE question_elo_ratings
+      ADD CONSTRAINT fk_question_elo_ratings_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_user_topic_mastery_topic_id ON user_topic_mastery (topic_id);
@@ -0,0 +1,8 @@
+-- 000021_review_deck.down.sql
+-- Purpose: Drop review decks.
+-- Risk: fast.
+-- Reversible: yes (destructive: review schedules are lost; review sessions read as standard).
+
+DROP TABLE IF EXISTS review_deck_items;
+
+COMMENT ON COLUMN practice_sessions.mode IS 'check (mode in (''standard'',''adaptive''))';
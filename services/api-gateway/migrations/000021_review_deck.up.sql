@@ -0,0 +1,42 @@
+-- 000021_review_deck.up.sql
+-- Purpose: Personal spaced-repetition review decks and review-due practice sessions.
+-- Risk: low (new table; comment change).
+-- Reversible: yes (drops table).
+
+COMMENT ON COLUMN practice_sessions.mode IS 'check (mode in (''standard'',''adaptive'',''review''))';
+
+-- SM-2 state per student and question. Missed questions are enrolled
+-- automatically; students can add others themselves.
+CREATE TABLE IF NOT EXISTS review_deck_items (
+  user_id text NOT NULL,
+  question_id text NOT NULL,
+  source text NOT NULL,
+  ease_factor double precision NOT NULL DEFAULT 2.5,
+  interval_days integer NOT NULL DEFAULT 0,
+  repetitions integer NOT NULL DEFAULT 0,
+  lapses integer NOT NULL DEFAULT 0,
+  due_at timestamp NOT NULL,
+  last_reviewed_at timestamp,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, question_id)
+);
+
+COMMENT ON COLUMN review_deck_items.source IS 'check (source in (''missed'',''bookmarked''))';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_review_deck_items_user_id') THEN
+    ALTER TABLE review_deck_items
+      ADD CONSTRAINT fk_review_deck_items_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_review_deck_items_question_id') THEN
+    ALTER TABLE review_deck_items
+      ADD CONSTRAINT fk_review_deck_items_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_review_deck_items_user_id_due_at ON review_deck_items (user_id, due_at);
@@ -0,0 +1,12 @@
+-- 000022_bookmarks.down.sql
+-- Purpose: Drop question bookmarks and practice session sources.
+-- Risk: fast.
+-- Reversible: yes (destructive: bookmarks and notes are lost).
+
+DROP INDEX IF EXISTS idx_practice_answers_user_id_question_id;
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS difficulty_id;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS topic_id;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS source;
+
+DROP TABLE IF EXISTS question_bookmarks;
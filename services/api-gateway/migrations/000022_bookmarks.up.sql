@@ -0,0 +1,45 @@
+-- 000022_bookmarks.up.sql
+-- Purpose: Question bookmarks and practice session sources (bookmarked, incorrect, unseen) with topic/difficulty filters.
+-- Risk: low (new table; new columns with defaults; backfill touches template-driven sessions only).
+-- Reversible: yes (drops table and columns).
+
+CREATE TABLE IF NOT EXISTS question_bookmarks (
+  user_id text NOT NULL,
+  question_id text NOT NULL,
+  note text NOT NULL DEFAULT '',
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, question_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bookmarks_user_id') THEN
+    ALTER TABLE question_bookmarks
+      ADD CONSTRAINT fk_question_bookmarks_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bookmarks_question_id') THEN
+    ALTER TABLE question_bookmarks
+      ADD CONSTRAINT fk_question_bookmarks_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_question_bookmarks_user_id_created_at ON question_bookmarks (user_id, created_at);
+
+-- The selection filters a session was created with, so adaptive sessions keep
+-- applying them as they grow. Template sessions copy the template's filters.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS source text NOT NULL DEFAULT 'all';
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS topic_id text;
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS difficulty_id text;
+COMMENT ON COLUMN practice_sessions.source IS 'check (source in (''all'',''bookmarked'',''incorrect'',''unseen''))';
+
+UPDATE practice_sessions s
+SET topic_id = t.topic_id, difficulty_id = t.difficulty_id
+FROM practice_templates t
+WHERE s.template_id = t.id AND s.topic_id IS NULL AND s.difficulty_id IS NULL;
+
+-- "incorrect" and "unseen" look up a student's answers per question.
+CREATE INDEX IF NOT EXISTS idx_practice_answers_user_id_question_id ON practice_answers (user_id, question_id, ts);
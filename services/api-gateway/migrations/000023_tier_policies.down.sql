@@ -0,0 +1,6 @@
+-- 000023_tier_policies.down.sql
+-- Purpose: Drop the weekly exam quota index.
+-- Risk: fast.
+-- Reversible: yes (tier policies keep the quota fields copied by the up migration).
+
+DROP INDEX IF EXISTS idx_exam_sessions_user_id_exam_package_id_created_at;
@@ -0,0 +1,19 @@
+-- 000023_tier_policies.up.sql
+-- Purpose: Move tier session quotas into the typed tier policy and index weekly quota counts.
+-- Risk: low (rewrites policy only on tiers with quota columns set).
+-- Reversible: yes (drops the index; policies keep the copied quotas).
+
+-- The policy is now the source of truth; the quota columns mirror it.
+UPDATE exam_package_tiers
+SET policy = (policy::jsonb || jsonb_build_object('maxPracticeSessionsPerWeek', max_practice_sessions_per_week))::json
+WHERE max_practice_sessions_per_week IS NOT NULL
+  AND json_typeof(policy) = 'object'
+  AND policy->'maxPracticeSessionsPerWeek' IS NULL;
+
+UPDATE exam_package_tiers
+SET policy = (policy::jsonb || jsonb_build_object('maxExamSessionsPerWeek', max_exam_sessions_per_week))::json
+WHERE max_exam_sessions_per_week IS NOT NULL
+  AND json_typeof(policy) = 'object'
+  AND policy->'maxExamSessionsPerWeek' IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_user_id_exam_package_id_created_at ON exam_sessions (user_id, exam_package_id, created_at);
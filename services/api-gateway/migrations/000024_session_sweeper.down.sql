@@ -0,0 +1,12 @@
+-- 000024_session_sweeper.down.sql
+-- Purpose: Drop session sweeper support.
+-- Risk: fast.
+-- Reversible: yes (destructive: practice session events are lost; abandoned exam sessions keep their status).
+
+DROP INDEX IF EXISTS idx_exam_sessions_active_last_heartbeat_at;
+DROP INDEX IF EXISTS idx_practice_sessions_active_timed_started_at;
+
+DROP TABLE IF EXISTS practice_session_events;
+
+ALTER TABLE exam_sessions DROP COLUMN IF EXISTS abandoned_at;
+COMMENT ON COLUMN exam_sessions.status IS 'check (status in (''active'',''submitted'',''terminated'',''invalidated''))';
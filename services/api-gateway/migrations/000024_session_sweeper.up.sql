@@ -0,0 +1,37 @@
+-- 000024_session_sweeper.up.sql
+-- Purpose: Support the background session sweeper (abandoned exam sessions, practice session events, sweep indexes).
+-- Risk: low (new nullable column, new table, partial indexes).
+-- Reversible: yes (drops table, column and indexes).
+
+ALTER TABLE exam_sessions ADD COLUMN IF NOT EXISTS abandoned_at timestamp;
+COMMENT ON COLUMN exam_sessions.status IS 'check (status in (''active'',''finished'',''terminated'',''invalid'',''abandoned''))';
+
+CREATE TABLE IF NOT EXISTS practice_session_events (
+  id bigserial PRIMARY KEY,
+  session_id text NOT NULL,
+  user_id text NOT NULL,
+  event_type text NOT NULL,
+  payload json,
+  created_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_practice_session_events_session_id') THEN
+    ALTER TABLE practice_session_events
+      ADD CONSTRAINT fk_practice_session_events_session_id
+      FOREIGN KEY (session_id) REFERENCES practice_sessions(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_practice_session_events_user_id') THEN
+    ALTER TABLE practice_session_events
+      ADD CONSTRAINT fk_practice_session_events_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_practice_session_events_session_id ON practice_session_events (session_id, created_at);
+
+-- The sweeper only looks at sessions that are still running.
+CREATE INDEX IF NOT EXISTS idx_practice_sessions_active_timed_started_at ON practice_sessions (started_at) WHERE status = 'active' AND is_timed;
+CREATE INDEX IF NOT EXISTS idx_exam_sessions_active_last_heartbeat_at ON exam_sessions (last_heartbeat_at) WHERE status = 'active';
@@ -0,0 +1,12 @@
+-- 000025_free_navigation.down.sql
+-- Purpose: Drop free-navigation practice sessions.
+-- Risk: fast.
+-- Reversible: yes (destructive: draft answers are deleted; free sessions read as linear).
+
+DROP INDEX IF EXISTS idx_practice_answers_session_id_question_id_ts;
+
+DELETE FROM practice_answers WHERE NOT is_final;
+ALTER TABLE practice_answers DROP COLUMN IF EXISTS is_final;
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS marked_question_ids;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS navigation;
@@ -0,0 +1,14 @@
+-- 000025_free_navigation.up.sql
+-- Purpose: Free-navigation practice sessions (jump, mark for review, change answers, explicit submit) and answer-change history.
+-- Risk: low (new columns with defaults; existing answers stay final).
+-- Reversible: yes (down deletes draft answers and drops the columns).
+
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS navigation text NOT NULL DEFAULT 'linear';
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS marked_question_ids json NOT NULL DEFAULT '[]';
+COMMENT ON COLUMN practice_sessions.navigation IS 'check (navigation in (''linear'',''free''))';
+
+-- Free-navigation sessions save every answer change as a draft row; submit
+-- marks the latest answer per question final. Graded reads use final rows.
+ALTER TABLE practice_answers ADD COLUMN IF NOT EXISTS is_final boolean NOT NULL DEFAULT true;
+
+CREATE INDEX IF NOT EXISTS idx_practice_answers_session_id_question_id_ts ON practice_answers (session_id, question_id, ts);
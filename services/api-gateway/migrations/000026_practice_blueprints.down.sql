@@ -0,0 +1,8 @@
+-- 000026_practice_blueprints.down.sql
+-- Purpose: Drop multi-section practice blueprints.
+-- Risk: fast.
+-- Reversible: yes (destructive: blueprint sections are lost).
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS section_started_at;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS sections;
+ALTER TABLE practice_templates DROP COLUMN IF EXISTS sections;
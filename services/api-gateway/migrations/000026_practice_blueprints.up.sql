@@ -0,0 +1,11 @@
+-- 000026_practice_blueprints.up.sql
+-- Purpose: Multi-section practice blueprints (sections with quotas, time limits and ordering) and section boundaries on sessions.
+-- Risk: low (new nullable columns).
+-- Reversible: yes (drops columns; blueprint templates and sessions lose their sections).
+
+-- A template with sections is a blueprint; target_count mirrors the blueprint total.
+ALTER TABLE practice_templates ADD COLUMN IF NOT EXISTS sections json;
+
+-- Section boundaries of blueprint sessions and when the current section started.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS sections json;
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS section_started_at timestamp;
@@ -0,0 +1,7 @@
+-- 000027_timing_policies.down.sql
+-- Purpose: Drop timing policies.
+-- Risk: fast.
+-- Reversible: yes (destructive: timing policies are lost; running sessions fall back to the default pace).
+
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS timing;
+ALTER TABLE practice_templates DROP COLUMN IF EXISTS timing;
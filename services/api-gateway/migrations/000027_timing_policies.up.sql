@@ -0,0 +1,10 @@
+-- 000027_timing_policies.up.sql
+-- Purpose: Configurable timing policies on practice templates and the resolved timing of timed practice sessions.
+-- Risk: low (new nullable columns; sessions without timing keep the 60 seconds per question pace).
+-- Reversible: yes (drops columns).
+
+-- Policy written by instructors: totalSeconds, secondsPerQuestion, difficultySeconds, autoAdvance, graceSeconds.
+ALTER TABLE practice_templates ADD COLUMN IF NOT EXISTS timing json;
+
+-- Snapshot resolved at session start: graceSeconds and per-question seconds and hard limits.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS timing json;
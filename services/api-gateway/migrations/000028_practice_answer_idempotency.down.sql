@@ -0,0 +1,6 @@
+-- 000028_practice_answer_idempotency.down.sql
+-- Purpose: Drop stored answer responses.
+-- Risk: fast.
+-- Reversible: yes (destructive: retried requests are no longer recognised).
+
+DROP TABLE IF EXISTS practice_answer_requests;
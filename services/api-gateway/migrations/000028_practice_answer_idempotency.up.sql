@@ -0,0 +1,26 @@
+-- 000028_practice_answer_idempotency.up.sql
+-- Purpose: Idempotent practice answer submission (Idempotency-Key replay).
+-- Risk: low (new table).
+-- Reversible: yes (drops table).
+
+-- The response to each accepted answer request that carried an
+-- Idempotency-Key, written in the transaction that recorded the answer, so a
+-- retried request gets the same response back.
+CREATE TABLE IF NOT EXISTS practice_answer_requests (
+  session_id text NOT NULL,
+  idempotency_key text NOT NULL,
+  question_id text NOT NULL,
+  choice_id text NOT NULL,
+  response json NOT NULL,
+  created_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (session_id, idempotency_key)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_practice_answer_requests_session_id') THEN
+    ALTER TABLE practice_answer_requests
+      ADD CONSTRAINT fk_practice_answer_requests_session_id
+      FOREIGN KEY (session_id) REFERENCES practice_sessions(id) ON DELETE CASCADE;
+  END IF;
+END $$;
@@ -0,0 +1,8 @@
+-- 000029_offline_answer_sync.down.sql
+-- Purpose: Drop offline sync columns.
+-- Risk: fast.
+-- Reversible: yes (destructive: synced answers lose their client sequence numbers and timestamps).
+
+DROP INDEX IF EXISTS idx_practice_answers_session_id_client_seq_unique;
+ALTER TABLE practice_answers DROP COLUMN IF EXISTS client_ts;
+ALTER TABLE practice_answers DROP COLUMN IF EXISTS client_seq;
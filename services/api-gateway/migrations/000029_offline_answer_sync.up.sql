@@ -0,0 +1,13 @@
+-- 000029_offline_answer_sync.up.sql
+-- Purpose: Offline answer sync: client sequence numbers and timestamps on practice answers.
+-- Risk: low (new nullable columns; the unique index only covers synced answers, which are new).
+-- Reversible: yes (drops index and columns).
+
+-- Answers synced from a client's offline queue carry the client's sequence
+-- number and clock; a sequence number already applied to a session is not
+-- applied again.
+ALTER TABLE practice_answers ADD COLUMN IF NOT EXISTS client_seq bigint;
+ALTER TABLE practice_answers ADD COLUMN IF NOT EXISTS client_ts timestamp;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_practice_answers_session_id_client_seq_unique
+  ON practice_answers (session_id, client_seq) WHERE client_seq IS NOT NULL;
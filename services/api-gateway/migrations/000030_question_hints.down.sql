@@ -0,0 +1,10 @@
+-- 000030_question_hints.down.sql
+-- Purpose: Drop question hints and hint usage.
+-- Risk: fast.
+-- Reversible: yes (destructive: authored hints and hint usage are lost).
+
+ALTER TABLE practice_answers DROP COLUMN IF EXISTS credit;
+ALTER TABLE practice_answers DROP COLUMN IF EXISTS hints_used;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS hint_penalty;
+ALTER TABLE practice_sessions DROP COLUMN IF EXISTS hints_used;
+DROP TABLE IF EXISTS question_bank_hints;
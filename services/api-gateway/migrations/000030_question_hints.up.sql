@@ -0,0 +1,35 @@
+-- 000030_question_hints.up.sql
+-- Purpose: Progressive question hints and hint usage in practice sessions.
+-- Risk: low (new table; new columns are nullable or have defaults).
+-- Reversible: yes (drops table and columns).
+
+-- Hints are revealed one at a time, in order_index order.
+CREATE TABLE IF NOT EXISTS question_bank_hints (
+  id text PRIMARY KEY,
+  question_id text NOT NULL,
+  order_index integer NOT NULL,
+  text text NOT NULL,
+  cloned_from_id text
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_question_bank_hints_question_id') THEN
+    ALTER TABLE question_bank_hints
+      ADD CONSTRAINT fk_question_bank_hints_question_id
+      FOREIGN KEY (question_id) REFERENCES question_bank_questions(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_bank_hints_question_id_order_index_unique
+  ON question_bank_hints (question_id, order_index);
+
+-- Hints revealed per question ({questionId: count}) and the credit a correct
+-- answer loses per hint, fixed when the session starts.
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS hints_used json NOT NULL DEFAULT '{}';
+ALTER TABLE practice_sessions ADD COLUMN IF NOT EXISTS hint_penalty double precision NOT NULL DEFAULT 0.25;
+
+-- Hints revealed before the answer, and the credit the answer earned (NULL
+-- for drafts and answers recorded before hints).
+ALTER TABLE practice_answers ADD COLUMN IF NOT EXISTS hints_used integer NOT NULL DEFAULT 0;
+ALTER TABLE practice_answers ADD COLUMN IF NOT EXISTS credit double precision;
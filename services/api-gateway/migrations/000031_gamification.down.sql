@@ -0,0 +1,10 @@
+-- 000031_gamification.down.sql
+-- Purpose: Drop the coin ledger, streaks and achievements.
+-- Risk: fast.
+-- Reversible: yes (destructive: coin balances, streaks and earned achievements are lost).
+
+DROP TABLE IF EXISTS user_streaks;
+DROP TABLE IF EXISTS user_achievements;
+DROP TABLE IF EXISTS achievements;
+DROP TABLE IF EXISTS coin_ledger;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
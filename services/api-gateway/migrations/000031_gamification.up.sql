@@ -0,0 +1,101 @@
+-- 000031_gamification.up.sql
+-- Purpose: Server-side coin ledger, daily streaks and data-driven achievements.
+-- Risk: low (new tables; users.timezone is nullable).
+-- Reversible: yes (drops tables and column).
+
+-- IANA timezone used to decide which calendar day a study event counts for.
+-- NULL means UTC.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone text;
+
+-- Append-only: rows are never updated or deleted. award_key identifies the
+-- award (e.g. practice:<sessionId>, streak:<date>) so replays pay once.
+CREATE TABLE IF NOT EXISTS coin_ledger (
+  id text PRIMARY KEY,
+  user_id text NOT NULL,
+  amount integer NOT NULL,
+  reason text NOT NULL,
+  source_id text,
+  award_key text NOT NULL,
+  created_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_coin_ledger_user_id') THEN
+    ALTER TABLE coin_ledger
+      ADD CONSTRAINT fk_coin_ledger_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coin_ledger_user_id_award_key_unique
+  ON coin_ledger (user_id, award_key);
+CREATE INDEX IF NOT EXISTS idx_coin_ledger_user_id_created_at
+  ON coin_ledger (user_id, created_at);
+
+-- rule: {"metric": "...", "threshold": N, "minQuestions": N}
+CREATE TABLE IF NOT EXISTS achievements (
+  id text PRIMARY KEY,
+  code text NOT NULL,
+  name text NOT NULL,
+  description text,
+  rule json NOT NULL,
+  coins integer NOT NULL DEFAULT 0,
+  is_active boolean NOT NULL DEFAULT true,
+  sort_order integer NOT NULL DEFAULT 0,
+  created_at timestamp NOT NULL DEFAULT now(),
+  updated_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_achievements_code_unique ON achievements (code);
+
+CREATE TABLE IF NOT EXISTS user_achievements (
+  user_id text NOT NULL,
+  achievement_id text NOT NULL,
+  earned_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, achievement_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_achievements_user_id') THEN
+    ALTER TABLE user_achievements
+      ADD CONSTRAINT fk_user_achievements_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_achievements_achievement_id') THEN
+    ALTER TABLE user_achievements
+      ADD CONSTRAINT fk_user_achievements_achievement_id
+      FOREIGN KEY (achievement_id) REFERENCES achievements(id);
+  END IF;
+END $$;
+
+-- last_active_date is a calendar day in the user's timezone.
+CREATE TABLE IF NOT EXISTS user_streaks (
+  user_id text PRIMARY KEY,
+  current_days integer NOT NULL DEFAULT 0,
+  longest_days integer NOT NULL DEFAULT 0,
+  last_active_date date,
+  updated_at timestamp NOT NULL DEFAULT now()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_user_streaks_user_id') THEN
+    ALTER TABLE user_streaks
+      ADD CONSTRAINT fk_user_streaks_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+INSERT INTO achievements (id, code, name, description, rule, coins, sort_order)
+VALUES
+  ('ach_first_practice', 'first_practice', 'First steps', 'Finish your first practice session.', '{"metric":"practiceSessionsCompleted","threshold":1}', 10, 10),
+  ('ach_practice_10', 'practice_10', 'Regular', 'Finish 10 practice sessions.', '{"metric":"practiceSessionsCompleted","threshold":10}', 50, 20),
+  ('ach_first_exam', 'first_exam', 'Exam ready', 'Submit your first exam.', '{"metric":"examsSubmitted","threshold":1}', 25, 30),
+  ('ach_streak_7', 'streak_7', 'One week streak', 'Study 7 days in a row.', '{"metric":"streakDays","threshold":7}', 30, 40),
+  ('ach_streak_30', 'streak_30', 'One month streak', 'Study 30 days in a row.', '{"metric":"streakDays","threshold":30}', 100, 50),
+  ('ach_accuracy_80', 'accuracy_80', 'Sharp', 'Score at least 80% in a practice session of 10 or more questions.', '{"metric":"sessionAccuracy","threshold":80,"minQuestions":10}', 10, 60),
+  ('ach_accuracy_90', 'accuracy_90', 'Sharper', 'Score at least 90% in a practice session of 10 or more questions.', '{"metric":"sessionAccuracy","threshold":90,"minQuestions":10}', 20, 70),
+  ('ach_accuracy_100', 'accuracy_100', 'Flawless', 'Answer every question right in a practice session of 10 or more questions.', '{"metric":"sessionAccuracy","threshold":100,"minQuestions":10}', 40, 80)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,9 @@
+-- 000032_leaderboards.down.sql
+-- Purpose: Drop leaderboards.
+-- Risk: fast.
+-- Reversible: yes (destructive: display names and opt-outs are lost; boards can be rebuilt from sessions).
+
+DROP TABLE IF EXISTS leaderboard_scores;
+DROP TABLE IF EXISTS leaderboard_results;
+ALTER TABLE users DROP COLUMN IF EXISTS leaderboard_name;
+ALTER TABLE users DROP COLUMN IF EXISTS leaderboard_opt_out;
@@ -0,0 +1,100 @@
+-- 000032_leaderboards.up.sql
+-- Purpose: Incrementally maintained leaderboards with opt-out and display names.
+-- Risk: medium (backfills from finished practice sessions and submitted exams).
+-- Reversible: yes (drops tables and columns).
+
+-- Students can leave every board and choose the name shown on them (NULL
+-- shows a stable pseudonym).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS leaderboard_opt_out boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS leaderboard_name text;
+
+-- One row per counted practice session or exam, so a result is added to the
+-- boards once. source is practice or exam.
+CREATE TABLE IF NOT EXISTS leaderboard_results (
+  user_id text NOT NULL,
+  source text NOT NULL,
+  source_id text NOT NULL,
+  exam_package_id text,
+  template_id text,
+  score double precision NOT NULL,
+  duration_seconds integer NOT NULL,
+  finished_at timestamp NOT NULL,
+  created_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, source, source_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_leaderboard_results_user_id') THEN
+    ALTER TABLE leaderboard_results
+      ADD CONSTRAINT fk_leaderboard_results_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+-- Running totals per board and student. scope_type is global (scope_id ''),
+-- package or template; period is week, month or all, starting at
+-- period_start (UTC Monday, first of the month, or 1970-01-01).
+CREATE TABLE IF NOT EXISTS leaderboard_scores (
+  scope_type text NOT NULL,
+  scope_id text NOT NULL,
+  period text NOT NULL,
+  period_start date NOT NULL,
+  user_id text NOT NULL,
+  score double precision NOT NULL DEFAULT 0,
+  duration_seconds bigint NOT NULL DEFAULT 0,
+  sessions integer NOT NULL DEFAULT 0,
+  exams integer NOT NULL DEFAULT 0,
+  updated_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (scope_type, scope_id, period, period_start, user_id)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_leaderboard_scores_user_id') THEN
+    ALTER TABLE leaderboard_scores
+      ADD CONSTRAINT fk_leaderboard_scores_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_leaderboard_scores_board_rank
+  ON leaderboard_scores (scope_type, scope_id, period, period_start, score DESC, duration_seconds);
+
+-- Backfill: practice sessions with graded answers score their credit
+-- (answers recorded before hints count 1 when correct); exams count without
+-- points.
+INSERT INTO leaderboard_results (user_id, source, source_id, exam_package_id, template_id, score, duration_seconds, finished_at)
+SELECT s.user_id, 'practice', s.id, s.package_id::text, s.template_id::text, a.score,
+  coalesce((SELECT sum(greatest(t.value::integer, 0)) FROM json_each_text(CASE WHEN json_typeof(s.question_timings) = 'object' THEN s.question_timings ELSE '{}' END) t), 0),
+  coalesce(s.last_activity_at, s.created_at)
+FROM practice_sessions s
+JOIN (
+  SELECT session_id, sum(coalesce(credit, CASE WHEN correct THEN 1 ELSE 0 END)) AS score
+  FROM practice_answers WHERE is_final GROUP BY session_id
+) a ON a.session_id = s.id
+WHERE s.status = 'finished'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO leaderboard_results (user_id, source, source_id, exam_package_id, template_id, score, duration_seconds, finished_at)
+SELECT user_id, 'exam', id, exam_package_id::text, NULL, 0,
+  greatest(extract(epoch FROM submitted_at - created_at), 0)::integer, submitted_at
+FROM exam_sessions
+WHERE status = 'finished' AND submitted_at IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO leaderboard_scores (scope_type, scope_id, period, period_start, user_id, score, duration_seconds, sessions, exams)
+SELECT sc.scope_type, sc.scope_id, w.period,
+  CASE w.period
+    WHEN 'week' THEN date_trunc('week', r.finished_at)::date
+    WHEN 'month' THEN date_trunc('month', r.finished_at)::date
+    ELSE DATE '1970-01-01'
+  END,
+  r.user_id, sum(r.score), sum(r.duration_seconds),
+  count(*) FILTER (WHERE r.source = 'practice'), count(*) FILTER (WHERE r.source = 'exam')
+FROM leaderboard_results r
+CROSS JOIN LATERAL (VALUES ('global', ''), ('package', r.exam_package_id), ('template', r.template_id)) AS sc(scope_type, scope_id)
+CROSS JOIN (VALUES ('week'), ('month'), ('all')) AS w(period)
+WHERE sc.scope_id IS NOT NULL
+GROUP BY 1, 2, 3, 4, 5
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- 000033_cohorts.down.sql
+-- Purpose: Drop cohorts.
+-- Risk: fast.
+-- Reversible: yes (destructive: cohorts and their members are lost; leaderboard totals keep practice-only time).
+
+DROP TABLE IF EXISTS cohort_members;
+DROP TABLE IF EXISTS cohorts;
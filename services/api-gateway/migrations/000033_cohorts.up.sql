@@ -0,0 +1,63 @@
+-- 000033_cohorts.up.sql
+-- Purpose: Cohorts (instructor-managed groups of students) for cohort leaderboards; exam time leaves the leaderboard tiebreak.
+-- Risk: medium (rebuilds leaderboard_scores from leaderboard_results).
+-- Reversible: yes (drops tables; destructive).
+
+CREATE TABLE IF NOT EXISTS cohorts (
+  id text PRIMARY KEY,
+  name text NOT NULL,
+  created_by_user_id text,
+  created_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS cohort_members (
+  cohort_id text NOT NULL,
+  user_id text NOT NULL,
+  added_by_user_id text,
+  created_at timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY (cohort_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cohort_members_user_id ON cohort_members (user_id);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_cohorts_created_by_user_id') THEN
+    ALTER TABLE cohorts
+      ADD CONSTRAINT fk_cohorts_created_by_user_id
+      FOREIGN KEY (created_by_user_id) REFERENCES users(id);
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_cohort_members_cohort_id') THEN
+    ALTER TABLE cohort_members
+      ADD CONSTRAINT fk_cohort_members_cohort_id
+      FOREIGN KEY (cohort_id) REFERENCES cohorts(id) ON DELETE CASCADE;
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname='fk_cohort_members_user_id') THEN
+    ALTER TABLE cohort_members
+      ADD CONSTRAINT fk_cohort_members_user_id
+      FOREIGN KEY (user_id) REFERENCES users(id);
+  END IF;
+END $$;
+
+-- Exams earn no points, so their time no longer breaks ties: only practice
+-- time counts. leaderboard_results keeps every duration, so the totals are
+-- rebuilt from it.
+DELETE FROM leaderboard_scores;
+
+INSERT INTO leaderboard_scores (scope_type, scope_id, period, period_start, user_id, score, duration_seconds, sessions, exams)
+SELECT sc.scope_type, sc.scope_id, w.period,
+  CASE w.period
+    WHEN 'week' THEN date_trunc('week', r.finished_at)::date
+    WHEN 'month' THEN date_trunc('month', r.finished_at)::date
+    ELSE DATE '1970-01-01'
+  END,
+  r.user_id, sum(r.score), coalesce(sum(r.duration_seconds) FILTER (WHERE r.source = 'practice'), 0),
+  count(*) FILTER (WHERE r.source = 'practice'), count(*) FILTER (WHERE r.source = 'exam')
+FROM leaderboard_results r
+CROSS JOIN LATERAL (VALUES ('global', ''), ('package', r.exam_package_id), ('template', r.template_id)) AS sc(scope_type, scope_id)
+CROSS JOIN (VALUES ('week'), ('month'), ('all')) AS w(period)
+WHERE sc.scope_id IS NOT NULL
+GROUP BY 1, 2, 3, 4, 5
+ON CONFLICT DO NOTHING;